@@ -8,6 +8,65 @@ const docTemplate = `{
     "schemes": {{ marshal .Schemes }},
     "components": {
         "schemas": {
+            "admin.MergeInput": {
+                "properties": {
+                    "sourceUid": {
+                        "example": "dup-user-456",
+                        "maxLength": 128,
+                        "type": "string"
+                    },
+                    "targetUid": {
+                        "example": "user-123",
+                        "maxLength": 128,
+                        "type": "string"
+                    }
+                },
+                "required": [
+                    "sourceUid",
+                    "targetUid"
+                ],
+                "type": "object"
+            },
+            "capabilities.Capabilities": {
+                "properties": {
+                    "maxBodyBytes": {
+                        "example": 1048576,
+                        "type": "integer"
+                    },
+                    "pagination": {
+                        "$ref": "#/components/schemas/capabilities.Pagination"
+                    },
+                    "responseMediaTypes": {
+                        "example": [
+                            "application/json",
+                            "application/cbor"
+                        ],
+                        "items": {
+                            "type": "string"
+                        },
+                        "type": "array",
+                        "uniqueItems": false
+                    }
+                },
+                "type": "object"
+            },
+            "capabilities.Pagination": {
+                "properties": {
+                    "defaultLimit": {
+                        "example": 20,
+                        "type": "integer"
+                    },
+                    "maxLimit": {
+                        "example": 100,
+                        "type": "integer"
+                    },
+                    "style": {
+                        "example": "cursor",
+                        "type": "string"
+                    }
+                },
+                "type": "object"
+            },
             "hello.CreateInput": {
                 "properties": {
                     "name": {
@@ -31,6 +90,34 @@ const docTemplate = `{
                 },
                 "type": "object"
             },
+            "identity.Identity": {
+                "properties": {
+                    "claims": {
+                        "additionalProperties": {},
+                        "type": "object"
+                    },
+                    "email": {
+                        "example": "john@example.com",
+                        "type": "string"
+                    },
+                    "emailVerified": {
+                        "example": true,
+                        "type": "boolean"
+                    },
+                    "roles": {
+                        "items": {
+                            "type": "string"
+                        },
+                        "type": "array",
+                        "uniqueItems": false
+                    },
+                    "uid": {
+                        "example": "user-123",
+                        "type": "string"
+                    }
+                },
+                "type": "object"
+            },
             "internal_http_v1_profile.Profile": {
                 "properties": {
                     "createdAt": {
@@ -72,6 +159,91 @@ const docTemplate = `{
                 },
                 "type": "object"
             },
+            "items.BatchCreateData": {
+                "properties": {
+                    "created": {
+                        "example": 2,
+                        "type": "integer"
+                    },
+                    "results": {
+                        "items": {
+                            "$ref": "#/components/schemas/items.BatchItemResult"
+                        },
+                        "type": "array",
+                        "uniqueItems": false
+                    }
+                },
+                "type": "object"
+            },
+            "items.BatchCreateInput": {
+                "properties": {
+                    "items": {
+                        "items": {
+                            "$ref": "#/components/schemas/items.CreateInput"
+                        },
+                        "maxItems": 100,
+                        "minItems": 1,
+                        "type": "array",
+                        "uniqueItems": false
+                    }
+                },
+                "required": [
+                    "items"
+                ],
+                "type": "object"
+            },
+            "items.BatchItemResult": {
+                "properties": {
+                    "index": {
+                        "example": 0,
+                        "type": "integer"
+                    },
+                    "item": {
+                        "$ref": "#/components/schemas/items.Item"
+                    },
+                    "status": {
+                        "example": "created",
+                        "type": "string"
+                    }
+                },
+                "type": "object"
+            },
+            "items.CreateInput": {
+                "properties": {
+                    "category": {
+                        "enum": [
+                            "electronics",
+                            "tools",
+                            "accessories",
+                            "robotics",
+                            "power",
+                            "components"
+                        ],
+                        "type": "string"
+                    },
+                    "description": {
+                        "maxLength": 500,
+                        "type": "string"
+                    },
+                    "inStock": {
+                        "type": "boolean"
+                    },
+                    "name": {
+                        "maxLength": 100,
+                        "minLength": 1,
+                        "type": "string"
+                    },
+                    "price": {
+                        "minimum": 0,
+                        "type": "number"
+                    }
+                },
+                "required": [
+                    "category",
+                    "name"
+                ],
+                "type": "object"
+            },
             "items.Item": {
                 "properties": {
                     "category": {
@@ -107,6 +279,10 @@ const docTemplate = `{
             },
             "items.ListData": {
                 "properties": {
+                    "hasMore": {
+                        "example": true,
+                        "type": "boolean"
+                    },
                     "items": {
                         "items": {
                             "$ref": "#/components/schemas/items.Item"
@@ -156,10 +332,23 @@ const docTemplate = `{
                     "email",
                     "firstname",
                     "lastname",
-                    "phoneNumber"
+                    "phoneNumber",
+                    "terms"
                 ],
                 "type": "object"
             },
+            "profile.Export": {
+                "properties": {
+                    "exportedAt": {
+                        "example": "2024-01-15T10:30:00.000Z",
+                        "type": "string"
+                    },
+                    "profile": {
+                        "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                    }
+                },
+                "type": "object"
+            },
             "profile.UpdateInput": {
                 "properties": {
                     "email": {
@@ -206,8 +395,44 @@ const docTemplate = `{
                 },
                 "type": "object"
             },
+            "respond.ItemError": {
+                "properties": {
+                    "detail": {
+                        "example": "item already exists",
+                        "type": "string"
+                    },
+                    "errors": {
+                        "items": {
+                            "$ref": "#/components/schemas/respond.ErrorDetail"
+                        },
+                        "type": "array",
+                        "uniqueItems": false
+                    },
+                    "index": {
+                        "example": 2,
+                        "type": "integer"
+                    },
+                    "status": {
+                        "example": 409,
+                        "type": "integer"
+                    }
+                },
+                "type": "object"
+            },
             "respond.ProblemDetails": {
                 "properties": {
+                    "category": {
+                        "description": "Category lists the type code of a TypedError and its ancestors, most\ngeneral first.",
+                        "example": [
+                            "validation",
+                            "validation/email"
+                        ],
+                        "items": {
+                            "type": "string"
+                        },
+                        "type": "array",
+                        "uniqueItems": false
+                    },
                     "detail": {
                         "example": "resource not found",
                         "type": "string"
@@ -219,6 +444,14 @@ const docTemplate = `{
                         "type": "array",
                         "uniqueItems": false
                     },
+                    "failures": {
+                        "description": "Failures lists the failed items of a batch operation; see MultiError.",
+                        "items": {
+                            "$ref": "#/components/schemas/respond.ItemError"
+                        },
+                        "type": "array",
+                        "uniqueItems": false
+                    },
                     "instance": {
                         "example": "/v1/items/42",
                         "type": "string"
@@ -257,6 +490,78 @@ const docTemplate = `{
         "url": ""
     },
     "paths": {
+        "/auth/me": {
+            "get": {
+                "description": "Returns the UID, email, roles, and claims of the caller's token for debugging.\nThe token itself is never returned.",
+                "responses": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/identity.Identity"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/identity.Identity"
+                                }
+                            }
+                        },
+                        "description": "OK"
+                    },
+                    "401": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unauthorized"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Current identity",
+                "tags": [
+                    "auth"
+                ]
+            }
+        },
+        "/capabilities": {
+            "get": {
+                "description": "Returns the response media types content negotiation selects from, the pagination style,\nand the request body limit, so clients need not discover them by trial and error.",
+                "responses": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/capabilities.Capabilities"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/capabilities.Capabilities"
+                                }
+                            }
+                        },
+                        "description": "OK"
+                    }
+                },
+                "summary": "Server capabilities",
+                "tags": [
+                    "capabilities"
+                ]
+            }
+        },
         "/hello": {
             "get": {
                 "description": "Returns a hello greeting",
@@ -284,6 +589,16 @@ const docTemplate = `{
             },
             "post": {
                 "description": "Creates a personalized greeting",
+                "parameters": [
+                    {
+                        "description": "return=minimal to omit the body",
+                        "in": "header",
+                        "name": "Prefer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
                 "requestBody": {
                     "content": {
                         "application/json": {
@@ -311,7 +626,15 @@ const docTemplate = `{
                                 }
                             }
                         },
-                        "description": "Created"
+                        "description": "Created",
+                        "headers": {
+                            "Preference-Applied": {
+                                "description": "return=minimal when the body was omitted",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
                     },
                     "400": {
                         "content": {
@@ -352,7 +675,7 @@ const docTemplate = `{
         },
         "/items": {
             "get": {
-                "description": "Returns a paginated list of items with optional category filtering",
+                "description": "Returns a paginated list of items with optional category filtering.\nAccept: application/x-ndjson streams all matching items one per line, ignoring cursor and limit.\nCategories are electronics, tools, accessories, robotics, power, and components.\nUnknown categories return 422, or an empty list on deployments with lenient categories.",
                 "parameters": [
                     {
                         "description": "Pagination cursor",
@@ -363,12 +686,11 @@ const docTemplate = `{
                         }
                     },
                     {
-                        "description": "Items per page",
+                        "description": "Items per page; 0 selects the default of 20 and values above 100 are clamped",
                         "in": "query",
                         "name": "limit",
                         "schema": {
-                            "maximum": 100,
-                            "minimum": 1,
+                            "minimum": 0,
                             "type": "integer"
                         }
                     },
@@ -377,14 +699,6 @@ const docTemplate = `{
                         "in": "query",
                         "name": "category",
                         "schema": {
-                            "enum": [
-                                "electronics",
-                                "tools",
-                                "accessories",
-                                "robotics",
-                                "power",
-                                "components"
-                            ],
                             "type": "string"
                         }
                     }
@@ -401,12 +715,17 @@ const docTemplate = `{
                                 "schema": {
                                     "$ref": "#/components/schemas/items.ListData"
                                 }
+                            },
+                            "application/x-ndjson": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/items.ListData"
+                                }
                             }
                         },
                         "description": "OK",
                         "headers": {
                             "Link": {
-                                "description": "RFC 8288 pagination links",
+                                "description": "RFC 8288 pagination, self, and describedby links",
                                 "schema": {
                                     "type": "string"
                                 }
@@ -424,6 +743,11 @@ const docTemplate = `{
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
                                 }
+                            },
+                            "application/x-ndjson": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
                             }
                         },
                         "description": "Bad Request"
@@ -439,6 +763,11 @@ const docTemplate = `{
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
                                 }
+                            },
+                            "application/x-ndjson": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
                             }
                         },
                         "description": "Unprocessable Entity"
@@ -450,15 +779,854 @@ const docTemplate = `{
                 ]
             }
         },
-        "/profile": {
-            "delete": {
-                "description": "Deletes the authenticated user's profile",
+        "/items/{id}": {
+            "get": {
+                "description": "Returns a single item by ID.",
+                "parameters": [
+                    {
+                        "description": "Item ID",
+                        "in": "path",
+                        "name": "id",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "401": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/items.Item"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/items.Item"
+                                }
+                            }
+                        },
+                        "description": "OK",
+                        "headers": {
+                            "Link": {
+                                "description": "RFC 8288 self and describedby links",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unprocessable Entity"
+                    }
+                },
+                "summary": "Get item",
+                "tags": [
+                    "items"
+                ]
+            }
+        },
+        "/items:batchCreate": {
+            "post": {
+                "description": "Creates up to 100 items atomically: either every item is created or none is.\nValidation errors are reported per item with locations such as items[2].name.",
+                "requestBody": {
+                    "content": {
+                        "application/json": {
+                            "schema": {
+                                "$ref": "#/components/schemas/items.BatchCreateInput",
+                                "summary": "body",
+                                "description": "Items to create"
+                            }
+                        }
+                    },
+                    "description": "Items to create",
+                    "required": true
+                },
+                "responses": {
+                    "201": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/items.BatchCreateData"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/items.BatchCreateData"
+                                }
+                            }
+                        },
+                        "description": "Created"
+                    },
+                    "400": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Bad Request"
+                    },
+                    "422": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unprocessable Entity"
+                    }
+                },
+                "summary": "Batch create items",
+                "tags": [
+                    "items"
+                ]
+            }
+        },
+        "/profile": {
+            "delete": {
+                "description": "Deletes the authenticated user's profile. With If-Match, deletes only if the ETag still matches.",
+                "parameters": [
+                    {
+                        "description": "ETag from a previous response",
+                        "in": "header",
+                        "name": "If-Match",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Not Found"
+                    },
+                    "412": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Delete profile",
+                "tags": [
+                    "profile"
+                ]
+            },
+            "get": {
+                "description": "Returns the authenticated user's profile\nDuring a storage outage a recently cached copy is served with a Warning header.\nAccept: text/vcard returns the profile as a vCard 4.0 contact for import into address books.",
+                "responses": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "text/vcard": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            }
+                        },
+                        "description": "OK",
+                        "headers": {
+                            "Age": {
+                                "description": "Seconds since a stale copy was cached",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "ETag": {
+                                "description": "Entity tag for conditional requests",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "Warning": {
+                                "description": "110 when the profile is a stale cached copy",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "401": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "text/vcard": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "text/vcard": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "text/vcard": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "text/vcard": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Get profile",
+                "tags": [
+                    "profile"
+                ]
+            },
+            "head": {
+                "description": "Returns 200 when the authenticated user has a profile and 404 otherwise, without a body",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Check profile existence",
+                "tags": [
+                    "profile"
+                ]
+            },
+            "patch": {
+                "description": "Partially updates the authenticated user's profile. Dry runs preview the result without persisting.\nWith Content-Type application/json-patch+json the body is an RFC 6902 patch: add and replace\nset top-level fields and remove clears phoneNumber. Other operations and immutable fields yield 422.",
+                "parameters": [
+                    {
+                        "description": "Validate and preview without persisting",
+                        "in": "query",
+                        "name": "dryRun",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    {
+                        "description": "dry-run to preview without persisting; return=minimal to omit the body",
+                        "in": "header",
+                        "name": "Prefer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "requestBody": {
+                    "content": {
+                        "application/json": {
+                            "schema": {
+                                "$ref": "#/components/schemas/profile.UpdateInput",
+                                "summary": "body",
+                                "description": "Profile update request body"
+                            }
+                        }
+                    },
+                    "description": "Profile update request body",
+                    "required": true
+                },
+                "responses": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            }
+                        },
+                        "description": "OK",
+                        "headers": {
+                            "Preference-Applied": {
+                                "description": "dry-run or return=minimal when applied",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Update profile",
+                "tags": [
+                    "profile"
+                ]
+            },
+            "post": {
+                "description": "Creates a new user profile. Dry runs return the normalized profile with 200 without persisting.",
+                "parameters": [
+                    {
+                        "description": "Validate and preview without persisting",
+                        "in": "query",
+                        "name": "dryRun",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    {
+                        "description": "dry-run to preview without persisting; return=minimal to omit the body",
+                        "in": "header",
+                        "name": "Prefer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "requestBody": {
+                    "content": {
+                        "application/json": {
+                            "schema": {
+                                "$ref": "#/components/schemas/profile.CreateInput",
+                                "summary": "body",
+                                "description": "Profile creation request body"
+                            }
+                        }
+                    },
+                    "description": "Profile creation request body",
+                    "required": true
+                },
+                "responses": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            }
+                        },
+                        "description": "OK",
+                        "headers": {
+                            "Preference-Applied": {
+                                "description": "dry-run when nothing was persisted",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "201": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            }
+                        },
+                        "description": "Created",
+                        "headers": {
+                            "ETag": {
+                                "description": "Entity tag of the created profile",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "Location": {
+                                "description": "URI of the created profile",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "Preference-Applied": {
+                                "description": "return=minimal when the body was omitted",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
+                    "409": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Conflict"
+                    },
+                    "422": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Create profile",
+                "tags": [
+                    "profile"
+                ]
+            },
+            "put": {
+                "description": "Creates the authenticated user's profile or replaces all of its fields, keeping its creation time",
+                "parameters": [
+                    {
+                        "description": "return=minimal to omit the body",
+                        "in": "header",
+                        "name": "Prefer",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "requestBody": {
+                    "content": {
+                        "application/json": {
+                            "schema": {
+                                "$ref": "#/components/schemas/profile.CreateInput",
+                                "summary": "body",
+                                "description": "Complete profile"
+                            }
+                        }
+                    },
+                    "description": "Complete profile",
+                    "required": true
+                },
+                "responses": {
+                    "200": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            }
+                        },
+                        "description": "OK",
+                        "headers": {
+                            "ETag": {
+                                "description": "Entity tag of the stored profile",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "Preference-Applied": {
+                                "description": "return=minimal when the body was omitted",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "201": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                }
+                            }
+                        },
+                        "description": "Created",
+                        "headers": {
+                            "ETag": {
+                                "description": "Entity tag of the stored profile",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "Location": {
+                                "description": "URI of the created profile",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            },
+                            "Preference-Applied": {
+                                "description": "return=minimal when the body was omitted",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Bad Request"
+                    },
+                    "401": {
                         "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
                             "application/json": {
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
@@ -467,18 +1635,43 @@ const docTemplate = `{
                         },
                         "description": "Unauthorized"
                     },
-                    "404": {
+                    "403": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
+                    "422": {
                         "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
                             "application/json": {
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
                                 }
                             }
                         },
-                        "description": "Not Found"
+                        "description": "Unprocessable Entity"
                     },
                     "500": {
                         "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
                             "application/json": {
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
@@ -493,28 +1686,43 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "summary": "Delete profile",
+                "summary": "Create or replace profile",
                 "tags": [
                     "profile"
                 ]
-            },
+            }
+        },
+        "/profile/export": {
             "get": {
-                "description": "Returns the authenticated user's profile",
+                "description": "Returns a downloadable copy of the authenticated user's data, encoded as JSON or as CBOR when the Accept header prefers it",
                 "responses": {
                     "200": {
                         "content": {
                             "application/cbor": {
                                 "schema": {
-                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                    "$ref": "#/components/schemas/profile.Export"
                                 }
                             },
                             "application/json": {
                                 "schema": {
-                                    "$ref": "#/components/schemas/internal_http_v1_profile.Profile"
+                                    "$ref": "#/components/schemas/profile.Export"
+                                }
+                            },
+                            "application/octet-stream": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/profile.Export"
                                 }
                             }
                         },
-                        "description": "OK"
+                        "description": "OK",
+                        "headers": {
+                            "Content-Disposition": {
+                                "description": "attachment; filename=profile-export.json or profile-export.cbor",
+                                "schema": {
+                                    "type": "string"
+                                }
+                            }
+                        }
                     },
                     "401": {
                         "content": {
@@ -527,10 +1735,35 @@ const docTemplate = `{
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
                                 }
+                            },
+                            "application/octet-stream": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
                             }
                         },
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/octet-stream": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
                     "404": {
                         "content": {
                             "application/cbor": {
@@ -542,6 +1775,11 @@ const docTemplate = `{
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
                                 }
+                            },
+                            "application/octet-stream": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
                             }
                         },
                         "description": "Not Found"
@@ -557,6 +1795,11 @@ const docTemplate = `{
                                 "schema": {
                                     "$ref": "#/components/schemas/respond.ProblemDetails"
                                 }
+                            },
+                            "application/octet-stream": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
                             }
                         },
                         "description": "Internal Server Error"
@@ -567,24 +1810,26 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "summary": "Get profile",
+                "summary": "Export profile",
                 "tags": [
                     "profile"
                 ]
-            },
-            "patch": {
-                "description": "Partially updates the authenticated user's profile",
+            }
+        },
+        "/profile:validate": {
+            "post": {
+                "description": "Validates and normalizes profile input like Create without reading or writing the store.\nReturns the profile Create would store for a user without one.",
                 "requestBody": {
                     "content": {
                         "application/json": {
                             "schema": {
-                                "$ref": "#/components/schemas/profile.UpdateInput",
+                                "$ref": "#/components/schemas/profile.CreateInput",
                                 "summary": "body",
-                                "description": "Profile update request body"
+                                "description": "Profile input to validate"
                             }
                         }
                     },
-                    "description": "Profile update request body",
+                    "description": "Profile input to validate",
                     "required": true
                 },
                 "responses": {
@@ -633,7 +1878,7 @@ const docTemplate = `{
                         },
                         "description": "Unauthorized"
                     },
-                    "404": {
+                    "403": {
                         "content": {
                             "application/cbor": {
                                 "schema": {
@@ -646,7 +1891,7 @@ const docTemplate = `{
                                 }
                             }
                         },
-                        "description": "Not Found"
+                        "description": "Forbidden"
                     },
                     "422": {
                         "content": {
@@ -684,28 +1929,117 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "summary": "Update profile",
+                "summary": "Validate profile",
                 "tags": [
                     "profile"
                 ]
-            },
+            }
+        },
+        "/profiles/{uid}:revoke": {
+            "post": {
+                "description": "Revokes every refresh token of the user so a compromised account is locked out immediately.\nRequires the admin role.",
+                "parameters": [
+                    {
+                        "description": "Target user ID",
+                        "in": "path",
+                        "name": "uid",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Not Found"
+                    },
+                    "422": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "content": {
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "summary": "Revoke user sessions",
+                "tags": [
+                    "admin"
+                ]
+            }
+        },
+        "/profiles:merge": {
             "post": {
-                "description": "Creates a new user profile",
+                "description": "Merges the source profile into the target when one user ends up with two accounts.\nTarget fields win unless empty. The source is archived and removed. Requires the admin role.",
                 "requestBody": {
                     "content": {
                         "application/json": {
                             "schema": {
-                                "$ref": "#/components/schemas/profile.CreateInput",
-                                "summary": "body",
-                                "description": "Profile creation request body"
+                                "oneOf": [
+                                    {
+                                        "type": "object"
+                                    },
+                                    {
+                                        "$ref": "#/components/schemas/admin.MergeInput",
+                                        "summary": "body",
+                                        "description": "Profiles to merge"
+                                    }
+                                ]
                             }
                         }
                     },
-                    "description": "Profile creation request body",
+                    "description": "Profiles to merge",
                     "required": true
                 },
                 "responses": {
-                    "201": {
+                    "200": {
                         "content": {
                             "application/cbor": {
                                 "schema": {
@@ -718,15 +2052,7 @@ const docTemplate = `{
                                 }
                             }
                         },
-                        "description": "Created",
-                        "headers": {
-                            "Location": {
-                                "description": "URI of the created profile",
-                                "schema": {
-                                    "type": "string"
-                                }
-                            }
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "content": {
@@ -758,7 +2084,7 @@ const docTemplate = `{
                         },
                         "description": "Unauthorized"
                     },
-                    "409": {
+                    "403": {
                         "content": {
                             "application/cbor": {
                                 "schema": {
@@ -771,7 +2097,22 @@ const docTemplate = `{
                                 }
                             }
                         },
-                        "description": "Conflict"
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "content": {
+                            "application/cbor": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            },
+                            "application/json": {
+                                "schema": {
+                                    "$ref": "#/components/schemas/respond.ProblemDetails"
+                                }
+                            }
+                        },
+                        "description": "Not Found"
                     },
                     "422": {
                         "content": {
@@ -809,9 +2150,9 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "summary": "Create profile",
+                "summary": "Merge duplicate profiles",
                 "tags": [
-                    "profile"
+                    "admin"
                 ]
             }
         }
@@ -4,9 +4,10 @@ import (
 	"context"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
-	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,12 +17,19 @@ import (
 
 	"github.com/janisto/echo-playground/internal/http/docs"
 	"github.com/janisto/echo-playground/internal/http/health"
+	"github.com/janisto/echo-playground/internal/http/v1/items"
 	"github.com/janisto/echo-playground/internal/http/v1/routes"
 	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/budget"
+	"github.com/janisto/echo-playground/internal/platform/config"
 	"github.com/janisto/echo-playground/internal/platform/firebase"
 	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/metrics"
 	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
+	"github.com/janisto/echo-playground/internal/platform/openapi"
+	"github.com/janisto/echo-playground/internal/platform/pagination"
 	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/timing"
 	"github.com/janisto/echo-playground/internal/platform/validate"
 	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
 )
@@ -38,21 +46,23 @@ import (
 // Version can be overridden at build time: -ldflags "-X main.Version=1.2.3"
 var Version = "dev"
 
+// logFlushTimeout bounds how long shutdown waits for buffered logs to drain.
+const logFlushTimeout = 5 * time.Second
+
 func main() {
 	ctx := context.Background()
 
-	firebaseProjectID := os.Getenv("FIREBASE_PROJECT_ID")
-	if firebaseProjectID == "" {
-		if os.Getenv("APP_ENVIRONMENT") == "development" {
-			firebaseProjectID = "demo-test-project"
-			applog.LogWarn(ctx, "using demo-test-project for local development")
-		} else {
-			applog.LogFatal(ctx, "FIREBASE_PROJECT_ID environment variable is required", nil)
-		}
+	cfg, err := config.Load()
+	if err != nil {
+		applog.LogFatal(ctx, "configuration error", err)
+	}
+	applog.SetLevel(cfg.LogLevel)
+	for _, warning := range cfg.Warnings {
+		applog.LogWarn(ctx, warning)
 	}
 
 	firebaseClients, err := firebase.InitializeClients(ctx, firebase.Config{
-		ProjectID: firebaseProjectID,
+		ProjectID: cfg.FirebaseProjectID,
 	})
 	if err != nil {
 		applog.LogFatal(ctx, "firebase init failed", err)
@@ -63,43 +73,132 @@ func main() {
 		}
 	}()
 
-	verifier := auth.NewFirebaseVerifier(firebaseClients.Auth)
-	profileService := profilesvc.NewFirestoreStore(firebaseClients.Firestore)
+	verifier := auth.NewBreakerVerifier(auth.NewFirebaseVerifier(firebaseClients.Auth), auth.BreakerConfig{})
+
+	var authSchemes []auth.Scheme
+	if cfg.AuthCookie != "" {
+		authSchemes = append(authSchemes, auth.CookieScheme(verifier, cfg.AuthCookie))
+	}
+	if cfg.APIKeys != nil {
+		authSchemes = append(authSchemes, auth.APIKeyScheme(cfg.APIKeys))
+	}
+	pagination.SetSigningKey(cfg.CursorSigningKey)
+
+	var storeOpts []profilesvc.FirestoreOption
+	if cfg.PIIKeyring != nil {
+		storeOpts = append(storeOpts, profilesvc.WithFieldEncryption(cfg.PIIKeyring))
+	}
+	profileStore := profilesvc.NewFirestoreStore(firebaseClients.Firestore, storeOpts...)
+	profileService := profilesvc.NewCachedService(
+		profilesvc.NewRetryService(profileStore, profilesvc.RetryConfig{}),
+		profilesvc.CacheConfig{},
+	)
 
 	e := echo.New()
 	e.Validator = validate.New()
 	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
-	e.IPExtractor = echo.ExtractIPFromRealIPHeader()
+	clientIPConfig := appmiddleware.ClientIPConfig{
+		TrustedProxies:   cfg.TrustedProxies,
+		RequestIDHeaders: cfg.RequestIDHeaders,
+	}
+	e.IPExtractor = appmiddleware.ClientIPExtractor(clientIPConfig)
 	e.Logger = applog.Logger()
 
+	if len(cfg.AllowedHosts) > 0 {
+		e.Pre(appmiddleware.AllowedHosts(appmiddleware.AllowedHostsConfig{
+			Hosts:          cfg.AllowedHosts,
+			AllowLocalhost: cfg.Development(),
+			ExemptPaths:    []string{"/health", "/health/ready"},
+		}))
+	}
+	e.Pre(
+		appmiddleware.ProxyHeaders(clientIPConfig),
+		appmiddleware.HSTS(appmiddleware.HSTSConfig{
+			MaxAge:            cfg.HSTSMaxAge,
+			IncludeSubDomains: true,
+			RedirectHTTP:      cfg.Production(),
+			RedirectHosts:     cfg.AllowedHosts,
+		}),
+		appmiddleware.TrailingSlash(appmiddleware.TrailingSlashStrip),
+	)
+	skipHealth := func(c *echo.Context) bool { return strings.HasPrefix(c.Request().URL.Path, "/health") }
+	// Probes, metrics scrapes, and NDJSON streams must not be cut off by the
+	// request deadline.
+	skipTimeout := func(c *echo.Context) bool {
+		return skipHealth(c) || c.Request().URL.Path == "/metrics" ||
+			respond.PrefersNDJSON(c.Request().Header.Get("Accept"))
+	}
+
 	e.Use(
-		appmiddleware.Security("/api-docs"),
+		appmiddleware.SecurityWithConfig(appmiddleware.SecurityConfig{
+			SkipPaths: []string{"/api-docs"},
+			Server:    cfg.ServerHeader,
+		}),
 		appmiddleware.Vary(),
 		appmiddleware.CORS(),
-		appmiddleware.RequestID(),
-		middleware.BodyLimit(1<<20),
+		appmiddleware.RequestIDWithConfig(appmiddleware.RequestIDConfig{
+			Generator:  cfg.RequestIDGenerator,
+			Headers:    cfg.RequestIDHeaders,
+			EchoSource: cfg.RequestIDEchoSource,
+		}),
+		appmiddleware.UniqueHeaders(appmiddleware.UniqueHeadersConfig{}),
+		middleware.BodyLimit(appmiddleware.MaxBodyBytes),
+		appmiddleware.DecompressBody(appmiddleware.MaxBodyBytes),
 		applog.RequestLogger(),
-		applog.AccessLogger(),
-		respond.Recoverer(),
+		applog.AccessLoggerWithConfig(applog.AccessLogConfig{SlowThreshold: cfg.SlowRequestThreshold}),
+		appmiddleware.ValidUTF8(),
+		appmiddleware.RateLimit(appmiddleware.RateLimitConfig{
+			Rate:    cfg.RateLimit,
+			Burst:   cfg.RateLimitBurst,
+			Skipper: func(c *echo.Context) bool { return cfg.RateLimit == 0 || skipHealth(c) },
+		}),
+		appmiddleware.ConcurrencyLimit(appmiddleware.ConcurrencyLimitConfig{Skipper: skipHealth}),
+		appmiddleware.RequestTimeout(appmiddleware.RequestTimeoutConfig{Skipper: skipTimeout}),
+		budget.RequestBudget(cfg.StoreBudget),
+		timing.ServerTiming(),
+		respond.FormatNegotiationWithConfig(respond.FormatNegotiationConfig{ExposeDecision: cfg.ExposeNegotiation}),
+		respond.AcceptCharset(),
+		respond.VersionNegotiation(),
+		respond.RecovererWithConfig(respond.RecovererConfig{UserID: auth.UserIDFromContext}),
 	)
 
+	if cfg.AbsoluteLocation {
+		e.Use(respond.Locations(respond.LocationConfig{Absolute: true}))
+	}
+
+	if cfg.OpenAPIValidation {
+		spec, err := openapi.Load("api-docs/swagger.json")
+		if err != nil {
+			applog.LogFatal(ctx, "failed to load OpenAPI spec", err)
+		}
+		e.Use(openapi.Middleware(spec))
+	}
+
 	e.GET("/health", health.Handler)
+	e.GET("/health/ready", health.ReadyHandler(profileStore.Ready))
+	if cfg.MetricsEnabled {
+		recorder := metrics.NewMemory()
+		metrics.SetRecorder(recorder)
+		e.GET("/metrics", echo.WrapHandler(recorder))
+	}
 	docs.Register(e, "api-docs/swagger.json")
 
-	v1 := e.Group("/v1")
-	routes.Register(v1, verifier, profileService)
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	var itemOpts []items.Option
+	if cfg.LenientCategories {
+		itemOpts = append(itemOpts, items.WithLenientCategories())
 	}
 
+	v1 := e.Group("/v1", appmiddleware.NoBody(appmiddleware.NoBodyConfig{}))
+	adminService := auth.NewAdminService(firebaseClients.Auth)
+	routes.Register(v1, verifier, profileService, adminService, itemOpts, authSchemes...)
+
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
 	applog.LogInfo(ctx, "server starting",
-		slog.String("addr", ":"+port),
+		slog.String("addr", addr),
 		slog.String("version", Version))
 
 	sc := echo.StartConfig{
-		Address:         ":" + port,
+		Address:         addr,
 		GracefulTimeout: 10 * time.Second,
 		BeforeServeFunc: func(s *http.Server) error {
 			s.ReadTimeout = 5 * time.Second
@@ -119,4 +218,10 @@ func main() {
 	}
 
 	applog.LogInfo(ctx, "server exited")
+
+	flushCtx, flushCancel := context.WithTimeout(ctx, logFlushTimeout)
+	defer flushCancel()
+	if err := applog.Flush(flushCtx); err != nil {
+		log.Printf("log flush failed: %v", err)
+	}
 }
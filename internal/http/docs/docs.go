@@ -1,24 +1,96 @@
 package docs
 
 import (
+	"bytes"
+	"crypto/rand"
 	_ "embed"
+	"errors"
+	"html/template"
+	"io/fs"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v5"
 )
 
 //go:embed swagger-ui.html
-var swaggerUI []byte
+var swaggerUIHTML string
+
+var swaggerUI = template.Must(template.New("swagger-ui").Parse(swaggerUIHTML))
+
+// NoncePlaceholder is replaced with the per-response nonce in the
+// Content-Security-Policy served with the Swagger UI page.
+const NoncePlaceholder = "{nonce}"
+
+// DefaultContentSecurityPolicy allows the Swagger UI assets loaded from
+// unpkg.com and the nonce-tagged inline bootstrap script. Swagger UI sets
+// inline styles and data: images, and fetches the spec from this origin.
+const DefaultContentSecurityPolicy = "default-src 'none'; " +
+	"script-src 'nonce-" + NoncePlaceholder + "' https://unpkg.com; " +
+	"style-src 'unsafe-inline' https://unpkg.com; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"base-uri 'none'; " +
+	"form-action 'none'; " +
+	"frame-ancestors 'none'"
+
+// SpecRoute is the route serving the OpenAPI spec, the target of
+// rel="describedby" links.
+const SpecRoute = "/api-docs/openapi.json"
+
+// YAMLSpecRoute serves the OpenAPI spec converted to YAML.
+const YAMLSpecRoute = "/api-docs/openapi.yaml"
+
+type options struct {
+	csp string
+}
+
+// Option configures Register.
+type Option func(*options)
+
+// WithContentSecurityPolicy replaces DefaultContentSecurityPolicy for the
+// Swagger UI page. NoncePlaceholder in policy is replaced with the nonce of
+// each response.
+func WithContentSecurityPolicy(policy string) Option {
+	return func(o *options) {
+		o.csp = policy
+	}
+}
 
 // Register wires documentation routes.
 // - GET /api-docs/openapi.json serves the generated OpenAPI 3.1 spec.
-// - GET /api-docs serves an embedded Swagger UI page.
-func Register(e *echo.Echo, specPath string) {
-	e.GET("/api-docs/openapi.json", func(c *echo.Context) error {
+// - GET /api-docs/openapi.yaml serves the same spec converted to YAML once at
+// registration.
+// - GET /api-docs serves an embedded Swagger UI page with a
+// Content-Security-Policy whose nonce is fresh for every response.
+func Register(e *echo.Echo, specPath string, opts ...Option) {
+	o := options{csp: DefaultContentSecurityPolicy}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	e.GET(SpecRoute, func(c *echo.Context) error {
 		return c.File(specPath)
 	})
-
+	yamlSpec, yamlErr := loadYAMLSpec(specPath)
+	e.GET(YAMLSpecRoute, func(c *echo.Context) error {
+		if errors.Is(yamlErr, fs.ErrNotExist) {
+			return echo.ErrNotFound
+		}
+		if yamlErr != nil {
+			return yamlErr
+		}
+		return c.Blob(http.StatusOK, MIMEApplicationYAML, yamlSpec)
+	})
 	e.GET("/api-docs", func(c *echo.Context) error {
-		return c.HTMLBlob(http.StatusOK, swaggerUI)
+		nonce := rand.Text()
+		var buf bytes.Buffer
+		if err := swaggerUI.Execute(&buf, struct{ Nonce string }{nonce}); err != nil {
+			return err
+		}
+		h := c.Response().Header()
+		h.Set("Content-Security-Policy", strings.ReplaceAll(o.csp, NoncePlaceholder, nonce))
+		h.Set("Cache-Control", "no-store")
+		return c.HTMLBlob(http.StatusOK, buf.Bytes())
 	})
 }
@@ -63,3 +63,61 @@ func TestRegister_OpenAPISpec(t *testing.T) {
 		t.Fatal("expected response to contain openapi spec content")
 	}
 }
+
+func TestRegister_SwaggerUIContentSecurityPolicy(t *testing.T) {
+	e := echo.New()
+	Register(e, "testdata/swagger.json")
+
+	nonceOf := func() (string, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api-docs", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		csp := rec.Header().Get("Content-Security-Policy")
+		if csp == "" {
+			t.Fatal("expected Content-Security-Policy header")
+		}
+		_, rest, ok := strings.Cut(csp, "'nonce-")
+		if !ok {
+			t.Fatalf("expected nonce source in CSP, got %q", csp)
+		}
+		nonce, _, _ := strings.Cut(rest, "'")
+		if nonce == "" {
+			t.Fatalf("expected non-empty nonce in CSP, got %q", csp)
+		}
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Fatalf("expected Cache-Control no-store, got %q", got)
+		}
+		return nonce, rec.Body.String()
+	}
+
+	nonce, body := nonceOf()
+	if !strings.Contains(body, `<script nonce="`+nonce+`">`) {
+		t.Fatalf("expected inline script tagged with nonce %q, got %s", nonce, body)
+	}
+	if strings.Count(body, `nonce="`+nonce+`"`) != strings.Count(body, "<script") {
+		t.Fatal("expected every script tag to carry the nonce")
+	}
+	if next, _ := nonceOf(); next == nonce {
+		t.Fatal("expected a fresh nonce per response")
+	}
+}
+
+func TestRegister_CustomContentSecurityPolicy(t *testing.T) {
+	e := echo.New()
+	Register(e, "testdata/swagger.json",
+		WithContentSecurityPolicy("script-src 'nonce-"+NoncePlaceholder+"' https://cdn.example.com"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api-docs", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	if !strings.HasPrefix(csp, "script-src 'nonce-") || !strings.HasSuffix(csp, "' https://cdn.example.com") {
+		t.Fatalf("expected custom policy, got %q", csp)
+	}
+	if strings.Contains(csp, NoncePlaceholder) {
+		t.Fatalf("expected placeholder to be replaced, got %q", csp)
+	}
+}
@@ -0,0 +1,19 @@
+package docs
+
+import (
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// MIMEApplicationYAML is the media type of YAML documents (RFC 9512).
+const MIMEApplicationYAML = "application/yaml"
+
+// loadYAMLSpec reads the JSON spec at path and converts it to YAML.
+func loadYAMLSpec(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
@@ -0,0 +1,66 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRegister_OpenAPISpecYAML(t *testing.T) {
+	const specPath = "../../../api-docs/swagger.json"
+	e := echo.New()
+	Register(e, specPath)
+
+	req := httptest.NewRequest(http.MethodGet, YAMLSpecRoute, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != MIMEApplicationYAML {
+		t.Fatalf("expected %s content type, got %q", MIMEApplicationYAML, ct)
+	}
+	if !bytes.HasPrefix(rec.Body.Bytes(), []byte("components:")) {
+		t.Fatalf("expected block-style YAML, got %.80q", rec.Body.String())
+	}
+
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		t.Fatalf("failed to read spec: %v", err)
+	}
+	var want, got any
+	if err = json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("failed to parse JSON spec: %v", err)
+	}
+	converted, err := yaml.YAMLToJSON(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("failed to parse YAML spec: %v", err)
+	}
+	if err = json.Unmarshal(converted, &got); err != nil {
+		t.Fatalf("failed to decode converted spec: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatal("expected the YAML spec to parse into the same structure as the JSON spec")
+	}
+}
+
+func TestRegister_OpenAPISpecYAMLMissing(t *testing.T) {
+	e := echo.New()
+	Register(e, "testdata/missing.json")
+
+	req := httptest.NewRequest(http.MethodGet, YAMLSpecRoute, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without a spec, got %d", rec.Code)
+	}
+}
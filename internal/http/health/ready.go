@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+// readyTimeout bounds how long a readiness probe may run.
+const readyTimeout = 5 * time.Second
+
+// Readiness statuses.
+const (
+	StatusReady       = "ready"
+	StatusUnavailable = "unavailable"
+)
+
+// Probe checks that a dependency can serve requests.
+type Probe func(ctx context.Context) error
+
+// ReadyResponse is the payload for the readiness endpoint.
+type ReadyResponse struct {
+	Status string `json:"status" cbor:"status" example:"ready"`
+}
+
+// ReadyHandler returns the readiness endpoint. It responds 503 with status
+// "unavailable" when probe fails.
+func ReadyHandler(probe Probe) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), readyTimeout)
+		defer cancel()
+
+		err := probe(ctx)
+		if err == nil {
+			return respond.Negotiate(c, http.StatusOK, ReadyResponse{Status: StatusReady})
+		}
+
+		applog.LogError(c.Request().Context(), "readiness check failed", err)
+		return respond.Negotiate(c, http.StatusServiceUnavailable, ReadyResponse{Status: StatusUnavailable})
+	}
+}
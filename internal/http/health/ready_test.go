@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func serveReady(t *testing.T, probe Probe) (*httptest.ResponseRecorder, ReadyResponse) {
+	t.Helper()
+	e := echo.New()
+	e.GET("/health/ready", ReadyHandler(probe))
+
+	req := httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var body ReadyResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return rec, body
+}
+
+func TestReadyHandler_Ready(t *testing.T) {
+	rec, body := serveReady(t, func(context.Context) error { return nil })
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body.Status != StatusReady {
+		t.Fatalf("expected status %q, got %q", StatusReady, body.Status)
+	}
+}
+
+func TestReadyHandler_Unavailable(t *testing.T) {
+	rec, body := serveReady(t, func(context.Context) error { return errors.New("connection refused") })
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if body.Status != StatusUnavailable {
+		t.Fatalf("expected plain unavailable status, got %+v", body)
+	}
+}
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	profilehttp "github.com/janisto/echo-playground/internal/http/v1/profile"
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/budget"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
+)
+
+// ProblemTypeSameProfile is the problem type of merges whose source and target
+// are the same profile.
+const ProblemTypeSameProfile = "same-profile"
+
+var _ = respond.MustRegisterProblemType(ProblemTypeSameProfile, http.StatusUnprocessableEntity, "Same Profile")
+
+// Register wires admin routes into the provided authenticated group. Every
+// route requires auth.AdminRole.
+func Register(g *echo.Group, svc *auth.AdminService, profiles profilesvc.Service) {
+	// Echo path parameters extend to the next slash, so the custom method
+	// suffix is split off by the handler rather than matched by the router.
+	g.POST("/profiles/:target", handleRevokeSessions(svc), auth.RequireRole(auth.AdminRole))
+	g.POST(`/profiles\:merge`, handleMergeProfiles(profiles), auth.RequireRole(auth.AdminRole))
+}
+
+// handleRevokeSessions godoc
+//
+//	@Summary		Revoke user sessions
+//	@Description	Revokes every refresh token of the user so a compromised account is locked out immediately.
+//	@Description	Requires the admin role.
+//	@Tags			admin
+//	@Param			uid	path	string	true	"Target user ID"
+//	@Success		204
+//	@Failure		401	{object}	respond.ProblemDetails
+//	@Failure		403	{object}	respond.ProblemDetails
+//	@Failure		404	{object}	respond.ProblemDetails
+//	@Failure		422	{object}	respond.ProblemDetails
+//	@Failure		500	{object}	respond.ProblemDetails
+//	@Security		BearerAuth
+//	@Router			/profiles/{uid}:revoke [post]
+func handleRevokeSessions(svc *auth.AdminService) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		uid, ok := strings.CutSuffix(c.Param("target"), ":revoke")
+		if !ok {
+			return echo.ErrNotFound
+		}
+		input := RevokeInput{UID: uid}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+
+		admin, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
+
+		ctx := c.Request().Context()
+		if err = svc.RevokeSessions(ctx, input.UID); err != nil {
+			applog.LogAuditEvent(ctx, "revoke_sessions", admin.UID, "user", input.UID, "failure", nil)
+			return respond.FromError(ctx, err, revokeErrors...)
+		}
+
+		applog.LogAuditEvent(ctx, "revoke_sessions", admin.UID, "user", input.UID, "success", nil)
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// handleMergeProfiles godoc
+//
+//	@Summary		Merge duplicate profiles
+//	@Description	Merges the source profile into the target when one user ends up with two accounts.
+//	@Description	Target fields win unless empty. The source is archived and removed. Requires the admin role.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json,application/cbor
+//	@Param			body	body		MergeInput	true	"Profiles to merge"
+//	@Success		200		{object}	internal_http_v1_profile.Profile
+//	@Failure		400		{object}	respond.ProblemDetails
+//	@Failure		401		{object}	respond.ProblemDetails
+//	@Failure		403		{object}	respond.ProblemDetails
+//	@Failure		404		{object}	respond.ProblemDetails
+//	@Failure		422		{object}	respond.ProblemDetails
+//	@Failure		500		{object}	respond.ProblemDetails
+//	@Security		BearerAuth
+//	@Router			/profiles:merge [post]
+func handleMergeProfiles(svc profilesvc.Service) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		var input MergeInput
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+
+		admin, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
+
+		ctx := c.Request().Context()
+		details := map[string]any{"target": input.TargetUID}
+		merged, err := svc.Merge(ctx, input.SourceUID, input.TargetUID)
+		if err != nil {
+			applog.LogAuditEvent(ctx, "merge_profiles", admin.UID, "profile", input.SourceUID, "failure", details)
+			return respond.FromError(ctx, err, mergeErrors...)
+		}
+
+		applog.LogAuditEvent(ctx, "merge_profiles", admin.UID, "profile", input.SourceUID, "success", details)
+		return respond.Negotiate(c, http.StatusOK, profilehttp.FromService(merged))
+	}
+}
+
+var mergeErrors = []respond.ErrorMapping{
+	{Err: profilesvc.ErrNotFound, Status: http.StatusNotFound, Detail: "profile not found"},
+	{Err: profilesvc.ErrSameProfile, Type: ProblemTypeSameProfile, Detail: "cannot merge a profile into itself"},
+	{Err: budget.ErrExhausted, Status: http.StatusServiceUnavailable, Detail: "request timed out"},
+}
+
+var revokeErrors = []respond.ErrorMapping{
+	{Err: auth.ErrUserNotFound, Status: http.StatusNotFound, Detail: "user not found"},
+}
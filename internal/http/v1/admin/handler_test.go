@@ -0,0 +1,336 @@
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/validate"
+	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
+)
+
+// fakeAuthClient records the UIDs whose refresh tokens were revoked.
+type fakeAuthClient struct {
+	revoked []string
+}
+
+func (f *fakeAuthClient) RevokeRefreshTokens(_ context.Context, uid string) error {
+	f.revoked = append(f.revoked, uid)
+	return nil
+}
+
+// auditHandler captures audit events written through the request logger.
+type auditHandler struct {
+	mu     sync.Mutex
+	events []map[string]string
+}
+
+func (h *auditHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *auditHandler) Handle(_ context.Context, r slog.Record) error {
+	if r.Message != "Audit event" {
+		return nil
+	}
+	attrs := make(map[string]string)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, attrs)
+	return nil
+}
+
+func (h *auditHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *auditHandler) WithGroup(string) slog.Handler      { return h }
+
+func setupEcho(user *auth.FirebaseUser, client auth.TokenRevoker, audit *auditHandler) *echo.Echo {
+	return setupEchoWithProfiles(user, client, profilesvc.NewMockStore(), audit)
+}
+
+func setupEchoWithProfiles(
+	user *auth.FirebaseUser,
+	client auth.TokenRevoker,
+	profiles profilesvc.Service,
+	audit *auditHandler,
+) *echo.Echo {
+	e := echo.New()
+	e.Validator = validate.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			ctx := applog.ContextWithLogger(c.Request().Context(), slog.New(audit))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	Register(e.Group("", auth.Middleware(&auth.MockVerifier{User: user})), auth.NewAdminService(client), profiles)
+	return e
+}
+
+func adminUser() *auth.FirebaseUser {
+	user := auth.TestUser()
+	user.UID = "admin-1"
+	user.Roles = []string{auth.AdminRole}
+	return user
+}
+
+func postRevoke(e *echo.Echo, uid string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/profiles/"+uid+":revoke", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRevokeSessions(t *testing.T) {
+	client := &fakeAuthClient{}
+	audit := &auditHandler{}
+	e := setupEcho(adminUser(), client, audit)
+
+	rec := postRevoke(e, "compromised-7")
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(client.revoked) != 1 || client.revoked[0] != "compromised-7" {
+		t.Fatalf("expected RevokeRefreshTokens for compromised-7, got %v", client.revoked)
+	}
+	if len(audit.events) != 1 {
+		t.Fatalf("expected one audit event, got %d", len(audit.events))
+	}
+	ev := audit.events[0]
+	if ev["audit.action"] != "revoke_sessions" || ev["audit.result"] != "success" {
+		t.Fatalf("unexpected audit event %v", ev)
+	}
+	if ev["audit.user_id"] != "admin-1" || ev["audit.resource_id"] != "compromised-7" {
+		t.Fatalf("expected admin-1 acting on compromised-7, got %v", ev)
+	}
+}
+
+func TestRevokeSessions_AdminAPIKey(t *testing.T) {
+	sum := sha256.Sum256([]byte("ops-secret"))
+	keys, err := auth.ParseAPIKeys("ops:" + hex.EncodeToString(sum[:]) + ":" + auth.AdminRole)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verifier, err := auth.NewAPIKeyVerifier(keys...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client := &fakeAuthClient{}
+	e := echo.New()
+	e.Validator = validate.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	Register(e.Group("", auth.MiddlewareWithSchemes(auth.APIKeyScheme(verifier))),
+		auth.NewAdminService(client), profilesvc.NewMockStore())
+
+	req := httptest.NewRequest(http.MethodPost, "/profiles/compromised-7:revoke", nil)
+	req.Header.Set(auth.HeaderAPIKey, "ops-secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(client.revoked) != 1 || client.revoked[0] != "compromised-7" {
+		t.Fatalf("expected RevokeRefreshTokens for compromised-7, got %v", client.revoked)
+	}
+}
+
+func TestRevokeSessions_RequiresAdmin(t *testing.T) {
+	client := &fakeAuthClient{}
+	audit := &auditHandler{}
+	e := setupEcho(auth.TestUser(), client, audit)
+
+	rec := postRevoke(e, "compromised-7")
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	if len(client.revoked) != 0 || len(audit.events) != 0 {
+		t.Fatalf("expected no revocation or audit event, got %v and %v", client.revoked, audit.events)
+	}
+}
+
+func TestRevokeSessions_UIDTooLong(t *testing.T) {
+	client := &fakeAuthClient{}
+	e := setupEcho(adminUser(), client, &auditHandler{})
+
+	uid := make([]byte, 129)
+	for i := range uid {
+		uid[i] = 'a'
+	}
+	rec := postRevoke(e, string(uid))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if len(client.revoked) != 0 {
+		t.Fatalf("expected no revocation, got %v", client.revoked)
+	}
+}
+
+func TestRevokeSessions_UnknownAction(t *testing.T) {
+	client := &fakeAuthClient{}
+	e := setupEcho(adminUser(), client, &auditHandler{})
+
+	req := httptest.NewRequest(http.MethodPost, "/profiles/compromised-7", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if len(client.revoked) != 0 {
+		t.Fatalf("expected no revocation, got %v", client.revoked)
+	}
+}
+
+func postMerge(e *echo.Echo, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/profiles:merge", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func newMergeStore(t *testing.T) *profilesvc.MockStore {
+	t.Helper()
+	store := profilesvc.NewMockStore()
+	ctx := context.Background()
+	if _, err := store.Create(ctx, "user-google", profilesvc.CreateParams{
+		Firstname:   "Jane",
+		Lastname:    "Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+358401234567",
+		Terms:       true,
+	}); err != nil {
+		t.Fatalf("Create source failed: %v", err)
+	}
+	if _, err := store.Create(ctx, "user-apple", profilesvc.CreateParams{
+		Firstname: "Janet",
+		Lastname:  "Doe",
+		Email:     "janet@example.com",
+		Marketing: true,
+		Terms:     true,
+	}); err != nil {
+		t.Fatalf("Create target failed: %v", err)
+	}
+	return store
+}
+
+func TestMergeProfiles(t *testing.T) {
+	store := newMergeStore(t)
+	audit := &auditHandler{}
+	e := setupEchoWithProfiles(adminUser(), &fakeAuthClient{}, store, audit)
+
+	rec := postMerge(e, `{"sourceUid":"user-google","targetUid":"user-apple"}`)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var merged map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &merged); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if merged["id"] != "user-apple" || merged["firstname"] != "Janet" || merged["email"] != "janet@example.com" {
+		t.Fatalf("expected target values to win, got %v", merged)
+	}
+	if merged["phoneNumber"] != "+358401234567" || merged["marketing"] != true {
+		t.Fatalf("expected the empty phone number filled from the source, got %v", merged)
+	}
+
+	target, err := store.Get(context.Background(), "user-apple")
+	if err != nil || target.PhoneNumber != "+358401234567" {
+		t.Fatalf("expected the stored target to be merged, got %+v, %v", target, err)
+	}
+	if _, err = store.Get(context.Background(), "user-google"); !errors.Is(err, profilesvc.ErrNotFound) {
+		t.Fatalf("expected the source to be gone, got %v", err)
+	}
+	if archived, ok := store.Merged("user-google"); !ok || archived.MergedInto != "user-apple" {
+		t.Fatalf("expected the source archived as merged into user-apple, got %+v", archived)
+	}
+
+	if len(audit.events) != 1 {
+		t.Fatalf("expected one audit event, got %d", len(audit.events))
+	}
+	ev := audit.events[0]
+	if ev["audit.action"] != "merge_profiles" || ev["audit.result"] != "success" ||
+		ev["audit.user_id"] != "admin-1" || ev["audit.resource_id"] != "user-google" {
+		t.Fatalf("unexpected audit event %v", ev)
+	}
+}
+
+func TestMergeProfiles_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     int
+		wantType string
+	}{
+		{"unknown source", `{"sourceUid":"nobody","targetUid":"user-apple"}`, http.StatusNotFound, "about:blank"},
+		{"unknown target", `{"sourceUid":"user-google","targetUid":"nobody"}`, http.StatusNotFound, "about:blank"},
+		{
+			"same profile", `{"sourceUid":"user-apple","targetUid":"user-apple"}`,
+			http.StatusUnprocessableEntity, respond.ProblemTypeBase + ProblemTypeSameProfile,
+		},
+		{
+			"missing source", `{"targetUid":"user-apple"}`,
+			http.StatusUnprocessableEntity, respond.ProblemTypeBase + respond.ProblemTypeValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMergeStore(t)
+			e := setupEchoWithProfiles(adminUser(), &fakeAuthClient{}, store, &auditHandler{})
+
+			rec := postMerge(e, tt.body)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			var problem respond.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if problem.Type != tt.wantType {
+				t.Fatalf("expected type %q, got %q", tt.wantType, problem.Type)
+			}
+			if _, err := store.Get(context.Background(), "user-google"); err != nil {
+				t.Fatalf("expected the source to remain, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMergeProfiles_RequiresAdmin(t *testing.T) {
+	store := newMergeStore(t)
+	e := setupEchoWithProfiles(auth.TestUser(), &fakeAuthClient{}, store, &auditHandler{})
+
+	rec := postMerge(e, `{"sourceUid":"user-google","targetUid":"user-apple"}`)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", rec.Code)
+	}
+	if _, err := store.Get(context.Background(), "user-google"); err != nil {
+		t.Fatalf("expected the source to remain, got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package admin
+
+// RevokeInput identifies the user whose sessions are revoked. UID is the
+// target path segment without its ":revoke" suffix.
+type RevokeInput struct {
+	UID string `param:"uid" validate:"required,max=128"`
+}
+
+// MergeInput names the duplicate profile merged into the one that is kept.
+type MergeInput struct {
+	SourceUID string `json:"sourceUid" validate:"required,max=128" example:"dup-user-456"`
+	TargetUID string `json:"targetUid" validate:"required,max=128" example:"user-123"`
+}
@@ -0,0 +1,51 @@
+package capabilities
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/pagination"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+// Config describes the request handling configured outside this package.
+type Config struct {
+	// MaxBodyBytes is the request body limit.
+	MaxBodyBytes int64
+}
+
+// Register wires the capabilities route into the provided group.
+func Register(g *echo.Group, cfg Config) {
+	g.GET("/capabilities", handleCapabilities(cfg))
+}
+
+// handleCapabilities godoc
+//
+//	@Summary		Server capabilities
+//	@Description	Returns the response media types content negotiation selects from, the pagination style,
+//	@Description	and the request body limit, so clients need not discover them by trial and error.
+//	@Tags			capabilities
+//	@Produce		json,application/cbor
+//	@Success		200	{object}	Capabilities
+//	@Router			/capabilities [get]
+func handleCapabilities(cfg Config) echo.HandlerFunc {
+	formats := respond.Formats()
+	responseTypes := make([]string, len(formats))
+	for i, f := range formats {
+		responseTypes[i] = f.MediaType()
+	}
+
+	body := Capabilities{
+		ResponseMediaTypes: responseTypes,
+		MaxBodyBytes:       cfg.MaxBodyBytes,
+		Pagination: Pagination{
+			Style:        "cursor",
+			DefaultLimit: pagination.DefaultLimit,
+			MaxLimit:     pagination.MaxLimit,
+		},
+	}
+	return func(c *echo.Context) error {
+		return respond.Negotiate(c, http.StatusOK, body)
+	}
+}
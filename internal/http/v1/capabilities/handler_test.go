@@ -0,0 +1,56 @@
+package capabilities
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/pagination"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+func setupEcho(cfg Config) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	Register(e.Group(""), cfg)
+	return e
+}
+
+func TestCapabilities(t *testing.T) {
+	e := setupEcho(Config{MaxBodyBytes: 1 << 20})
+
+	decoders := map[string]func([]byte, any) error{
+		"application/json": json.Unmarshal,
+		"application/cbor": cbor.Unmarshal,
+	}
+	for accept, unmarshal := range decoders {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+			req.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", rec.Code)
+			}
+			var caps Capabilities
+			if err := unmarshal(rec.Body.Bytes(), &caps); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if !slices.Equal(caps.ResponseMediaTypes, []string{"application/json", "application/cbor"}) {
+				t.Fatalf("expected JSON and CBOR responses, got %v", caps.ResponseMediaTypes)
+			}
+			if caps.MaxBodyBytes != 1<<20 {
+				t.Fatalf("expected max body 1048576, got %d", caps.MaxBodyBytes)
+			}
+			if caps.Pagination.Style != "cursor" || caps.Pagination.MaxLimit != pagination.MaxLimit {
+				t.Fatalf("unexpected pagination %+v", caps.Pagination)
+			}
+		})
+	}
+}
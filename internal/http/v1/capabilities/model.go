@@ -0,0 +1,15 @@
+package capabilities
+
+// Capabilities describes the representations and limits the API supports.
+type Capabilities struct {
+	ResponseMediaTypes []string   `json:"responseMediaTypes" example:"application/json,application/cbor"`
+	MaxBodyBytes       int64      `json:"maxBodyBytes"       example:"1048576"`
+	Pagination         Pagination `json:"pagination"`
+}
+
+// Pagination describes how list endpoints page their results.
+type Pagination struct {
+	Style        string `json:"style"        example:"cursor"`
+	DefaultLimit int    `json:"defaultLimit" example:"20"`
+	MaxLimit     int    `json:"maxLimit"     example:"100"`
+}
@@ -37,9 +37,11 @@ func getHandler(c *echo.Context) error {
 //	@Tags			hello
 //	@Produce		json,application/cbor
 //	@Param			body	body		CreateInput	true	"Greeting request body"
+//	@Param			Prefer	header		string		false	"return=minimal to omit the body"
 //	@Success		201		{object}	Data
 //	@Failure		400		{object}	respond.ProblemDetails
 //	@Failure		422		{object}	respond.ProblemDetails
+//	@Header			201		{string}	Preference-Applied	"return=minimal when the body was omitted"
 //	@Router			/hello [post]
 func createHandler(c *echo.Context) error {
 	var input CreateInput
@@ -55,5 +57,5 @@ func createHandler(c *echo.Context) error {
 		slog.String("name", input.Name))
 
 	data := Data{Message: fmt.Sprintf("Hello, %s!", input.Name)}
-	return respond.Negotiate(c, http.StatusCreated, data)
+	return respond.NegotiateOrMinimal(c, http.StatusCreated, data)
 }
@@ -88,6 +88,26 @@ func TestCreateHello_Success(t *testing.T) {
 	}
 }
 
+func TestCreateHello_ReturnMinimal(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodPost, "/hello", strings.NewReader(`{"name":"Alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Prefer", "return=minimal")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "return=minimal" {
+		t.Fatalf("expected Preference-Applied return=minimal, got %q", got)
+	}
+}
+
 func TestCreateHello_MissingName(t *testing.T) {
 	e := setupEcho()
 
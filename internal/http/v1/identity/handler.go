@@ -0,0 +1,51 @@
+package identity
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+// Register wires identity routes into the provided authenticated group.
+func Register(g *echo.Group) {
+	g.GET("/auth/me", handleMe)
+}
+
+// handleMe godoc
+//
+//	@Summary		Current identity
+//	@Description	Returns the UID, email, roles, and claims of the caller's token for debugging.
+//	@Description	The token itself is never returned.
+//	@Tags			auth
+//	@Produce		json,application/cbor
+//	@Success		200	{object}	Identity
+//	@Failure		401	{object}	respond.ProblemDetails
+//	@Security		BearerAuth
+//	@Router			/auth/me [get]
+func handleMe(c *echo.Context) error {
+	user, err := auth.UserFromEchoContext(c)
+	if err != nil {
+		return respond.Error401("unauthorized")
+	}
+
+	roles := user.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	claims := user.Claims
+	if claims == nil {
+		claims = map[string]any{}
+	}
+
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return respond.Negotiate(c, http.StatusOK, Identity{
+		UID:           user.UID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Roles:         roles,
+		Claims:        claims,
+	})
+}
@@ -0,0 +1,104 @@
+package identity
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+func setupEcho(verifier auth.Verifier) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	Register(e.Group("", auth.Middleware(verifier)))
+	return e
+}
+
+func getMe(e *echo.Echo, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer secret-token-value")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMe(t *testing.T) {
+	user := auth.TestUser()
+	user.Roles = []string{"admin"}
+	user.Claims = map[string]any{"roles": []any{"admin"}, "tenant": "acme"}
+	e := setupEcho(&auth.MockVerifier{User: user})
+
+	rec := getMe(e, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Fatalf("expected Cache-Control no-store, got %q", got)
+	}
+	if strings.Contains(rec.Body.String(), "secret-token-value") {
+		t.Fatal("expected raw token to never be echoed")
+	}
+
+	var got Identity
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if got.UID != user.UID || got.Email != user.Email || !got.EmailVerified {
+		t.Fatalf("unexpected identity %+v", got)
+	}
+	if len(got.Roles) != 1 || got.Roles[0] != "admin" {
+		t.Fatalf("expected roles [admin], got %v", got.Roles)
+	}
+	if got.Claims["tenant"] != "acme" {
+		t.Fatalf("expected tenant claim, got %v", got.Claims)
+	}
+}
+
+func TestMe_NoClaims(t *testing.T) {
+	e := setupEcho(&auth.MockVerifier{User: auth.TestUser()})
+
+	rec := getMe(e, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"roles":[]`) || !strings.Contains(body, `"claims":{}`) {
+		t.Fatalf("expected empty roles and claims, got %s", body)
+	}
+}
+
+func TestMe_CBOR(t *testing.T) {
+	user := auth.TestUser()
+	user.Claims = map[string]any{"tenant": "acme"}
+	e := setupEcho(&auth.MockVerifier{User: user})
+
+	rec := getMe(e, "application/cbor")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got Identity
+	if err := cbor.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal CBOR: %v", err)
+	}
+	if got.UID != user.UID || got.Claims["tenant"] != "acme" {
+		t.Fatalf("unexpected identity %+v", got)
+	}
+}
+
+func TestMe_Unauthenticated(t *testing.T) {
+	e := setupEcho(&auth.MockVerifier{Error: auth.ErrInvalidToken})
+
+	rec := getMe(e, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,10 @@
+package identity
+
+// Identity describes the authenticated caller as decoded from their token.
+type Identity struct {
+	UID           string         `json:"uid"                     example:"user-123"`
+	Email         string         `json:"email,omitempty"         example:"john@example.com"`
+	EmailVerified bool           `json:"emailVerified"           example:"true"`
+	Roles         []string       `json:"roles"`
+	Claims        map[string]any `json:"claims"`
+}
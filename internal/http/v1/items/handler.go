@@ -1,101 +1,245 @@
 package items
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/http/docs"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
 	"github.com/janisto/echo-playground/internal/platform/pagination"
+	"github.com/janisto/echo-playground/internal/platform/query"
 	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/validate"
 )
 
 const cursorType = "item"
 
-// Register wires item routes into the provided group.
-func Register(g *echo.Group) {
-	g.GET("/items", listHandler)
+// options holds per-deployment handler settings.
+type options struct {
+	lenientCategories bool
+}
+
+// Option configures the item handlers.
+type Option func(*options)
+
+// WithLenientCategories makes GET /items answer a well-formed but unknown
+// category with an empty page instead of 422, so clients keep working when
+// they learn about a category before this deployment does.
+func WithLenientCategories() Option {
+	return func(o *options) { o.lenientCategories = true }
+}
+
+// cacheControl lets browsers and CDNs cache successful item reads, which are
+// public and identical for every client.
+const cacheControl = "public, max-age=60"
+
+// Register wires item routes backed by s into the provided group.
+func Register(g *echo.Group, s Store, opts ...Option) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	g.GET("/items", listHandler(s, o),
+		appmiddleware.CacheControl(cacheControl),
+		appmiddleware.CoalesceWithConfig(appmiddleware.CoalesceConfig{Skipper: wantsStream}),
+		query.Pagination(query.PageConfig{CursorType: cursorType, Skipper: wantsStream}),
+	)
+	g.GET("/items/:id", getHandler(s), appmiddleware.CacheControl(cacheControl))
+	g.POST(`/items\:batchCreate`, batchCreateHandler(s))
 }
 
 // listHandler godoc
 //
 //	@Summary		List items
-//	@Description	Returns a paginated list of items with optional category filtering
+//	@Description	Returns a paginated list of items with optional category filtering.
+//	@Description	Accept: application/x-ndjson streams all matching items one per line, ignoring cursor and limit.
+//	@Description	Categories are electronics, tools, accessories, robotics, power, and components.
+//	@Description	Unknown categories return 422, or an empty list on deployments with lenient categories.
 //	@Tags			items
-//	@Produce		json,application/cbor
+//	@Produce		json,application/cbor,application/x-ndjson
 //	@Param			cursor		query		string	false	"Pagination cursor"
-//	@Param			limit		query		int		false	"Items per page"		minimum(1)	maximum(100)
-//	@Param			category	query		string	false	"Filter by category"	Enums(electronics, tools, accessories, robotics, power, components)
+//	@Param			limit		query		int		false	"Items per page; 0 selects the default of 20 and values above 100 are clamped"	minimum(0)
+//	@Param			category	query		string	false	"Filter by category"
 //	@Success		200			{object}	ListData
 //	@Failure		400			{object}	respond.ProblemDetails
 //	@Failure		422			{object}	respond.ProblemDetails
-//	@Header			200			{string}	Link	"RFC 8288 pagination links"
+//	@Header			200			{string}	Link	"RFC 8288 pagination, self, and describedby links"
 //	@Router			/items [get]
-func listHandler(c *echo.Context) error {
-	var input ListInput
-	if err := c.Bind(&input); err != nil {
-		return err
-	}
-	if err := c.Validate(&input); err != nil {
-		return err
-	}
-
-	limit := input.Limit
-	if limit == 0 {
-		limit = pagination.DefaultLimit
-	}
-
-	cursor, err := pagination.DecodeCursor(input.Cursor)
-	if err != nil {
-		return respond.Error400("invalid cursor format")
-	}
-
-	if cursor.Type != "" && cursor.Type != cursorType {
-		return respond.Error400("cursor type mismatch")
-	}
-
-	filtered := filterItems(mockItems, input.Category)
-
-	if cursor.Value != "" && findItemIndex(filtered, cursor.Value) == -1 {
-		return respond.Error400("cursor references unknown item")
+func listHandler(s Store, o options) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		var input ListInput
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+		if input.Category != "" && !slices.Contains(categories, input.Category) && !o.lenientCategories {
+			return &validate.ValidationError{
+				Message: "validation failed",
+				Fields: []validate.FieldError{{
+					Field:   "category",
+					Message: "category must be one of: " + strings.Join(categories, ", "),
+					Value:   input.Category,
+				}},
+			}
+		}
+
+		ctx := c.Request().Context()
+		if input.Category != "" {
+			applog.AddContextAttrs(ctx, slog.String("category", input.Category))
+		}
+		items, err := s.List(ctx, input.Category)
+		if err != nil {
+			return respond.FromError(ctx, err)
+		}
+		if wantsStream(c) {
+			return respond.StreamNDJSON(c, http.StatusOK, slices.Values(items))
+		}
+
+		page, _ := query.Page(c)
+
+		filters := url.Values{}
+		if input.Category != "" {
+			filters.Set("category", input.Category)
+		}
+
+		// Cursors are scoped to the filters in effect when they were issued so
+		// next and prev pages always cover the same filtered set.
+		scopedType := pagination.ScopedType(cursorType, filters)
+		if page.Cursor.Type != "" && page.Cursor.Type != scopedType {
+			return respond.Error400("cursor was issued for different filters")
+		}
+
+		// Cursors are positions in ID order, so a cursor whose item has since
+		// been removed resumes at the next item.
+		result := pagination.PaginateByID(
+			items,
+			page.Cursor,
+			page.Limit,
+			scopedType,
+			itemID,
+			"/v1/items",
+			filters,
+		)
+
+		result.AddLinks(
+			pagination.Link{Target: c.Request().URL.RequestURI(), Rel: pagination.RelSelf},
+			pagination.Link{Target: docs.SpecRoute, Rel: pagination.RelDescribedBy},
+		)
+		c.Response().Header().Set("Link", result.LinkHeader)
+		return respond.Negotiate(c, http.StatusOK, ListData{
+			Items:   result.Items,
+			Total:   result.Total,
+			HasMore: result.HasMore,
+		})
 	}
+}
 
-	query := url.Values{}
-	if input.Category != "" {
-		query.Set("category", input.Category)
+// getHandler godoc
+//
+//	@Summary		Get item
+//	@Description	Returns a single item by ID.
+//	@Tags			items
+//	@Produce		json,application/cbor
+//	@Param			id	path		string	true	"Item ID"
+//	@Success		200	{object}	Item
+//	@Header			200	{string}	Link	"RFC 8288 self and describedby links"
+//	@Failure		404	{object}	respond.ProblemDetails
+//	@Failure		422	{object}	respond.ProblemDetails
+//	@Router			/items/{id} [get]
+func getHandler(s Store) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		var input GetInput
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+
+		ctx := c.Request().Context()
+		item, err := s.Get(ctx, input.ID)
+		if err != nil {
+			return respond.FromError(ctx, err, storeErrors...)
+		}
+		respond.AddSelfLinks(c, docs.SpecRoute)
+		return respond.Negotiate(c, http.StatusOK, item)
 	}
+}
 
-	result := pagination.Paginate(
-		filtered,
-		cursor,
-		limit,
-		cursorType,
-		func(item Item) string { return item.ID },
-		"/v1/items",
-		query,
-	)
-
-	if result.LinkHeader != "" {
-		c.Response().Header().Set("Link", result.LinkHeader)
+// batchCreateHandler godoc
+//
+//	@Summary		Batch create items
+//	@Description	Creates up to 100 items atomically: either every item is created or none is.
+//	@Description	Validation errors are reported per item with locations such as items[2].name.
+//	@Tags			items
+//	@Produce		json,application/cbor
+//	@Param			body	body		BatchCreateInput	true	"Items to create"
+//	@Success		201		{object}	BatchCreateData
+//	@Failure		400		{object}	respond.ProblemDetails
+//	@Failure		422		{object}	respond.ProblemDetails
+//	@Router			/items:batchCreate [post]
+func batchCreateHandler(s Store) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		var input BatchCreateInput
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+
+		var fields []validate.FieldError
+		for i := range input.Items {
+			err := c.Validate(&input.Items[i])
+			if err == nil {
+				continue
+			}
+			var ve *validate.ValidationError
+			if !errors.As(err, &ve) {
+				return err
+			}
+			for _, f := range ve.Fields {
+				f.Field = fmt.Sprintf("items[%d].%s", i, f.Field)
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) > 0 {
+			return &validate.ValidationError{Message: "validation failed", Fields: fields}
+		}
+
+		ctx := c.Request().Context()
+		created, err := s.CreateAll(ctx, input.Items, time.Now().UTC())
+		if err != nil {
+			return respond.FromError(ctx, err)
+		}
+		results := make([]BatchItemResult, len(created))
+		for i, item := range created {
+			results[i] = BatchItemResult{Index: i, Status: "created", Item: item}
+		}
+		return respond.Negotiate(c, http.StatusCreated, BatchCreateData{
+			Created: len(created),
+			Results: results,
+		})
 	}
-	return respond.Negotiate(c, http.StatusOK, ListData{
-		Items: result.Items,
-		Total: result.Total,
-	})
 }
 
-func filterItems(items []Item, category string) []Item {
-	if category == "" {
-		return items
-	}
-	return slices.DeleteFunc(slices.Clone(items), func(item Item) bool {
-		return item.Category != category
-	})
+var storeErrors = []respond.ErrorMapping{
+	{Err: ErrNotFound, Status: http.StatusNotFound, Detail: "item not found"},
 }
 
-func findItemIndex(items []Item, id string) int {
-	return slices.IndexFunc(items, func(item Item) bool {
-		return item.ID == id
-	})
+func itemID(item Item) string { return item.ID }
+
+func wantsStream(c *echo.Context) bool {
+	return respond.PrefersNDJSON(c.Request().Header.Get("Accept"))
 }
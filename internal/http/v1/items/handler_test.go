@@ -1,28 +1,51 @@
 package items
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/http/docs"
 	"github.com/janisto/echo-playground/internal/platform/pagination"
 	"github.com/janisto/echo-playground/internal/platform/respond"
 	"github.com/janisto/echo-playground/internal/platform/validate"
 )
 
 func setupEcho() *echo.Echo {
+	return setupEchoWithStore(NewSampleStore())
+}
+
+func setupEchoWithStore(s Store) *echo.Echo {
 	e := echo.New()
 	e.Validator = validate.New()
 	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
-	Register(e.Group(""))
+	Register(e.Group(""), s)
 	return e
 }
 
+// failingStore fails every operation with err.
+type failingStore struct {
+	err error
+}
+
+func (s failingStore) List(context.Context, string) ([]Item, error) { return nil, s.err }
+
+func (s failingStore) Get(context.Context, string) (Item, error) { return Item{}, s.err }
+
+func (s failingStore) CreateAll(context.Context, []CreateInput, time.Time) ([]Item, error) {
+	return nil, s.err
+}
+
 func TestListItems_DefaultLimit(t *testing.T) {
 	e := setupEcho()
 
@@ -101,6 +124,52 @@ func TestListItems_InvalidCategory(t *testing.T) {
 	if rec.Code != http.StatusUnprocessableEntity {
 		t.Fatalf("expected 422, got %d", rec.Code)
 	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Location != "category" {
+		t.Fatalf("expected a category field error, got %+v", problem.Errors)
+	}
+}
+
+func TestListItems_LenientCategories(t *testing.T) {
+	e := echo.New()
+	e.Validator = validate.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	Register(e.Group(""), NewSampleStore(), WithLenientCategories())
+
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"unknown category", "/items?category=drones", http.StatusOK},
+		{"known category", "/items?category=tools", http.StatusOK},
+		{"malformed category", "/items?category=not-a-category", http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d; body: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?category=drones", nil))
+	if !strings.Contains(rec.Body.String(), `"items":[]`) {
+		t.Fatalf("expected an empty items array, got %s", rec.Body.String())
+	}
+	var data ListData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if data.Total != 0 || data.HasMore || linkTarget(rec.Header().Get("Link"), "next") != "" {
+		t.Fatalf("expected an empty page without a next link, got %+v", data)
+	}
 }
 
 func TestListItems_InvalidCursor(t *testing.T) {
@@ -136,16 +205,13 @@ func TestListItems_CursorTypeMismatch(t *testing.T) {
 	}
 }
 
-func TestListItems_CursorUnknownItem(t *testing.T) {
+func TestListItems_CursorPastLastItem(t *testing.T) {
 	e := setupEcho()
 
-	cursor := pagination.Cursor{Type: cursorType, Value: "nonexistent"}.Encode()
-	req := httptest.NewRequest(http.MethodGet, "/items?cursor="+cursor, nil)
-	rec := httptest.NewRecorder()
-	e.ServeHTTP(rec, req)
-
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("expected 400, got %d", rec.Code)
+	cursor := pagination.Cursor{Type: cursorType, Value: "zzz"}.Encode()
+	data := listPage(t, e, "/items?cursor="+cursor)
+	if len(data.Items) != 0 || data.HasMore {
+		t.Fatalf("expected an empty last page, got %v", pageIDs(data.Items))
 	}
 }
 
@@ -169,13 +235,31 @@ func TestListItems_Pagination(t *testing.T) {
 	}
 }
 
-func TestListItems_LimitTooHigh(t *testing.T) {
+func TestListItems_LimitTooHighIsClamped(t *testing.T) {
 	e := setupEcho()
 
 	req := httptest.NewRequest(http.MethodGet, "/items?limit=101", nil)
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	link := rec.Header().Get("Link")
+	for _, rel := range []string{"next", "prev"} {
+		if target := linkTarget(link, rel); target != "" && !strings.Contains(target, "limit=100") {
+			t.Fatalf("expected %s link to carry the clamped limit, got %q", rel, link)
+		}
+	}
+}
+
+func TestListItems_NegativeLimit(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items?limit=-1", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
 	if rec.Code != http.StatusUnprocessableEntity {
 		t.Fatalf("expected 422, got %d", rec.Code)
 	}
@@ -313,3 +397,552 @@ func TestListItems_FilterCategoryWithPagination(t *testing.T) {
 		}
 	}
 }
+
+func TestListItems_HasMore(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items?limit=5", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var first ListData
+	if err := json.Unmarshal(rec.Body.Bytes(), &first); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if !first.HasMore {
+		t.Fatal("expected hasMore on first page")
+	}
+	if !strings.Contains(rec.Header().Get("Link"), `rel="next"`) {
+		t.Fatal("expected next link when hasMore")
+	}
+
+	lastID := mockItems[len(mockItems)-3].ID
+	cursor := pagination.Cursor{Type: cursorType, Value: lastID}.Encode()
+	req = httptest.NewRequest(http.MethodGet, "/items?limit=5&cursor="+cursor, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var last ListData
+	if err := json.Unmarshal(rec.Body.Bytes(), &last); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if last.HasMore {
+		t.Fatal("expected no hasMore on last page")
+	}
+	if len(last.Items) != 2 {
+		t.Fatalf("expected 2 items on last page, got %d", len(last.Items))
+	}
+	if strings.Contains(rec.Header().Get("Link"), `rel="next"`) {
+		t.Fatal("expected no next link on last page")
+	}
+}
+
+func TestListItems_DepthLimit(t *testing.T) {
+	e := setupEcho()
+
+	target := "/items?limit=1"
+	for page := range pagination.DefaultMaxDepth {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("page %d: expected 200, got %d", page, rec.Code)
+		}
+		link := rec.Header().Get("Link")
+		start, end := strings.Index(link, "</v1"), strings.Index(link, `>; rel="next"`)
+		if start == -1 || end == -1 {
+			t.Fatalf("page %d: expected next link, got %q", page, link)
+		}
+		target = link[start+len("</v1") : end]
+	}
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 beyond max depth, got %d", rec.Code)
+	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if !strings.Contains(problem.Detail, "narrow the filter") {
+		t.Fatalf("expected depth guidance in detail, got %q", problem.Detail)
+	}
+}
+
+// linkTarget returns the request target of the Link header entry with rel,
+// relative to the group the items routes are registered on, or "".
+func linkTarget(link, rel string) string {
+	for entry := range strings.SplitSeq(link, ", ") {
+		if strings.HasSuffix(entry, `; rel="`+rel+`"`) {
+			target, _, _ := strings.Cut(strings.TrimPrefix(entry, "</v1"), ">")
+			return target
+		}
+	}
+	return ""
+}
+
+func TestListItems_FilteredPrevNext(t *testing.T) {
+	e := setupEcho()
+
+	get := func(target string) (ListData, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", target, rec.Code)
+		}
+		var data ListData
+		if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		for _, item := range data.Items {
+			if item.Category != "tools" {
+				t.Fatalf("GET %s: expected only tools, got %s in %q", target, item.ID, item.Category)
+			}
+		}
+		return data, rec.Header().Get("Link")
+	}
+
+	var forward [][]string
+	target := "/items?category=tools&limit=2"
+	for target != "" {
+		data, link := get(target)
+		var ids []string
+		for _, item := range data.Items {
+			ids = append(ids, item.ID)
+		}
+		forward = append(forward, ids)
+		if next := linkTarget(link, "next"); next != "" {
+			if !strings.Contains(next, "category=tools") {
+				t.Fatalf("expected next link to keep the filter, got %q", next)
+			}
+			target = next
+			continue
+		}
+		target = linkTarget(link, "prev")
+		break
+	}
+	if len(forward) < 2 {
+		t.Fatalf("expected several filtered pages, got %d", len(forward))
+	}
+
+	for i := len(forward) - 2; i >= 0; i-- {
+		if !strings.Contains(target, "category=tools") {
+			t.Fatalf("expected prev link to keep the filter, got %q", target)
+		}
+		data, link := get(target)
+		var ids []string
+		for _, item := range data.Items {
+			ids = append(ids, item.ID)
+		}
+		if !slices.Equal(ids, forward[i]) {
+			t.Fatalf("page %d: expected %v going back, got %v", i, forward[i], ids)
+		}
+		target = linkTarget(link, "prev")
+	}
+	if target != "" {
+		t.Fatalf("expected no prev link on the first page, got %q", target)
+	}
+}
+
+func TestListItems_CursorFilterMismatch(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items?category=tools&limit=2", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	next := linkTarget(rec.Header().Get("Link"), "next")
+	if next == "" {
+		t.Fatal("expected next link")
+	}
+	replayed := strings.Replace(next, "category=tools", "category=electronics", 1)
+
+	req = httptest.NewRequest(http.MethodGet, replayed, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for cursor replayed with other filters, got %d", rec.Code)
+	}
+}
+
+func TestListItems_NDJSON(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items?category=electronics&limit=1", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("expected application/x-ndjson, got %q", ct)
+	}
+	if rec.Header().Get("Link") != "" {
+		t.Fatal("expected no Link header for streamed response")
+	}
+
+	want := filterItems(mockItems, "electronics")
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines ignoring limit, got %d", len(want), len(lines))
+	}
+	for i, line := range lines {
+		var item Item
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("line %d: failed to unmarshal %q: %v", i, line, err)
+		}
+		if item.ID != want[i].ID {
+			t.Fatalf("line %d: expected %s, got %s", i, want[i].ID, item.ID)
+		}
+	}
+}
+
+func TestGetItem(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items/item-003", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var item Item
+	if err := json.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if item.ID != "item-003" || item.Name != "Gamma Tool" {
+		t.Fatalf("expected item-003 Gamma Tool, got %+v", item)
+	}
+}
+
+func TestItems_SelfAndDescribedByLinks(t *testing.T) {
+	e := setupEcho()
+
+	for _, target := range []string{"/items?category=tools&limit=2", "/items/item-003"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d", target, rec.Code)
+		}
+		link := rec.Header().Get("Link")
+		if !strings.Contains(link, "<"+target+`>; rel="self"`) {
+			t.Fatalf("GET %s: expected self link to the request, got %q", target, link)
+		}
+		if !strings.Contains(link, "<"+docs.SpecRoute+`>; rel="describedby"`) {
+			t.Fatalf("GET %s: expected describedby link to the spec, got %q", target, link)
+		}
+	}
+}
+
+func TestGetItem_NotFound(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items/item-999", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", ct)
+	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if problem.Detail != "item not found" {
+		t.Fatalf("expected detail %q, got %q", "item not found", problem.Detail)
+	}
+}
+
+func TestGetItem_CBOR(t *testing.T) {
+	e := setupEcho()
+
+	req := httptest.NewRequest(http.MethodGet, "/items/item-010", nil)
+	req.Header.Set("Accept", "application/cbor")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/cbor" {
+		t.Fatalf("expected application/cbor, got %q", ct)
+	}
+	var item Item
+	if err := cbor.Unmarshal(rec.Body.Bytes(), &item); err != nil {
+		t.Fatalf("failed to unmarshal CBOR: %v", err)
+	}
+	if item.ID != "item-010" {
+		t.Fatalf("expected item-010, got %q", item.ID)
+	}
+}
+
+func listTotal(t *testing.T, e *echo.Echo) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var data ListData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	return data.Total
+}
+
+func postBatch(e *echo.Echo, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items:batchCreate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBatchCreate_AllValid(t *testing.T) {
+	e := setupEcho()
+
+	rec := postBatch(e, `{"items":[
+		{"name":"Omega Widget","category":"tools","price":9.5,"inStock":true},
+		{"name":"Omega Cable","category":"accessories","price":3}
+	]}`)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var data BatchCreateData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if data.Created != 2 || len(data.Results) != 2 {
+		t.Fatalf("expected 2 created results, got %+v", data)
+	}
+	for i, r := range data.Results {
+		if r.Index != i || r.Status != "created" || r.Item.ID == "" {
+			t.Fatalf("unexpected result %d: %+v", i, r)
+		}
+	}
+	if data.Results[1].Item.Name != "Omega Cable" {
+		t.Fatalf("expected input order preserved, got %q", data.Results[1].Item.Name)
+	}
+	if total := listTotal(t, e); total != len(mockItems)+2 {
+		t.Fatalf("expected %d items after batch, got %d", len(mockItems)+2, total)
+	}
+}
+
+func TestBatchCreate_InvalidItemCreatesNothing(t *testing.T) {
+	e := setupEcho()
+
+	rec := postBatch(e, `{"items":[
+		{"name":"Omega Widget","category":"tools","price":9.5},
+		{"name":"Omega Cable","category":"accessories","price":3},
+		{"name":"","category":"garden","price":-1}
+	]}`)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	locations := make(map[string]bool)
+	for _, e := range problem.Errors {
+		locations[e.Location] = true
+	}
+	for _, want := range []string{"items[2].name", "items[2].category", "items[2].price"} {
+		if !locations[want] {
+			t.Fatalf("expected error at %s, got %+v", want, problem.Errors)
+		}
+	}
+	if len(problem.Errors) != 3 {
+		t.Fatalf("expected only the invalid item reported, got %+v", problem.Errors)
+	}
+	if total := listTotal(t, e); total != len(mockItems) {
+		t.Fatalf("expected no items created, got total %d", total)
+	}
+}
+
+func TestBatchCreate_BatchBounds(t *testing.T) {
+	e := setupEcho()
+
+	tooMany := `{"items":[` + strings.Repeat(`{"name":"x","category":"tools"},`, 100) + `{"name":"x","category":"tools"}]}`
+	for name, body := range map[string]string{
+		"empty":    `{"items":[]}`,
+		"missing":  `{}`,
+		"too many": tooMany,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := postBatch(e, body)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d", rec.Code)
+			}
+		})
+	}
+	if total := listTotal(t, e); total != len(mockItems) {
+		t.Fatalf("expected no items created, got total %d", total)
+	}
+}
+
+func TestBatchCreate_MalformedBody(t *testing.T) {
+	e := setupEcho()
+
+	rec := postBatch(e, `{"items":`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func smallStore() *MemoryStore {
+	return NewMemoryStore([]Item{
+		{ID: "c", Name: "C", Category: "tools"},
+		{ID: "a", Name: "A", Category: "tools"},
+		{ID: "e", Name: "E", Category: "power"},
+		{ID: "b", Name: "B", Category: "tools"},
+		{ID: "d", Name: "D", Category: "tools"},
+	})
+}
+
+func listPage(t *testing.T, e *echo.Echo, target string) ListData {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	var data ListData
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	return data
+}
+
+func pageIDs(items []Item) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestListItems_CustomStore(t *testing.T) {
+	e := setupEchoWithStore(smallStore())
+
+	all := listPage(t, e, "/items")
+	if got := pageIDs(all.Items); !slices.Equal(got, []string{"a", "b", "c", "d", "e"}) {
+		t.Fatalf("expected every item sorted by ID, got %v", got)
+	}
+	if all.Total != 5 || all.HasMore {
+		t.Fatalf("expected total 5 without more, got total %d hasMore %v", all.Total, all.HasMore)
+	}
+
+	first := listPage(t, e, "/items?category=tools&limit=3")
+	if got := pageIDs(first.Items); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Fatalf("expected first tools page a,b,c, got %v", got)
+	}
+	if first.Total != 4 || !first.HasMore {
+		t.Fatalf("expected total 4 with more, got total %d hasMore %v", first.Total, first.HasMore)
+	}
+
+	scoped := pagination.ScopedType(cursorType, url.Values{"category": {"tools"}})
+	cursor := pagination.Cursor{Type: scoped, Value: "c"}.Encode()
+	second := listPage(t, e, "/items?category=tools&limit=3&cursor="+cursor)
+	if got := pageIDs(second.Items); !slices.Equal(got, []string{"d"}) {
+		t.Fatalf("expected second tools page d, got %v", got)
+	}
+	if second.HasMore {
+		t.Fatal("expected no more items after the last page")
+	}
+}
+
+func TestListItems_CursorItemRemoved(t *testing.T) {
+	first := listPage(t, setupEchoWithStore(smallStore()), "/items?category=tools&limit=2")
+	if got := pageIDs(first.Items); !slices.Equal(got, []string{"a", "b"}) {
+		t.Fatalf("expected first tools page a,b, got %v", got)
+	}
+
+	// Remove the boundary item b before the next page is requested.
+	e := setupEchoWithStore(NewMemoryStore([]Item{
+		{ID: "a", Name: "A", Category: "tools"},
+		{ID: "c", Name: "C", Category: "tools"},
+		{ID: "d", Name: "D", Category: "tools"},
+		{ID: "e", Name: "E", Category: "power"},
+	}))
+	scoped := pagination.ScopedType(cursorType, url.Values{"category": {"tools"}})
+
+	tests := []struct {
+		name      string
+		direction pagination.Direction
+		want      []string
+	}{
+		{"forward", pagination.Forward, []string{"c", "d"}},
+		{"backward", pagination.Backward, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := pagination.Cursor{Type: scoped, Value: "b", Direction: tt.direction}.Encode()
+			page := listPage(t, e, "/items?category=tools&limit=2&cursor="+cursor)
+			if got := pageIDs(page.Items); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected page %v after the removed item, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetItem_CustomStore(t *testing.T) {
+	e := setupEchoWithStore(smallStore())
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/e", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/item-001", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected sample item to be absent from custom store, got %d", rec.Code)
+	}
+}
+
+func TestItems_StoreError(t *testing.T) {
+	e := setupEchoWithStore(failingStore{err: errors.New("backend unavailable")})
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodGet, "/items", ""},
+		{http.MethodGet, "/items/item-001", ""},
+		{http.MethodPost, "/items:batchCreate", `{"items":[{"name":"X","category":"tools"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("expected 500, got %d; body: %s", rec.Code, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "backend unavailable") {
+				t.Fatal("expected store error details to stay internal")
+			}
+		})
+	}
+}
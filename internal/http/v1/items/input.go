@@ -1,8 +1,32 @@
 package items
 
+// categories lists the known item categories. CreateInput's oneof tag must
+// stay in sync with it.
+var categories = []string{"electronics", "tools", "accessories", "robotics", "power", "components"}
+
 // ListInput defines query parameters for listing items.
+// The cursor and limit parameters are parsed by the query.Pagination middleware.
+// Whether category must be a known category is decided by the handler; see
+// WithLenientCategories.
 type ListInput struct {
-	Cursor   string `query:"cursor"`
-	Limit    int    `query:"limit"    validate:"omitempty,min=1,max=100"`
-	Category string `query:"category" validate:"omitempty,oneof=electronics tools accessories robotics power components"`
+	Category string `query:"category" validate:"omitempty,max=50,alphanum"`
+}
+
+// GetInput defines path parameters for fetching a single item.
+type GetInput struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// CreateInput describes a single item to create.
+type CreateInput struct {
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Category    string  `json:"category" validate:"required,oneof=electronics tools accessories robotics power components"`
+	Price       float64 `json:"price" validate:"gte=0"`
+	InStock     bool    `json:"inStock"`
+	Description string  `json:"description" validate:"max=500"`
+}
+
+// BatchCreateInput is the request body for creating up to 100 items at once.
+type BatchCreateInput struct {
+	Items []CreateInput `json:"items" validate:"required,min=1,max=100"`
 }
@@ -19,8 +19,9 @@ type Item struct {
 
 // ListData is the response body containing paginated items.
 type ListData struct {
-	Items []Item `json:"items"`
-	Total int    `json:"total" example:"30"`
+	Items   []Item `json:"items"`
+	Total   int    `json:"total"   example:"30"`
+	HasMore bool   `json:"hasMore" example:"true"`
 }
 
 // mockItems provides sample data for pagination demonstration.
@@ -296,3 +297,16 @@ var mockItems = []Item{
 		Description: "Gold-plated premium cable",
 	},
 }
+
+// BatchItemResult reports the outcome for one input of a batch create.
+type BatchItemResult struct {
+	Index  int    `json:"index"  example:"0"`
+	Status string `json:"status" example:"created"`
+	Item   Item   `json:"item"`
+}
+
+// BatchCreateData is the response body for a successful batch create.
+type BatchCreateData struct {
+	Created int               `json:"created" example:"2"`
+	Results []BatchItemResult `json:"results"`
+}
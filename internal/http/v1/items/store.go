@@ -0,0 +1,147 @@
+package items
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/janisto/echo-playground/internal/platform/pagination"
+	"github.com/janisto/echo-playground/internal/platform/timeutil"
+)
+
+var (
+	// ErrNotFound is returned by Store.Get for unknown item IDs.
+	ErrNotFound = errors.New("item not found")
+	// ErrDuplicateID is returned by Store.CreateAll when an assigned ID is
+	// already taken.
+	ErrDuplicateID = errors.New("item id already exists")
+)
+
+// itemIDPrefix prefixes the sequence number of assigned item IDs.
+const itemIDPrefix = "item-"
+
+// Store defines item persistence. Handlers paginate and encode; the store
+// owns the data and category filtering.
+type Store interface {
+	// List returns the items in category, or every item when category is
+	// empty, ordered by ID. Callers must not modify the returned slice.
+	List(ctx context.Context, category string) ([]Item, error)
+	// Get returns the item with id or ErrNotFound.
+	Get(ctx context.Context, id string) (Item, error)
+	// CreateAll stores one item per input atomically: concurrent readers
+	// observe either none or all of them.
+	CreateAll(ctx context.Context, inputs []CreateInput, now time.Time) ([]Item, error)
+}
+
+// MemoryStore implements Store in memory. Items are kept sorted by ID with a
+// per-category index, so List is a map lookup without copying. Writes replace
+// the slices and the index, so slices returned by List stay valid without
+// holding the lock. New IDs come from a counter seeded past the highest
+// "item-N" ID in the initial items.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	items      []Item
+	byCategory map[string][]Item
+	nextID     int
+}
+
+// NewMemoryStore creates a store holding a copy of items.
+func NewMemoryStore(items []Item) *MemoryStore {
+	s := &MemoryStore{nextID: 1}
+	for _, item := range items {
+		if n, ok := itemSeq(item.ID); ok && n >= s.nextID {
+			s.nextID = n + 1
+		}
+	}
+	s.index(slices.Clone(items))
+	return s
+}
+
+// itemSeq returns the sequence number of an "item-N" ID.
+func itemSeq(id string) (int, bool) {
+	digits, ok := strings.CutPrefix(id, itemIDPrefix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	return n, err == nil && n >= 0
+}
+
+// NewSampleStore creates a store holding its own copy of the sample items.
+func NewSampleStore() *MemoryStore {
+	return NewMemoryStore(mockItems)
+}
+
+// index sorts items by ID and rebuilds the category index. items must not be
+// shared with readers.
+func (s *MemoryStore) index(items []Item) {
+	pagination.SortByKey(items, nil, itemID)
+	byCategory := make(map[string][]Item)
+	for _, item := range items {
+		byCategory[item.Category] = append(byCategory[item.Category], item)
+	}
+	for category, list := range byCategory {
+		byCategory[category] = slices.Clip(list)
+	}
+	s.items = slices.Clip(items)
+	s.byCategory = byCategory
+}
+
+func (s *MemoryStore) List(_ context.Context, category string) ([]Item, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if category == "" {
+		return s.items, nil
+	}
+	return s.byCategory[category], nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Item, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := findItem(s.items, id)
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	return s.items[i], nil
+}
+
+func (s *MemoryStore) CreateAll(_ context.Context, inputs []CreateInput, now time.Time) ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := make([]Item, len(inputs))
+	for i, in := range inputs {
+		id := fmt.Sprintf("%s%03d", itemIDPrefix, s.nextID+i)
+		if _, ok := findItem(s.items, id); ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateID, id)
+		}
+		created[i] = Item{
+			ID:          id,
+			Name:        in.Name,
+			Category:    in.Category,
+			Price:       in.Price,
+			InStock:     in.InStock,
+			CreatedAt:   timeutil.NewTime(now),
+			Description: in.Description,
+		}
+	}
+	s.nextID += len(inputs)
+	s.index(slices.Concat(s.items, created))
+	return created, nil
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// findItem returns the position of id in items sorted by ID.
+func findItem(items []Item, id string) (int, bool) {
+	return slices.BinarySearchFunc(items, id, func(item Item, id string) int {
+		return cmp.Compare(item.ID, id)
+	})
+}
@@ -0,0 +1,158 @@
+package items
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/janisto/echo-playground/internal/platform/pagination"
+)
+
+// filterItems is the clone-and-filter the category index replaced, kept as
+// the reference for expected results.
+func filterItems(items []Item, category string) []Item {
+	return slices.DeleteFunc(slices.Clone(items), func(item Item) bool {
+		return item.Category != category
+	})
+}
+
+func sortedByID(items []Item) []Item {
+	sorted := slices.Clone(items)
+	pagination.SortByKey(sorted, nil, itemID)
+	return sorted
+}
+
+func TestMemoryStore_ListMatchesFilter(t *testing.T) {
+	s := NewSampleStore()
+	ctx := context.Background()
+
+	categories := []string{"", "electronics", "tools", "accessories", "robotics", "power", "components", "unknown"}
+	for _, category := range categories {
+		t.Run(category, func(t *testing.T) {
+			want := sortedByID(mockItems)
+			if category != "" {
+				want = filterItems(want, category)
+			}
+			got, err := s.List(ctx, category)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(pageIDs(got), pageIDs(want)) {
+				t.Fatalf("expected %v, got %v", pageIDs(want), pageIDs(got))
+			}
+
+			if category == "unknown" {
+				return
+			}
+			target := "/items?limit=100"
+			if category != "" {
+				target += "&category=" + category
+			}
+			data := listPage(t, setupEchoWithStore(s), target)
+			if data.Total != len(want) || !slices.Equal(pageIDs(data.Items), pageIDs(want)) {
+				t.Fatalf("expected total %d with %v, got total %d with %v",
+					len(want), pageIDs(want), data.Total, pageIDs(data.Items))
+			}
+		})
+	}
+}
+
+func TestMemoryStore_CreateAllUpdatesIndex(t *testing.T) {
+	s := smallStore()
+	ctx := context.Background()
+
+	before, err := s.List(ctx, "tools")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	beforeIDs := pageIDs(before)
+
+	created, err := s.CreateAll(ctx, []CreateInput{
+		{Name: "F", Category: "tools"},
+		{Name: "G", Category: "robotics"},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(pageIDs(before), beforeIDs) {
+		t.Fatalf("expected earlier List result to stay unchanged, got %v", pageIDs(before))
+	}
+	tools, _ := s.List(ctx, "tools")
+	if want := append(slices.Clone(beforeIDs), created[0].ID); !slices.Equal(pageIDs(tools), want) {
+		t.Fatalf("expected tools %v, got %v", want, pageIDs(tools))
+	}
+	robotics, _ := s.List(ctx, "robotics")
+	if got := pageIDs(robotics); !slices.Equal(got, []string{created[1].ID}) {
+		t.Fatalf("expected new robotics category, got %v", got)
+	}
+	all, _ := s.List(ctx, "")
+	if len(all) != 7 || !slices.IsSortedFunc(all, func(a, b Item) int { return cmp.Compare(a.ID, b.ID) }) {
+		t.Fatalf("expected 7 items sorted by ID, got %v", pageIDs(all))
+	}
+}
+
+func TestMemoryStore_CreateAllSkipsExistingIDs(t *testing.T) {
+	s := NewMemoryStore([]Item{{ID: "item-001"}, {ID: "item-007"}, {ID: "custom"}})
+	ctx := context.Background()
+
+	created, err := s.CreateAll(ctx, []CreateInput{{Name: "A"}, {Name: "B"}}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := pageIDs(created); !slices.Equal(got, []string{"item-008", "item-009"}) {
+		t.Fatalf("expected IDs after the highest existing one, got %v", got)
+	}
+	if created, _ = s.CreateAll(ctx, []CreateInput{{Name: "C"}}, time.Now()); created[0].ID != "item-010" {
+		t.Fatalf("expected the counter to keep advancing, got %v", pageIDs(created))
+	}
+	all, _ := s.List(ctx, "")
+	if len(all) != 6 {
+		t.Fatalf("expected 6 items, got %v", pageIDs(all))
+	}
+}
+
+// largeStore holds n items spread over the sample categories.
+func largeStore(n int) *MemoryStore {
+	categories := []string{"electronics", "tools", "accessories", "robotics", "power", "components"}
+	items := make([]Item, n)
+	for i := range items {
+		items[i] = Item{ID: fmt.Sprintf("item-%06d", i), Category: categories[i%len(categories)]}
+	}
+	return NewMemoryStore(items)
+}
+
+func BenchmarkListCategory(b *testing.B) {
+	s := largeStore(10000)
+	ctx := context.Background()
+
+	b.Run("index", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			items, _ := s.List(ctx, "tools")
+			_ = pagination.Paginate(items, pagination.Cursor{}, 20, cursorType, itemID, "/v1/items", nil)
+		}
+	})
+	b.Run("clone-and-filter", func(b *testing.B) {
+		all, _ := s.List(ctx, "")
+		b.ReportAllocs()
+		for b.Loop() {
+			items := filterItems(all, "tools")
+			pagination.SortByKey(items, nil, itemID)
+			_ = pagination.Paginate(items, pagination.Cursor{}, 20, cursorType, itemID, "/v1/items", nil)
+		}
+	})
+}
+
+func BenchmarkListItems_Handler(b *testing.B) {
+	e := setupEchoWithStore(largeStore(10000))
+	b.ReportAllocs()
+	for b.Loop() {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items?category=tools&limit=20", nil))
+	}
+}
@@ -3,22 +3,41 @@ package profile
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v5"
 
 	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/budget"
 	applog "github.com/janisto/echo-playground/internal/platform/logging"
 	"github.com/janisto/echo-playground/internal/platform/respond"
 	"github.com/janisto/echo-playground/internal/platform/timeutil"
+	"github.com/janisto/echo-playground/internal/platform/validate"
 	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
 )
 
-// Register wires profile routes into the provided group.
-// The group is expected to have auth middleware applied.
+// ProblemTypeTermsNotAccepted is the problem type of profiles submitted with
+// terms explicitly declined.
+const ProblemTypeTermsNotAccepted = "terms-not-accepted"
+
+var _ = respond.MustRegisterProblemType(ProblemTypeTermsNotAccepted, http.StatusUnprocessableEntity, "Terms Not Accepted")
+
+// Register wires profile routes into the provided group. The group is
+// expected to have auth middleware applied. Service principals are rejected,
+// since the routes act on the caller's own profile.
 func Register(g *echo.Group, svc profilesvc.Service) {
+	g = g.Group("", auth.RequireEndUser())
 	g.POST("/profile", handleCreateProfile(svc))
+	g.POST(`/profile\:validate`, handleValidateProfile())
 	g.GET("/profile", handleGetProfile(svc))
+	g.HEAD("/profile", handleProfileExists(svc))
+	g.GET("/profile/export", handleExportProfile(svc))
+	g.PUT("/profile", handleReplaceProfile(svc))
 	g.PATCH("/profile", handleUpdateProfile(svc))
 	g.DELETE("/profile", handleDeleteProfile(svc))
 }
@@ -26,17 +45,24 @@ func Register(g *echo.Group, svc profilesvc.Service) {
 // handleCreateProfile godoc
 //
 //	@Summary		Create profile
-//	@Description	Creates a new user profile
+//	@Description	Creates a new user profile. Dry runs return the normalized profile with 200 without persisting.
 //	@Tags			profile
 //	@Produce		json,application/cbor
 //	@Param			body	body		CreateInput	true	"Profile creation request body"
+//	@Param			dryRun	query		bool		false	"Validate and preview without persisting"
+//	@Param			Prefer	header		string		false	"dry-run to preview without persisting; return=minimal to omit the body"
+//	@Success		200		{object}	Profile
 //	@Success		201		{object}	Profile
 //	@Failure		400		{object}	respond.ProblemDetails
 //	@Failure		401		{object}	respond.ProblemDetails
+//	@Failure		403		{object}	respond.ProblemDetails
 //	@Failure		409		{object}	respond.ProblemDetails
 //	@Failure		422		{object}	respond.ProblemDetails
 //	@Failure		500		{object}	respond.ProblemDetails
-//	@Header			201		{string}	Location	"URI of the created profile"
+//	@Header			201		{string}	Location			"URI of the created profile"
+//	@Header			201		{string}	ETag				"Entity tag of the created profile"
+//	@Header			201		{string}	Preference-Applied	"return=minimal when the body was omitted"
+//	@Header			200		{string}	Preference-Applied	"dry-run when nothing was persisted"
 //	@Security		BearerAuth
 //	@Router			/profile [post]
 func handleCreateProfile(svc profilesvc.Service) echo.HandlerFunc {
@@ -49,8 +75,8 @@ func handleCreateProfile(svc profilesvc.Service) echo.HandlerFunc {
 			return err
 		}
 
-		if !input.Terms {
-			return respond.Error422("terms must be accepted")
+		if err := requireTerms(c.Request().Context(), input); err != nil {
+			return err
 		}
 
 		user, err := auth.UserFromEchoContext(c)
@@ -58,21 +84,73 @@ func handleCreateProfile(svc profilesvc.Service) echo.HandlerFunc {
 			return respond.Error401("unauthorized")
 		}
 
+		dryRun, err := dryRunRequested(c)
+		if err != nil {
+			return err
+		}
+
 		ctx := c.Request().Context()
-		profile, err := svc.Create(ctx, user.UID, profilesvc.CreateParams{
-			Firstname:   input.Firstname,
-			Lastname:    input.Lastname,
-			Email:       input.Email,
-			PhoneNumber: input.PhoneNumber,
-			Marketing:   input.Marketing,
-			Terms:       input.Terms,
-		})
+		params := createParams(input)
+
+		if dryRun {
+			profile, err := profilesvc.PreviewCreate(ctx, svc, user.UID, params)
+			if err != nil {
+				return mapServiceError(ctx, err)
+			}
+			c.Response().Header().Set(respond.HeaderPreferenceApplied, preferDryRun)
+			return respond.Negotiate(c, http.StatusOK, FromService(profile))
+		}
+
+		profile, err := svc.Create(ctx, user.UID, params)
 		if err != nil {
 			return mapServiceError(ctx, err)
 		}
 
-		c.Response().Header().Set("Location", "/v1/profile")
-		return respond.Negotiate(c, http.StatusCreated, toHTTPProfile(profile))
+		respond.SetLocation(c, "/v1/profile")
+		c.Response().Header().Set(headerETag, profileETag(profile))
+		return respond.NegotiateOrMinimal(c, http.StatusCreated, FromService(profile))
+	}
+}
+
+// handleValidateProfile godoc
+//
+//	@Summary		Validate profile
+//	@Description	Validates and normalizes profile input like Create without reading or writing the store.
+//	@Description	Returns the profile Create would store for a user without one.
+//	@Tags			profile
+//	@Produce		json,application/cbor
+//	@Param			body	body		CreateInput	true	"Profile input to validate"
+//	@Success		200		{object}	Profile
+//	@Failure		400		{object}	respond.ProblemDetails
+//	@Failure		401		{object}	respond.ProblemDetails
+//	@Failure		403		{object}	respond.ProblemDetails
+//	@Failure		422		{object}	respond.ProblemDetails
+//	@Failure		500		{object}	respond.ProblemDetails
+//	@Security		BearerAuth
+//	@Router			/profile:validate [post]
+func handleValidateProfile() echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		var input CreateInput
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+		if err := requireTerms(c.Request().Context(), input); err != nil {
+			return err
+		}
+
+		user, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
+
+		profile, err := profilesvc.PreviewNew(user.UID, createParams(input))
+		if err != nil {
+			return mapServiceError(c.Request().Context(), err)
+		}
+		return respond.Negotiate(c, http.StatusOK, FromService(profile))
 	}
 }
 
@@ -80,12 +158,18 @@ func handleCreateProfile(svc profilesvc.Service) echo.HandlerFunc {
 //
 //	@Summary		Get profile
 //	@Description	Returns the authenticated user's profile
+//	@Description	During a storage outage a recently cached copy is served with a Warning header.
+//	@Description	Accept: text/vcard returns the profile as a vCard 4.0 contact for import into address books.
 //	@Tags			profile
-//	@Produce		json,application/cbor
+//	@Produce		json,application/cbor,text/vcard
 //	@Success		200	{object}	Profile
 //	@Failure		401	{object}	respond.ProblemDetails
+//	@Failure		403	{object}	respond.ProblemDetails
 //	@Failure		404	{object}	respond.ProblemDetails
 //	@Failure		500	{object}	respond.ProblemDetails
+//	@Header			200	{string}	ETag	"Entity tag for conditional requests"
+//	@Header			200	{string}	Warning	"110 when the profile is a stale cached copy"
+//	@Header			200	{string}	Age		"Seconds since a stale copy was cached"
 //	@Security		BearerAuth
 //	@Router			/profile [get]
 func handleGetProfile(svc profilesvc.Service) echo.HandlerFunc {
@@ -97,24 +181,169 @@ func handleGetProfile(svc profilesvc.Service) echo.HandlerFunc {
 
 		ctx := c.Request().Context()
 		profile, err := svc.Get(ctx, user.UID)
+		if err != nil {
+			cached, age, ok := staleProfile(svc, user.UID, err)
+			if !ok {
+				return mapServiceError(ctx, err)
+			}
+			applog.LogWarn(ctx, "serving stale profile", slog.Duration("age", age), slog.Any("error", err))
+			c.Response().Header().Set(headerWarning, staleWarning)
+			c.Response().Header().Set(headerAge, strconv.Itoa(int(age.Seconds())))
+			profile = cached
+		}
+
+		c.Response().Header().Set(headerETag, profileETag(profile))
+		if respond.PrefersVCard(c.Request().Header.Get(echo.HeaderAccept)) {
+			return c.Blob(http.StatusOK, respond.MIMETextVCard+"; charset=utf-8", vCard(FromService(profile)))
+		}
+		return respond.Negotiate(c, http.StatusOK, FromService(profile))
+	}
+}
+
+// handleProfileExists godoc
+//
+//	@Summary		Check profile existence
+//	@Description	Returns 200 when the authenticated user has a profile and 404 otherwise, without a body
+//	@Tags			profile
+//	@Success		200
+//	@Failure		401
+//	@Failure		403
+//	@Failure		404
+//	@Failure		500
+//	@Security		BearerAuth
+//	@Router			/profile [head]
+func handleProfileExists(svc profilesvc.Service) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		user, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
+
+		ctx := c.Request().Context()
+		exists, err := svc.Exists(ctx, user.UID)
 		if err != nil {
 			return mapServiceError(ctx, err)
 		}
+		if !exists {
+			return respond.Error404("profile not found")
+		}
+		return c.NoContent(http.StatusOK)
+	}
+}
+
+// handleExportProfile godoc
+//
+//	@Summary		Export profile
+//	@Description	Returns a downloadable copy of the authenticated user's data, encoded as JSON or as CBOR when the Accept header prefers it
+//	@Tags			profile
+//	@Produce		octet-stream,json,application/cbor
+//	@Success		200	{object}	Export
+//	@Failure		401	{object}	respond.ProblemDetails
+//	@Failure		403	{object}	respond.ProblemDetails
+//	@Failure		404	{object}	respond.ProblemDetails
+//	@Failure		500	{object}	respond.ProblemDetails
+//	@Header			200	{string}	Content-Disposition	"attachment; filename=profile-export.json or profile-export.cbor"
+//	@Security		BearerAuth
+//	@Router			/profile/export [get]
+func handleExportProfile(svc profilesvc.Service) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		user, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
 
-		return respond.Negotiate(c, http.StatusOK, toHTTPProfile(profile))
+		ctx := c.Request().Context()
+		profile, err := svc.Get(ctx, user.UID)
+		if err != nil {
+			return mapServiceError(ctx, err)
+		}
+
+		applog.LogAuditEvent(ctx, "export", user.UID, "profile", user.UID, "success", nil)
+
+		// The generic content type makes browsers save the file rather than
+		// render it; the extension records the negotiated encoding.
+		h := c.Response().Header()
+		h.Set(echo.HeaderContentType, echo.MIMEOctetStream)
+		h.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment",
+			map[string]string{"filename": exportFilename + "." + respond.PreferredFormat(c).String()}))
+		h.Set(echo.HeaderCacheControl, "no-store")
+		return respond.Negotiate(c, http.StatusOK, Export{
+			ExportedAt: timeutil.Time{Time: time.Now().UTC()},
+			Profile:    FromService(profile),
+		})
+	}
+}
+
+// handleReplaceProfile godoc
+//
+//	@Summary		Create or replace profile
+//	@Description	Creates the authenticated user's profile or replaces all of its fields, keeping its creation time
+//	@Tags			profile
+//	@Produce		json,application/cbor
+//	@Param			body	body		CreateInput	true	"Complete profile"
+//	@Param			Prefer	header		string		false	"return=minimal to omit the body"
+//	@Success		200		{object}	Profile
+//	@Success		201		{object}	Profile
+//	@Failure		400		{object}	respond.ProblemDetails
+//	@Failure		401		{object}	respond.ProblemDetails
+//	@Failure		403		{object}	respond.ProblemDetails
+//	@Failure		422		{object}	respond.ProblemDetails
+//	@Failure		500		{object}	respond.ProblemDetails
+//	@Header			201		{string}	Location			"URI of the created profile"
+//	@Header			200,201	{string}	ETag				"Entity tag of the stored profile"
+//	@Header			200,201	{string}	Preference-Applied	"return=minimal when the body was omitted"
+//	@Security		BearerAuth
+//	@Router			/profile [put]
+func handleReplaceProfile(svc profilesvc.Service) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		var input CreateInput
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		if err := c.Validate(&input); err != nil {
+			return err
+		}
+		if err := requireTerms(c.Request().Context(), input); err != nil {
+			return err
+		}
+
+		user, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
+
+		ctx := c.Request().Context()
+		profile, created, err := svc.Upsert(ctx, user.UID, createParams(input))
+		if err != nil {
+			return mapServiceError(ctx, err)
+		}
+
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+			respond.SetLocation(c, "/v1/profile")
+		}
+		c.Response().Header().Set(headerETag, profileETag(profile))
+		return respond.NegotiateOrMinimal(c, status, FromService(profile))
 	}
 }
 
 // handleUpdateProfile godoc
 //
 //	@Summary		Update profile
-//	@Description	Partially updates the authenticated user's profile
+//	@Description	Partially updates the authenticated user's profile. Dry runs preview the result without persisting.
+//	@Description	With Content-Type application/json-patch+json the body is an RFC 6902 patch: add and replace
+//	@Description	set top-level fields and remove clears phoneNumber. Other operations and immutable fields yield 422.
 //	@Tags			profile
 //	@Produce		json,application/cbor
 //	@Param			body	body		UpdateInput	true	"Profile update request body"
+//	@Param			dryRun	query		bool		false	"Validate and preview without persisting"
+//	@Param			Prefer	header		string		false	"dry-run to preview without persisting; return=minimal to omit the body"
 //	@Success		200		{object}	Profile
+//	@Header			200		{string}	Preference-Applied	"dry-run or return=minimal when applied"
 //	@Failure		400		{object}	respond.ProblemDetails
 //	@Failure		401		{object}	respond.ProblemDetails
+//	@Failure		403		{object}	respond.ProblemDetails
 //	@Failure		404		{object}	respond.ProblemDetails
 //	@Failure		422		{object}	respond.ProblemDetails
 //	@Failure		500		{object}	respond.ProblemDetails
@@ -122,8 +351,16 @@ func handleGetProfile(svc profilesvc.Service) echo.HandlerFunc {
 //	@Router			/profile [patch]
 func handleUpdateProfile(svc profilesvc.Service) echo.HandlerFunc {
 	return func(c *echo.Context) error {
-		var input UpdateInput
-		if err := c.Bind(&input); err != nil {
+		var (
+			input      UpdateInput
+			clearPhone bool
+		)
+		if isJSONPatch(c.Request()) {
+			var err error
+			if input, clearPhone, err = bindJSONPatch(c); err != nil {
+				return err
+			}
+		} else if err := c.Bind(&input); err != nil {
 			return err
 		}
 		if err := c.Validate(&input); err != nil {
@@ -135,30 +372,53 @@ func handleUpdateProfile(svc profilesvc.Service) echo.HandlerFunc {
 			return respond.Error401("unauthorized")
 		}
 
+		dryRun, err := dryRunRequested(c)
+		if err != nil {
+			return err
+		}
+
 		ctx := c.Request().Context()
-		profile, err := svc.Update(ctx, user.UID, profilesvc.UpdateParams{
+		params := profilesvc.UpdateParams{
 			Firstname:   input.Firstname,
 			Lastname:    input.Lastname,
 			Email:       input.Email,
 			PhoneNumber: input.PhoneNumber,
 			Marketing:   input.Marketing,
-		})
+		}
+		if clearPhone {
+			params.PhoneNumber = new(string)
+		}
+
+		var profile *profilesvc.Profile
+		if dryRun {
+			profile, err = profilesvc.PreviewUpdate(ctx, svc, user.UID, params)
+		} else {
+			profile, err = svc.Update(ctx, user.UID, params)
+		}
 		if err != nil {
 			return mapServiceError(ctx, err)
 		}
+		if dryRun {
+			c.Response().Header().Set(respond.HeaderPreferenceApplied, preferDryRun)
+			return respond.Negotiate(c, http.StatusOK, FromService(profile))
+		}
 
-		return respond.Negotiate(c, http.StatusOK, toHTTPProfile(profile))
+		c.Response().Header().Set(headerETag, profileETag(profile))
+		return respond.NegotiateOrMinimal(c, http.StatusOK, FromService(profile))
 	}
 }
 
 // handleDeleteProfile godoc
 //
 //	@Summary		Delete profile
-//	@Description	Deletes the authenticated user's profile
+//	@Description	Deletes the authenticated user's profile. With If-Match, deletes only if the ETag still matches.
 //	@Tags			profile
+//	@Param			If-Match	header	string	false	"ETag from a previous response"
 //	@Success		204
 //	@Failure		401	{object}	respond.ProblemDetails
+//	@Failure		403	{object}	respond.ProblemDetails
 //	@Failure		404	{object}	respond.ProblemDetails
+//	@Failure		412	{object}	respond.ProblemDetails
 //	@Failure		500	{object}	respond.ProblemDetails
 //	@Security		BearerAuth
 //	@Router			/profile [delete]
@@ -169,8 +429,21 @@ func handleDeleteProfile(svc profilesvc.Service) echo.HandlerFunc {
 			return respond.Error401("unauthorized")
 		}
 
+		var params profilesvc.DeleteParams
+		ifMatch := c.Request().Header.Get(headerIfMatch)
+		anyVersion := strings.TrimSpace(ifMatch) == "*"
+		if ifMatch != "" && !anyVersion {
+			params.Versions = parseIfMatch(ifMatch)
+			if len(params.Versions) == 0 {
+				return respond.Error412("profile has been modified")
+			}
+		}
+
 		ctx := c.Request().Context()
-		if err := svc.Delete(ctx, user.UID); err != nil {
+		if err := svc.Delete(ctx, user.UID, params); err != nil {
+			if anyVersion && errors.Is(err, profilesvc.ErrNotFound) {
+				return respond.Error412("profile does not exist")
+			}
 			return mapServiceError(ctx, err)
 		}
 
@@ -178,28 +451,112 @@ func handleDeleteProfile(svc profilesvc.Service) echo.HandlerFunc {
 	}
 }
 
+const (
+	headerETag     = "ETag"
+	headerWarning  = "Warning"
+	headerAge      = "Age"
+	headerIfMatch  = "If-Match"
+	preferDryRun   = "dry-run"
+	exportFilename = "profile-export"
+)
+
+// staleWarning marks a profile served from cache during an outage.
+const staleWarning = `110 - "Response is Stale"`
+
+// isJSONPatch reports whether the request body is an RFC 6902 JSON Patch.
+func isJSONPatch(r *http.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	return err == nil && mt == MIMEJSONPatch
+}
+
+// dryRunRequested reports whether the client asked to preview a mutation via
+// the dryRun query parameter or an RFC 7240 Prefer: dry-run header.
+func dryRunRequested(c *echo.Context) (bool, error) {
+	dryRun, err := echo.QueryParamOr(c, "dryRun", false)
+	if err != nil {
+		return false, respond.Error400("invalid dryRun parameter")
+	}
+	if dryRun {
+		return true, nil
+	}
+	_, ok := respond.Preference(c.Request(), preferDryRun)
+	return ok, nil
+}
+
+// requireTerms rejects profiles whose terms were explicitly declined; omitted
+// terms are already rejected by validation. The rejection is a business rule,
+// so it carries ProblemTypeTermsNotAccepted rather than the validation type.
+func requireTerms(ctx context.Context, input CreateInput) error {
+	if *input.Terms {
+		return nil
+	}
+	p := respond.TypedError(ctx, ProblemTypeTermsNotAccepted, "terms must be accepted")
+	p.Errors = []respond.ErrorDetail{{
+		Message:  "terms must be accepted",
+		Location: "terms",
+		Value:    "false",
+	}}
+	return p
+}
+
+// profileETag returns the strong entity tag for p.
+func profileETag(p *profilesvc.Profile) string {
+	return `"` + p.Version() + `"`
+}
+
+// parseIfMatch returns the versions from the strong entity tags in an If-Match
+// header. Weak tags never match under the strong comparison If-Match requires.
+func parseIfMatch(header string) []string {
+	var versions []string
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		versions = append(versions, tag[1:len(tag)-1])
+	}
+	return versions
+}
+
+var serviceErrors = []respond.ErrorMapping{
+	{Err: profilesvc.ErrNotFound, Status: http.StatusNotFound, Detail: "profile not found"},
+	{Err: profilesvc.ErrAlreadyExists, Status: http.StatusConflict, Detail: "profile already exists"},
+	{Err: profilesvc.ErrVersionMismatch, Status: http.StatusPreconditionFailed, Detail: "profile has been modified"},
+	{Err: budget.ErrExhausted, Status: http.StatusServiceUnavailable, Detail: "request timed out"},
+}
+
+// staleReader is implemented by services that keep recently read profiles,
+// such as profilesvc.CachedService.
+type staleReader interface {
+	Stale(userID string) (*profilesvc.Profile, time.Duration, bool)
+}
+
+// staleProfile returns a cached profile to serve in place of a failed read.
+// Only transient failures qualify, so a deleted profile is never revived.
+func staleProfile(svc profilesvc.Service, userID string, err error) (*profilesvc.Profile, time.Duration, bool) {
+	sr, ok := svc.(staleReader)
+	if !ok || !profilesvc.IsTransient(err) {
+		return nil, 0, false
+	}
+	return sr.Stale(userID)
+}
+
 func mapServiceError(ctx context.Context, err error) error {
-	switch {
-	case errors.Is(err, profilesvc.ErrNotFound):
-		return respond.Error404("profile not found")
-	case errors.Is(err, profilesvc.ErrAlreadyExists):
-		return respond.Error409("profile already exists")
-	default:
-		applog.LogError(ctx, "unexpected service error", err)
-		return respond.Error500("internal error")
+	// Field errors from service-side normalization render as 422 like binding validation.
+	var ve *validate.ValidationError
+	if errors.As(err, &ve) {
+		return ve
 	}
+	return respond.FromError(ctx, err, serviceErrors...)
 }
 
-func toHTTPProfile(p *profilesvc.Profile) Profile {
-	return Profile{
-		ID:          p.ID,
-		Firstname:   p.Firstname,
-		Lastname:    p.Lastname,
-		Email:       p.Email,
-		PhoneNumber: p.PhoneNumber,
-		Marketing:   p.Marketing,
-		Terms:       p.Terms,
-		CreatedAt:   timeutil.Time{Time: p.CreatedAt},
-		UpdatedAt:   timeutil.Time{Time: p.UpdatedAt},
+func createParams(input CreateInput) profilesvc.CreateParams {
+	return profilesvc.CreateParams{
+		Firstname:   input.Firstname,
+		Lastname:    input.Lastname,
+		Email:       input.Email,
+		PhoneNumber: input.PhoneNumber,
+		Marketing:   input.Marketing,
+		Terms:       *input.Terms,
 	}
 }
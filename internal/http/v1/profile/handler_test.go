@@ -8,10 +8,15 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/labstack/echo/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/budget"
 	"github.com/janisto/echo-playground/internal/platform/respond"
 	"github.com/janisto/echo-playground/internal/platform/validate"
 	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
@@ -55,11 +60,11 @@ func (s *errService) Update(
 	return s.Service.Update(ctx, userID, params)
 }
 
-func (s *errService) Delete(ctx context.Context, userID string) error {
+func (s *errService) Delete(ctx context.Context, userID string, params profilesvc.DeleteParams) error {
 	if s.deleteErr != nil {
 		return s.deleteErr
 	}
-	return s.Service.Delete(ctx, userID)
+	return s.Service.Delete(ctx, userID, params)
 }
 
 func setupEcho(verifier auth.Verifier, svc profilesvc.Service) *echo.Echo {
@@ -108,6 +113,39 @@ func TestCreateProfile_Success(t *testing.T) {
 	}
 }
 
+func TestCreateProfile_ReturnMinimal(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Prefer", "return=minimal")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("Location"); got != "/v1/profile" {
+		t.Fatalf("expected Location '/v1/profile', got %q", got)
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Fatal("expected ETag header")
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "return=minimal" {
+		t.Fatalf("expected Preference-Applied return=minimal, got %q", got)
+	}
+
+	if _, err := svc.Get(context.Background(), auth.TestUser().UID); err != nil {
+		t.Fatalf("expected profile to be persisted, got %v", err)
+	}
+}
+
 func TestCreateProfile_Duplicate(t *testing.T) {
 	svc := profilesvc.NewMockStore()
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
@@ -161,13 +199,47 @@ func TestCreateProfile_ValidationError(t *testing.T) {
 	}
 }
 
-func TestCreateProfile_TermsNotAccepted(t *testing.T) {
-	svc := profilesvc.NewMockStore()
+func TestValidateProfile(t *testing.T) {
+	svc := &errService{
+		Service:   profilesvc.NewMockStore(),
+		createErr: errors.New("store must not be written"),
+		getErr:    errors.New("store must not be read"),
+	}
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
 	e := setupEcho(verifier, svc)
 
-	body := `{"firstname":"John","lastname":"Doe","email":"john@example.com","phoneNumber":"+358401234567","terms":false}`
-	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(body))
+	body := `{"firstname":" John ","lastname":"Doe","email":"John@Example.COM",` +
+		`"phoneNumber":"+358401234567","terms":true}`
+	req := httptest.NewRequest(http.MethodPost, "/profile:validate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	var got Profile
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if got.Email != "john@example.com" || got.Firstname != "John" {
+		t.Fatalf("expected normalized values, got %+v", got)
+	}
+	if rec.Header().Get("Location") != "" || rec.Header().Get("ETag") != "" {
+		t.Fatal("expected no Location or ETag for a validation response")
+	}
+	if _, err := svc.Service.Get(context.Background(), auth.TestUser().UID); !errors.Is(err, profilesvc.ErrNotFound) {
+		t.Fatalf("expected nothing persisted, got %v", err)
+	}
+}
+
+func TestValidateProfile_Invalid(t *testing.T) {
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, profilesvc.NewMockStore())
+
+	body := `{"firstname":"","lastname":"Doe","email":"bad","phoneNumber":"+358401234567","terms":false}`
+	req := httptest.NewRequest(http.MethodPost, "/profile:validate", strings.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer test-token")
 	rec := httptest.NewRecorder()
@@ -176,6 +248,118 @@ func TestCreateProfile_TermsNotAccepted(t *testing.T) {
 	if rec.Code != http.StatusUnprocessableEntity {
 		t.Fatalf("expected 422, got %d; body: %s", rec.Code, rec.Body.String())
 	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	locations := make(map[string]bool)
+	for _, fe := range problem.Errors {
+		locations[fe.Location] = true
+	}
+	for _, want := range []string{"firstname", "email"} {
+		if !locations[want] {
+			t.Fatalf("expected an error at %s, got %+v", want, problem.Errors)
+		}
+	}
+}
+
+func TestCreateProfile_Terms(t *testing.T) {
+	const (
+		validationType = respond.ProblemTypeBase + respond.ProblemTypeValidation
+		termsType      = respond.ProblemTypeBase + ProblemTypeTermsNotAccepted
+	)
+	const base = `"firstname":"John","lastname":"Doe","email":"john@example.com","phoneNumber":"+358401234567"`
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		message string
+		typ     string
+	}{
+		{"omitted", `{` + base + `}`, http.StatusUnprocessableEntity, "terms is required", validationType},
+		{"null", `{` + base + `,"terms":null}`, http.StatusUnprocessableEntity, "terms is required", validationType},
+		{"declined", `{` + base + `,"terms":false}`, http.StatusUnprocessableEntity, "terms must be accepted", termsType},
+		{"accepted", `{` + base + `,"terms":true}`, http.StatusCreated, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := profilesvc.NewMockStore()
+			verifier := &auth.MockVerifier{User: auth.TestUser()}
+			e := setupEcho(verifier, svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d; body: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.message == "" {
+				return
+			}
+
+			var problem respond.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if len(problem.Errors) != 1 {
+				t.Fatalf("expected one field error, got %+v", problem.Errors)
+			}
+			if got := problem.Errors[0]; got.Location != "terms" || got.Message != tt.message {
+				t.Fatalf("expected terms error %q, got %+v", tt.message, got)
+			}
+			if problem.Type != tt.typ {
+				t.Fatalf("expected type %q, got %q", tt.typ, problem.Type)
+			}
+		})
+	}
+}
+
+func TestCreateProfile_MarketingRequiresTerms(t *testing.T) {
+	const base = `"firstname":"John","lastname":"Doe","email":"john@example.com","phoneNumber":"+358401234567"`
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"marketing without terms", `{` + base + `,"marketing":true,"terms":false}`, http.StatusUnprocessableEntity},
+		{"terms without marketing", `{` + base + `,"marketing":false,"terms":true}`, http.StatusCreated},
+		{"both", `{` + base + `,"marketing":true,"terms":true}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := profilesvc.NewMockStore()
+			verifier := &auth.MockVerifier{User: auth.TestUser()}
+			e := setupEcho(verifier, svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d; body: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status != http.StatusUnprocessableEntity {
+				return
+			}
+
+			var problem respond.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if len(problem.Errors) != 1 {
+				t.Fatalf("expected one field error, got %+v", problem.Errors)
+			}
+			got := problem.Errors[0]
+			if got.Location != "marketing" || got.Message != "marketing requires terms to be true" {
+				t.Fatalf("expected marketing error, got %+v", got)
+			}
+		})
+	}
 }
 
 func TestCreateProfile_Unauthorized(t *testing.T) {
@@ -229,6 +413,120 @@ func TestGetProfile_Success(t *testing.T) {
 	}
 }
 
+func TestGetProfile_FullRepresentation(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	user := auth.TestUser()
+	e := setupEcho(&auth.MockVerifier{User: user}, svc)
+
+	_, err := svc.Create(context.Background(), user.UID, profilesvc.CreateParams{
+		Firstname: "John",
+		Lastname:  "Doe",
+		Email:     "john@example.com",
+		Terms:     true,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		prefer string
+		want   any
+	}{
+		{"default", "", ""},
+		{"compatibility", "optional=null", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			req.Header.Set("Authorization", "Bearer test-token")
+			if tt.prefer != "" {
+				req.Header.Set("Prefer", tt.prefer)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+			}
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			phone, ok := body["phoneNumber"]
+			if !ok {
+				t.Fatalf("expected phoneNumber to be present, got %s", rec.Body.String())
+			}
+			if phone != tt.want {
+				t.Fatalf("expected phoneNumber %#v, got %#v", tt.want, phone)
+			}
+			if body["firstname"] != "John" {
+				t.Fatalf("expected firstname John, got %#v", body["firstname"])
+			}
+		})
+	}
+}
+
+func TestGetProfile_VCard(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	user := auth.TestUser()
+	e := setupEcho(&auth.MockVerifier{User: user}, svc)
+
+	_, err := svc.Create(context.Background(), user.UID, profilesvc.CreateParams{
+		Firstname:   "John",
+		Lastname:    "Doe",
+		Email:       "john@example.com",
+		PhoneNumber: "+358401234567",
+		Terms:       true,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Accept", "text/vcard")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/vcard; charset=utf-8" {
+		t.Fatalf("expected vCard content type, got %q", ct)
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Fatal("expected an ETag on the vCard response")
+	}
+	want := "BEGIN:VCARD\r\n" +
+		"VERSION:4.0\r\n" +
+		"FN:John Doe\r\n" +
+		"N:Doe;John;;;\r\n" +
+		"EMAIL:john@example.com\r\n" +
+		"TEL;VALUE=uri:tel:+358401234567\r\n" +
+		"END:VCARD\r\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("expected vCard\n%q\ngot\n%q", want, got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Accept", "application/json, text/vcard;q=0.5")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected JSON for a client preferring JSON, got %q", ct)
+	}
+	var p Profile
+	if err = json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if p.Email != "john@example.com" {
+		t.Fatalf("expected email john@example.com, got %q", p.Email)
+	}
+}
+
 func TestGetProfile_NotFound(t *testing.T) {
 	svc := profilesvc.NewMockStore()
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
@@ -244,6 +542,167 @@ func TestGetProfile_NotFound(t *testing.T) {
 	}
 }
 
+func TestProfileRoutes_RejectServicePrincipal(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	service := auth.TestUser()
+	service.UID = "service:billing"
+	service.Service = true
+	e := setupEcho(&auth.MockVerifier{User: service}, svc)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/profile", strings.NewReader(validCreateBody()))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: expected 403, got %d", method, rec.Code)
+		}
+	}
+	if exists, _ := svc.Exists(context.Background(), "service:billing"); exists {
+		t.Fatal("expected no profile for the service principal")
+	}
+}
+
+func TestProfileExists(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	head := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodHead, "/profile", nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := head(); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before create, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	rec = head()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 after create, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestExportProfile_Success(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/profile/export", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "attachment; filename=profile-export.json" {
+		t.Fatalf("expected attachment Content-Disposition, got %q", cd)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Fatalf("expected application/octet-stream, got %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Fatalf("expected Cache-Control no-store, got %q", cc)
+	}
+
+	var export Export
+	if err := json.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if export.ExportedAt.IsZero() {
+		t.Fatal("expected exportedAt to be set")
+	}
+	if export.Profile.Firstname != "John" || export.Profile.Email != "john@example.com" {
+		t.Fatalf("unexpected profile in export: %+v", export.Profile)
+	}
+}
+
+func TestExportProfile_CBOR(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/profile/export", nil)
+	req.Header.Set("Accept", "application/cbor")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "attachment; filename=profile-export.cbor" {
+		t.Fatalf("expected cbor filename, got %q", cd)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Fatalf("expected application/octet-stream, got %q", ct)
+	}
+
+	var export Export
+	if err := cbor.Unmarshal(rec.Body.Bytes(), &export); err != nil {
+		t.Fatalf("failed to decode cbor: %v", err)
+	}
+	if export.Profile.Email != "john@example.com" {
+		t.Fatalf("unexpected profile in export: %+v", export.Profile)
+	}
+}
+
+func TestExportProfile_NotFound(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/profile/export", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "" {
+		t.Fatalf("expected no Content-Disposition on error, got %q", cd)
+	}
+}
+
 func TestUpdateProfile_Success(t *testing.T) {
 	svc := profilesvc.NewMockStore()
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
@@ -284,6 +743,118 @@ func TestUpdateProfile_Success(t *testing.T) {
 	}
 }
 
+func TestReplaceProfile(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	put := func(body string) (*httptest.ResponseRecorder, Profile) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, "/profile", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var p Profile
+		if rec.Code < 300 {
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+		}
+		return rec, p
+	}
+
+	rec, created := put(validCreateBody())
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Location"); got != "/v1/profile" {
+		t.Fatalf("expected Location '/v1/profile', got %q", got)
+	}
+
+	time.Sleep(time.Millisecond)
+	body := `{"firstname":"Jane","lastname":"Smith","email":"jane@example.com",` +
+		`"phoneNumber":"+358409876543","terms":true}`
+	rec, replaced := put(body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Location"); got != "" {
+		t.Fatalf("expected no Location on replace, got %q", got)
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Fatal("expected ETag header")
+	}
+	if replaced.Firstname != "Jane" || replaced.Email != "jane@example.com" || replaced.Marketing {
+		t.Fatalf("expected every field replaced, got %+v", replaced)
+	}
+	if !replaced.CreatedAt.Equal(created.CreatedAt.Time) {
+		t.Fatalf("expected createdAt %v to be kept, got %v", created.CreatedAt, replaced.CreatedAt)
+	}
+	if !replaced.UpdatedAt.After(created.UpdatedAt.Time) {
+		t.Fatalf("expected updatedAt after %v, got %v", created.UpdatedAt, replaced.UpdatedAt)
+	}
+}
+
+func TestReplaceProfile_RequiresFullBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		detail string
+	}{
+		{"partial", `{"firstname":"Jane"}`, "lastname is required"},
+		{"declined terms", strings.Replace(validCreateBody(), `"marketing":true,"terms":true`,
+			`"terms":false`, 1), "terms must be accepted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := profilesvc.NewMockStore()
+			e := setupEcho(&auth.MockVerifier{User: auth.TestUser()}, svc)
+
+			req := httptest.NewRequest(http.MethodPut, "/profile", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d; body: %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.detail) {
+				t.Fatalf("expected %q in body, got %s", tt.detail, rec.Body.String())
+			}
+			if _, err := svc.Get(context.Background(), auth.TestUser().UID); !errors.Is(err, profilesvc.ErrNotFound) {
+				t.Fatalf("expected nothing stored, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateProfile_ReturnMinimal(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+	createAndFetchETag(t, e)
+
+	req := httptest.NewRequest(http.MethodPatch, "/profile", strings.NewReader(`{"firstname":"Jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Prefer", "return=minimal")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rec.Body.String())
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Fatal("expected ETag header")
+	}
+}
+
 func TestUpdateProfile_NotFound(t *testing.T) {
 	svc := profilesvc.NewMockStore()
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
@@ -368,8 +939,9 @@ func TestProfile_InvalidToken(t *testing.T) {
 	}
 
 	wwwAuth := rec.Header().Get("WWW-Authenticate")
-	if wwwAuth != "Bearer" {
-		t.Fatalf("expected WWW-Authenticate: Bearer, got %q", wwwAuth)
+	want := `Bearer error="invalid_token", error_description="the token is malformed or invalid"`
+	if wwwAuth != want {
+		t.Fatalf("expected WWW-Authenticate: %s, got %q", want, wwwAuth)
 	}
 }
 
@@ -608,6 +1180,109 @@ func TestGetProfile_InternalServiceError(t *testing.T) {
 	}
 }
 
+func TestGetProfile_BudgetExhausted(t *testing.T) {
+	svc := &errService{
+		Service: profilesvc.NewMockStore(),
+		getErr:  budget.ErrExhausted,
+	}
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetProfile_StaleDuringOutage(t *testing.T) {
+	inner := &errService{Service: profilesvc.NewMockStore()}
+	svc := profilesvc.NewCachedService(inner, profilesvc.CacheConfig{})
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+	if rec = get(); rec.Header().Get("Warning") != "" {
+		t.Fatalf("expected no Warning on a fresh read, got %q", rec.Header().Get("Warning"))
+	}
+
+	inner.getErr = status.Error(codes.Unavailable, "firestore unavailable")
+	rec = get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from cache, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if w := rec.Header().Get("Warning"); !strings.HasPrefix(w, "110 ") {
+		t.Fatalf("expected a 110 Warning, got %q", w)
+	}
+	if rec.Header().Get("Age") == "" || rec.Header().Get("ETag") == "" {
+		t.Fatalf("expected Age and ETag headers, got %v", rec.Header())
+	}
+	var p Profile
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if p.Firstname != "John" {
+		t.Fatalf("expected cached firstname John, got %q", p.Firstname)
+	}
+}
+
+func TestGetProfile_OutageWithoutCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		cached bool
+		err    error
+	}{
+		{"transient error on cache miss", false, status.Error(codes.Unavailable, "firestore unavailable")},
+		{"non-transient error on cache hit", true, errors.New("database timeout")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &errService{Service: profilesvc.NewMockStore()}
+			svc := profilesvc.NewCachedService(inner, profilesvc.CacheConfig{})
+			verifier := &auth.MockVerifier{User: auth.TestUser()}
+			e := setupEcho(verifier, svc)
+
+			if tt.cached {
+				req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer test-token")
+				e.ServeHTTP(httptest.NewRecorder(), req)
+			}
+			inner.getErr = tt.err
+
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("expected 500, got %d; body: %s", rec.Code, rec.Body.String())
+			}
+			if rec.Header().Get("Warning") != "" {
+				t.Fatal("expected no Warning on an error response")
+			}
+		})
+	}
+}
+
 func TestUpdateProfile_InternalServiceError(t *testing.T) {
 	store := profilesvc.NewMockStore()
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
@@ -739,3 +1414,341 @@ func TestDeleteProfile_NoUserInContext(t *testing.T) {
 		t.Fatalf("expected 401, got %d; body: %s", rec.Code, rec.Body.String())
 	}
 }
+
+func TestCreateProfile_DryRun(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	body := `{"firstname":"John","lastname":"Doe","email":"John@Example.COM","phoneNumber":"+358401234567","terms":true}`
+	req := httptest.NewRequest(http.MethodPost, "/profile?dryRun=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "dry-run" {
+		t.Fatalf("expected Preference-Applied dry-run, got %q", got)
+	}
+	if rec.Header().Get("Location") != "" {
+		t.Fatal("expected no Location header for dry run")
+	}
+
+	var p Profile
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if p.Email != "john@example.com" {
+		t.Fatalf("expected normalized email, got %q", p.Email)
+	}
+
+	if _, err := svc.Get(context.Background(), auth.TestUser().UID); !errors.Is(err, profilesvc.ErrNotFound) {
+		t.Fatalf("expected store to remain empty, got %v", err)
+	}
+}
+
+func TestCreateProfile_DryRunDuplicate(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Prefer", "dry-run")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestUpdateProfile_DryRun(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	body := `{"firstname":"Jane","email":"JANE@EXAMPLE.COM"}`
+	req = httptest.NewRequest(http.MethodPatch, "/profile", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Prefer", "return=representation, dry-run")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "dry-run" {
+		t.Fatalf("expected Preference-Applied dry-run, got %q", got)
+	}
+
+	var p Profile
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if p.Firstname != "Jane" || p.Email != "jane@example.com" {
+		t.Fatalf("expected previewed changes, got %q %q", p.Firstname, p.Email)
+	}
+
+	stored, err := svc.Get(context.Background(), auth.TestUser().UID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.Firstname != "John" || stored.Email != "john@example.com" {
+		t.Fatalf("expected stored profile unchanged, got %q %q", stored.Firstname, stored.Email)
+	}
+}
+
+func TestUpdateProfile_InvalidDryRun(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodPatch, "/profile?dryRun=maybe", strings.NewReader(`{"firstname":"Jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+// createAndFetchETag creates the test user's profile and returns the ETag from GET.
+func createAndFetchETag(t *testing.T, e *echo.Echo) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(validCreateBody()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header on GET")
+	}
+	return etag
+}
+
+func TestDeleteProfile_IfMatch(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+	etag := createAndFetchETag(t, e)
+
+	req := httptest.NewRequest(http.MethodDelete, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("If-Match", `"other", `+etag)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	if _, err := svc.Get(context.Background(), auth.TestUser().UID); !errors.Is(err, profilesvc.ErrNotFound) {
+		t.Fatalf("expected profile deleted, got %v", err)
+	}
+}
+
+func TestDeleteProfile_IfMatchStale(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+	etag := createAndFetchETag(t, e)
+
+	// A concurrent update changes the version.
+	time.Sleep(time.Millisecond)
+	req := httptest.NewRequest(http.MethodPatch, "/profile", strings.NewReader(`{"firstname":"Jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if got := rec.Header().Get("ETag"); got == "" || got == etag {
+		t.Fatalf("expected new ETag after update, got %q", got)
+	}
+
+	tests := []struct {
+		name    string
+		ifMatch string
+	}{
+		{"stale", etag},
+		{"weak", "W/" + etag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/profile", nil)
+			req.Header.Set("Authorization", "Bearer test-token")
+			req.Header.Set("If-Match", tt.ifMatch)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusPreconditionFailed {
+				t.Fatalf("expected 412, got %d", rec.Code)
+			}
+		})
+	}
+	if _, err := svc.Get(context.Background(), auth.TestUser().UID); err != nil {
+		t.Fatalf("expected profile to remain, got %v", err)
+	}
+}
+
+func TestDeleteProfile_IfMatchAny(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	req := httptest.NewRequest(http.MethodDelete, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for missing profile, got %d", rec.Code)
+	}
+
+	createAndFetchETag(t, e)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+}
+
+func TestCreateProfile_InvisibleNameRejected(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	verifier := &auth.MockVerifier{User: auth.TestUser()}
+	e := setupEcho(verifier, svc)
+
+	body := `{"firstname":"\u200b","lastname":"Doe","email":"john@example.com","phoneNumber":"+358401234567","terms":true}`
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Location != "firstname" {
+		t.Fatalf("expected firstname field error, got %+v", problem.Errors)
+	}
+}
+
+func patchProfile(e *echo.Echo, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/profile", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUpdateProfile_JSONPatch(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	e := setupEcho(&auth.MockVerifier{User: auth.TestUser()}, svc)
+	createAndFetchETag(t, e)
+
+	rec := patchProfile(e, `[
+		{"op":"replace","path":"/firstname","value":"Jane"},
+		{"op":"remove","path":"/phoneNumber"}
+	]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+
+	var p Profile
+	if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if p.Firstname != "Jane" {
+		t.Fatalf("expected firstname 'Jane', got %q", p.Firstname)
+	}
+	if p.PhoneNumber != "" {
+		t.Fatalf("expected phoneNumber to be cleared, got %q", p.PhoneNumber)
+	}
+	if p.Lastname != "Doe" {
+		t.Fatalf("expected lastname 'Doe' (unchanged), got %q", p.Lastname)
+	}
+}
+
+func TestUpdateProfile_JSONPatchRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			"immutable createdAt",
+			`[{"op":"replace","path":"/createdAt","value":"2020-01-01T00:00:00Z"}]`,
+			http.StatusUnprocessableEntity,
+		},
+		{"immutable id", `[{"op":"remove","path":"/id"}]`, http.StatusUnprocessableEntity},
+		{"remove required field", `[{"op":"remove","path":"/firstname"}]`, http.StatusUnprocessableEntity},
+		{"unsupported op", `[{"op":"move","from":"/firstname","path":"/lastname"}]`, http.StatusUnprocessableEntity},
+		{"nested path", `[{"op":"replace","path":"/firstname/0","value":"J"}]`, http.StatusUnprocessableEntity},
+		{"wrong value type", `[{"op":"replace","path":"/marketing","value":"yes"}]`, http.StatusUnprocessableEntity},
+		{"invalid result", `[{"op":"replace","path":"/email","value":"not-an-email"}]`, http.StatusUnprocessableEntity},
+		{"malformed document", `{"op":"replace"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := profilesvc.NewMockStore()
+			e := setupEcho(&auth.MockVerifier{User: auth.TestUser()}, svc)
+			createAndFetchETag(t, e)
+
+			rec := patchProfile(e, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d; body: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status == http.StatusUnprocessableEntity {
+				var problem respond.ProblemDetails
+				if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("failed to unmarshal: %v", err)
+				}
+				if want := respond.ProblemTypeBase + respond.ProblemTypeValidation; problem.Type != want {
+					t.Fatalf("expected type %q, got %q", want, problem.Type)
+				}
+			}
+
+			p, err := svc.Get(context.Background(), auth.TestUser().UID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Firstname != "John" || p.Email != "john@example.com" || p.PhoneNumber != "+358401234567" {
+				t.Fatalf("expected profile to be unchanged, got %+v", p)
+			}
+		})
+	}
+}
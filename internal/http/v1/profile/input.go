@@ -1,13 +1,13 @@
 package profile
 
-// CreateInput for POST /profile.
+// CreateInput for POST and PUT /profile.
 type CreateInput struct {
 	Firstname   string `json:"firstname"   validate:"required,min=1,max=100" example:"John"`
 	Lastname    string `json:"lastname"    validate:"required,min=1,max=100" example:"Doe"`
 	Email       string `json:"email"       validate:"required,email"         example:"john@example.com"`
 	PhoneNumber string `json:"phoneNumber" validate:"required,e164"          example:"+358401234567"`
-	Marketing   bool   `json:"marketing"                                     example:"true"`
-	Terms       bool   `json:"terms"                                         example:"true"`
+	Marketing   bool   `json:"marketing"   validate:"implies=terms"          example:"true"`
+	Terms       *bool  `json:"terms"       validate:"required"               example:"true"`
 }
 
 // UpdateInput for PATCH /profile.
@@ -1,6 +1,9 @@
 package profile
 
-import "github.com/janisto/echo-playground/internal/platform/timeutil"
+import (
+	"github.com/janisto/echo-playground/internal/platform/timeutil"
+	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
+)
 
 // Profile represents a user profile response.
 type Profile struct {
@@ -14,3 +17,29 @@ type Profile struct {
 	CreatedAt   timeutil.Time `json:"createdAt"   example:"2024-01-15T10:30:00.000Z"`
 	UpdatedAt   timeutil.Time `json:"updatedAt"   example:"2024-01-15T10:30:00.000Z"`
 }
+
+// OptionalFields lists fields sent as null in full representations when empty.
+func (Profile) OptionalFields() []string {
+	return []string{"phoneNumber"}
+}
+
+// FromService converts a stored profile to its response representation.
+func FromService(p *profilesvc.Profile) Profile {
+	return Profile{
+		ID:          p.ID,
+		Firstname:   p.Firstname,
+		Lastname:    p.Lastname,
+		Email:       p.Email,
+		PhoneNumber: p.PhoneNumber,
+		Marketing:   p.Marketing,
+		Terms:       p.Terms,
+		CreatedAt:   timeutil.Time{Time: p.CreatedAt},
+		UpdatedAt:   timeutil.Time{Time: p.UpdatedAt},
+	}
+}
+
+// Export is the downloadable copy of a user's data.
+type Export struct {
+	ExportedAt timeutil.Time `json:"exportedAt" example:"2024-01-15T10:30:00.000Z"`
+	Profile    Profile       `json:"profile"`
+}
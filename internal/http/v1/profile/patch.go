@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+// MIMEJSONPatch is the RFC 6902 JSON Patch media type accepted by PATCH /profile.
+const MIMEJSONPatch = "application/json-patch+json"
+
+// patchOp is a single RFC 6902 operation.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+	From  string          `json:"from,omitempty"`
+}
+
+// immutableFields are profile members a patch must not touch.
+var immutableFields = []string{"id", "terms", "createdAt", "updatedAt"}
+
+// bindJSONPatch translates an RFC 6902 patch in the request body into an
+// update. Only top-level members are addressable: add and replace set a
+// mutable field, and remove clears phoneNumber, the only optional field.
+// clearPhone reports that phoneNumber is removed. Other operations, and
+// patches touching immutable or unknown members, yield 422.
+func bindJSONPatch(c *echo.Context) (input UpdateInput, clearPhone bool, err error) {
+	var ops []patchOp
+	dec := json.NewDecoder(c.Request().Body)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&ops); err != nil {
+		return UpdateInput{}, false, respond.Error400("malformed JSON Patch document")
+	}
+
+	var details []respond.ErrorDetail
+	for i, op := range ops {
+		msg := applyPatchOp(&input, &clearPhone, op)
+		if msg != "" {
+			details = append(details, respond.ErrorDetail{
+				Message:  msg,
+				Location: fmt.Sprintf("body[%d]", i),
+				Value:    op.Op + " " + op.Path,
+			})
+		}
+	}
+	if len(details) > 0 {
+		return UpdateInput{}, false, respond.Error422("invalid JSON Patch", details...)
+	}
+	return input, clearPhone, nil
+}
+
+// applyPatchOp records op in input and returns a message when it is invalid.
+func applyPatchOp(input *UpdateInput, clearPhone *bool, op patchOp) string {
+	name, ok := strings.CutPrefix(op.Path, "/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "path must name a top-level member"
+	}
+	if slices.Contains(immutableFields, name) {
+		return name + " cannot be modified"
+	}
+
+	var target any
+	switch name {
+	case "firstname":
+		target = &input.Firstname
+	case "lastname":
+		target = &input.Lastname
+	case "email":
+		target = &input.Email
+	case "phoneNumber":
+		target = &input.PhoneNumber
+	case "marketing":
+		target = &input.Marketing
+	default:
+		return "unknown member " + name
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		if op.Value == nil || bytes.Equal(op.Value, []byte("null")) {
+			return "value is required"
+		}
+		if err := json.Unmarshal(op.Value, target); err != nil {
+			return "value has the wrong type"
+		}
+		if name == "phoneNumber" {
+			*clearPhone = false
+		}
+	case "remove":
+		if name != "phoneNumber" {
+			return name + " cannot be removed"
+		}
+		input.PhoneNumber = nil
+		*clearPhone = true
+	default:
+		return "unsupported operation " + op.Op
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package profile
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// vCardLineLimit is the longest content line RFC 6350 allows before folding,
+// in octets excluding the line break.
+const vCardLineLimit = 75
+
+// vCard renders p as a vCard 4.0 (RFC 6350) contact with its name, email and,
+// when set, phone number.
+func vCard(p Profile) []byte {
+	var b strings.Builder
+	writeVCardLine(&b, "BEGIN:VCARD")
+	writeVCardLine(&b, "VERSION:4.0")
+	writeVCardLine(&b, "FN:"+escapeVCard(strings.TrimSpace(p.Firstname+" "+p.Lastname)))
+	writeVCardLine(&b, "N:"+escapeVCard(p.Lastname)+";"+escapeVCard(p.Firstname)+";;;")
+	writeVCardLine(&b, "EMAIL:"+escapeVCard(p.Email))
+	if p.PhoneNumber != "" {
+		writeVCardLine(&b, "TEL;VALUE=uri:tel:"+p.PhoneNumber)
+	}
+	writeVCardLine(&b, "END:VCARD")
+	return []byte(b.String())
+}
+
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeVCard escapes a text value so separators and line breaks in it are
+// read literally.
+func escapeVCard(s string) string {
+	return vCardEscaper.Replace(s)
+}
+
+// writeVCardLine writes line terminated by CRLF, folding it onto continuation
+// lines that start with a space once it exceeds vCardLineLimit octets. Folds
+// never split a UTF-8 sequence.
+func writeVCardLine(b *strings.Builder, line string) {
+	limit := vCardLineLimit
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+		line = line[i:]
+		limit = vCardLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
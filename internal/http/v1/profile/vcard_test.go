@@ -0,0 +1,36 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestVCard_EscapesText(t *testing.T) {
+	got := string(vCard(Profile{Firstname: "Ann, Jr.", Lastname: `O;Neil\`, Email: "ann@example.com"}))
+	for _, line := range []string{`FN:Ann\, Jr. O\;Neil\\`, `N:O\;Neil\\;Ann\, Jr.;;;`} {
+		if !strings.Contains(got, line+"\r\n") {
+			t.Fatalf("expected line %q in\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "TEL") {
+		t.Fatalf("expected no TEL without a phone number, got\n%s", got)
+	}
+}
+
+func TestVCard_FoldsLongLines(t *testing.T) {
+	name := strings.Repeat("Ä", 60)
+	got := string(vCard(Profile{Firstname: name, Lastname: "Doe", Email: "a@example.com"}))
+
+	for line := range strings.SplitSeq(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+		if len(line) > vCardLineLimit {
+			t.Fatalf("line of %d octets exceeds the limit: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Fatalf("fold split a UTF-8 sequence: %q", line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(got, "\r\n ", ""); !strings.Contains(unfolded, "FN:"+name+" Doe\r\n") {
+		t.Fatalf("expected FN to unfold to the full name, got\n%s", unfolded)
+	}
+}
@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/http/v1/capabilities"
+	"github.com/janisto/echo-playground/internal/http/v1/hello"
+	"github.com/janisto/echo-playground/internal/http/v1/identity"
+	"github.com/janisto/echo-playground/internal/http/v1/items"
+	"github.com/janisto/echo-playground/internal/http/v1/profile"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/timeutil"
+)
+
+// fieldPaths returns the dotted path of every map key in a decoded document,
+// with slice elements addressed by index.
+func fieldPaths(v any) []string {
+	var paths []string
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				paths = append(paths, prefix+k)
+				walk(prefix+k+".", child)
+			}
+		case []any:
+			for i, child := range v {
+				walk(prefix+strconv.Itoa(i)+".", child)
+			}
+		}
+	}
+	walk("", v)
+	slices.Sort(paths)
+	return paths
+}
+
+// negotiatedFields encodes data with respond.Negotiate for accept and returns
+// the field paths of the response.
+func negotiatedFields(t *testing.T, accept string, data any) []string {
+	t.Helper()
+	e := echo.New()
+	e.GET("/", func(c *echo.Context) error {
+		return respond.Negotiate(c, http.StatusOK, data)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != accept {
+		t.Fatalf("expected %s, got %q", accept, ct)
+	}
+	var doc any
+	if accept == "application/cbor" {
+		dm, err := cbor.DecOptions{DefaultMapType: reflect.TypeFor[map[string]any]()}.DecMode()
+		if err != nil {
+			t.Fatalf("failed to build CBOR decoder: %v", err)
+		}
+		if err = dm.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("failed to unmarshal CBOR: %v", err)
+		}
+	} else if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to unmarshal JSON: %v", err)
+	}
+	return fieldPaths(doc)
+}
+
+func TestModels_CBORFieldPresenceMatchesJSON(t *testing.T) {
+	created := timeutil.NewTime(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	item := items.Item{ID: "item-001", Name: "Alpha Widget", Category: "electronics", Price: 29.99, CreatedAt: created}
+	fullProfile := profile.Profile{
+		ID:          "user-123",
+		Firstname:   "John",
+		Lastname:    "Doe",
+		Email:       "john@example.com",
+		PhoneNumber: "+358401234567",
+		Marketing:   true,
+		Terms:       true,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+
+	tests := []struct {
+		name string
+		data any
+	}{
+		{"zero profile", profile.Profile{}},
+		{"profile with false bools", profile.Profile{ID: "user-123", Firstname: "John", CreatedAt: created}},
+		{"full profile", fullProfile},
+		{"export", profile.Export{ExportedAt: created, Profile: fullProfile}},
+		{"zero problem", respond.ProblemDetails{}},
+		{"problem with empty lists", respond.ProblemDetails{
+			Status:   http.StatusBadRequest,
+			Errors:   []respond.ErrorDetail{},
+			Failures: []respond.ItemError{},
+			Category: []string{},
+		}},
+		{"full problem", respond.ProblemDetails{
+			Type:     "/problems/validation-error",
+			Title:    "Validation Error",
+			Status:   http.StatusUnprocessableEntity,
+			Detail:   "validation failed",
+			Instance: "/v1/profile",
+			Errors:   []respond.ErrorDetail{{Message: "required"}, {Message: "invalid", Location: "email", Value: "x"}},
+			Failures: []respond.ItemError{{Index: 1, Status: http.StatusConflict}},
+			Category: []string{"validation"},
+		}},
+		{"zero hello", hello.Data{}},
+		{"hello", hello.Data{Message: "Hello, World!"}},
+		{"nil item list", items.ListData{}},
+		{"empty item list", items.ListData{Items: []items.Item{}}},
+		{"item list", items.ListData{Items: []items.Item{item, {}}, Total: 2, HasMore: true}},
+		{"batch create", items.BatchCreateData{
+			Created: 1,
+			Results: []items.BatchItemResult{{Status: "created", Item: item}},
+		}},
+		{"zero identity", identity.Identity{}},
+		{"identity", identity.Identity{UID: "u", Email: "a@example.com", Roles: []string{}, Claims: map[string]any{}}},
+		{"zero capabilities", capabilities.Capabilities{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonFields := negotiatedFields(t, "application/json", tt.data)
+			cborFields := negotiatedFields(t, "application/cbor", tt.data)
+			if !slices.Equal(jsonFields, cborFields) {
+				t.Fatalf("field presence differs\nJSON: %v\nCBOR: %v", jsonFields, cborFields)
+			}
+		})
+	}
+}
+
+// TestModels_CBORTagsMatchJSON guards structs that declare cbor tags: a cbor
+// tag overrides the json tag the encoder otherwise falls back to, so it must
+// repeat the same name and omitempty.
+func TestModels_CBORTagsMatchJSON(t *testing.T) {
+	types := []reflect.Type{
+		reflect.TypeFor[profile.Profile](),
+		reflect.TypeFor[profile.Export](),
+		reflect.TypeFor[respond.ProblemDetails](),
+		reflect.TypeFor[respond.ErrorDetail](),
+		reflect.TypeFor[respond.ItemError](),
+		reflect.TypeFor[hello.Data](),
+		reflect.TypeFor[items.Item](),
+		reflect.TypeFor[items.ListData](),
+		reflect.TypeFor[items.BatchItemResult](),
+		reflect.TypeFor[items.BatchCreateData](),
+		reflect.TypeFor[identity.Identity](),
+		reflect.TypeFor[capabilities.Capabilities](),
+		reflect.TypeFor[capabilities.Pagination](),
+	}
+	for _, typ := range types {
+		for i := range typ.NumField() {
+			f := typ.Field(i)
+			cborTag, ok := f.Tag.Lookup("cbor")
+			if !ok {
+				continue
+			}
+			if jsonTag := f.Tag.Get("json"); cborTag != jsonTag {
+				t.Errorf("%s.%s: cbor tag %q differs from json tag %q", typ, f.Name, cborTag, jsonTag)
+			}
+		}
+	}
+}
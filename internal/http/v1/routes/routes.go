@@ -3,18 +3,39 @@ package routes
 import (
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/http/v1/admin"
+	"github.com/janisto/echo-playground/internal/http/v1/capabilities"
 	"github.com/janisto/echo-playground/internal/http/v1/hello"
+	"github.com/janisto/echo-playground/internal/http/v1/identity"
 	"github.com/janisto/echo-playground/internal/http/v1/items"
 	"github.com/janisto/echo-playground/internal/http/v1/profile"
 	"github.com/janisto/echo-playground/internal/platform/auth"
+	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
 	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
 )
 
 // Register wires all v1 routes into the provided group.
-func Register(v1 *echo.Group, verifier auth.Verifier, svc profilesvc.Service) {
+// Protected routes authenticate with a Firebase bearer token first, then
+// fall back to any additional schemes (e.g. service API keys) in order.
+// Admin routes are registered only when adminSvc is non-nil. itemOpts
+// configures the item handlers.
+func Register(
+	v1 *echo.Group,
+	verifier auth.Verifier,
+	svc profilesvc.Service,
+	adminSvc *auth.AdminService,
+	itemOpts []items.Option,
+	schemes ...auth.Scheme,
+) {
 	hello.Register(v1)
-	items.Register(v1)
+	capabilities.Register(v1, capabilities.Config{MaxBodyBytes: appmiddleware.MaxBodyBytes})
+	items.Register(v1, items.NewSampleStore(), itemOpts...)
 
-	protected := v1.Group("", auth.Middleware(verifier))
+	schemes = append([]auth.Scheme{auth.BearerScheme(verifier)}, schemes...)
+	protected := v1.Group("", auth.MiddlewareWithSchemes(schemes...))
 	profile.Register(protected, svc)
+	identity.Register(protected)
+	if adminSvc != nil {
+		admin.Register(protected, adminSvc, svc)
+	}
 }
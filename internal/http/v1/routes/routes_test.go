@@ -1,6 +1,9 @@
 package routes
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -11,28 +14,18 @@ import (
 
 	"github.com/janisto/echo-playground/internal/http/health"
 	"github.com/janisto/echo-playground/internal/platform/auth"
-	applog "github.com/janisto/echo-playground/internal/platform/logging"
-	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
 	"github.com/janisto/echo-playground/internal/platform/respond"
-	"github.com/janisto/echo-playground/internal/platform/validate"
 	profilesvc "github.com/janisto/echo-playground/internal/service/profile"
+	"github.com/janisto/echo-playground/internal/testutil"
 )
 
 func setupTestServer(verifier auth.Verifier, svc profilesvc.Service) *echo.Echo {
-	e := echo.New()
-	e.Validator = validate.New()
-	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
-	e.Use(
-		appmiddleware.RequestID(),
-		applog.RequestLogger(),
-		respond.Recoverer(),
-	)
-
-	e.GET("/health", health.Handler)
-
-	v1 := e.Group("/v1")
-	Register(v1, verifier, svc)
-	return e
+	return testutil.NewTestServer(testutil.ServerOptions{
+		Verifier: verifier,
+		Register: func(v1 *echo.Group, verifier auth.Verifier) {
+			Register(v1, verifier, svc, nil, nil)
+		},
+	})
 }
 
 func TestHealthEndpoint(t *testing.T) {
@@ -106,6 +99,39 @@ func TestItemsEndpoint(t *testing.T) {
 	}
 }
 
+func TestCacheControlPerRoute(t *testing.T) {
+	svc := profilesvc.NewMockStore()
+	if _, err := svc.Create(context.Background(), auth.TestUser().UID, profilesvc.CreateParams{
+		Firstname: "John", Lastname: "Doe", Email: "john@example.com", PhoneNumber: "+358401234567", Terms: true,
+	}); err != nil {
+		t.Fatalf("failed to seed profile: %v", err)
+	}
+	e := setupTestServer(&auth.MockVerifier{User: auth.TestUser()}, svc)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"item list", "/v1/items", "public, max-age=60"},
+		{"item", "/v1/items/item-001", "public, max-age=60"},
+		{"unknown item", "/v1/items/missing", "no-store"},
+		{"profile", "/v1/profile", "no-store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Fatalf("expected Cache-Control %q, got %q (status %d)", tt.want, got, rec.Code)
+			}
+		})
+	}
+}
+
 func TestNotFoundReturns404(t *testing.T) {
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
 	svc := profilesvc.NewMockStore()
@@ -236,6 +262,81 @@ func TestProfileCRUD(t *testing.T) {
 	}
 }
 
+func TestProfileCreateRejectsInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name      string
+		firstname string
+		want      int
+	}{
+		{"invalid sequence", "Jo\xffhn", http.StatusBadRequest},
+		{"valid multibyte", "Jöhn 日本", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupTestServer(&auth.MockVerifier{User: auth.TestUser()}, profilesvc.NewMockStore())
+
+			body := `{"firstname":"` + tt.firstname +
+				`","lastname":"Doe","email":"john@example.com","phoneNumber":"+358401234567","terms":true}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/profile", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d; body: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			if tt.want == http.StatusBadRequest {
+				if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+					t.Fatalf("expected Problem Details, got %q", ct)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileCreateRejectsDuplicateHeaders(t *testing.T) {
+	body := `{"firstname":"John","lastname":"Doe","email":"john@example.com",` +
+		`"phoneNumber":"+358401234567","terms":true}`
+	tests := []struct {
+		name        string
+		auth        []string
+		contentType []string
+		want        int
+	}{
+		{"single headers", []string{"Bearer test-token"}, []string{"application/json"}, http.StatusCreated},
+		{"duplicate Authorization", []string{"Bearer test-token", "Bearer other-token"},
+			[]string{"application/json"}, http.StatusBadRequest},
+		{"duplicate Content-Type", []string{"Bearer test-token"},
+			[]string{"application/json", "application/x-www-form-urlencoded"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupTestServer(&auth.MockVerifier{User: auth.TestUser()}, profilesvc.NewMockStore())
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/profile", strings.NewReader(body))
+			req.Header["Authorization"] = tt.auth
+			req.Header["Content-Type"] = tt.contentType
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d; body: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			if tt.want != http.StatusBadRequest {
+				return
+			}
+			var problem respond.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("expected Problem Details: %v", err)
+			}
+			if !strings.HasPrefix(problem.Detail, "duplicate ") {
+				t.Fatalf("expected a duplicate header detail, got %q", problem.Detail)
+			}
+		})
+	}
+}
+
 func TestPanicRecovery(t *testing.T) {
 	verifier := &auth.MockVerifier{User: auth.TestUser()}
 	svc := profilesvc.NewMockStore()
@@ -261,3 +362,39 @@ func TestPanicRecovery(t *testing.T) {
 		t.Fatalf("expected status 500, got %d", problem.Status)
 	}
 }
+
+func TestProfileCreateGzipBody(t *testing.T) {
+	profile := `{"firstname":"John","lastname":"Doe","email":"john@example.com",` +
+		`"phoneNumber":"+358401234567","terms":true}`
+	tests := []struct {
+		name    string
+		padding int
+		want    int
+	}{
+		{"normal payload", 0, http.StatusCreated},
+		{"expands past the body limit", 8 << 20, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setupTestServer(&auth.MockVerifier{User: auth.TestUser()}, profilesvc.NewMockStore())
+
+			var body bytes.Buffer
+			zw := gzip.NewWriter(&body)
+			_, _ = zw.Write(bytes.Repeat([]byte(" "), tt.padding))
+			_, _ = zw.Write([]byte(profile))
+			if err := zw.Close(); err != nil {
+				t.Fatalf("failed to compress: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/v1/profile", &body)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", "gzip")
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d; body: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
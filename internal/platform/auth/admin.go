@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	fbauth "firebase.google.com/go/v4/auth"
+)
+
+// AdminRole is the role required for administrative endpoints. Firebase users
+// receive it through the "roles" custom claim.
+const AdminRole = "admin"
+
+// ErrUserNotFound indicates the target user does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// TokenRevoker is the subset of the Firebase Auth client used to revoke
+// sessions. *fbauth.Client satisfies it.
+type TokenRevoker interface {
+	RevokeRefreshTokens(ctx context.Context, uid string) error
+}
+
+// AdminService performs privileged operations on other users' accounts.
+type AdminService struct {
+	client TokenRevoker
+}
+
+// NewAdminService creates an AdminService backed by the given client.
+func NewAdminService(client TokenRevoker) *AdminService {
+	return &AdminService{client: client}
+}
+
+// RevokeSessions revokes every refresh token of the user, so existing
+// sessions end at their next token refresh. FirebaseVerifier checks
+// revocation on every request, so ID tokens issued before the call are
+// rejected immediately.
+func (s *AdminService) RevokeSessions(ctx context.Context, uid string) error {
+	if err := s.client.RevokeRefreshTokens(ctx, uid); err != nil {
+		if fbauth.IsUserNotFound(err) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeRevoker struct {
+	uids []string
+	err  error
+}
+
+func (f *fakeRevoker) RevokeRefreshTokens(_ context.Context, uid string) error {
+	f.uids = append(f.uids, uid)
+	return f.err
+}
+
+func TestAdminService_RevokeSessions(t *testing.T) {
+	client := &fakeRevoker{}
+	if err := NewAdminService(client).RevokeSessions(context.Background(), "user-42"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.uids) != 1 || client.uids[0] != "user-42" {
+		t.Fatalf("expected revocation for user-42, got %v", client.uids)
+	}
+}
+
+func TestAdminService_RevokeSessionsError(t *testing.T) {
+	boom := errors.New("backend unavailable")
+	err := NewAdminService(&fakeRevoker{err: boom}).RevokeSessions(context.Background(), "user-42")
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected backend error, got %v", err)
+	}
+}
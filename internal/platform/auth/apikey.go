@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ServiceRole is the role granted to callers authenticated with an API key.
+const ServiceRole = "service"
+
+// APIKey describes a service credential by the SHA-256 digest of its secret.
+// Plaintext keys are never stored.
+type APIKey struct {
+	Name  string
+	Hash  string // hex-encoded SHA-256 of the key
+	Roles []string
+}
+
+type apiKeyEntry struct {
+	name  string
+	hash  []byte
+	roles []string
+}
+
+// APIKeyVerifier implements Verifier for static service-to-service API keys.
+// Authenticated callers receive a synthetic FirebaseUser with UID "service:<name>".
+type APIKeyVerifier struct {
+	keys []apiKeyEntry
+}
+
+// NewAPIKeyVerifier creates a verifier for the given hashed keys.
+// Keys without explicit roles are granted ServiceRole.
+func NewAPIKeyVerifier(keys ...APIKey) (*APIKeyVerifier, error) {
+	entries := make([]apiKeyEntry, 0, len(keys))
+	for _, k := range keys {
+		if k.Name == "" {
+			return nil, errors.New("api key name is required")
+		}
+		hash, err := hex.DecodeString(k.Hash)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: hash must be a hex-encoded SHA-256 digest", k.Name)
+		}
+		roles := k.Roles
+		if len(roles) == 0 {
+			roles = []string{ServiceRole}
+		}
+		entries = append(entries, apiKeyEntry{name: k.Name, hash: hash, roles: roles})
+	}
+	return &APIKeyVerifier{keys: entries}, nil
+}
+
+// ParseAPIKeys parses a comma-separated list of "name:sha256hex" entries,
+// as read from the API_KEYS environment variable. An entry may grant roles
+// with a third "|"-separated field, as in "ops:sha256hex:admin|service";
+// entries without one are granted ServiceRole.
+func ParseAPIKeys(spec string) ([]APIKey, error) {
+	var keys []APIKey
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(part, ":")
+		hash, roleSpec, hasRoles := strings.Cut(rest, ":")
+		if !ok || name == "" || hash == "" {
+			return nil, errors.New("invalid api key entry: expected name:sha256hex[:roles]")
+		}
+		var roles []string
+		if hasRoles {
+			for role := range strings.SplitSeq(roleSpec, "|") {
+				if role = strings.TrimSpace(role); role != "" {
+					roles = append(roles, role)
+				}
+			}
+			if len(roles) == 0 {
+				return nil, fmt.Errorf("api key %q: roles must not be empty", name)
+			}
+		}
+		keys = append(keys, APIKey{Name: name, Hash: strings.ToLower(hash), Roles: roles})
+	}
+	return keys, nil
+}
+
+// Verify hashes the presented key and compares it against every configured
+// digest in constant time.
+func (v *APIKeyVerifier) Verify(_ context.Context, key string) (*FirebaseUser, error) {
+	if key == "" {
+		return nil, ErrInvalidToken
+	}
+	sum := sha256.Sum256([]byte(key))
+
+	var match *apiKeyEntry
+	for i := range v.keys {
+		if subtle.ConstantTimeCompare(sum[:], v.keys[i].hash) == 1 {
+			match = &v.keys[i]
+		}
+	}
+	if match == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &FirebaseUser{
+		UID:     "service:" + match.name,
+		Roles:   append([]string(nil), match.roles...),
+		Service: true,
+	}, nil
+}
+
+var _ Verifier = (*APIKeyVerifier)(nil)
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPIKeyVerifier_ValidKey(t *testing.T) {
+	v, err := NewAPIKeyVerifier(APIKey{Name: "billing", Hash: hashKey("secret-1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	user, err := v.Verify(context.Background(), "secret-1")
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if user.UID != "service:billing" {
+		t.Fatalf("expected UID service:billing, got %q", user.UID)
+	}
+	if !user.HasRole(ServiceRole) {
+		t.Fatalf("expected default service role, got %v", user.Roles)
+	}
+	if !user.Service {
+		t.Fatal("expected a service principal")
+	}
+}
+
+func TestAPIKeyVerifier_CustomRoles(t *testing.T) {
+	v, err := NewAPIKeyVerifier(
+		APIKey{Name: "a", Hash: hashKey("key-a")},
+		APIKey{Name: "b", Hash: hashKey("key-b"), Roles: []string{"admin"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	user, err := v.Verify(context.Background(), "key-b")
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if user.UID != "service:b" || !slices.Equal(user.Roles, []string{"admin"}) {
+		t.Fatalf("unexpected user %+v", user)
+	}
+}
+
+func TestAPIKeyVerifier_InvalidKey(t *testing.T) {
+	v, err := NewAPIKeyVerifier(APIKey{Name: "billing", Hash: hashKey("secret-1")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"wrong", ""} {
+		if _, err := v.Verify(context.Background(), key); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify(%q): expected ErrInvalidToken, got %v", key, err)
+		}
+	}
+}
+
+func TestNewAPIKeyVerifier_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		key  APIKey
+	}{
+		{"missing name", APIKey{Hash: hashKey("x")}},
+		{"non-hex hash", APIKey{Name: "a", Hash: "zz"}},
+		{"short hash", APIKey{Name: "a", Hash: "abcd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAPIKeyVerifier(tt.key); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	h := hashKey("k")
+	keys, err := ParseAPIKeys(" billing:" + h + " , ,reports:" + h)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "billing" || keys[1].Name != "reports" || keys[0].Hash != h {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	keys, err = ParseAPIKeys("ops:" + h + ":admin|service")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].Hash != h || !slices.Equal(keys[0].Roles, []string{"admin", "service"}) {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	for _, spec := range []string{"no-separator", ":" + h, "name:", "name:" + h + ":", "name:" + h + ":|"} {
+		if _, err := ParseAPIKeys(spec); err == nil {
+			t.Fatalf("ParseAPIKeys(%q): expected error", spec)
+		}
+	}
+}
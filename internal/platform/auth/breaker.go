@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+)
+
+// Circuit breaker defaults applied when BreakerConfig fields are zero.
+const (
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// BreakerConfig configures BreakerVerifier.
+type BreakerConfig struct {
+	// Threshold is the number of consecutive ErrCertificateFetch failures
+	// that open the circuit.
+	Threshold int
+	// Cooldown is how long the circuit stays open before a probe is allowed.
+	Cooldown time.Duration
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// BreakerVerifier decorates a Verifier with a circuit breaker for Firebase
+// outages. After Threshold consecutive ErrCertificateFetch failures the
+// circuit opens and Verify fails fast with ErrCertificateFetch, which the
+// middleware answers with 503 and Retry-After. Once Cooldown has passed, one
+// request probes the wrapped verifier while the rest keep failing fast; any
+// outcome other than ErrCertificateFetch closes the circuit, another fetch
+// failure reopens it.
+//
+// Token errors such as ErrInvalidToken show that certificates were fetched,
+// so they reset the failure count.
+type BreakerVerifier struct {
+	next Verifier
+	cfg  BreakerConfig
+	now  func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewBreakerVerifier wraps next with a circuit breaker.
+func NewBreakerVerifier(next Verifier, cfg BreakerConfig) *BreakerVerifier {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultBreakerThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultBreakerCooldown
+	}
+	return &BreakerVerifier{next: next, cfg: cfg, now: time.Now}
+}
+
+// Verify delegates to the wrapped verifier unless the circuit is open.
+func (v *BreakerVerifier) Verify(ctx context.Context, token string) (*FirebaseUser, error) {
+	if !v.allow() {
+		return nil, ErrCertificateFetch
+	}
+	user, err := v.next.Verify(ctx, token)
+	v.record(ctx, errors.Is(err, ErrCertificateFetch))
+	return user, err
+}
+
+// allow reports whether a request may reach the wrapped verifier, moving an
+// open circuit to half-open once the cooldown has passed.
+func (v *BreakerVerifier) allow() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	switch v.state {
+	case breakerOpen:
+		if v.now().Sub(v.openedAt) < v.cfg.Cooldown {
+			return false
+		}
+		v.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (v *BreakerVerifier) record(ctx context.Context, fetchFailed bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !fetchFailed {
+		if v.state == breakerHalfOpen {
+			applog.LogInfo(ctx, "auth circuit closed")
+		}
+		v.state = breakerClosed
+		v.failures = 0
+		return
+	}
+
+	v.failures++
+	if v.state == breakerHalfOpen || v.failures >= v.cfg.Threshold {
+		if v.state != breakerOpen {
+			applog.LogWarn(ctx, "auth circuit opened", slog.Int("failures", v.failures))
+		}
+		v.state = breakerOpen
+		v.openedAt = v.now()
+	}
+}
+
+var _ Verifier = (*BreakerVerifier)(nil)
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingVerifier records calls and returns the configured outcome.
+type countingVerifier struct {
+	calls int
+	err   error
+}
+
+func (v *countingVerifier) Verify(context.Context, string) (*FirebaseUser, error) {
+	v.calls++
+	if v.err != nil {
+		return nil, v.err
+	}
+	return TestUser(), nil
+}
+
+func newTestBreaker(next Verifier, now *time.Time) *BreakerVerifier {
+	b := NewBreakerVerifier(next, BreakerConfig{Threshold: 3, Cooldown: time.Minute})
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestBreakerVerifier_TripsAndFailsFast(t *testing.T) {
+	now := time.Now()
+	next := &countingVerifier{err: ErrCertificateFetch}
+	b := newTestBreaker(next, &now)
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := b.Verify(ctx, "token"); !errors.Is(err, ErrCertificateFetch) {
+			t.Fatalf("expected ErrCertificateFetch, got %v", err)
+		}
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected 3 calls before tripping, got %d", next.calls)
+	}
+
+	next.err = nil
+	now = now.Add(30 * time.Second)
+	if _, err := b.Verify(ctx, "token"); !errors.Is(err, ErrCertificateFetch) {
+		t.Fatalf("expected fast failure while open, got %v", err)
+	}
+	if next.calls != 3 {
+		t.Fatalf("expected no call while open, got %d calls", next.calls)
+	}
+}
+
+func TestBreakerVerifier_RecoversAfterProbe(t *testing.T) {
+	now := time.Now()
+	next := &countingVerifier{err: ErrCertificateFetch}
+	b := newTestBreaker(next, &now)
+	ctx := context.Background()
+
+	for range 3 {
+		_, _ = b.Verify(ctx, "token")
+	}
+
+	next.err = nil
+	now = now.Add(time.Minute)
+	user, err := b.Verify(ctx, "token")
+	if err != nil || user == nil {
+		t.Fatalf("expected half-open probe to succeed, got %v", err)
+	}
+	if next.calls != 4 {
+		t.Fatalf("expected the probe to reach the verifier, got %d calls", next.calls)
+	}
+
+	if _, err = b.Verify(ctx, "token"); err != nil {
+		t.Fatalf("expected closed circuit to pass requests, got %v", err)
+	}
+	if next.calls != 5 {
+		t.Fatalf("expected 5 calls, got %d", next.calls)
+	}
+}
+
+func TestBreakerVerifier_FailedProbeReopens(t *testing.T) {
+	now := time.Now()
+	next := &countingVerifier{err: ErrCertificateFetch}
+	b := newTestBreaker(next, &now)
+	ctx := context.Background()
+
+	for range 3 {
+		_, _ = b.Verify(ctx, "token")
+	}
+	now = now.Add(time.Minute)
+	if _, err := b.Verify(ctx, "token"); !errors.Is(err, ErrCertificateFetch) {
+		t.Fatalf("expected probe to fail, got %v", err)
+	}
+	if next.calls != 4 {
+		t.Fatalf("expected the probe to reach the verifier, got %d calls", next.calls)
+	}
+
+	now = now.Add(30 * time.Second)
+	_, _ = b.Verify(ctx, "token")
+	if next.calls != 4 {
+		t.Fatalf("expected a failed probe to restart the cooldown, got %d calls", next.calls)
+	}
+}
+
+func TestBreakerVerifier_TokenErrorsResetFailures(t *testing.T) {
+	now := time.Now()
+	next := &countingVerifier{}
+	b := newTestBreaker(next, &now)
+	ctx := context.Background()
+
+	outcomes := []error{ErrCertificateFetch, ErrCertificateFetch, ErrInvalidToken, ErrCertificateFetch, ErrCertificateFetch}
+	for _, err := range outcomes {
+		next.err = err
+		_, _ = b.Verify(ctx, "token")
+	}
+
+	next.err = nil
+	if _, err := b.Verify(ctx, "token"); err != nil {
+		t.Fatalf("expected circuit to stay closed, got %v", err)
+	}
+}
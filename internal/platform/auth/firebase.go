@@ -3,6 +3,8 @@ package auth
 import (
 	"context"
 	"errors"
+	"net/http"
+	"slices"
 	"strings"
 
 	fbauth "firebase.google.com/go/v4/auth"
@@ -13,6 +15,18 @@ type FirebaseUser struct {
 	UID           string
 	Email         string
 	EmailVerified bool
+	Roles         []string
+	// Service marks a principal authenticated with an API key rather than
+	// an end user.
+	Service bool
+	// Claims holds the token's non-registered claims, including custom
+	// claims such as roles. It never contains the raw token.
+	Claims map[string]any
+}
+
+// HasRole reports whether the user was granted the given role.
+func (u *FirebaseUser) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
 }
 
 // Error types for authentication failures.
@@ -67,9 +81,23 @@ func (v *FirebaseVerifier) Verify(ctx context.Context, idToken string) (*Firebas
 		UID:           token.UID,
 		Email:         email,
 		EmailVerified: verified,
+		Roles:         claimRoles(token.Claims["roles"]),
+		Claims:        token.Claims,
 	}, nil
 }
 
+// claimRoles reads the "roles" custom claim, a list of role names.
+func claimRoles(claim any) []string {
+	list, _ := claim.([]any)
+	var roles []string
+	for _, v := range list {
+		if role, ok := v.(string); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
 // ExtractBearerToken extracts the token from Authorization header.
 func ExtractBearerToken(header string) (string, error) {
 	if header == "" {
@@ -82,4 +110,13 @@ func ExtractBearerToken(header string) (string, error) {
 	return parts[1], nil
 }
 
+// ExtractCookieToken returns the token stored in the named request cookie.
+func ExtractCookieToken(r *http.Request, name string) (string, error) {
+	cookie, err := r.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		return "", ErrNoToken
+	}
+	return cookie.Value, nil
+}
+
 var _ Verifier = (*FirebaseVerifier)(nil)
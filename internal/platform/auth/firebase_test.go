@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -216,3 +217,23 @@ func TestFirebaseVerifier_Verify_DisabledUser(t *testing.T) {
 		t.Fatalf("expected ErrUserDisabled or ErrInvalidToken, got %v", err)
 	}
 }
+
+func TestClaimRoles(t *testing.T) {
+	tests := []struct {
+		name  string
+		claim any
+		want  []string
+	}{
+		{"missing", nil, nil},
+		{"list", []any{"admin", "support"}, []string{"admin", "support"}},
+		{"skips non-strings", []any{"admin", 7, ""}, []string{"admin"}},
+		{"not a list", "admin", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := claimRoles(tt.claim); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
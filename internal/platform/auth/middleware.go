@@ -4,48 +4,146 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
 	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/metrics"
 	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/timing"
 )
 
-// userContextKey is the context key for the authenticated user.
-type userContextKey struct{}
+// userKey stores the authenticated user on the request context.
+var userKey = ctxkeys.NewKey[*FirebaseUser]("user")
+
+// HeaderAPIKey is the request header carrying service API keys.
+const HeaderAPIKey = "X-API-Key"
+
+// Scheme binds a credential location in the request to the Verifier that
+// validates it. Extract returns ErrNoToken when the credential is absent.
+// Challenge, when set, builds the scheme's WWW-Authenticate value for a 401;
+// reason is "" when no credential was presented for the scheme, otherwise the
+// failure category from verification.
+type Scheme struct {
+	Name      string
+	Extract   func(r *http.Request) (string, error)
+	Verifier  Verifier
+	Challenge func(reason string) string
+}
+
+// BearerScheme reads a bearer token from the Authorization header.
+func BearerScheme(verifier Verifier) Scheme {
+	return Scheme{
+		Name: "Bearer",
+		Extract: func(r *http.Request) (string, error) {
+			return ExtractBearerToken(r.Header.Get("Authorization"))
+		},
+		Verifier:  verifier,
+		Challenge: bearerChallenge,
+	}
+}
+
+// APIKeyScheme reads a service API key from the X-API-Key header.
+func APIKeyScheme(verifier Verifier) Scheme {
+	return Scheme{
+		Name: "ApiKey",
+		Extract: func(r *http.Request) (string, error) {
+			key := r.Header.Get(HeaderAPIKey)
+			if key == "" {
+				return "", ErrNoToken
+			}
+			return key, nil
+		},
+		Verifier:  verifier,
+		Challenge: apiKeyChallenge,
+	}
+}
+
+// CookieScheme reads a Firebase ID token from the named cookie, for browser
+// clients that keep it in an HttpOnly cookie. It applies only when the
+// Authorization header is absent, so a header always takes precedence.
+// Because browsers attach cookies to cross-site requests, the cookie is
+// ignored for unsafe methods unless Sec-Fetch-Site or Origin shows the request
+// came from the same origin.
+func CookieScheme(verifier Verifier, name string) Scheme {
+	csrf := http.NewCrossOriginProtection()
+	return Scheme{
+		Name: "Cookie",
+		Extract: func(r *http.Request) (string, error) {
+			if r.Header.Get("Authorization") != "" {
+				return "", ErrNoToken
+			}
+			if err := csrf.Check(r); err != nil {
+				return "", ErrNoToken
+			}
+			return ExtractCookieToken(r, name)
+		},
+		Verifier: verifier,
+	}
+}
 
 // Middleware returns Echo middleware for Firebase authentication.
 // Applied at the group level to protect routes requiring authentication.
 func Middleware(verifier Verifier) echo.MiddlewareFunc {
+	return MiddlewareWithSchemes(BearerScheme(verifier))
+}
+
+// MiddlewareWithSchemes returns Echo middleware that tries each scheme in order
+// and authenticates the request with the first one that succeeds. When every
+// scheme fails, the failures are aggregated into a single 401 response that
+// carries one WWW-Authenticate challenge per scheme, or a 503 if any failure
+// was a transient certificate fetch error.
+func MiddlewareWithSchemes(schemes ...Scheme) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
-			token, err := ExtractBearerToken(c.Request().Header.Get("Authorization"))
-			if err != nil {
-				applog.LogWarn(c.Request().Context(), "auth failed: missing or invalid header",
-					slog.String("reason", "no_token"))
-				c.Response().Header().Set("WWW-Authenticate", "Bearer")
-				return respond.Error401("missing or invalid authorization header")
-			}
+			ctx := c.Request().Context()
+			rec := timing.FromContext(ctx)
 
-			user, err := verifier.Verify(c.Request().Context(), token)
-			if err != nil {
-				reason := categorizeAuthError(err)
-				applog.LogWarn(c.Request().Context(), "auth failed: token verification failed",
-					slog.String("reason", reason))
+			var errs []error
+			reasons := make([]string, len(schemes))
+			for i, s := range schemes {
+				token, err := s.Extract(c.Request())
+				if err != nil {
+					continue
+				}
 
-				if errors.Is(err, ErrCertificateFetch) {
-					c.Response().Header().Set("Retry-After", "30")
-					return respond.Error503("authentication service temporarily unavailable")
+				rec.Start("auth")
+				user, err := s.Verifier.Verify(ctx, token)
+				rec.Stop("auth")
+				if err != nil {
+					errs = append(errs, err)
+					reasons[i] = categorizeAuthError(err)
+					continue
 				}
-				c.Response().Header().Set("WWW-Authenticate", "Bearer")
-				return respond.Error401("invalid or expired token")
+
+				userKey.Set(c, user)
+
+				return next(c)
+			}
+
+			if len(errs) == 0 {
+				applog.LogWarn(ctx, "auth failed: missing or invalid header",
+					slog.String("reason", "no_token"))
+				metrics.IncCounter(metrics.AuthFailures, metrics.Labels{"reason": "no_token"})
+				setChallenges(c.Response().Header(), schemes, reasons)
+				return respond.Error401("missing or invalid authorization header")
 			}
 
-			c.Set("user", user)
-			ctx := context.WithValue(c.Request().Context(), userContextKey{}, user)
-			c.SetRequest(c.Request().WithContext(ctx))
+			err := errors.Join(errs...)
+			reason := categorizeAuthError(err)
+			applog.LogWarn(ctx, "auth failed: token verification failed",
+				slog.String("reason", reason))
+			metrics.IncCounter(metrics.AuthFailures, metrics.Labels{"reason": reason})
 
-			return next(c)
+			if errors.Is(err, ErrCertificateFetch) {
+				c.Response().Header().Set("Retry-After", "30")
+				return respond.Error503("authentication service temporarily unavailable")
+			}
+			setChallenges(c.Response().Header(), schemes, reasons)
+			return respond.Error401("invalid or expired token")
 		}
 	}
 }
@@ -68,14 +166,126 @@ func categorizeAuthError(err error) string {
 	}
 }
 
+// authRealm is the protection space advertised in Bearer challenges.
+const authRealm = "api"
+
+// tokenErrorDescriptions maps categorizeAuthError reasons to the
+// error_description sent in RFC 6750 challenges.
+var tokenErrorDescriptions = map[string]string{
+	"token_expired": "the token expired",
+	"token_revoked": "the token has been revoked",
+	"user_disabled": "the user account is disabled",
+	"invalid_token": "the token is malformed or invalid",
+}
+
+// setChallenges replaces the WWW-Authenticate header with one value per scheme
+// that defines a challenge, in scheme order.
+func setChallenges(h http.Header, schemes []Scheme, reasons []string) {
+	h.Del("WWW-Authenticate")
+	for i, s := range schemes {
+		if s.Challenge != nil {
+			h.Add("WWW-Authenticate", s.Challenge(reasons[i]))
+		}
+	}
+}
+
+// apiKeyChallenge builds the WWW-Authenticate value for the ApiKey scheme,
+// naming the header that carries the key.
+func apiKeyChallenge(string) string {
+	return "ApiKey realm=" + quoteAuthParam(authRealm) + ", header=" + quoteAuthParam(HeaderAPIKey)
+}
+
+// bearerChallenge builds an RFC 6750 Section 3 WWW-Authenticate value.
+// An empty reason means no credentials were presented, so only the realm is
+// sent; otherwise the challenge carries error="invalid_token" and a
+// description of the failure category.
+func bearerChallenge(reason string) string {
+	if reason == "" {
+		return "Bearer realm=" + quoteAuthParam(authRealm)
+	}
+	desc, ok := tokenErrorDescriptions[reason]
+	if !ok {
+		desc = tokenErrorDescriptions["invalid_token"]
+	}
+	return "Bearer error=" + quoteAuthParam("invalid_token") +
+		", error_description=" + quoteAuthParam(desc)
+}
+
+// quoteAuthParam renders v as an RFC 9110 quoted-string, escaping quotes and
+// backslashes.
+func quoteAuthParam(v string) string {
+	var b strings.Builder
+	b.Grow(len(v) + 2)
+	b.WriteByte('"')
+	for i := range len(v) {
+		if v[i] == '"' || v[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(v[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// RequireRole returns Echo middleware that only admits authenticated users
+// granted role. Apply it after the authentication middleware; callers without
+// the role receive 403.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			user, err := UserFromEchoContext(c)
+			if err != nil {
+				return respond.Error401("unauthorized")
+			}
+			if !user.HasRole(role) {
+				applog.LogWarn(c.Request().Context(), "auth failed: missing role", slog.String("role", role))
+				return respond.Error403("insufficient permissions")
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireEndUser returns Echo middleware that rejects service principals with
+// 403, for routes that act on the caller's own user data. Apply it after the
+// authentication middleware.
+func RequireEndUser() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			user, err := UserFromEchoContext(c)
+			if err != nil {
+				return respond.Error401("unauthorized")
+			}
+			if user.Service {
+				applog.LogWarn(c.Request().Context(), "auth failed: service principal on user route")
+				return respond.Error403("service principals cannot access user resources")
+			}
+			return next(c)
+		}
+	}
+}
+
 // UserFromEchoContext retrieves the authenticated user from Echo context.
 func UserFromEchoContext(c *echo.Context) (*FirebaseUser, error) {
-	return echo.ContextGet[*FirebaseUser](c, "user")
+	user, ok := userKey.Get(c)
+	if !ok || user == nil {
+		return nil, echo.ErrNonExistentKey
+	}
+	return user, nil
 }
 
 // UserFromContext retrieves the authenticated user from standard context.
 // Returns nil if no user is authenticated.
 func UserFromContext(ctx context.Context) *FirebaseUser {
-	user, _ := ctx.Value(userContextKey{}).(*FirebaseUser)
+	user, _ := userKey.Value(ctx)
 	return user
 }
+
+// UserIDFromContext returns the authenticated user's UID, or an empty string
+// if no user is authenticated.
+func UserIDFromContext(ctx context.Context) string {
+	if user := UserFromContext(ctx); user != nil {
+		return user.UID
+	}
+	return ""
+}
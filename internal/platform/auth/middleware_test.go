@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/platform/metrics"
 	"github.com/janisto/echo-playground/internal/platform/respond"
 )
 
@@ -64,8 +66,8 @@ func TestMiddleware_MissingAuthHeader(t *testing.T) {
 	}
 
 	wwwAuth := rec.Header().Get("WWW-Authenticate")
-	if wwwAuth != "Bearer" {
-		t.Fatalf("expected WWW-Authenticate: Bearer, got %q", wwwAuth)
+	if wwwAuth != `Bearer realm="api"` {
+		t.Fatalf(`expected WWW-Authenticate: Bearer realm="api", got %q`, wwwAuth)
 	}
 }
 
@@ -89,6 +91,32 @@ func TestMiddleware_InvalidToken(t *testing.T) {
 	}
 }
 
+func TestMiddleware_InvalidTokenMetric(t *testing.T) {
+	mem := metrics.NewMemory()
+	metrics.SetRecorder(mem)
+	t.Cleanup(func() { metrics.SetRecorder(nil) })
+
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.Use(Middleware(&MockVerifier{Error: ErrInvalidToken}))
+	e.GET("/test", func(c *echo.Context) error {
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer bad-token")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if got := mem.Count(metrics.AuthFailures, metrics.Labels{"reason": "invalid_token"}); got != 2 {
+		t.Fatalf("expected invalid_token counter 2, got %d", got)
+	}
+	if got := mem.Count(metrics.AuthFailures, metrics.Labels{"reason": "token_expired"}); got != 0 {
+		t.Fatalf("expected token_expired counter 0, got %d", got)
+	}
+}
+
 func TestMiddleware_ExpiredToken(t *testing.T) {
 	verifier := &MockVerifier{Error: ErrTokenExpired}
 
@@ -189,6 +217,117 @@ func TestUserFromContext_Standard(t *testing.T) {
 	}
 }
 
+func TestUserFromEchoContext_IgnoresStringKey(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.GET("/spoofed", func(c *echo.Context) error {
+		c.Set("user", &FirebaseUser{UID: "spoofed"})
+		if _, err := UserFromEchoContext(c); err == nil {
+			t.Fatal("expected a string-keyed user to be ignored")
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/authenticated", func(c *echo.Context) error {
+		c.Set("user", &FirebaseUser{UID: "spoofed"})
+		u, err := UserFromEchoContext(c)
+		if err != nil {
+			return err
+		}
+		if UserFromContext(c.Request().Context()) != u {
+			t.Fatal("expected the Echo and request context accessors to agree")
+		}
+		return c.String(http.StatusOK, u.UID)
+	}, Middleware(&MockVerifier{User: TestUser()}))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/spoofed", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/authenticated", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if got := rec.Body.String(); got != TestUser().UID {
+		t.Fatalf("expected the verified user %q, got %q", TestUser().UID, got)
+	}
+}
+
+func TestUserIDFromContext(t *testing.T) {
+	if got := UserIDFromContext(context.Background()); got != "" {
+		t.Fatalf("expected empty UID without user, got %q", got)
+	}
+	user := TestUser()
+	ctx := userKey.With(context.Background(), user)
+	if got := UserIDFromContext(ctx); got != user.UID {
+		t.Fatalf("expected %q, got %q", user.UID, got)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	admin := TestUser()
+	admin.Roles = []string{AdminRole}
+
+	tests := []struct {
+		name   string
+		user   *FirebaseUser
+		status int
+	}{
+		{"granted", admin, http.StatusNoContent},
+		{"missing role", TestUser(), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+			e.Use(Middleware(&MockVerifier{User: tt.user}), RequireRole(AdminRole))
+			e.GET("/admin", func(c *echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireEndUser(t *testing.T) {
+	service := TestUser()
+	service.UID = "service:billing"
+	service.Service = true
+
+	tests := []struct {
+		name   string
+		user   *FirebaseUser
+		status int
+	}{
+		{"end user", TestUser(), http.StatusNoContent},
+		{"service principal", service, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+			e.Use(Middleware(&MockVerifier{User: tt.user}), RequireEndUser())
+			e.GET("/profile", func(c *echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			req.Header.Set("Authorization", "Bearer test-token")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
 func TestCategorizeAuthError(t *testing.T) {
 	tests := []struct {
 		err  error
@@ -210,3 +349,354 @@ func TestCategorizeAuthError(t *testing.T) {
 		})
 	}
 }
+
+func newSchemesEcho(t *testing.T, schemes ...Scheme) *echo.Echo {
+	t.Helper()
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.Use(MiddlewareWithSchemes(schemes...))
+	e.GET("/test", func(c *echo.Context) error {
+		u, err := UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error500("no user in context")
+		}
+		return c.JSON(http.StatusOK, map[string]string{"uid": u.UID})
+	})
+	return e
+}
+
+func newAPIKeyScheme(t *testing.T) Scheme {
+	t.Helper()
+	v, err := NewAPIKeyVerifier(APIKey{Name: "billing", Hash: hashKey("service-secret")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return APIKeyScheme(v)
+}
+
+func TestMiddlewareWithSchemes_APIKeyGrantsAccess(t *testing.T) {
+	e := newSchemesEcho(t, BearerScheme(&MockVerifier{Error: ErrInvalidToken}), newAPIKeyScheme(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(HeaderAPIKey, "service-secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d; body: %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if body["uid"] != "service:billing" {
+		t.Fatalf("expected service:billing, got %q", body["uid"])
+	}
+}
+
+func TestMiddlewareWithSchemes_InvalidAPIKey(t *testing.T) {
+	e := newSchemesEcho(t, BearerScheme(&MockVerifier{User: TestUser()}), newAPIKeyScheme(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(HeaderAPIKey, "wrong-secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal problem: %v", err)
+	}
+	if problem.Detail != "invalid or expired token" {
+		t.Fatalf("unexpected detail %q", problem.Detail)
+	}
+}
+
+func TestMiddlewareWithSchemes_BearerStillWorks(t *testing.T) {
+	user := TestUser()
+	e := newSchemesEcho(t, BearerScheme(&MockVerifier{User: user}), newAPIKeyScheme(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	req.Header.Set(HeaderAPIKey, "wrong-secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if body["uid"] != user.UID {
+		t.Fatalf("expected Bearer scheme to win, got uid %q", body["uid"])
+	}
+}
+
+func TestMiddlewareWithSchemes_AllFailAggregated(t *testing.T) {
+	e := newSchemesEcho(t, BearerScheme(&MockVerifier{Error: ErrCertificateFetch}), newAPIKeyScheme(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer some-token")
+	req.Header.Set(HeaderAPIKey, "wrong-secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 when any scheme hit a transient failure, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareWithSchemes_NoCredentials(t *testing.T) {
+	e := newSchemesEcho(t, BearerScheme(&MockVerifier{User: TestUser()}), newAPIKeyScheme(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareWithSchemes_MultipleChallenges(t *testing.T) {
+	apiKeyChallenge := `ApiKey realm="api", header="X-API-Key"`
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    []string
+	}{
+		{
+			"no credentials",
+			nil,
+			[]string{`Bearer realm="api"`, apiKeyChallenge},
+		},
+		{
+			"all verifiers fail",
+			map[string]string{"Authorization": "Bearer t", HeaderAPIKey: "wrong-secret"},
+			[]string{`Bearer error="invalid_token", error_description="the token expired"`, apiKeyChallenge},
+		},
+		{
+			"only api key presented",
+			map[string]string{HeaderAPIKey: "wrong-secret"},
+			[]string{`Bearer realm="api"`, apiKeyChallenge},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSchemesEcho(t, BearerScheme(&MockVerifier{Error: ErrTokenExpired}), newAPIKeyScheme(t))
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+			if got := rec.Header().Values("WWW-Authenticate"); !slices.Equal(got, tt.want) {
+				t.Fatalf("expected challenges %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMiddleware_WWWAuthenticateChallenge(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		header string
+		want   string
+	}{
+		{"missing token", nil, "", `Bearer realm="api"`},
+		{"malformed header", nil, "Basic dXNlcjpwYXNz", `Bearer realm="api"`},
+		{
+			"expired",
+			ErrTokenExpired,
+			"Bearer t",
+			`Bearer error="invalid_token", error_description="the token expired"`,
+		},
+		{
+			"revoked",
+			ErrTokenRevoked,
+			"Bearer t",
+			`Bearer error="invalid_token", error_description="the token has been revoked"`,
+		},
+		{
+			"disabled",
+			ErrUserDisabled,
+			"Bearer t",
+			`Bearer error="invalid_token", error_description="the user account is disabled"`,
+		},
+		{
+			"invalid",
+			ErrInvalidToken,
+			"Bearer t",
+			`Bearer error="invalid_token", error_description="the token is malformed or invalid"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &MockVerifier{User: TestUser(), Error: tt.err}
+
+			e := echo.New()
+			e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+			e.Use(Middleware(verifier))
+			e.GET("/test", func(c *echo.Context) error {
+				return c.JSON(http.StatusOK, nil)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMiddleware_CertificateFetchNoChallenge(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.Use(Middleware(&MockVerifier{Error: ErrCertificateFetch}))
+	e.GET("/test", func(c *echo.Context) error {
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer t")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("WWW-Authenticate"); got != "" {
+		t.Fatalf("expected no challenge on 503, got %q", got)
+	}
+}
+
+func TestQuoteAuthParam(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"api", `"api"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+		{"", `""`},
+	}
+	for _, tt := range tests {
+		if got := quoteAuthParam(tt.in); got != tt.want {
+			t.Fatalf("quoteAuthParam(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// tokenVerifier accepts only the tokens it maps to users.
+type tokenVerifier map[string]*FirebaseUser
+
+func (v tokenVerifier) Verify(_ context.Context, token string) (*FirebaseUser, error) {
+	if user, ok := v[token]; ok {
+		return user, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+func TestCookieScheme(t *testing.T) {
+	verifier := tokenVerifier{
+		"header-token": {UID: "header-user"},
+		"cookie-token": {UID: "cookie-user"},
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		status int
+		uid    string
+	}{
+		{"cookie only", "", "cookie-token", http.StatusOK, "cookie-user"},
+		{"header overrides cookie", "Bearer header-token", "cookie-token", http.StatusOK, "header-user"},
+		{"invalid header ignores valid cookie", "Bearer bogus", "cookie-token", http.StatusUnauthorized, ""},
+		{"invalid cookie", "", "bogus", http.StatusUnauthorized, ""},
+		{"empty cookie", "", "", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSchemesEcho(t, BearerScheme(verifier), CookieScheme(verifier, "__session"))
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			req.AddCookie(&http.Cookie{Name: "__session", Value: tt.cookie})
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d; body: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				if got := rec.Header().Values("WWW-Authenticate"); len(got) != 1 {
+					t.Fatalf("expected only the Bearer challenge, got %v", got)
+				}
+				return
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if body["uid"] != tt.uid {
+				t.Fatalf("expected uid %q, got %q", tt.uid, body["uid"])
+			}
+		})
+	}
+}
+
+func TestCookieScheme_RejectsCrossOriginUnsafeMethods(t *testing.T) {
+	verifier := tokenVerifier{"cookie-token": {UID: "cookie-user"}}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{"cross-site post", http.MethodPost, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusUnauthorized},
+		{"same-site post", http.MethodPost, map[string]string{"Sec-Fetch-Site": "same-site"}, http.StatusUnauthorized},
+		{"foreign origin post", http.MethodPost, map[string]string{"Origin": "https://evil.example"}, http.StatusUnauthorized},
+		{"same-origin post", http.MethodPost, map[string]string{"Sec-Fetch-Site": "same-origin"}, http.StatusOK},
+		{"matching origin post", http.MethodPost, map[string]string{"Origin": "http://example.com"}, http.StatusOK},
+		{"cross-site get", http.MethodGet, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSchemesEcho(t, BearerScheme(verifier), CookieScheme(verifier, "__session"))
+			e.POST("/test", func(c *echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			req.AddCookie(&http.Cookie{Name: "__session", Value: "cookie-token"})
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d; body: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package budget bounds the total time a request spends in backend calls.
+// Each call runs under the time left in the request's Budget and subtracts
+// its duration, so a handler making several calls cannot exceed the budget
+// however many calls it makes.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrExhausted is returned by Run once the budget is spent. It wraps
+// context.DeadlineExceeded, so callers treating deadlines as timeouts handle
+// it too.
+var ErrExhausted = fmt.Errorf("request budget exhausted: %w", context.DeadlineExceeded)
+
+type ctxBudgetKey struct{}
+
+// Budget is the time a request may still spend in backend calls. A nil
+// Budget is unlimited, so callers can use FromContext without checking for
+// middleware presence.
+type Budget struct {
+	mu        sync.Mutex
+	remaining time.Duration
+	now       func() time.Time
+}
+
+// New returns a Budget of total.
+func New(total time.Duration) *Budget {
+	return &Budget{remaining: total, now: time.Now}
+}
+
+// NewContext returns a copy of ctx carrying b.
+func NewContext(ctx context.Context, b *Budget) context.Context {
+	return context.WithValue(ctx, ctxBudgetKey{}, b)
+}
+
+// FromContext returns the Budget attached to ctx, or nil when none is present.
+func FromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(ctxBudgetKey{}).(*Budget)
+	return b
+}
+
+// Run calls fn with a context that expires when the budget runs out, then
+// subtracts the time fn took. It returns ErrExhausted without calling fn when
+// nothing is left, and in place of fn's error when fn exceeded the budget.
+// Concurrent calls each subtract their own duration.
+func (b *Budget) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b == nil {
+		return fn(ctx)
+	}
+
+	b.mu.Lock()
+	remaining := b.remaining
+	b.mu.Unlock()
+	if remaining <= 0 {
+		return ErrExhausted
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, remaining)
+	defer cancel()
+
+	start := b.now()
+	err := fn(callCtx)
+	b.mu.Lock()
+	b.remaining -= b.now().Sub(start)
+	b.mu.Unlock()
+
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return ErrExhausted
+	}
+	return err
+}
+
+// Run calls fn under the Budget attached to ctx, or directly without one.
+func Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return FromContext(ctx).Run(ctx, fn)
+}
@@ -0,0 +1,95 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBudget(total time.Duration) (*Budget, *time.Time) {
+	now := time.Unix(0, 0)
+	b := New(total)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestRun_SubtractsElapsedTime(t *testing.T) {
+	b, now := newTestBudget(100 * time.Millisecond)
+	ctx := context.Background()
+
+	for range 2 {
+		err := b.Run(ctx, func(context.Context) error {
+			*now = now.Add(40 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected call within budget, got %v", err)
+		}
+	}
+
+	var deadline time.Time
+	err := b.Run(ctx, func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		*now = now.Add(20 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected call within budget, got %v", err)
+	}
+	if left := time.Until(deadline); left > 20*time.Millisecond {
+		t.Fatalf("expected the call deadline to be at most the 20ms left, got %v", left)
+	}
+
+	called := false
+	err = b.Run(ctx, func(context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ErrExhausted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected ErrExhausted, got %v", err)
+	}
+	if called {
+		t.Fatal("expected fn not to run once the budget is spent")
+	}
+}
+
+func TestRun_CallExceedingBudget(t *testing.T) {
+	b := New(10 * time.Millisecond)
+	err := b.Run(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("rpc error: deadline exceeded")
+	})
+	if !errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected ErrExhausted, got %v", err)
+	}
+}
+
+func TestRun_KeepsErrorsWithinBudget(t *testing.T) {
+	b, _ := newTestBudget(time.Second)
+	want := errors.New("not found")
+	if err := b.Run(context.Background(), func(context.Context) error { return want }); !errors.Is(err, want) {
+		t.Fatalf("expected fn's error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := b.Run(ctx, func(ctx context.Context) error { return ctx.Err() })
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrExhausted) {
+		t.Fatalf("expected the caller's cancellation, got %v", err)
+	}
+}
+
+func TestRun_WithoutBudget(t *testing.T) {
+	called := false
+	err := Run(context.Background(), func(ctx context.Context) error {
+		called = true
+		if _, ok := ctx.Deadline(); ok {
+			t.Fatal("expected no deadline without a budget")
+		}
+		return nil
+	})
+	if err != nil || !called {
+		t.Fatalf("expected fn to run unbounded, got called=%v, err=%v", called, err)
+	}
+}
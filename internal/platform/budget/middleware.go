@@ -0,0 +1,26 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// DefaultTotal is the budget used when RequestBudget is given a non-positive
+// total. It stays below the default request timeout, leaving time to render
+// the response.
+const DefaultTotal = 5 * time.Second
+
+// RequestBudget returns Echo middleware that attaches a Budget of total to
+// each request context, defaulting to DefaultTotal.
+func RequestBudget(total time.Duration) echo.MiddlewareFunc {
+	if total <= 0 {
+		total = DefaultTotal
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			c.SetRequest(c.Request().WithContext(NewContext(c.Request().Context(), New(total))))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package budget
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestRequestBudget(t *testing.T) {
+	tests := []struct {
+		name  string
+		total time.Duration
+		want  time.Duration
+	}{
+		{"configured", 2 * time.Second, 2 * time.Second},
+		{"default", 0, DefaultTotal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Budget
+			e := echo.New()
+			e.Use(RequestBudget(tt.total))
+			e.GET("/", func(c *echo.Context) error {
+				got = FromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if got == nil {
+				t.Fatal("expected a budget on the request context")
+			}
+			if got.remaining != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got.remaining)
+			}
+		})
+	}
+}
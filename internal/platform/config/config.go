@@ -0,0 +1,308 @@
+// Package config loads and validates the server configuration from the
+// environment.
+package config
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/fieldcrypt"
+	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
+)
+
+// Environment names accepted in APP_ENVIRONMENT.
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// DemoProjectID is the Firebase project used in development when
+// FIREBASE_PROJECT_ID is unset. The demo- prefix keeps the SDK on emulators.
+const DemoProjectID = "demo-test-project"
+
+// DefaultPort is the listen port when PORT is unset.
+const DefaultPort = "8080"
+
+// DefaultServerHeader is the Server response header when SERVER_HEADER is
+// unset. It identifies neither the language nor the framework.
+const DefaultServerHeader = "api"
+
+// Config is the typed server configuration.
+type Config struct {
+	Host        string
+	Port        string
+	LogLevel    slog.Level
+	Environment string
+
+	FirebaseProjectID string
+
+	// APIKeys is nil when API_KEYS is unset.
+	APIKeys *auth.APIKeyVerifier
+	// PIIKeyring is nil when PII_ENCRYPTION_KEYS is unset.
+	PIIKeyring *fieldcrypt.Keyring
+	// TrustedProxies is nil when TRUSTED_PROXIES is unset, selecting the
+	// default private ranges.
+	TrustedProxies []netip.Prefix
+	AllowedHosts   []string
+	// CursorSigningKey is a random per-process key when CURSOR_SIGNING_KEY
+	// is unset outside production, so cursors are always signed.
+	CursorSigningKey []byte
+	// AuthCookie names the cookie read for Firebase ID tokens when the
+	// Authorization header is absent; empty disables cookie authentication.
+	AuthCookie string
+	// ServerHeader is the Server response header; empty when SERVER_HEADER
+	// is "none", removing the header.
+	ServerHeader string
+	// RequestIDGenerator creates request IDs; UUIDv4 unless
+	// REQUEST_ID_FORMAT is uuidv7.
+	RequestIDGenerator appmiddleware.RequestIDGenerator
+	// RequestIDHeaders lists the inbound headers checked in order for a
+	// request ID; nil when REQUEST_ID_HEADERS is unset, selecting X-Request-ID.
+	RequestIDHeaders []string
+	// RequestIDEchoSource also returns a reused request ID under the header
+	// it was read from.
+	RequestIDEchoSource bool
+
+	AbsoluteLocation  bool
+	OpenAPIValidation bool
+	MetricsEnabled    bool
+	// LenientCategories answers unknown item categories with an empty list
+	// instead of 422.
+	LenientCategories bool
+	// ExposeNegotiation reports the negotiated response format in the
+	// X-Negotiated header.
+	ExposeNegotiation bool
+
+	// SlowRequestThreshold is zero when SLOW_REQUEST_THRESHOLD is unset,
+	// disabling slow request logging.
+	SlowRequestThreshold time.Duration
+	// RateLimit is the requests per second each client IP may sustain; zero
+	// when RATE_LIMIT is "none", disabling rate limiting.
+	RateLimit float64
+	// RateLimitBurst is the requests a client IP may send at once.
+	RateLimitBurst int
+	// HSTSMaxAge is zero when HSTS_MAX_AGE is unset, selecting
+	// middleware.DefaultHSTSMaxAge.
+	HSTSMaxAge time.Duration
+	// StoreBudget bounds the time one request spends in Firestore calls.
+	// Zero when STORE_BUDGET is unset, selecting budget.DefaultTotal.
+	StoreBudget time.Duration
+
+	// Warnings lists non-fatal configuration issues to log at startup.
+	Warnings []string
+}
+
+// Development reports whether the server runs in the development environment.
+func (c Config) Development() bool {
+	return c.Environment == EnvDevelopment
+}
+
+// Production reports whether the server runs in the production environment.
+func (c Config) Production() bool {
+	return c.Environment == EnvProduction
+}
+
+// Load reads the configuration from the process environment.
+func Load() (Config, error) {
+	return LoadFrom(os.Getenv)
+}
+
+// LoadFrom reads the configuration using getenv. Every invalid or missing
+// variable is reported in the returned error, not only the first.
+func LoadFrom(getenv func(string) string) (Config, error) {
+	var errs []error
+	fail := func(name string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	cfg := Config{
+		Host:        getenv("HOST"),
+		Port:        getenv("PORT"),
+		Environment: strings.TrimSpace(getenv("APP_ENVIRONMENT")),
+	}
+
+	if cfg.Port == "" {
+		cfg.Port = DefaultPort
+	} else if n, err := strconv.Atoi(cfg.Port); err != nil || n < 1 || n > 65535 {
+		fail("PORT", fmt.Errorf("must be a port number between 1 and 65535, got %q", cfg.Port))
+	}
+
+	if v := getenv("LOG_LEVEL"); v != "" {
+		if err := cfg.LogLevel.UnmarshalText([]byte(v)); err != nil {
+			fail("LOG_LEVEL", fmt.Errorf("must be one of debug, info, warn, error, got %q", v))
+		}
+	}
+
+	switch cfg.Environment {
+	case "", EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		fail("APP_ENVIRONMENT", fmt.Errorf("must be one of %s, %s, %s, got %q",
+			EnvDevelopment, EnvStaging, EnvProduction, cfg.Environment))
+	}
+
+	cfg.FirebaseProjectID = getenv("FIREBASE_PROJECT_ID")
+	if cfg.FirebaseProjectID == "" {
+		if cfg.Development() {
+			cfg.FirebaseProjectID = DemoProjectID
+			cfg.Warnings = append(cfg.Warnings, "using "+DemoProjectID+" for local development")
+		} else {
+			fail("FIREBASE_PROJECT_ID", errors.New("required outside development"))
+		}
+	}
+
+	if spec := getenv("API_KEYS"); spec != "" {
+		keys, err := auth.ParseAPIKeys(spec)
+		if err == nil {
+			cfg.APIKeys, err = auth.NewAPIKeyVerifier(keys...)
+		}
+		if err != nil {
+			fail("API_KEYS", err)
+		}
+	}
+
+	if spec := getenv("PII_ENCRYPTION_KEYS"); spec != "" {
+		keys, err := fieldcrypt.ParseKeys(spec)
+		if err == nil {
+			cfg.PIIKeyring, err = fieldcrypt.NewKeyring(keys...)
+		}
+		if err != nil {
+			fail("PII_ENCRYPTION_KEYS", err)
+		}
+	}
+
+	if spec := getenv("TRUSTED_PROXIES"); spec != "" {
+		proxies, err := appmiddleware.ParseTrustedProxies(spec)
+		if err != nil {
+			fail("TRUSTED_PROXIES", err)
+		}
+		cfg.TrustedProxies = proxies
+	}
+
+	if spec := getenv("ALLOWED_HOSTS"); spec != "" {
+		cfg.AllowedHosts = strings.Split(spec, ",")
+	}
+
+	cfg.AuthCookie = strings.TrimSpace(getenv("AUTH_COOKIE"))
+
+	switch v := strings.TrimSpace(getenv("SERVER_HEADER")); {
+	case v == "":
+		cfg.ServerHeader = DefaultServerHeader
+	case strings.EqualFold(v, "none"):
+	default:
+		cfg.ServerHeader = v
+	}
+
+	cfg.RequestIDGenerator = appmiddleware.UUIDv4
+	if v := strings.TrimSpace(getenv("REQUEST_ID_FORMAT")); v != "" {
+		gen, err := appmiddleware.ParseRequestIDFormat(strings.ToLower(v))
+		if err != nil {
+			fail("REQUEST_ID_FORMAT", err)
+		}
+		cfg.RequestIDGenerator = gen
+	}
+
+	for h := range strings.SplitSeq(getenv("REQUEST_ID_HEADERS"), ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			cfg.RequestIDHeaders = append(cfg.RequestIDHeaders, h)
+		}
+	}
+
+	if key := getenv("CURSOR_SIGNING_KEY"); key != "" {
+		cfg.CursorSigningKey = []byte(key)
+	} else if cfg.Production() {
+		fail("CURSOR_SIGNING_KEY", errors.New("required in production"))
+	} else {
+		// Cursors stay tamper-proof without a configured key, but are only
+		// valid on the instance that issued them until it restarts.
+		cfg.CursorSigningKey = make([]byte, 32)
+		if _, err := rand.Read(cfg.CursorSigningKey); err != nil {
+			fail("CURSOR_SIGNING_KEY", fmt.Errorf("generating a random key: %w", err))
+		}
+		cfg.Warnings = append(cfg.Warnings,
+			"CURSOR_SIGNING_KEY not set; signing cursors with a random per-process key")
+	}
+
+	if v := getenv("SLOW_REQUEST_THRESHOLD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			fail("SLOW_REQUEST_THRESHOLD", fmt.Errorf("must be a non-negative duration such as 500ms, got %q", v))
+		}
+		cfg.SlowRequestThreshold = d
+	}
+
+	if v := getenv("HSTS_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < time.Second {
+			fail("HSTS_MAX_AGE", fmt.Errorf("must be a duration of at least 1s such as 8760h, got %q", v))
+		}
+		cfg.HSTSMaxAge = d
+	}
+
+	switch v := strings.TrimSpace(getenv("RATE_LIMIT")); {
+	case v == "":
+		cfg.RateLimit = appmiddleware.DefaultRateLimit
+	case strings.EqualFold(v, "none"):
+	default:
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || math.IsInf(r, 0) {
+			fail("RATE_LIMIT", fmt.Errorf("must be a positive number of requests per second or none, got %q", v))
+		}
+		cfg.RateLimit = r
+	}
+
+	cfg.RateLimitBurst = appmiddleware.DefaultBurst
+	if v := getenv("RATE_LIMIT_BURST"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			fail("RATE_LIMIT_BURST", fmt.Errorf("must be a positive integer, got %q", v))
+		}
+		cfg.RateLimitBurst = n
+	}
+
+	if v := getenv("STORE_BUDGET"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fail("STORE_BUDGET", fmt.Errorf("must be a positive duration such as 3s, got %q", v))
+		}
+		cfg.StoreBudget = d
+	}
+
+	flags := []struct {
+		name string
+		dst  *bool
+	}{
+		{"ABSOLUTE_LOCATION", &cfg.AbsoluteLocation},
+		{"OPENAPI_VALIDATION", &cfg.OpenAPIValidation},
+		{"METRICS_ENABLED", &cfg.MetricsEnabled},
+		{"ITEMS_LENIENT_CATEGORIES", &cfg.LenientCategories},
+		{"EXPOSE_NEGOTIATION", &cfg.ExposeNegotiation},
+		{"REQUEST_ID_ECHO_SOURCE", &cfg.RequestIDEchoSource},
+	}
+	for _, f := range flags {
+		v := getenv(f.name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			fail(f.name, fmt.Errorf("must be true or false, got %q", v))
+			continue
+		}
+		*f.dst = b
+	}
+
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(name string) string { return env[name] }
+}
+
+func TestLoadFrom_Valid(t *testing.T) {
+	cfg, err := LoadFrom(envFunc(map[string]string{
+		"PORT":                     "9090",
+		"LOG_LEVEL":                "debug",
+		"APP_ENVIRONMENT":          "production",
+		"FIREBASE_PROJECT_ID":      "my-project",
+		"API_KEYS":                 "ci:" + strings.Repeat("ab", 32),
+		"PII_ENCRYPTION_KEYS":      "k1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		"TRUSTED_PROXIES":          "10.0.0.0/8",
+		"ALLOWED_HOSTS":            "api.example.com,example.com",
+		"CURSOR_SIGNING_KEY":       "secret",
+		"METRICS_ENABLED":          "true",
+		"ITEMS_LENIENT_CATEGORIES": "true",
+		"EXPOSE_NEGOTIATION":       "true",
+		"OPENAPI_VALIDATION":       "false",
+		"SLOW_REQUEST_THRESHOLD":   "750ms",
+		"STORE_BUDGET":             "3s",
+		"RATE_LIMIT":               "2.5",
+		"HSTS_MAX_AGE":             "720h",
+		"RATE_LIMIT_BURST":         "5",
+		"AUTH_COOKIE":              "__session",
+		"SERVER_HEADER":            "edge",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != "9090" || cfg.LogLevel != slog.LevelDebug || !cfg.Production() {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if cfg.FirebaseProjectID != "my-project" {
+		t.Fatalf("expected project my-project, got %q", cfg.FirebaseProjectID)
+	}
+	if cfg.APIKeys == nil || cfg.PIIKeyring == nil {
+		t.Fatal("expected API keys and PII keyring to be built")
+	}
+	if len(cfg.TrustedProxies) != 1 || len(cfg.AllowedHosts) != 2 {
+		t.Fatalf("unexpected proxies %v or hosts %v", cfg.TrustedProxies, cfg.AllowedHosts)
+	}
+	if !cfg.MetricsEnabled || !cfg.LenientCategories || !cfg.ExposeNegotiation || cfg.OpenAPIValidation ||
+		cfg.AbsoluteLocation {
+		t.Fatalf("unexpected flags %+v", cfg)
+	}
+	if cfg.AuthCookie != "__session" {
+		t.Fatalf("expected auth cookie __session, got %q", cfg.AuthCookie)
+	}
+	if cfg.ServerHeader != "edge" {
+		t.Fatalf("expected server header edge, got %q", cfg.ServerHeader)
+	}
+	if cfg.SlowRequestThreshold != 750*time.Millisecond {
+		t.Fatalf("expected slow request threshold 750ms, got %v", cfg.SlowRequestThreshold)
+	}
+	if cfg.StoreBudget != 3*time.Second {
+		t.Fatalf("expected store budget 3s, got %v", cfg.StoreBudget)
+	}
+	if cfg.HSTSMaxAge != 720*time.Hour {
+		t.Fatalf("expected HSTS max-age 720h, got %v", cfg.HSTSMaxAge)
+	}
+	if cfg.RateLimit != 2.5 || cfg.RateLimitBurst != 5 {
+		t.Fatalf("expected rate limit 2.5 with burst 5, got %v/%d", cfg.RateLimit, cfg.RateLimitBurst)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", cfg.Warnings)
+	}
+}
+
+func TestLoadFrom_DevelopmentDefaults(t *testing.T) {
+	cfg, err := LoadFrom(envFunc(map[string]string{"APP_ENVIRONMENT": "development"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != DefaultPort {
+		t.Fatalf("expected default port, got %q", cfg.Port)
+	}
+	if cfg.LogLevel != slog.LevelInfo {
+		t.Fatalf("expected info level, got %v", cfg.LogLevel)
+	}
+	if cfg.FirebaseProjectID != DemoProjectID {
+		t.Fatalf("expected demo project, got %q", cfg.FirebaseProjectID)
+	}
+	if cfg.TrustedProxies != nil || cfg.APIKeys != nil || cfg.PIIKeyring != nil {
+		t.Fatalf("expected optional settings unset, got %+v", cfg)
+	}
+	if cfg.ServerHeader != DefaultServerHeader {
+		t.Fatalf("expected default server header, got %q", cfg.ServerHeader)
+	}
+	if len(cfg.CursorSigningKey) != 32 {
+		t.Fatalf("expected a random 32-byte cursor key, got %d bytes", len(cfg.CursorSigningKey))
+	}
+	if len(cfg.Warnings) != 2 {
+		t.Fatalf("expected demo project and cursor key warnings, got %v", cfg.Warnings)
+	}
+}
+
+func TestLoadFrom_AggregatesErrors(t *testing.T) {
+	_, err := LoadFrom(envFunc(map[string]string{
+		"PORT":                   "http",
+		"LOG_LEVEL":              "verbose",
+		"APP_ENVIRONMENT":        "prod",
+		"TRUSTED_PROXIES":        "not-a-cidr",
+		"PII_ENCRYPTION_KEYS":    "k1:c2hvcnQ=",
+		"METRICS_ENABLED":        "yes",
+		"SLOW_REQUEST_THRESHOLD": "-1s",
+		"STORE_BUDGET":           "0s",
+		"RATE_LIMIT":             "fast",
+		"HSTS_MAX_AGE":           "1ms",
+		"RATE_LIMIT_BURST":       "0",
+	}))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, name := range []string{
+		"PORT", "LOG_LEVEL", "APP_ENVIRONMENT", "FIREBASE_PROJECT_ID",
+		"TRUSTED_PROXIES", "PII_ENCRYPTION_KEYS", "METRICS_ENABLED", "SLOW_REQUEST_THRESHOLD", "STORE_BUDGET",
+		"RATE_LIMIT", "RATE_LIMIT_BURST", "HSTS_MAX_AGE",
+	} {
+		if !strings.Contains(err.Error(), name+":") {
+			t.Fatalf("expected %s in error, got:\n%v", name, err)
+		}
+	}
+}
+
+func TestLoadFrom_CursorSigningKeyRequiredInProduction(t *testing.T) {
+	_, err := LoadFrom(envFunc(map[string]string{"APP_ENVIRONMENT": "production", "FIREBASE_PROJECT_ID": "p"}))
+	if err == nil || !strings.Contains(err.Error(), "CURSOR_SIGNING_KEY:") {
+		t.Fatalf("expected CURSOR_SIGNING_KEY error, got %v", err)
+	}
+}
+
+func TestLoadFrom_PortRange(t *testing.T) {
+	for _, port := range []string{"0", "65536", "-1"} {
+		t.Run(port, func(t *testing.T) {
+			_, err := LoadFrom(envFunc(map[string]string{"PORT": port, "FIREBASE_PROJECT_ID": "p"}))
+			if err == nil || !strings.Contains(err.Error(), "PORT:") {
+				t.Fatalf("expected PORT error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadFrom_ServerHeaderNone(t *testing.T) {
+	cfg, err := LoadFrom(envFunc(map[string]string{"FIREBASE_PROJECT_ID": "p", "SERVER_HEADER": "None"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ServerHeader != "" {
+		t.Fatalf("expected server header to be removed, got %q", cfg.ServerHeader)
+	}
+}
+
+func TestLoadFrom_RateLimit(t *testing.T) {
+	cfg, err := LoadFrom(envFunc(map[string]string{"FIREBASE_PROJECT_ID": "p"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RateLimit != appmiddleware.DefaultRateLimit || cfg.RateLimitBurst != appmiddleware.DefaultBurst {
+		t.Fatalf("expected default rate limit, got %v/%d", cfg.RateLimit, cfg.RateLimitBurst)
+	}
+
+	cfg, err = LoadFrom(envFunc(map[string]string{"FIREBASE_PROJECT_ID": "p", "RATE_LIMIT": "none"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RateLimit != 0 {
+		t.Fatalf("expected rate limiting to be disabled, got %v", cfg.RateLimit)
+	}
+}
+
+func TestLoadFrom_RequestIDHeaders(t *testing.T) {
+	cfg, err := LoadFrom(envFunc(map[string]string{
+		"FIREBASE_PROJECT_ID":    "p",
+		"REQUEST_ID_HEADERS":     " X-Request-ID, X-Correlation-ID,,X-Trace-Id ",
+		"REQUEST_ID_ECHO_SOURCE": "true",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"X-Request-ID", "X-Correlation-ID", "X-Trace-Id"}
+	if !slices.Equal(cfg.RequestIDHeaders, want) || !cfg.RequestIDEchoSource {
+		t.Fatalf("expected headers %v with echo source, got %v, %v", want, cfg.RequestIDHeaders, cfg.RequestIDEchoSource)
+	}
+
+	cfg, err = LoadFrom(envFunc(map[string]string{"FIREBASE_PROJECT_ID": "p"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RequestIDHeaders != nil || cfg.RequestIDEchoSource {
+		t.Fatalf("expected default request ID headers, got %v, %v", cfg.RequestIDHeaders, cfg.RequestIDEchoSource)
+	}
+}
+
+func TestLoadFrom_RequestIDFormat(t *testing.T) {
+	cfg, err := LoadFrom(envFunc(map[string]string{"FIREBASE_PROJECT_ID": "p", "REQUEST_ID_FORMAT": "UUIDv7"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	id, err := uuid.Parse(cfg.RequestIDGenerator())
+	if err != nil || id.Version() != 7 {
+		t.Fatalf("expected a UUIDv7 generator, got %v, %v", id, err)
+	}
+
+	_, err = LoadFrom(envFunc(map[string]string{"FIREBASE_PROJECT_ID": "p", "REQUEST_ID_FORMAT": "ulid"}))
+	if err == nil || !strings.Contains(err.Error(), "REQUEST_ID_FORMAT") {
+		t.Fatalf("expected REQUEST_ID_FORMAT error, got %v", err)
+	}
+}
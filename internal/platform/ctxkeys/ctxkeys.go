@@ -0,0 +1,66 @@
+// Package ctxkeys provides typed request-scoped values. Values live on the
+// request's context.Context under unexported pointer keys, so packages share
+// them through accessors instead of string keys on the Echo context, and a
+// misspelled or reused name can neither read nor overwrite them.
+package ctxkeys
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v5"
+)
+
+// Key identifies a request-scoped value of type T. Keys compare by identity:
+// two keys created with the same name are distinct.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey returns a new Key. name only appears in String.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String returns the key's name.
+func (k *Key[T]) String() string {
+	return "ctxkeys." + k.name
+}
+
+// With returns a copy of ctx carrying v under k.
+func (k *Key[T]) With(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// Value returns the value stored under k in ctx and whether one was present.
+func (k *Key[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// Set stores v under k on the request context of c.
+func (k *Key[T]) Set(c *echo.Context, v T) {
+	c.SetRequest(c.Request().WithContext(k.With(c.Request().Context(), v)))
+}
+
+// Get returns the value stored under k on the request context of c.
+func (k *Key[T]) Get(c *echo.Context) (T, bool) {
+	return k.Value(c.Request().Context())
+}
+
+var requestIDKey = NewKey[string]("request_id")
+
+// SetRequestID stores the request ID on the request context of c.
+func SetRequestID(c *echo.Context, id string) {
+	requestIDKey.Set(c, id)
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return requestIDKey.With(ctx, id)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := requestIDKey.Value(ctx)
+	return id
+}
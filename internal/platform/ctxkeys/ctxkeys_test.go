@@ -0,0 +1,51 @@
+package ctxkeys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestKey_SameNameDoesNotCollide(t *testing.T) {
+	a := NewKey[string]("request_id")
+	b := NewKey[string]("request_id")
+
+	ctx := a.With(context.Background(), "a")
+	if _, ok := b.Value(ctx); ok {
+		t.Fatal("expected keys with the same name to be distinct")
+	}
+	if got := RequestID(ctx); got != "" {
+		t.Fatalf("expected a foreign key not to set the request ID, got %q", got)
+	}
+	if got, _ := ctx.Value("request_id").(string); got != "" {
+		t.Fatalf("expected a string key not to match, got %q", got)
+	}
+	if got, ok := a.Value(ctx); !ok || got != "a" {
+		t.Fatalf("expected a, got %q, %v", got, ok)
+	}
+}
+
+func TestKey_SetAndGet(t *testing.T) {
+	key := NewKey[int]("count")
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	if _, ok := key.Get(c); ok {
+		t.Fatal("expected no value before Set")
+	}
+	key.Set(c, 3)
+	SetRequestID(c, "req-1")
+
+	if got, ok := key.Get(c); !ok || got != 3 {
+		t.Fatalf("expected 3, got %d, %v", got, ok)
+	}
+	if got := RequestID(c.Request().Context()); got != "req-1" {
+		t.Fatalf("expected req-1, got %q", got)
+	}
+	if c.Get("request_id") != nil {
+		t.Fatal("expected nothing stored under the string key")
+	}
+}
@@ -0,0 +1,144 @@
+// Package fieldcrypt encrypts individual string fields with AES-256-GCM.
+//
+// Encrypted values embed the ID of the key that produced them, so keys can be
+// rotated by adding a new primary key while older keys remain available for
+// decryption.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks encrypted values: "enc:v1:<keyID>:<base64url(nonce||ciphertext)>".
+const prefix = "enc:v1:"
+
+// KeySize is the required key length in bytes (AES-256).
+const KeySize = 32
+
+// Errors returned by Keyring.
+var (
+	ErrUnknownKey = errors.New("fieldcrypt: unknown key id")
+	ErrMalformed  = errors.New("fieldcrypt: malformed ciphertext")
+)
+
+// Key is a named AES-256 key.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring encrypts with its primary key and decrypts with any of its keys.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring. The first key is the primary key used for
+// encryption; the remaining keys are only used to decrypt existing values.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("fieldcrypt: at least one key is required")
+	}
+	k := &Keyring{primary: keys[0].ID, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for _, key := range keys {
+		if key.ID == "" || strings.Contains(key.ID, ":") {
+			return nil, fmt.Errorf("fieldcrypt: invalid key id %q", key.ID)
+		}
+		if len(key.Secret) != KeySize {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be %d bytes", key.ID, KeySize)
+		}
+		if _, dup := k.aeads[key.ID]; dup {
+			return nil, fmt.Errorf("fieldcrypt: duplicate key id %q", key.ID)
+		}
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[key.ID] = aead
+	}
+	return k, nil
+}
+
+// ParseKeys parses a comma-separated list of "id:base64key" pairs, as read
+// from the PII_ENCRYPTION_KEYS environment variable. Keys may use standard or
+// URL-safe Base64.
+func ParseKeys(spec string) ([]Key, error) {
+	var keys []Key
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(part, ":")
+		if !ok || id == "" || encoded == "" {
+			return nil, errors.New("fieldcrypt: invalid key entry: expected id:base64key")
+		}
+		secret, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			secret, err = base64.URLEncoding.DecodeString(encoded)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %q is not valid base64", id)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
+
+// IsEncrypted reports whether value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Encrypt seals plaintext with the primary key. aad binds the ciphertext to
+// its context (for example a document ID and field name) so it cannot be
+// copied to another field. Empty plaintext is returned unchanged.
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(aad))
+	return prefix + k.primary + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with the same aad. Values without
+// the encryption prefix are returned unchanged so data written before
+// encryption was enabled stays readable.
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", ErrMalformed
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(aad))
+	if err != nil {
+		return "", ErrMalformed
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,135 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(id string, b byte) Key {
+	return Key{ID: id, Secret: bytes.Repeat([]byte{b}, KeySize)}
+}
+
+func TestKeyring_EncryptDecrypt_Roundtrip(t *testing.T) {
+	k, err := NewKeyring(testKey("k1", 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	enc, err := k.Encrypt("jane@example.com", "profiles/u1#email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsEncrypted(enc) || strings.Contains(enc, "jane") {
+		t.Fatalf("expected ciphertext, got %q", enc)
+	}
+	if !strings.HasPrefix(enc, "enc:v1:k1:") {
+		t.Fatalf("expected key id in ciphertext, got %q", enc)
+	}
+
+	dec, err := k.Decrypt(enc, "profiles/u1#email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dec != "jane@example.com" {
+		t.Fatalf("expected plaintext, got %q", dec)
+	}
+}
+
+func TestKeyring_Encrypt_RandomNonce(t *testing.T) {
+	k, _ := NewKeyring(testKey("k1", 1))
+	a, _ := k.Encrypt("same", "aad")
+	b, _ := k.Encrypt("same", "aad")
+	if a == b {
+		t.Fatal("expected distinct ciphertexts for repeated plaintext")
+	}
+}
+
+func TestKeyring_Decrypt_WrongAAD(t *testing.T) {
+	k, _ := NewKeyring(testKey("k1", 1))
+	enc, _ := k.Encrypt("jane@example.com", "profiles/u1#email")
+
+	if _, err := k.Decrypt(enc, "profiles/u2#email"); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, _ := NewKeyring(testKey("k1", 1))
+	enc, _ := old.Encrypt("555-0100", "aad")
+
+	rotated, err := NewKeyring(testKey("k2", 2), testKey("k1", 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dec, err := rotated.Decrypt(enc, "aad")
+	if err != nil || dec != "555-0100" {
+		t.Fatalf("expected old ciphertext to decrypt, got %q, %v", dec, err)
+	}
+	reenc, _ := rotated.Encrypt("555-0100", "aad")
+	if !strings.HasPrefix(reenc, "enc:v1:k2:") {
+		t.Fatalf("expected primary key k2, got %q", reenc)
+	}
+
+	if _, err := old.Decrypt(reenc, "aad"); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+}
+
+func TestKeyring_PlaintextPassthrough(t *testing.T) {
+	k, _ := NewKeyring(testKey("k1", 1))
+
+	if enc, _ := k.Encrypt("", "aad"); enc != "" {
+		t.Fatalf("expected empty value unchanged, got %q", enc)
+	}
+	if dec, err := k.Decrypt("legacy@example.com", "aad"); err != nil || dec != "legacy@example.com" {
+		t.Fatalf("expected legacy plaintext unchanged, got %q, %v", dec, err)
+	}
+}
+
+func TestKeyring_Decrypt_Malformed(t *testing.T) {
+	k, _ := NewKeyring(testKey("k1", 1))
+	for _, v := range []string{"enc:v1:k1", "enc:v1:k1:!!!", "enc:v1:k1:AAAA"} {
+		if _, err := k.Decrypt(v, "aad"); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("expected ErrMalformed for %q, got %v", v, err)
+		}
+	}
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	tests := map[string][]Key{
+		"no keys":      nil,
+		"short key":    {{ID: "k1", Secret: []byte("short")}},
+		"empty id":     {testKey("", 1)},
+		"colon in id":  {testKey("a:b", 1)},
+		"duplicate id": {testKey("k1", 1), testKey("k1", 2)},
+	}
+	for name, keys := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewKeyring(keys...); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	secret := bytes.Repeat([]byte{7}, KeySize)
+	spec := "k2:" + base64.StdEncoding.EncodeToString(secret) + ", k1:" + base64.URLEncoding.EncodeToString(secret)
+
+	keys, err := ParseKeys(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k2" || keys[1].ID != "k1" || !bytes.Equal(keys[1].Secret, secret) {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	for _, bad := range []string{"k1", "k1:", ":abc", "k1:%%%"} {
+		if _, err := ParseKeys(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"sync"
 )
 
 type (
@@ -11,18 +12,48 @@ type (
 	ctxTraceIDKey struct{}
 )
 
+// loggerRef holds the request-scoped logger. It is stored on the context by
+// pointer so AddContextAttrs can replace the logger for every holder of the
+// request context, including middleware that logs after the handler returns.
+type loggerRef struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
 // LoggerFromContext returns the request-scoped logger if present,
 // otherwise falls back to the global logger.
 func LoggerFromContext(ctx context.Context) *slog.Logger {
 	if ctx == nil {
 		return Logger()
 	}
-	if l, ok := ctx.Value(ctxLoggerKey{}).(*slog.Logger); ok && l != nil {
-		return l
+	if ref, ok := ctx.Value(ctxLoggerKey{}).(*loggerRef); ok {
+		ref.mu.Lock()
+		defer ref.mu.Unlock()
+		if ref.logger != nil {
+			return ref.logger
+		}
 	}
 	return Logger()
 }
 
+// AddContextAttrs adds attrs to the request-scoped logger in ctx, so every
+// later log line for the request includes them, the access log summary among
+// them. It does nothing when ctx has no request-scoped logger.
+func AddContextAttrs(ctx context.Context, attrs ...slog.Attr) {
+	if ctx == nil || len(attrs) == 0 {
+		return
+	}
+	ref, ok := ctx.Value(ctxLoggerKey{}).(*loggerRef)
+	if !ok {
+		return
+	}
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+	if ref.logger != nil {
+		ref.logger = slog.New(ref.logger.Handler().WithAttrs(attrs))
+	}
+}
+
 // TraceIDFromContext returns the correlation identifier (trace or request ID) if present.
 func TraceIDFromContext(ctx context.Context) *string {
 	if ctx == nil {
@@ -53,6 +84,15 @@ func LogError(ctx context.Context, msg string, err error, attrs ...slog.Attr) {
 	LoggerFromContext(ctx).LogAttrs(ctx, slog.LevelError, msg, attrs...)
 }
 
+// LogCritical writes a critical message, which pages on-call, using the
+// request-aware logger and appends the error attribute when err is non-nil.
+func LogCritical(ctx context.Context, msg string, err error, attrs ...slog.Attr) {
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	LoggerFromContext(ctx).LogAttrs(ctx, LevelCritical, msg, attrs...)
+}
+
 // LogFatal logs with emergency severity and terminates the process.
 // It attaches the error attribute when err is non-nil.
 //
@@ -62,15 +102,17 @@ func LogFatal(ctx context.Context, msg string, err error, attrs ...slog.Attr) {
 	if err != nil {
 		attrs = append(attrs, slog.Any("error", err))
 	}
-	LoggerFromContext(ctx).LogAttrs(ctx, levelEmergency, msg, attrs...)
+	LoggerFromContext(ctx).LogAttrs(ctx, LevelEmergency, msg, attrs...)
 	os.Exit(1)
 }
 
-func contextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+// ContextWithLogger returns a copy of ctx whose request-scoped logger is
+// logger, as returned by LoggerFromContext.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return context.WithValue(ctx, ctxLoggerKey{}, logger)
+	return context.WithValue(ctx, ctxLoggerKey{}, &loggerRef{logger: logger})
 }
 
 func contextWithTraceID(ctx context.Context, traceID string) context.Context {
@@ -18,7 +18,7 @@ func TestLoggerFromContext_Nil(t *testing.T) {
 func TestLoggerFromContext_WithLogger(t *testing.T) {
 	var buf bytes.Buffer
 	customLogger := slog.New(slog.NewJSONHandler(&buf, nil))
-	ctx := contextWithLogger(context.Background(), customLogger)
+	ctx := ContextWithLogger(context.Background(), customLogger)
 
 	l := LoggerFromContext(ctx)
 	if l != customLogger {
@@ -63,7 +63,7 @@ func TestTraceIDFromContext_EmptyTraceID(t *testing.T) {
 func TestLogInfo(t *testing.T) {
 	var buf bytes.Buffer
 	logger := slog.New(slog.NewJSONHandler(&buf, nil))
-	ctx := contextWithLogger(context.Background(), logger)
+	ctx := ContextWithLogger(context.Background(), logger)
 
 	LogInfo(ctx, "test info", slog.String("key", "val"))
 
@@ -79,10 +79,41 @@ func TestLogInfo(t *testing.T) {
 	}
 }
 
+func TestAddContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := ContextWithLogger(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	LogInfo(ctx, "before")
+	AddContextAttrs(ctx, slog.String("action", "update"))
+	LogInfo(ctx, "after")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d", len(lines))
+	}
+	for i, want := range []any{nil, "update"} {
+		var entry map[string]any
+		if err := json.Unmarshal(lines[i], &entry); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if entry["action"] != want {
+			t.Fatalf("line %d: expected action %v, got %v", i, want, entry["action"])
+		}
+	}
+}
+
+func TestAddContextAttrs_WithoutLogger(t *testing.T) {
+	ctx := context.Background()
+	AddContextAttrs(ctx, slog.String("action", "update"))
+	if LoggerFromContext(ctx) != Logger() {
+		t.Fatal("expected the global logger to stay untouched")
+	}
+}
+
 func TestLogWarn(t *testing.T) {
 	var buf bytes.Buffer
 	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
-	ctx := contextWithLogger(context.Background(), logger)
+	ctx := ContextWithLogger(context.Background(), logger)
 
 	LogWarn(ctx, "test warn")
 
@@ -98,7 +129,7 @@ func TestLogWarn(t *testing.T) {
 func TestLogError_WithError(t *testing.T) {
 	var buf bytes.Buffer
 	logger := slog.New(slog.NewJSONHandler(&buf, nil))
-	ctx := contextWithLogger(context.Background(), logger)
+	ctx := ContextWithLogger(context.Background(), logger)
 
 	LogError(ctx, "test error", errForTest("boom"))
 
@@ -117,7 +148,7 @@ func TestLogError_WithError(t *testing.T) {
 func TestLogError_NilError(t *testing.T) {
 	var buf bytes.Buffer
 	logger := slog.New(slog.NewJSONHandler(&buf, nil))
-	ctx := contextWithLogger(context.Background(), logger)
+	ctx := ContextWithLogger(context.Background(), logger)
 
 	LogError(ctx, "no error", nil)
 
@@ -132,7 +163,7 @@ func TestLogError_NilError(t *testing.T) {
 
 func TestContextWithLogger_NilContext(t *testing.T) {
 	logger := slog.Default()
-	ctx := contextWithLogger(context.TODO(), logger)
+	ctx := ContextWithLogger(context.TODO(), logger)
 	if ctx == nil {
 		t.Fatal("expected non-nil context")
 	}
@@ -169,7 +200,7 @@ func TestTraceIDFromContext_NilContext(t *testing.T) {
 
 func TestContextWithLogger_NilCtx(t *testing.T) {
 	logger := slog.Default()
-	ctx := contextWithLogger(nil, logger) //nolint:staticcheck // intentional nil to test nil-safety
+	ctx := ContextWithLogger(nil, logger) //nolint:staticcheck // intentional nil to test nil-safety
 	if ctx == nil {
 		t.Fatal("expected non-nil context from nil input")
 	}
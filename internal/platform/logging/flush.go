@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Flusher is implemented by log handlers that buffer records and write them
+// asynchronously.
+type Flusher interface {
+	// Flush writes all pending records, returning early if ctx is done.
+	Flush(ctx context.Context) error
+}
+
+var (
+	flushersMu sync.Mutex
+	flushers   []Flusher
+)
+
+// RegisterFlusher adds f to the set of handlers drained by Flush.
+func RegisterFlusher(f Flusher) {
+	flushersMu.Lock()
+	defer flushersMu.Unlock()
+	flushers = append(flushers, f)
+}
+
+// Flush drains every registered buffered handler. It is called during shutdown
+// after the server stops accepting connections so pending audit and access
+// logs are not lost. The synchronous default logger needs no flushing.
+func Flush(ctx context.Context) error {
+	flushersMu.Lock()
+	pending := append([]Flusher(nil), flushers...)
+	flushersMu.Unlock()
+
+	var errs []error
+	for _, f := range pending {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := f.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// bufferedHandler holds records in memory until flushed to next.
+type bufferedHandler struct {
+	mu      sync.Mutex
+	next    slog.Handler
+	pending []slog.Record
+}
+
+func (h *bufferedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *bufferedHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pending = append(h.pending, r.Clone())
+	return nil
+}
+
+func (h *bufferedHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *bufferedHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *bufferedHandler) Flush(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, r := range h.pending {
+		if err := h.next.Handle(ctx, r); err != nil {
+			return err
+		}
+	}
+	h.pending = nil
+	return nil
+}
+
+func resetFlushers(t *testing.T) {
+	t.Helper()
+	flushersMu.Lock()
+	saved := flushers
+	flushers = nil
+	flushersMu.Unlock()
+	t.Cleanup(func() {
+		flushersMu.Lock()
+		flushers = saved
+		flushersMu.Unlock()
+	})
+}
+
+func TestFlush_NoFlushers(t *testing.T) {
+	resetFlushers(t)
+
+	if err := Flush(context.Background()); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+}
+
+func TestFlush_WritesPendingRecords(t *testing.T) {
+	resetFlushers(t)
+
+	var buf bytes.Buffer
+	h := &bufferedHandler{next: slog.NewJSONHandler(&buf, nil)}
+	RegisterFlusher(h)
+
+	ctx := ContextWithLogger(context.Background(), slog.New(h))
+	LogAuditEvent(ctx, "delete", "user-123", "profile", "user-123", "success", nil)
+
+	if buf.Len() != 0 {
+		t.Fatalf("expected record to be buffered, got %q", buf.String())
+	}
+
+	if err := Flush(context.Background()); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), `"audit.action":"delete"`) {
+		t.Fatalf("expected audit record after flush, got %q", buf.String())
+	}
+}
+
+func TestFlush_CanceledContext(t *testing.T) {
+	resetFlushers(t)
+
+	var buf bytes.Buffer
+	RegisterFlusher(&bufferedHandler{next: slog.NewJSONHandler(&buf, nil)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := Flush(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -12,8 +12,15 @@ import (
 var (
 	loggerOnce sync.Once
 	baseLogger *slog.Logger
+	logLevel   slog.LevelVar
 )
 
+// SetLevel sets the minimum level of the process-wide logger. The default is
+// slog.LevelInfo.
+func SetLevel(level slog.Level) {
+	logLevel.Set(level)
+}
+
 // gcpHandler wraps slog.JSONHandler to remap level names to GCP Cloud Logging
 // severity strings and format timestamps with microsecond precision.
 type gcpHandler struct {
@@ -39,20 +46,22 @@ var gcpLevelNames = map[slog.Level]string{
 	slog.LevelInfo:  "INFO",
 	slog.LevelWarn:  "WARNING",
 	slog.LevelError: "ERROR",
-	levelCritical:   "CRITICAL",
-	levelAlert:      "ALERT",
-	levelEmergency:  "EMERGENCY",
+	LevelCritical:   "CRITICAL",
+	LevelAlert:      "ALERT",
+	LevelEmergency:  "EMERGENCY",
 }
 
+// Levels above slog.LevelError, mapped to the Cloud Logging severities of the
+// same name. Alerting policies page on CRITICAL and above.
 const (
-	levelCritical  = slog.LevelError + 4
-	levelAlert     = slog.LevelError + 8
-	levelEmergency = slog.LevelError + 12
+	LevelCritical  = slog.LevelError + 4
+	LevelAlert     = slog.LevelError + 8
+	LevelEmergency = slog.LevelError + 12
 )
 
 func initLogger() {
 	h := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+		Level: &logLevel,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 			if a.Key == slog.TimeKey {
 				a.Value = slog.StringValue(a.Value.Time().UTC().Format(timeutil.RFC3339Micros))
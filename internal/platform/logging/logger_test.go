@@ -20,6 +20,19 @@ func TestLogger_ReturnsLogger(t *testing.T) {
 	}
 }
 
+func TestSetLevel(t *testing.T) {
+	t.Cleanup(func() { SetLevel(slog.LevelInfo) })
+	ctx := context.Background()
+
+	if Logger().Enabled(ctx, slog.LevelDebug) {
+		t.Fatal("expected debug disabled by default")
+	}
+	SetLevel(slog.LevelDebug)
+	if !Logger().Enabled(ctx, slog.LevelDebug) {
+		t.Fatal("expected debug enabled after SetLevel")
+	}
+}
+
 func TestGCPHandler_LevelMapping(t *testing.T) {
 	tests := []struct {
 		level    slog.Level
@@ -29,9 +42,9 @@ func TestGCPHandler_LevelMapping(t *testing.T) {
 		{slog.LevelInfo, "INFO"},
 		{slog.LevelWarn, "WARNING"},
 		{slog.LevelError, "ERROR"},
-		{levelCritical, "CRITICAL"},
-		{levelAlert, "ALERT"},
-		{levelEmergency, "EMERGENCY"},
+		{LevelCritical, "CRITICAL"},
+		{LevelAlert, "ALERT"},
+		{LevelEmergency, "EMERGENCY"},
 	}
 
 	for _, tt := range tests {
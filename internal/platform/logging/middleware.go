@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
 )
 
 // RequestLogger returns Echo middleware that enriches the request context
@@ -15,7 +17,7 @@ func RequestLogger() echo.MiddlewareFunc {
 			header := c.Request().Header.Get(traceparentHeader)
 			projectID := resolveProjectID()
 
-			reqID, _ := c.Get("request_id").(string)
+			reqID := ctxkeys.RequestID(c.Request().Context())
 
 			traceID := traceResource(header, projectID)
 			if traceID == "" && reqID != "" {
@@ -26,7 +28,7 @@ func RequestLogger() echo.MiddlewareFunc {
 
 			ctx := c.Request().Context()
 			ctx = contextWithTraceID(ctx, traceID)
-			ctx = contextWithLogger(ctx, logger)
+			ctx = ContextWithLogger(ctx, logger)
 			c.SetRequest(c.Request().WithContext(ctx))
 
 			return next(c)
@@ -34,9 +36,22 @@ func RequestLogger() echo.MiddlewareFunc {
 	}
 }
 
+// AccessLogConfig configures the AccessLogger middleware.
+type AccessLogConfig struct {
+	// SlowThreshold logs requests that take longer at warn level as
+	// "slow request" instead of "request completed", so latency
+	// regressions can be alerted on. Zero disables slow request logging.
+	SlowThreshold time.Duration
+}
+
 // AccessLogger returns Echo middleware that logs structured request summaries
 // after each request completes.
 func AccessLogger() echo.MiddlewareFunc {
+	return AccessLoggerWithConfig(AccessLogConfig{})
+}
+
+// AccessLoggerWithConfig returns AccessLogger middleware with the given config.
+func AccessLoggerWithConfig(cfg AccessLogConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
 			start := time.Now()
@@ -50,15 +65,26 @@ func AccessLogger() echo.MiddlewareFunc {
 				status = resp.Status
 				size = int(resp.Size)
 			}
+			duration := time.Since(start)
 
-			logger := LoggerFromContext(c.Request().Context())
-			logger.LogAttrs(c.Request().Context(), slog.LevelInfo, "request completed",
+			attrs := []slog.Attr{
 				slog.String("method", c.Request().Method),
 				slog.String("path", c.Request().URL.Path),
 				slog.Int("status", status),
 				slog.Int("bytes", size),
-				slog.Duration("duration", time.Since(start)),
-			)
+				slog.Duration("duration", duration),
+			}
+			level, msg := slog.LevelInfo, "request completed"
+			if cfg.SlowThreshold > 0 && duration > cfg.SlowThreshold {
+				level, msg = slog.LevelWarn, "slow request"
+				attrs = append(attrs,
+					slog.String("route", c.Path()),
+					slog.Duration("threshold", cfg.SlowThreshold),
+				)
+			}
+
+			ctx := c.Request().Context()
+			LoggerFromContext(ctx).LogAttrs(ctx, level, msg, attrs...)
 
 			return err
 		}
@@ -1,18 +1,24 @@
 package logging
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
 )
 
 func TestRequestLogger_EnrichesContext(t *testing.T) {
 	e := echo.New()
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
-			c.Set("request_id", "test-req-id")
+			ctxkeys.SetRequestID(c, "test-req-id")
 			return next(c)
 		}
 	})
@@ -90,3 +96,99 @@ func TestAccessLogger_ErrorPropagation(t *testing.T) {
 		t.Fatalf("expected 400, got %d", rec.Code)
 	}
 }
+
+func TestAccessLogger_IncludesContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			ctx := ContextWithLogger(c.Request().Context(), logger)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.Use(AccessLogger())
+	e.GET("/items", func(c *echo.Context) error {
+		ctx := c.Request().Context()
+		AddContextAttrs(ctx, slog.String("category", "tools"))
+		LogInfo(ctx, "items listed")
+		return c.NoContent(http.StatusOK)
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected handler and access log lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("failed to unmarshal: %v", err)
+		}
+		if entry["category"] != "tools" {
+			t.Fatalf("expected category on %q, got %v", entry["msg"], entry["category"])
+		}
+	}
+}
+
+func TestAccessLogger_SlowRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		sleep     time.Duration
+		level     string
+		msg       string
+	}{
+		{"slow", 5 * time.Millisecond, 20 * time.Millisecond, "WARN", "slow request"},
+		{"fast", time.Minute, 0, "INFO", "request completed"},
+		{"disabled", 0, 5 * time.Millisecond, "INFO", "request completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c *echo.Context) error {
+					ctx := ContextWithLogger(c.Request().Context(), logger)
+					c.SetRequest(c.Request().WithContext(ctx))
+					return next(c)
+				}
+			})
+			e.Use(AccessLoggerWithConfig(AccessLogConfig{SlowThreshold: tt.threshold}))
+			e.GET("/items/:id", func(c *echo.Context) error {
+				time.Sleep(tt.sleep)
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/42", nil))
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if entry["level"] != tt.level || entry["msg"] != tt.msg {
+				t.Fatalf("expected %s %q, got %v %q", tt.level, tt.msg, entry["level"], entry["msg"])
+			}
+			if entry["status"] != float64(http.StatusNoContent) {
+				t.Fatalf("expected status 204, got %v", entry["status"])
+			}
+			if tt.level != "WARN" {
+				if _, ok := entry["route"]; ok {
+					t.Fatal("expected no route on regular request log")
+				}
+				return
+			}
+			if entry["route"] != "/items/:id" {
+				t.Fatalf("expected route /items/:id, got %v", entry["route"])
+			}
+			if d, ok := entry["duration"].(float64); !ok || time.Duration(d) <= tt.threshold {
+				t.Fatalf("expected duration above threshold, got %v", entry["duration"])
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Memory is an in-process Recorder that serves its counters in the
+// Prometheus text exposition format.
+type Memory struct {
+	mu     sync.Mutex
+	series map[string]uint64
+}
+
+// NewMemory returns an empty Memory recorder.
+func NewMemory() *Memory {
+	return &Memory{series: make(map[string]uint64)}
+}
+
+// IncCounter implements Recorder.
+func (m *Memory) IncCounter(name string, labels Labels) {
+	key := seriesKey(name, labels)
+	m.mu.Lock()
+	m.series[key]++
+	m.mu.Unlock()
+}
+
+// Count returns the current value of the series identified by name and labels.
+func (m *Memory) Count(name string, labels Labels) uint64 {
+	key := seriesKey(name, labels)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.series[key]
+}
+
+// ServeHTTP writes all series sorted by name and labels.
+func (m *Memory) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	snapshot := maps.Clone(m.series)
+	m.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, key := range slices.Sorted(maps.Keys(snapshot)) {
+		_, _ = fmt.Fprintf(w, "%s %d\n", key, snapshot[key])
+	}
+}
+
+// seriesKey formats name and labels as name{k="v",...} with sorted keys.
+func seriesKey(name string, labels Labels) string {
+	if len(labels) == 0 {
+		return name
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, k := range slices.Sorted(maps.Keys(labels)) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var _ Recorder = (*Memory)(nil)
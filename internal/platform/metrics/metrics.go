@@ -0,0 +1,49 @@
+// Package metrics provides labeled counters behind an injectable Recorder.
+// Until SetRecorder is called every update is a no-op, so instrumented code
+// needs no configuration checks.
+package metrics
+
+import (
+	"sync/atomic"
+)
+
+// Counter names recorded by platform packages.
+const (
+	// AuthFailures counts rejected authentication attempts, labeled by reason.
+	AuthFailures = "auth_failures_total"
+	// RateLimitRejections counts requests rejected with 429 Too Many Requests.
+	RateLimitRejections = "rate_limit_rejections_total"
+	// NegotiatedFormats counts responses written by respond.Negotiate, labeled
+	// by format (json, cbor) and reason (explicit, default, fallback).
+	NegotiatedFormats = "negotiated_formats_total"
+)
+
+// Labels are the dimensions of a counter series.
+type Labels map[string]string
+
+// Recorder receives counter increments. Implementations must be safe for
+// concurrent use.
+type Recorder interface {
+	IncCounter(name string, labels Labels)
+}
+
+type holder struct{ r Recorder }
+
+var current atomic.Pointer[holder]
+
+// SetRecorder installs r as the process-wide Recorder. Passing nil removes it,
+// making updates no-ops again.
+func SetRecorder(r Recorder) {
+	if r == nil {
+		current.Store(nil)
+		return
+	}
+	current.Store(&holder{r: r})
+}
+
+// IncCounter increments the named counter on the installed Recorder.
+func IncCounter(name string, labels Labels) {
+	if h := current.Load(); h != nil {
+		h.r.IncCounter(name, labels)
+	}
+}
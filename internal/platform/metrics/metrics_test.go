@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIncCounter_NoopByDefault(t *testing.T) {
+	SetRecorder(nil)
+	IncCounter(AuthFailures, Labels{"reason": "invalid_token"})
+}
+
+func TestIncCounter_UsesInstalledRecorder(t *testing.T) {
+	mem := NewMemory()
+	SetRecorder(mem)
+	t.Cleanup(func() { SetRecorder(nil) })
+
+	IncCounter(AuthFailures, Labels{"reason": "invalid_token"})
+	IncCounter(AuthFailures, Labels{"reason": "invalid_token"})
+	IncCounter(AuthFailures, Labels{"reason": "token_expired"})
+
+	if got := mem.Count(AuthFailures, Labels{"reason": "invalid_token"}); got != 2 {
+		t.Fatalf("expected 2, got %d", got)
+	}
+	if got := mem.Count(AuthFailures, Labels{"reason": "token_expired"}); got != 1 {
+		t.Fatalf("expected 1, got %d", got)
+	}
+}
+
+func TestMemory_ServeHTTP(t *testing.T) {
+	mem := NewMemory()
+	mem.IncCounter(RateLimitRejections, nil)
+	mem.IncCounter(AuthFailures, Labels{"reason": "no_token", "scheme": "Bearer"})
+
+	rec := httptest.NewRecorder()
+	mem.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := "auth_failures_total{reason=\"no_token\",scheme=\"Bearer\"} 1\nrate_limit_rejections_total 1\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
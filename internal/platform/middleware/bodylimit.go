@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+)
+
+// MaxBodyBytes is the request body limit cmd/server applies with Echo's
+// BodyLimit middleware.
+const MaxBodyBytes int64 = 1 << 20
+
+// DecompressBody returns Echo middleware that decodes gzip request bodies
+// (Content-Encoding: gzip) and caps the decompressed body at limit bytes,
+// defaulting to MaxBodyBytes when limit is not positive. Reading past the cap
+// fails with 413, so handlers stop decoding a compression bomb once limit
+// bytes have been inflated instead of expanding it in memory.
+//
+// Register it after BodyLimit: BodyLimit then bounds the compressed bytes read
+// from the connection and DecompressBody the bytes handlers decode.
+func DecompressBody(limit int64) echo.MiddlewareFunc {
+	if limit <= 0 {
+		limit = MaxBodyBytes
+	}
+	return middleware.DecompressWithConfig(middleware.DecompressConfig{MaxDecompressedSize: limit})
+}
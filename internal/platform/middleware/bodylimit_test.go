@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+)
+
+// countingReader records how many compressed bytes were consumed.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func gzipBody(t *testing.T, parts ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		t.Fatalf("failed to create gzip writer: %v", err)
+	}
+	for _, part := range parts {
+		if _, err = io.WriteString(zw, part); err != nil {
+			t.Fatalf("failed to compress: %v", err)
+		}
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newDecompressEcho(limit int64) *echo.Echo {
+	e := echo.New()
+	e.Use(middleware.BodyLimit(MaxBodyBytes), DecompressBody(limit))
+	e.POST("/profile", func(c *echo.Context) error {
+		var input struct {
+			Firstname string `json:"firstname"`
+		}
+		if err := json.NewDecoder(c.Request().Body).Decode(&input); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, input.Firstname)
+	})
+	return e
+}
+
+func postGzip(e *echo.Echo, body io.Reader) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/profile", body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderContentEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDecompressBody_RejectsCompressionBomb(t *testing.T) {
+	const limit = 4 << 10
+	padding := strings.Repeat(" ", 1<<20)
+	parts := []string{`{"firstname":`}
+	for range 16 {
+		parts = append(parts, padding)
+	}
+	parts = append(parts, `"Ada"}`)
+	compressed := gzipBody(t, parts...)
+	if int64(len(compressed)) > MaxBodyBytes {
+		t.Fatalf("expected the compressed payload to fit the body limit, got %d bytes", len(compressed))
+	}
+
+	body := &countingReader{r: bytes.NewReader(compressed)}
+	rec := postGzip(newDecompressEcho(limit), body)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if body.read >= len(compressed) {
+		t.Fatalf("expected decoding to stop early, consumed %d of %d compressed bytes", body.read, len(compressed))
+	}
+}
+
+func TestDecompressBody_AllowsNormalPayload(t *testing.T) {
+	rec := postGzip(newDecompressEcho(0), bytes.NewReader(gzipBody(t, `{"firstname":"Ada"}`)))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "Ada" {
+		t.Fatalf("expected 200 with the decoded name, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDecompressBody_LeavesIdentityBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(`{"firstname":"Ada"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	newDecompressEcho(0).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "Ada" {
+		t.Fatalf("expected 200 with the name, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+// CacheControl returns route middleware that replaces the Cache-Control:
+// no-store set by Security with value on successful GET and HEAD responses,
+// so browsers and CDNs may cache public resources. Error responses and other
+// methods keep no-store. The header is set just before the response is
+// committed, so the middleware must run after Security; apply it per route.
+func CacheControl(value string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			method := c.Request().Method
+			if method != http.MethodGet && method != http.MethodHead {
+				return next(c)
+			}
+
+			if resp, err := echo.UnwrapResponse(c.Response()); err == nil {
+				resp.Before(func() {
+					if resp.Status < http.StatusMultipleChoices || resp.Status == http.StatusNotModified {
+						resp.Header().Set("Cache-Control", value)
+					}
+				})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestCacheControl(t *testing.T) {
+	const public = "public, max-age=60"
+	e := echo.New()
+	e.Use(Security())
+	ok := func(c *echo.Context) error { return c.String(http.StatusOK, "ok") }
+	e.GET("/public", ok, CacheControl(public))
+	e.HEAD("/public", ok, CacheControl(public))
+	e.POST("/public", ok, CacheControl(public))
+	e.GET("/public/missing", func(*echo.Context) error { return echo.ErrNotFound }, CacheControl(public))
+	e.GET("/public/unchanged", func(c *echo.Context) error {
+		return c.NoContent(http.StatusNotModified)
+	}, CacheControl(public))
+	e.GET("/private", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/public", public},
+		{http.MethodHead, "/public", public},
+		{http.MethodGet, "/public/unchanged", public},
+		{http.MethodPost, "/public", "no-store"},
+		{http.MethodGet, "/public/missing", "no-store"},
+		{http.MethodGet, "/private", "no-store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if got := rec.Header().Get("Cache-Control"); got != tt.want {
+				t.Fatalf("expected Cache-Control %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// defaultTrustedProxies are the ranges trusted when ClientIPConfig.TrustedProxies
+// is nil: loopback, link-local, and private networks.
+var defaultTrustedProxies = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("fc00::/7"),
+}
+
+// ClientIPConfig configures client IP extraction.
+type ClientIPConfig struct {
+	// TrustedProxies lists the networks of proxies whose forwarding headers
+	// are believed. Defaults to loopback, link-local, and private ranges; an
+	// empty non-nil slice trusts no proxy.
+	TrustedProxies []netip.Prefix
+	// RequestIDHeaders lists inbound request ID headers besides X-Request-ID
+	// that ProxyHeaders removes from untrusted peers.
+	RequestIDHeaders []string
+}
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs or bare IPs, as
+// read from the TRUSTED_PROXIES environment variable.
+func ParseTrustedProxies(spec string) ([]netip.Prefix, error) {
+	prefixes := []netip.Prefix{}
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			addr, err := netip.ParseAddr(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", part, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", part, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ClientIPExtractor returns an echo.IPExtractor backed by ClientIP, for use as
+// Echo#IPExtractor so c.RealIP() reports the client address.
+func ClientIPExtractor(cfg ClientIPConfig) echo.IPExtractor {
+	return func(r *http.Request) string {
+		return ClientIP(r, cfg)
+	}
+}
+
+// ClientIP returns the address of the client that sent r. The proxy chain is
+// read from Forwarded (RFC 7239) for= parameters, falling back to
+// X-Forwarded-For and then X-Real-IP, with the peer address as the final hop.
+// Hops are walked from the nearest one outward, skipping trusted proxies; the
+// first untrusted hop is the client. Entries to its left were supplied by the
+// client and are ignored, so spoofed hops cannot change the result. When
+// every hop is trusted the leftmost is returned.
+func ClientIP(r *http.Request, cfg ClientIPConfig) string {
+	peer, ok := parseHop(r.RemoteAddr)
+	if !ok {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		return host
+	}
+	if !cfg.trusts(peer) {
+		return peer.String()
+	}
+
+	client := peer
+	hops := forwardedHops(r.Header)
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !cfg.trusts(addr) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// trusts reports whether addr belongs to a trusted proxy network.
+func (cfg ClientIPConfig) trusts(addr netip.Addr) bool {
+	trusted := cfg.TrustedProxies
+	if trusted == nil {
+		trusted = defaultTrustedProxies
+	}
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHops returns the proxy chain reported by the request headers,
+// client first.
+func forwardedHops(h http.Header) []string {
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		var hops []string
+		for _, v := range values {
+			for element := range strings.SplitSeq(v, ",") {
+				for pair := range strings.SplitSeq(element, ";") {
+					key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+					if ok && strings.EqualFold(key, "for") {
+						hops = append(hops, strings.Trim(value, `"`))
+					}
+				}
+			}
+		}
+		return hops
+	}
+	if values := h.Values("X-Forwarded-For"); len(values) > 0 {
+		var hops []string
+		for _, v := range values {
+			for hop := range strings.SplitSeq(v, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+		return hops
+	}
+	if v := h.Get("X-Real-IP"); v != "" {
+		return []string{strings.TrimSpace(v)}
+	}
+	return nil
+}
+
+// parseHop parses an address that may carry a port or IPv6 brackets, such as
+// "192.0.2.1:8080", "[2001:db8::1]:4711", or "2001:db8::1".
+func parseHop(s string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
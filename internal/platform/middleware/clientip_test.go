@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8:ffff::/48")}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string][]string
+		trusted []netip.Prefix
+		builtin bool
+		want    string
+	}{
+		{
+			name:   "no headers",
+			remote: "203.0.113.7:51234",
+			want:   "203.0.113.7",
+		},
+		{
+			name:    "forwarded quoted ipv6 with port",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"Forwarded": {`for="[2001:db8:cafe::17]:4711";proto=https`}},
+			want:    "2001:db8:cafe::17",
+		},
+		{
+			name:    "forwarded multiple elements",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"Forwarded": {"for=198.51.100.9, For=10.0.0.5;by=10.0.0.2"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:   "forwarded preferred over x-forwarded-for",
+			remote: "10.0.0.2:443",
+			headers: map[string][]string{
+				"Forwarded":       {"for=198.51.100.9"},
+				"X-Forwarded-For": {"192.0.2.99"},
+			},
+			want: "198.51.100.9",
+		},
+		{
+			name:    "x-forwarded-for chain",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.9, 10.0.0.9", "10.0.0.5"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "spoofed hops stripped",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"X-Forwarded-For": {"1.2.3.4, 127.0.0.1, 198.51.100.9, 10.0.0.5"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "spoofed forwarded hops stripped",
+			remote:  "[2001:db8:ffff::1]:443",
+			headers: map[string][]string{"Forwarded": {`for=10.0.0.1, for="[2001:db8:cafe::17]"`}},
+			want:    "2001:db8:cafe::17",
+		},
+		{
+			name:    "untrusted peer ignores headers",
+			remote:  "203.0.113.7:51234",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.9"}, "X-Real-IP": {"198.51.100.10"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "x-real-ip fallback",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"X-Real-IP": {"198.51.100.10"}},
+			want:    "198.51.100.10",
+		},
+		{
+			name:    "all trusted returns leftmost",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"X-Forwarded-For": {"10.1.1.1, 10.0.0.5"}},
+			want:    "10.1.1.1",
+		},
+		{
+			name:    "obfuscated hop stops walk",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"Forwarded": {"for=198.51.100.9, for=unknown, for=10.0.0.5"}},
+			want:    "10.0.0.5",
+		},
+		{
+			name:    "empty trust list",
+			remote:  "10.0.0.2:443",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.9"}},
+			trusted: []netip.Prefix{},
+			want:    "10.0.0.2",
+		},
+		{
+			name:    "default trust list",
+			remote:  "192.168.1.10:443",
+			headers: map[string][]string{"X-Forwarded-For": {"198.51.100.9"}},
+			builtin: true,
+			want:    "198.51.100.9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, vs := range tt.headers {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			cfg := ClientIPConfig{TrustedProxies: proxies}
+			if tt.trusted != nil {
+				cfg.TrustedProxies = tt.trusted
+			}
+			if tt.builtin {
+				cfg.TrustedProxies = nil
+			}
+			if got := ClientIP(req, cfg); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClientIPExtractor_RealIP(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = ClientIPExtractor(ClientIPConfig{})
+	var got string
+	e.GET("/", func(c *echo.Context) error {
+		got = c.RealIP()
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:8080"
+	req.Header.Set("Forwarded", "for=198.51.100.9")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "198.51.100.9" {
+		t.Fatalf("expected RealIP from Forwarded, got %q", got)
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	got, err := ParseTrustedProxies(" 10.0.0.0/8, 192.0.2.1 ,2001:db8::/32,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.0.2.1/32", "2001:db8::/32"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	if _, err := ParseTrustedProxies("10.0.0.0/99"); err == nil {
+		t.Fatal("expected error for invalid prefix")
+	}
+	if _, err := ParseTrustedProxies("not-an-ip"); err == nil {
+		t.Fatal("expected error for invalid address")
+	}
+}
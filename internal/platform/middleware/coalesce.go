@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// Coalesce returns Echo middleware that collapses concurrent identical GET and
+// HEAD requests into a single handler invocation. The first request runs the
+// handler while duplicates wait and then receive a copy of the same status,
+// headers, and body. Errors returned by the handler are returned to every
+// caller so each request renders its own error response.
+//
+// Requests are keyed by method, path, normalized query string, and the Accept
+// and Prefer headers, so clients negotiating different representations never
+// share a body. Only apply to public routes whose response does not depend on the
+// caller's identity.
+func Coalesce() echo.MiddlewareFunc {
+	return CoalesceWithConfig(CoalesceConfig{})
+}
+
+// CoalesceConfig configures the Coalesce middleware.
+type CoalesceConfig struct {
+	// Skipper bypasses coalescing, e.g. for streamed responses that must not
+	// be buffered.
+	Skipper func(c *echo.Context) bool
+}
+
+// CoalesceWithConfig returns Coalesce middleware with the given config.
+func CoalesceWithConfig(cfg CoalesceConfig) echo.MiddlewareFunc {
+	var group singleflight.Group
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				return next(c)
+			}
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			v, _, _ := group.Do(coalesceKey(req), func() (any, error) {
+				capture := &captureWriter{header: make(http.Header)}
+				orig := c.Response()
+				c.SetResponse(capture)
+				defer c.SetResponse(orig)
+
+				capture.err = next(c)
+				return capture, nil
+			})
+
+			res, _ := v.(*captureWriter)
+			if res.err != nil {
+				return res.err
+			}
+
+			h := c.Response().Header()
+			for k, vals := range res.header {
+				if k == "Vary" {
+					addVary(h, vals)
+					continue
+				}
+				h[k] = append([]string(nil), vals...)
+			}
+			status := res.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			c.Response().WriteHeader(status)
+			_, err := c.Response().Write(res.body.Bytes())
+			return err
+		}
+	}
+}
+
+// coalesceKey identifies requests that may share a response.
+// url.Values.Encode sorts by key, so parameter order does not matter.
+func coalesceKey(r *http.Request) string {
+	return r.Method + " " + r.URL.Path + "?" + r.URL.Query().Encode() +
+		"\n" + r.Header.Get("Accept") + "\n" + strings.Join(r.Header.Values("Prefer"), ",")
+}
+
+// captureWriter buffers a handler's response so it can be replayed to every
+// coalesced caller.
+type captureWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+	err    error
+}
+
+func (w *captureWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
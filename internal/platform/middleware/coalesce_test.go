@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newCoalesceEcho(handler echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.GET("/items", handler, Coalesce())
+	e.POST("/items", handler, Coalesce())
+	return e
+}
+
+// runConcurrent serves reqs in parallel. The handler is held until all callers
+// have had a chance to join the in-flight request.
+func runConcurrent(
+	t *testing.T,
+	e *echo.Echo,
+	calls *atomic.Int32,
+	release chan struct{},
+	reqs []*http.Request,
+) []*httptest.ResponseRecorder {
+	t.Helper()
+	recs := make([]*httptest.ResponseRecorder, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		recs[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			e.ServeHTTP(recs[i], req)
+		})
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	return recs
+}
+
+func TestCoalesce_SharesConcurrentIdenticalRequests(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := newCoalesceEcho(func(c *echo.Context) error {
+		n := calls.Add(1)
+		<-release
+		c.Response().Header().Set("X-Call", "shared")
+		return c.JSON(http.StatusOK, map[string]int32{"call": n})
+	})
+
+	reqs := make([]*http.Request, 10)
+	for i := range reqs {
+		// Parameter order differs to exercise query normalization.
+		if i%2 == 0 {
+			reqs[i] = httptest.NewRequest(http.MethodGet, "/items?limit=5&category=tools", nil)
+		} else {
+			reqs[i] = httptest.NewRequest(http.MethodGet, "/items?category=tools&limit=5", nil)
+		}
+	}
+	recs := runConcurrent(t, e, &calls, release, reqs)
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", got)
+	}
+	want := recs[0].Body.String()
+	for i, rec := range recs {
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, rec.Code)
+		}
+		if rec.Body.String() != want {
+			t.Fatalf("request %d: expected body %q, got %q", i, want, rec.Body.String())
+		}
+		if rec.Header().Get("X-Call") != "shared" {
+			t.Fatalf("request %d: expected captured header to be replayed", i)
+		}
+	}
+}
+
+func TestCoalesce_DifferentAcceptNotShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := newCoalesceEcho(func(c *echo.Context) error {
+		calls.Add(1)
+		<-release
+		return c.String(http.StatusOK, c.Request().Header.Get("Accept"))
+	})
+
+	jsonReq := httptest.NewRequest(http.MethodGet, "/items", nil)
+	jsonReq.Header.Set("Accept", "application/json")
+	cborReq := httptest.NewRequest(http.MethodGet, "/items", nil)
+	cborReq.Header.Set("Accept", "application/cbor")
+	recs := runConcurrent(t, e, &calls, release, []*http.Request{jsonReq, cborReq})
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", got)
+	}
+	if recs[0].Body.String() != "application/json" {
+		t.Fatalf("expected json body, got %q", recs[0].Body.String())
+	}
+	if recs[1].Body.String() != "application/cbor" {
+		t.Fatalf("expected cbor body, got %q", recs[1].Body.String())
+	}
+}
+
+func TestCoalesce_DifferentPreferNotShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := newCoalesceEcho(func(c *echo.Context) error {
+		calls.Add(1)
+		<-release
+		return c.String(http.StatusOK, c.Request().Header.Get("Prefer"))
+	})
+
+	plainReq := httptest.NewRequest(http.MethodGet, "/items", nil)
+	nullReq := httptest.NewRequest(http.MethodGet, "/items", nil)
+	nullReq.Header.Set("Prefer", "optional=null")
+	recs := runConcurrent(t, e, &calls, release, []*http.Request{plainReq, nullReq})
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", got)
+	}
+	if recs[0].Body.String() != "" {
+		t.Fatalf("expected empty body, got %q", recs[0].Body.String())
+	}
+	if recs[1].Body.String() != "optional=null" {
+		t.Fatalf("expected optional=null body, got %q", recs[1].Body.String())
+	}
+}
+
+func TestCoalesce_MergesVary(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := echo.New()
+	e.Use(Vary())
+	e.GET("/items", func(c *echo.Context) error {
+		calls.Add(1)
+		<-release
+		c.Response().Header().Add("Vary", "accept, Prefer")
+		return c.String(http.StatusOK, "ok")
+	}, Coalesce())
+
+	reqs := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/items", nil),
+		httptest.NewRequest(http.MethodGet, "/items", nil),
+	}
+	recs := runConcurrent(t, e, &calls, release, reqs)
+
+	for i, rec := range recs {
+		if got := rec.Header().Values("Vary"); !slices.Equal(got, []string{"Accept", "Prefer"}) {
+			t.Fatalf("request %d: expected Vary [Accept Prefer], got %v", i, got)
+		}
+	}
+}
+
+func TestCoalesce_ErrorReturnedToEveryCaller(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := newCoalesceEcho(func(c *echo.Context) error {
+		calls.Add(1)
+		<-release
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "unavailable")
+	})
+
+	reqs := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/items", nil),
+		httptest.NewRequest(http.MethodGet, "/items", nil),
+	}
+	recs := runConcurrent(t, e, &calls, release, reqs)
+
+	for i, rec := range recs {
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("request %d: expected 503, got %d", i, rec.Code)
+		}
+	}
+}
+
+func TestCoalesce_SkipsUnsafeMethods(t *testing.T) {
+	var calls atomic.Int32
+	e := newCoalesceEcho(func(c *echo.Context) error {
+		calls.Add(1)
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", nil))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected handler to run for each POST, ran %d times", got)
+	}
+}
+
+func TestCoalesce_Skipper(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	e := echo.New()
+	e.GET("/items", func(c *echo.Context) error {
+		calls.Add(1)
+		<-release
+		return c.NoContent(http.StatusNoContent)
+	}, CoalesceWithConfig(CoalesceConfig{
+		Skipper: func(c *echo.Context) bool { return c.Request().Header.Get("Accept") == "application/x-ndjson" },
+	}))
+
+	reqs := make([]*http.Request, 2)
+	for i := range reqs {
+		reqs[i] = httptest.NewRequest(http.MethodGet, "/items", nil)
+		reqs[i].Header.Set("Accept", "application/x-ndjson")
+	}
+	runConcurrent(t, e, &calls, release, reqs)
+
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected skipped requests to run separately, ran %d times", got)
+	}
+}
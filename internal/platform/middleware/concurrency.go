@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+)
+
+// DefaultMaxPerIP is the concurrent request cap per client IP when
+// ConcurrencyLimitConfig.MaxPerIP is unset.
+const DefaultMaxPerIP = 64
+
+// ConcurrencyLimitConfig configures the ConcurrencyLimit middleware.
+type ConcurrencyLimitConfig struct {
+	// MaxPerIP is the number of requests a single client IP may have in
+	// flight. Defaults to DefaultMaxPerIP.
+	MaxPerIP int
+	// Skipper bypasses the limit, e.g. for health checks.
+	Skipper func(c *echo.Context) bool
+}
+
+// ConcurrencyLimit returns Echo middleware that caps the number of in-flight
+// requests per client IP and rejects requests beyond the cap with 429 Too
+// Many Requests. Clients are keyed by Context#RealIP, so set Echo#IPExtractor
+// with ClientIPExtractor to count clients behind trusted proxies separately.
+//
+// Slots are released when the request completes, including when a handler
+// further down the chain panics.
+func ConcurrencyLimit(cfg ConcurrencyLimitConfig) echo.MiddlewareFunc {
+	if cfg.MaxPerIP <= 0 {
+		cfg.MaxPerIP = DefaultMaxPerIP
+	}
+
+	var (
+		mu       sync.Mutex
+		inflight = make(map[string]int)
+	)
+	release := func(ip string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if inflight[ip] <= 1 {
+			delete(inflight, ip)
+			return
+		}
+		inflight[ip]--
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			ip := c.RealIP()
+			mu.Lock()
+			if inflight[ip] >= cfg.MaxPerIP {
+				mu.Unlock()
+				return echo.NewHTTPError(http.StatusTooManyRequests, "too many concurrent requests")
+			}
+			inflight[ip]++
+			mu.Unlock()
+			defer release(ip)
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func serveFrom(e *echo.Echo, ip, path string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "127.0.0.1:8080"
+	req.Header.Set("X-Forwarded-For", ip)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	const maxPerIP = 2
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+
+	e := echo.New()
+	e.IPExtractor = ClientIPExtractor(ClientIPConfig{})
+	e.Use(ConcurrencyLimit(ConcurrencyLimitConfig{MaxPerIP: maxPerIP}))
+	e.GET("/slow", func(c *echo.Context) error {
+		entered <- struct{}{}
+		<-unblock
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.GET("/fast", func(c *echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, maxPerIP)
+	for i := range maxPerIP {
+		wg.Go(func() {
+			codes[i] = serveFrom(e, "198.51.100.1", "/slow")
+		})
+		<-entered
+	}
+
+	if got := serveFrom(e, "198.51.100.1", "/fast"); got != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 over the cap, got %d", got)
+	}
+	if got := serveFrom(e, "198.51.100.2", "/fast"); got != http.StatusNoContent {
+		t.Fatalf("expected other IP to be unaffected, got %d", got)
+	}
+
+	close(unblock)
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusNoContent {
+			t.Fatalf("expected in-flight request %d to succeed, got %d", i, code)
+		}
+	}
+	if got := serveFrom(e, "198.51.100.1", "/fast"); got != http.StatusNoContent {
+		t.Fatalf("expected slots to be released, got %d", got)
+	}
+}
+
+func TestConcurrencyLimit_ReleasesOnPanic(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = ClientIPExtractor(ClientIPConfig{})
+	e.Use(ConcurrencyLimit(ConcurrencyLimitConfig{MaxPerIP: 1}))
+	e.GET("/panic", func(*echo.Context) error {
+		panic("boom")
+	})
+	e.GET("/fast", func(c *echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic to propagate")
+			}
+		}()
+		serveFrom(e, "198.51.100.1", "/panic")
+	}()
+
+	if got := serveFrom(e, "198.51.100.1", "/fast"); got != http.StatusNoContent {
+		t.Fatalf("expected slot to be released after panic, got %d", got)
+	}
+}
+
+func TestConcurrencyLimit_Skipper(t *testing.T) {
+	unblock := make(chan struct{})
+	entered := make(chan struct{})
+
+	e := echo.New()
+	e.Use(ConcurrencyLimit(ConcurrencyLimitConfig{
+		MaxPerIP: 1,
+		Skipper:  func(c *echo.Context) bool { return c.Request().URL.Path == "/health" },
+	}))
+	e.GET("/slow", func(c *echo.Context) error {
+		entered <- struct{}{}
+		<-unblock
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.GET("/health", func(c *echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	var wg sync.WaitGroup
+	wg.Go(func() { serveFrom(e, "198.51.100.1", "/slow") })
+	<-entered
+
+	if got := serveFrom(e, "198.51.100.1", "/health"); got != http.StatusNoContent {
+		t.Fatalf("expected skipped path to bypass the cap, got %d", got)
+	}
+	close(unblock)
+	wg.Wait()
+}
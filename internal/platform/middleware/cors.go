@@ -2,14 +2,58 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
 )
 
+// DefaultCORSMaxAge is how long browsers may cache preflight results when
+// CORSConfig.MaxAge is zero.
+const DefaultCORSMaxAge = 10 * time.Minute
+
+// corsAllowHeaders lists request headers browsers may send cross-origin.
+var corsAllowHeaders = []string{
+	"Accept",
+	"Authorization",
+	"Content-Type",
+	"If-Match",
+	"Prefer",
+	"X-CSRF-Token",
+	"X-Request-ID",
+	"X-Request-Timeout",
+	"traceparent",
+}
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// MaxAge sets Access-Control-Max-Age on preflight responses.
+	// Defaults to DefaultCORSMaxAge; a negative value disables caching.
+	MaxAge time.Duration
+}
+
 // CORS returns Echo middleware that applies permissive CORS defaults suitable for APIs.
 func CORS() echo.MiddlewareFunc {
-	return middleware.CORSWithConfig(middleware.CORSConfig{
+	return CORSWithConfig(CORSConfig{})
+}
+
+// CORSWithConfig returns CORS middleware with the given config. Preflight
+// responses reflect the requested headers that are in the allowed set and
+// omit the rest, so the browser rejects requests using disallowed headers.
+func CORSWithConfig(cfg CORSConfig) echo.MiddlewareFunc {
+	maxAge := cfg.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultCORSMaxAge
+	}
+	// Echo sends "0" for negative values and omits the header for zero.
+	maxAgeSeconds := int(maxAge / time.Second)
+	if maxAge < 0 {
+		maxAgeSeconds = -1
+	}
+
+	cors := middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{
 			http.MethodGet,
@@ -20,19 +64,55 @@ func CORS() echo.MiddlewareFunc {
 			http.MethodDelete,
 			http.MethodOptions,
 		},
-		AllowHeaders: []string{
-			"Accept",
-			"Authorization",
-			"Content-Type",
-			"X-CSRF-Token",
-			"X-Request-ID",
-			"traceparent",
-		},
 		ExposeHeaders: []string{
+			"Deprecation",
+			"ETag",
 			"Link",
 			"Location",
+			"Preference-Applied",
+			HeaderRateLimitReset,
+			"Retry-After",
+			"Sunset",
+			"X-Negotiated",
 			"X-Request-ID",
 		},
-		MaxAge: 300,
+		MaxAge: maxAgeSeconds,
 	})
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := cors(next)
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if req.Method == http.MethodOptions {
+				filterRequestHeaders(req.Header)
+			}
+			return h(c)
+		}
+	}
+}
+
+// filterRequestHeaders drops headers outside corsAllowHeaders from
+// Access-Control-Request-Headers. Echo's CORS middleware reflects the
+// remaining value into Access-Control-Allow-Headers.
+func filterRequestHeaders(h http.Header) {
+	requested := h.Values("Access-Control-Request-Headers")
+	if len(requested) == 0 {
+		return
+	}
+
+	var allowed []string
+	for _, v := range requested {
+		for name := range strings.SplitSeq(v, ",") {
+			name = strings.TrimSpace(name)
+			if slices.ContainsFunc(corsAllowHeaders, func(a string) bool { return strings.EqualFold(a, name) }) {
+				allowed = append(allowed, name)
+			}
+		}
+	}
+
+	if len(allowed) == 0 {
+		h.Del("Access-Control-Request-Headers")
+		return
+	}
+	h.Set("Access-Control-Request-Headers", strings.Join(allowed, ","))
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v5"
 )
@@ -70,3 +71,69 @@ func TestCORS_ExposedHeaders(t *testing.T) {
 		t.Fatal("expected Access-Control-Expose-Headers to be set")
 	}
 }
+
+func TestCORS_PreflightMaxAge(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  CORSConfig
+		want string
+	}{
+		{"default", CORSConfig{}, "600"},
+		{"custom", CORSConfig{MaxAge: time.Hour}, "3600"},
+		{"disabled", CORSConfig{MaxAge: -1}, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(CORSWithConfig(tt.cfg))
+			e.GET("/test", func(c *echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+			req.Header.Set("Origin", "http://example.com")
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Max-Age"); got != tt.want {
+				t.Fatalf("expected Access-Control-Max-Age %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCORS_PreflightReflectsAllowedHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+	}{
+		{"allowed", "authorization, content-type", "authorization,content-type"},
+		{"mixed", "Authorization, X-Evil-Header, If-Match", "Authorization,If-Match"},
+		{"disallowed only", "X-Evil-Header", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(CORS())
+			e.PATCH("/test", func(c *echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+			req.Header.Set("Origin", "http://example.com")
+			req.Header.Set("Access-Control-Request-Method", "PATCH")
+			req.Header.Set("Access-Control-Request-Headers", tt.requested)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("expected 204, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Headers"); got != tt.want {
+				t.Fatalf("expected Access-Control-Allow-Headers %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
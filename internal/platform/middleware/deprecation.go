@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// DeprecationConfig configures the Deprecation middleware.
+type DeprecationConfig struct {
+	// Sunset is when the routes stop being served, sent as the Sunset
+	// header (RFC 8594). Omitted when zero.
+	Sunset time.Time
+	// DeprecatedAt, when set, is sent as "@<unix seconds>" in the Deprecation
+	// header (RFC 9745) instead of "true".
+	DeprecatedAt time.Time
+	// Link is the URL of human-readable deprecation documentation, sent as a
+	// Link with rel="deprecation".
+	Link string
+}
+
+// Deprecation returns Echo middleware that marks every response of the routes
+// it wraps as deprecated and announces the sunset time. Apply it to the route
+// groups being phased out.
+func Deprecation(sunset time.Time) echo.MiddlewareFunc {
+	return DeprecationWithConfig(DeprecationConfig{Sunset: sunset})
+}
+
+// DeprecationWithConfig returns Deprecation middleware with the given config.
+// Headers are added just before the response is written, so Link headers set
+// by the handler (such as pagination links) are kept.
+func DeprecationWithConfig(cfg DeprecationConfig) echo.MiddlewareFunc {
+	deprecation := "true"
+	if !cfg.DeprecatedAt.IsZero() {
+		deprecation = "@" + strconv.FormatInt(cfg.DeprecatedAt.Unix(), 10)
+	}
+	var sunset string
+	if !cfg.Sunset.IsZero() {
+		sunset = cfg.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var link string
+	if cfg.Link != "" {
+		link = "<" + cfg.Link + `>; rel="deprecation"; type="text/html"`
+	}
+
+	apply := func(h http.Header) {
+		h.Set("Deprecation", deprecation)
+		if sunset != "" {
+			h.Set("Sunset", sunset)
+		}
+		if link != "" {
+			h.Add("Link", link)
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			resp, err := echo.UnwrapResponse(c.Response())
+			if err != nil {
+				apply(c.Response().Header())
+				return next(c)
+			}
+			resp.Before(func() { apply(resp.Header()) })
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+var testSunset = time.Date(2027, time.March, 31, 23, 59, 59, 0, time.FixedZone("EET", 2*60*60))
+
+func newDeprecationEcho(cfg DeprecationConfig) *echo.Echo {
+	e := echo.New()
+	old := e.Group("/v1", DeprecationWithConfig(cfg))
+	old.GET("/items", func(c *echo.Context) error {
+		c.Response().Header().Set("Link", `</v1/items?cursor=abc>; rel="next"`)
+		return c.String(http.StatusOK, "ok")
+	})
+	e.GET("/v2/items", func(c *echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+func TestDeprecation_SetsHeaders(t *testing.T) {
+	e := newDeprecationEcho(DeprecationConfig{Sunset: testSunset, Link: "https://example.com/docs/v1-deprecation"})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Fatalf("expected unaltered response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Deprecation"); got != "true" {
+		t.Fatalf("expected Deprecation true, got %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "Wed, 31 Mar 2027 21:59:59 GMT" {
+		t.Fatalf("expected Sunset as HTTP-date in GMT, got %q", got)
+	}
+	links := rec.Header().Values("Link")
+	if len(links) != 2 {
+		t.Fatalf("expected handler and deprecation links, got %q", links)
+	}
+	if links[1] != `<https://example.com/docs/v1-deprecation>; rel="deprecation"; type="text/html"` {
+		t.Fatalf("unexpected deprecation link %q", links[1])
+	}
+}
+
+func TestDeprecation_SunsetParses(t *testing.T) {
+	e := newDeprecationEcho(DeprecationConfig{Sunset: testSunset})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	got, err := http.ParseTime(rec.Header().Get("Sunset"))
+	if err != nil {
+		t.Fatalf("Sunset is not an HTTP-date: %v", err)
+	}
+	if !got.Equal(testSunset) {
+		t.Fatalf("expected %v, got %v", testSunset, got)
+	}
+}
+
+func TestDeprecation_DeprecatedAt(t *testing.T) {
+	deprecatedAt := time.Date(2026, time.June, 30, 23, 59, 59, 0, time.UTC)
+	e := newDeprecationEcho(DeprecationConfig{DeprecatedAt: deprecatedAt})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Deprecation"); got != "@1782863999" {
+		t.Fatalf("expected structured date, got %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "" {
+		t.Fatalf("expected no Sunset when unset, got %q", got)
+	}
+}
+
+func TestDeprecation_OnlyWrappedRoutes(t *testing.T) {
+	e := newDeprecationEcho(DeprecationConfig{Sunset: testSunset})
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/items", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Deprecation") != "" || rec.Header().Get("Sunset") != "" {
+		t.Fatalf("expected no deprecation headers, got %v", rec.Header())
+	}
+}
+
+func TestDeprecation_Shorthand(t *testing.T) {
+	e := echo.New()
+	e.GET("/old", func(c *echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, Deprecation(testSunset))
+
+	req := httptest.NewRequest(http.MethodGet, "/old", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Deprecation") != "true" || rec.Header().Get("Sunset") == "" {
+		t.Fatalf("expected deprecation headers, got %v", rec.Header())
+	}
+}
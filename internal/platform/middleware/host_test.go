@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newAllowedHostsEcho(cfg AllowedHostsConfig) *echo.Echo {
+	e := echo.New()
+	e.Pre(AllowedHosts(cfg))
+	handler := func(c *echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+	e.GET("/health", handler)
+	e.GET("/v1/items", handler)
+	return e
+}
+
+func TestAllowedHosts(t *testing.T) {
+	cfg := AllowedHostsConfig{
+		Hosts:       []string{"api.example.com"},
+		ExemptPaths: []string{"/health"},
+	}
+
+	tests := []struct {
+		name string
+		host string
+		path string
+		want int
+	}{
+		{"allowed host", "api.example.com", "/v1/items", http.StatusOK},
+		{"allowed host with port", "API.example.com:443", "/v1/items", http.StatusOK},
+		{"allowed host with trailing dot", "api.example.com.", "/v1/items", http.StatusOK},
+		{"unknown host", "evil.example.com", "/v1/items", http.StatusMisdirectedRequest},
+		{"localhost not allowed", "localhost:8080", "/v1/items", http.StatusMisdirectedRequest},
+		{"health exempt", "10.0.0.5:8080", "/health", http.StatusOK},
+		{"health exempt with trailing slash", "10.0.0.5:8080", "/health/", http.StatusNotFound},
+		{"unknown host on unknown path", "evil.example.com", "/missing", http.StatusMisdirectedRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newAllowedHostsEcho(cfg)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAllowedHosts_Localhost(t *testing.T) {
+	e := newAllowedHostsEcho(AllowedHostsConfig{AllowLocalhost: true})
+
+	for _, host := range []string{"localhost:8080", "127.0.0.1:8080", "[::1]:8080"} {
+		t.Run(host, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+			req.Host = host
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// DefaultHSTSMaxAge is the Strict-Transport-Security max-age used when
+// HSTSConfig.MaxAge is zero.
+const DefaultHSTSMaxAge = 365 * 24 * time.Hour
+
+// HSTSConfig configures the HSTS middleware.
+type HSTSConfig struct {
+	// MaxAge is how long browsers remember to use HTTPS only.
+	// Defaults to DefaultHSTSMaxAge.
+	MaxAge time.Duration
+	// IncludeSubDomains extends the policy to all subdomains.
+	IncludeSubDomains bool
+	// RedirectHTTP answers plaintext requests with 308 Permanent Redirect to
+	// the https URL. Enable only in production.
+	RedirectHTTP bool
+	// RedirectHosts lists the hosts plaintext requests may be redirected to.
+	// Ports are ignored and matching is case-insensitive. Requests for other
+	// hosts are served as-is, so the client-supplied Host never reaches a
+	// Location header; no request is redirected when it is empty.
+	RedirectHosts []string
+}
+
+// HSTS returns Echo middleware that sets Strict-Transport-Security on HTTPS
+// responses. TLS is terminated at the proxy, so a request counts as HTTPS when
+// it arrived over TLS or X-Forwarded-Proto reports https. The header is never
+// sent over plaintext, where browsers ignore it and local development would
+// otherwise be affected.
+//
+// With RedirectHTTP, requests the proxy reports as http for one of
+// RedirectHosts are redirected to https. Requests without X-Forwarded-Proto
+// are served as-is so direct probes such as health checks keep working.
+//
+// Register with Echo#Pre so plaintext requests are redirected before routing.
+func HSTS(cfg HSTSConfig) echo.MiddlewareFunc {
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultHSTSMaxAge
+	}
+	value := "max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+	if cfg.IncludeSubDomains {
+		value += "; includeSubDomains"
+	}
+	redirectHosts := make(map[string]struct{}, len(cfg.RedirectHosts))
+	for _, h := range cfg.RedirectHosts {
+		if h = normalizeHost(h); h != "" {
+			redirectHosts[h] = struct{}{}
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			proto := forwardedProto(req)
+
+			if req.TLS != nil || proto == "https" {
+				c.Response().Header().Set("Strict-Transport-Security", value)
+				return next(c)
+			}
+
+			if cfg.RedirectHTTP && proto == "http" {
+				host := normalizeHost(req.Host)
+				if _, ok := redirectHosts[host]; ok {
+					if strings.Contains(host, ":") {
+						host = "[" + host + "]"
+					}
+					return c.Redirect(http.StatusPermanentRedirect, "https://"+host+req.URL.RequestURI())
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// forwardedProto returns the lowercased scheme reported by the nearest proxy,
+// the last X-Forwarded-Proto value, or "" when the header is absent. Earlier
+// values may have been supplied by the client.
+func forwardedProto(r *http.Request) string {
+	values := r.Header.Values("X-Forwarded-Proto")
+	if len(values) == 0 {
+		return ""
+	}
+	last := values[len(values)-1]
+	if i := strings.LastIndexByte(last, ','); i >= 0 {
+		last = last[i+1:]
+	}
+	return strings.ToLower(strings.TrimSpace(last))
+}
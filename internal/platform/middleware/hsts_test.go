@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newHSTSEcho(cfg HSTSConfig) *echo.Echo {
+	e := echo.New()
+	e.Pre(HSTS(cfg))
+	e.GET("/v1/items", func(c *echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+func TestHSTS_ForwardedHTTPS(t *testing.T) {
+	e := newHSTSEcho(HSTSConfig{IncludeSubDomains: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	want := "max-age=31536000; includeSubDomains"
+	if got := rec.Header().Get("Strict-Transport-Security"); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestHSTS_ForwardedHTTP(t *testing.T) {
+	e := newHSTSEcho(HSTSConfig{IncludeSubDomains: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	req.Header.Set("X-Forwarded-Proto", "http")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 without redirect, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Fatalf("expected no HSTS header over http, got %q", got)
+	}
+}
+
+func TestHSTS_DirectTLS(t *testing.T) {
+	e := newHSTSEcho(HSTSConfig{MaxAge: time.Hour})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "max-age=3600" {
+		t.Fatalf("expected max-age=3600, got %q", got)
+	}
+}
+
+func TestHSTS_RedirectHTTP(t *testing.T) {
+	e := newHSTSEcho(HSTSConfig{RedirectHTTP: true, RedirectHosts: []string{"API.example.com"}})
+
+	req := httptest.NewRequest(http.MethodGet, "http://api.example.com:80/v1/items?limit=5", nil)
+	req.Header.Set("X-Forwarded-Proto", "http")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected 308, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "https://api.example.com/v1/items?limit=5" {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+}
+
+func TestHSTS_RedirectSkipsUnknownProto(t *testing.T) {
+	e := newHSTSEcho(HSTSConfig{RedirectHTTP: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 without X-Forwarded-Proto, got %d", rec.Code)
+	}
+}
+
+func TestHSTS_RedirectRejectsUnknownHost(t *testing.T) {
+	e := newHSTSEcho(HSTSConfig{RedirectHTTP: true, RedirectHosts: []string{"api.example.com"}})
+
+	req := httptest.NewRequest(http.MethodGet, "http://evil.example/v1/items", nil)
+	req.Header.Set("X-Forwarded-Proto", "http")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Location") != "" {
+		t.Fatalf("expected no redirect for an unlisted host, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestForwardedProto_UsesNearestProxy(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"HTTPS"}, "https"},
+		{[]string{"https, http"}, "http"},
+		{[]string{"http", "https"}, "https"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, v := range tt.values {
+			req.Header.Add("X-Forwarded-Proto", v)
+		}
+		if got := forwardedProto(req); got != tt.want {
+			t.Fatalf("forwardedProto(%q) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v5"
+)
+
+// NoBodyConfig configures the NoBody middleware.
+type NoBodyConfig struct {
+	// Methods lists the methods that must not carry a body. Defaults to GET,
+	// HEAD, and DELETE.
+	Methods []string
+	// Lenient discards unexpected bodies instead of rejecting the request.
+	Lenient bool
+	// Skipper bypasses the check, e.g. for a route that reads a DELETE body.
+	Skipper func(c *echo.Context) bool
+}
+
+// NoBody returns Echo middleware that rejects requests carrying a body on
+// methods that do not expect one with 400 Bad Request. Such bodies are almost
+// always client bugs, and Echo's binder would otherwise let them override
+// query parameters. In lenient mode the body is discarded instead, so
+// handlers never see it. Apply it per route group.
+func NoBody(cfg NoBodyConfig) echo.MiddlewareFunc {
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodGet, http.MethodHead, http.MethodDelete}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			if !slices.Contains(cfg.Methods, req.Method) || !hasBody(req) {
+				return next(c)
+			}
+			if !cfg.Lenient {
+				return echo.NewHTTPError(http.StatusBadRequest, "request body not allowed for "+req.Method)
+			}
+
+			_ = req.Body.Close()
+			req.Body = http.NoBody
+			req.ContentLength = 0
+			req.Header.Del(echo.HeaderContentLength)
+			req.Header.Del(echo.HeaderContentType)
+			return next(c)
+		}
+	}
+}
+
+// hasBody reports whether r declares a body, by length or chunked encoding.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && (r.ContentLength > 0 || len(r.TransferEncoding) > 0)
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newNoBodyEcho(cfg NoBodyConfig) *echo.Echo {
+	e := echo.New()
+	g := e.Group("/v1", NoBody(cfg))
+	handler := func(c *echo.Context) error {
+		var input struct {
+			Category string `query:"category" json:"category"`
+		}
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, input.Category)
+	}
+	g.GET("/items", handler)
+	g.DELETE("/items", handler)
+	g.POST("/items", handler)
+	e.GET("/items", handler)
+	return e
+}
+
+func TestNoBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		lenient  bool
+		method   string
+		target   string
+		withBody bool
+		want     int
+		wantOut  string
+	}{
+		{"strict GET with body", false, http.MethodGet, "/v1/items?category=tools", true, http.StatusBadRequest, ""},
+		{"strict DELETE with body", false, http.MethodDelete, "/v1/items", true, http.StatusBadRequest, ""},
+		{"strict GET without body", false, http.MethodGet, "/v1/items?category=tools", false, http.StatusOK, "tools"},
+		{"strict POST with body", false, http.MethodPost, "/v1/items", true, http.StatusOK, "x"},
+		{"lenient GET ignores body", true, http.MethodGet, "/v1/items?category=tools", true, http.StatusOK, "tools"},
+		{"group without middleware", false, http.MethodGet, "/items?category=tools", true, http.StatusOK, "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newNoBodyEcho(NoBodyConfig{Lenient: tt.lenient})
+			var body string
+			if tt.withBody {
+				body = `{"category":"x"}`
+			}
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(body))
+			if tt.withBody {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d; body: %s", tt.want, rec.Code, rec.Body.String())
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != tt.wantOut {
+				t.Fatalf("expected category %q, got %q", tt.wantOut, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestNoBody_ChunkedBody(t *testing.T) {
+	e := newNoBodyEcho(NoBodyConfig{})
+	req := httptest.NewRequest(http.MethodGet, "/v1/items", strings.NewReader(`{}`))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
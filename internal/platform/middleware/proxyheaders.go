@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/labstack/echo/v5"
+)
+
+// proxyHeaders are request headers that only a proxy in front of the server
+// may set. Clients connecting directly could otherwise spoof their address,
+// scheme, host, or request ID.
+var proxyHeaders = []string{
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Real-IP",
+	HeaderXRequestID,
+}
+
+// ProxyHeaders returns Echo middleware that removes forwarding headers
+// (Forwarded, X-Forwarded-*, X-Real-IP, X-Request-ID, and
+// cfg.RequestIDHeaders) from requests whose immediate peer is not in
+// cfg.TrustedProxies, so HSTS detection, request ID reuse, and Location URLs
+// rely only on headers set by a trusted proxy.
+//
+// Register with Echo#Pre ahead of HSTS so the headers are removed before any
+// other middleware reads them.
+func ProxyHeaders(cfg ClientIPConfig) echo.MiddlewareFunc {
+	headers := append(slices.Clone(proxyHeaders), cfg.RequestIDHeaders...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if peer, ok := parseHop(req.RemoteAddr); !ok || !cfg.trusts(peer) {
+				for _, name := range headers {
+					req.Header.Del(name)
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newProxyHeadersEcho(cfg ClientIPConfig) *echo.Echo {
+	e := echo.New()
+	e.IPExtractor = ClientIPExtractor(cfg)
+	e.Pre(ProxyHeaders(cfg), HSTS(HSTSConfig{}))
+	e.Use(RequestID())
+	e.GET("/v1/items", func(c *echo.Context) error {
+		return c.String(http.StatusOK, c.RealIP())
+	})
+	return e
+}
+
+func TestProxyHeaders(t *testing.T) {
+	cfg := ClientIPConfig{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		trusted    bool
+	}{
+		{"trusted peer", "10.0.0.5:4711", true},
+		{"untrusted peer", "203.0.113.7:4711", false},
+		{"unparseable peer", "pipe", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newProxyHeadersEcho(cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/items", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "198.51.100.9")
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set(HeaderXRequestID, "client-supplied-id")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", rec.Code)
+			}
+			hsts := rec.Header().Get("Strict-Transport-Security") != ""
+			if hsts != tt.trusted {
+				t.Fatalf("expected HSTS set=%v, got %v", tt.trusted, hsts)
+			}
+			reused := rec.Header().Get(HeaderXRequestID) == "client-supplied-id"
+			if reused != tt.trusted {
+				t.Fatalf("expected request ID reused=%v, got %v", tt.trusted, reused)
+			}
+			if tt.trusted && rec.Body.String() != "198.51.100.9" {
+				t.Fatalf("expected forwarded client IP, got %q", rec.Body.String())
+			}
+			if !tt.trusted && rec.Body.String() == "198.51.100.9" {
+				t.Fatal("expected forwarded client IP to be ignored")
+			}
+		})
+	}
+}
+
+func TestProxyHeaders_RequestIDHeaders(t *testing.T) {
+	cfg := ClientIPConfig{
+		TrustedProxies:   []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		RequestIDHeaders: []string{"X-Correlation-ID"},
+	}
+	for remoteAddr, trusted := range map[string]bool{"10.0.0.5:4711": true, "203.0.113.7:4711": false} {
+		e := echo.New()
+		e.Pre(ProxyHeaders(cfg))
+		e.Use(RequestIDWithConfig(RequestIDConfig{Headers: cfg.RequestIDHeaders}))
+		e.GET("/", func(c *echo.Context) error { return c.NoContent(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Correlation-ID", "gateway-id")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if reused := rec.Header().Get(HeaderXRequestID) == "gateway-id"; reused != trusted {
+			t.Fatalf("%s: expected correlation ID reused=%v, got %v", remoteAddr, trusted, reused)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"container/list"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// Rate limit defaults applied when RateLimitConfig fields are zero.
+const (
+	DefaultRateLimit        = 10
+	DefaultBurst            = 20
+	DefaultRateLimitClients = 10000
+)
+
+// HeaderRateLimitReset is the number of seconds until the client may send
+// another request.
+const HeaderRateLimitReset = "RateLimit-Reset"
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	// Rate is the number of requests per second a client IP may sustain.
+	// Defaults to DefaultRateLimit.
+	Rate float64
+	// Burst is the number of requests a client IP may send at once.
+	// Defaults to DefaultBurst.
+	Burst int
+	// MaxClients bounds the client IPs tracked at once; the least recently
+	// seen client is forgotten to admit a new one. Defaults to
+	// DefaultRateLimitClients.
+	MaxClients int
+	// Skipper bypasses the limit, e.g. for health checks.
+	Skipper func(c *echo.Context) bool
+}
+
+// tokenBucket holds up to burst tokens and refills at rate tokens per second.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the last update.
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	}
+	b.last = now
+}
+
+// take consumes a token and reports whether one was available.
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) bool {
+	b.refill(now, rate, burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// clientBucket is the bucket of one client IP in the LRU list.
+type clientBucket struct {
+	ip     string
+	bucket tokenBucket
+}
+
+// nextToken returns the instant the bucket next holds a whole token. It is
+// now when a token is already available.
+func (b *tokenBucket) nextToken(now time.Time, rate float64, burst int) time.Time {
+	b.refill(now, rate, burst)
+	if b.tokens >= 1 {
+		return now
+	}
+	return now.Add(time.Duration((1 - b.tokens) / rate * float64(time.Second)))
+}
+
+// RateLimit returns Echo middleware that limits each client IP with a token
+// bucket and rejects requests without a token with 429 Too Many Requests.
+// Rejections carry Retry-After and RateLimit-Reset with the seconds until the
+// client's bucket refills a token, rounded up, so clients retry when the
+// request can succeed. Clients are keyed by Context#RealIP, and at most
+// MaxClients buckets are kept, evicting the least recently seen client.
+func RateLimit(cfg RateLimitConfig) echo.MiddlewareFunc {
+	if cfg.Rate <= 0 {
+		cfg.Rate = DefaultRateLimit
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = DefaultBurst
+	}
+	if cfg.MaxClients <= 0 {
+		cfg.MaxClients = DefaultRateLimitClients
+	}
+
+	var (
+		mu      sync.Mutex
+		buckets = make(map[string]*list.Element)
+		lru     = list.New()
+	)
+	// allow takes a token for ip, or returns the delay until one is available.
+	allow := func(ip string, now time.Time) (bool, time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		el, ok := buckets[ip]
+		if ok {
+			lru.MoveToFront(el)
+		} else {
+			if lru.Len() >= cfg.MaxClients {
+				oldest := lru.Back()
+				lru.Remove(oldest)
+				delete(buckets, oldest.Value.(*clientBucket).ip)
+			}
+			el = lru.PushFront(&clientBucket{ip: ip, bucket: tokenBucket{tokens: float64(cfg.Burst), last: now}})
+			buckets[ip] = el
+		}
+		b := &el.Value.(*clientBucket).bucket
+		if b.take(now, cfg.Rate, cfg.Burst) {
+			return true, 0
+		}
+		return false, b.nextToken(now, cfg.Rate, cfg.Burst).Sub(now)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			ok, wait := allow(c.RealIP(), time.Now())
+			if !ok {
+				seconds := strconv.Itoa(retrySeconds(wait))
+				h := c.Response().Header()
+				h.Set("Retry-After", seconds)
+				h.Set(HeaderRateLimitReset, seconds)
+				return echo.NewHTTPError(http.StatusTooManyRequests, "rate limit exceeded")
+			}
+			return next(c)
+		}
+	}
+}
+
+// retrySeconds rounds wait up to whole seconds, at least 1, as Retry-After
+// cannot express fractions.
+func retrySeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
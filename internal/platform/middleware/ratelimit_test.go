@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestTokenBucket_NextToken(t *testing.T) {
+	const rate, burst = 0.5, 2
+	t0 := time.Unix(1_700_000_000, 0)
+	b := &tokenBucket{tokens: burst, last: t0}
+
+	for i := range burst {
+		if !b.take(t0, rate, burst) {
+			t.Fatalf("expected token %d of the burst", i+1)
+		}
+	}
+	if b.take(t0, rate, burst) {
+		t.Fatal("expected the depleted bucket to reject")
+	}
+	if got, want := b.nextToken(t0, rate, burst), t0.Add(2*time.Second); !got.Equal(want) {
+		t.Fatalf("expected the next token at %v, got %v", want, got)
+	}
+
+	later := t0.Add(1500 * time.Millisecond)
+	if got, want := b.nextToken(later, rate, burst), t0.Add(2*time.Second); !got.Equal(want) {
+		t.Fatalf("expected the refill instant to stay at %v, got %v", want, got)
+	}
+	if !b.take(t0.Add(2*time.Second), rate, burst) {
+		t.Fatal("expected a token once the refill instant has passed")
+	}
+}
+
+func TestRateLimit_RetryAfterMatchesRefill(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+		want int
+	}{
+		{"one token every four seconds", 0.25, 4},
+		{"sub-second refill rounds up", 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.IPExtractor = ClientIPExtractor(ClientIPConfig{})
+			e.Use(RateLimit(RateLimitConfig{Rate: tt.rate, Burst: 1}))
+			e.GET("/items", func(c *echo.Context) error {
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			if code := serveFrom(e, "198.51.100.7", "/items"); code != http.StatusNoContent {
+				t.Fatalf("expected the first request to pass, got %d", code)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			req.RemoteAddr = "127.0.0.1:8080"
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("expected 429 for the depleted bucket, got %d", rec.Code)
+			}
+			want := strconv.Itoa(tt.want)
+			if got := rec.Header().Get("Retry-After"); got != want {
+				t.Fatalf("expected Retry-After %s, got %q", want, got)
+			}
+			if got := rec.Header().Get(HeaderRateLimitReset); got != want {
+				t.Fatalf("expected RateLimit-Reset %s, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestRateLimit_PerClient(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = ClientIPExtractor(ClientIPConfig{})
+	e.Use(RateLimit(RateLimitConfig{Rate: 0.1, Burst: 1, Skipper: func(c *echo.Context) bool {
+		return c.Request().URL.Path == "/health"
+	}}))
+	ok := func(c *echo.Context) error { return c.NoContent(http.StatusNoContent) }
+	e.GET("/items", ok)
+	e.GET("/health", ok)
+
+	if code := serveFrom(e, "198.51.100.1", "/items"); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	if code := serveFrom(e, "198.51.100.1", "/items"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for the second request, got %d", code)
+	}
+	if code := serveFrom(e, "198.51.100.2", "/items"); code != http.StatusNoContent {
+		t.Fatalf("expected another client to have its own bucket, got %d", code)
+	}
+	if code := serveFrom(e, "198.51.100.1", "/health"); code != http.StatusNoContent {
+		t.Fatalf("expected skipped paths to bypass the limit, got %d", code)
+	}
+}
+
+func TestRateLimit_EvictsLeastRecentClient(t *testing.T) {
+	e := echo.New()
+	e.IPExtractor = ClientIPExtractor(ClientIPConfig{})
+	e.Use(RateLimit(RateLimitConfig{Rate: 0.1, Burst: 1, MaxClients: 2}))
+	e.GET("/items", func(c *echo.Context) error { return c.NoContent(http.StatusNoContent) })
+
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2"} {
+		if code := serveFrom(e, ip, "/items"); code != http.StatusNoContent {
+			t.Fatalf("expected 204 for %s, got %d", ip, code)
+		}
+	}
+	if code := serveFrom(e, "198.51.100.1", "/items"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for a tracked client, got %d", code)
+	}
+	if code := serveFrom(e, "198.51.100.3", "/items"); code != http.StatusNoContent {
+		t.Fatalf("expected 204 for a new client, got %d", code)
+	}
+	if code := serveFrom(e, "198.51.100.2", "/items"); code != http.StatusNoContent {
+		t.Fatalf("expected the least recent client to be forgotten, got %d", code)
+	}
+	if code := serveFrom(e, "198.51.100.3", "/items"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for a client still tracked, got %d", code)
+	}
+}
+
+func TestRetrySeconds(t *testing.T) {
+	for wait, want := range map[time.Duration]int{
+		0:                       1,
+		300 * time.Millisecond:  1,
+		time.Second:             1,
+		1001 * time.Millisecond: 2,
+	} {
+		if got := retrySeconds(wait); got != want {
+			t.Fatalf("retrySeconds(%v): expected %d, got %d", wait, want, got)
+		}
+	}
+}
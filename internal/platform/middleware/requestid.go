@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
 )
 
 const (
@@ -29,20 +34,98 @@ func isValidRequestID(id string) bool {
 	return true
 }
 
+// Request ID formats accepted by ParseRequestIDFormat.
+const (
+	RequestIDFormatUUIDv4 = "uuidv4"
+	RequestIDFormatUUIDv7 = "uuidv7"
+)
+
+// RequestIDGenerator returns a new request ID. Generated IDs must satisfy the
+// same rules as inbound ones: at most 128 printable ASCII characters.
+type RequestIDGenerator func() string
+
+// UUIDv4 generates random UUIDv4 request IDs.
+func UUIDv4() string {
+	return uuid.NewString()
+}
+
+// UUIDv7 generates time-ordered UUIDv7 request IDs, so IDs generated by one
+// process sort by creation time in logs. It falls back to UUIDv4 if the
+// random source fails.
+func UUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// ParseRequestIDFormat returns the generator for format, one of
+// RequestIDFormatUUIDv4 and RequestIDFormatUUIDv7.
+func ParseRequestIDFormat(format string) (RequestIDGenerator, error) {
+	switch format {
+	case RequestIDFormatUUIDv4:
+		return UUIDv4, nil
+	case RequestIDFormatUUIDv7:
+		return UUIDv7, nil
+	default:
+		return nil, fmt.Errorf("must be one of %s, %s, got %q", RequestIDFormatUUIDv4, RequestIDFormatUUIDv7, format)
+	}
+}
+
+// RequestIDConfig configures RequestIDWithConfig.
+type RequestIDConfig struct {
+	// Generator creates IDs for requests without a valid inbound ID.
+	// Defaults to UUIDv4.
+	Generator RequestIDGenerator
+	// Headers lists the request headers checked, in order, for an inbound ID
+	// to reuse; the first holding a valid ID wins. Defaults to X-Request-ID.
+	Headers []string
+	// EchoSource also returns a reused ID under the header it was read from
+	// when that is not X-Request-ID.
+	EchoSource bool
+}
+
 // RequestID returns Echo middleware that injects a UUIDv4 request identifier.
 // If the incoming request provides a valid X-Request-ID header, that value is reused.
 // Invalid request IDs (too long, empty, or containing non-printable characters)
 // are rejected and a new UUID is generated instead.
 func RequestID() echo.MiddlewareFunc {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig returns RequestID middleware that reuses the first valid
+// ID among cfg.Headers and generates one with cfg.Generator otherwise. The ID
+// is always returned as X-Request-ID.
+func RequestIDWithConfig(cfg RequestIDConfig) echo.MiddlewareFunc {
+	if cfg.Generator == nil {
+		cfg.Generator = UUIDv4
+	}
+	headers := []string{HeaderXRequestID}
+	if len(cfg.Headers) > 0 {
+		headers = make([]string, len(cfg.Headers))
+		for i, h := range cfg.Headers {
+			headers[i] = http.CanonicalHeaderKey(h)
+		}
+	}
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
-			reqID := c.Request().Header.Get(HeaderXRequestID)
-			if !isValidRequestID(reqID) {
-				reqID = uuid.NewString()
+			var reqID, source string
+			for _, h := range headers {
+				if v := c.Request().Header.Get(h); isValidRequestID(v) {
+					reqID, source = v, h
+					break
+				}
+			}
+			if reqID == "" {
+				reqID = cfg.Generator()
 			}
 
-			c.Set("request_id", reqID)
+			ctxkeys.SetRequestID(c, reqID)
 			c.Response().Header().Set(HeaderXRequestID, reqID)
+			if cfg.EchoSource && source != "" && source != HeaderXRequestID {
+				c.Response().Header().Set(source, reqID)
+			}
 
 			return next(c)
 		}
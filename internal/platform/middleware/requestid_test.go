@@ -3,10 +3,15 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
 )
 
 func TestRequestID_GeneratesUUID(t *testing.T) {
@@ -110,7 +115,7 @@ func TestRequestID_SetsInContext(t *testing.T) {
 	e.Use(RequestID())
 	var ctxID string
 	e.GET("/test", func(c *echo.Context) error {
-		ctxID, _ = c.Get("request_id").(string)
+		ctxID = ctxkeys.RequestID(c.Request().Context())
 		return c.JSON(http.StatusOK, nil)
 	})
 
@@ -152,3 +157,180 @@ func TestIsValidRequestID(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestIDWithConfig_UUIDv7(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestIDWithConfig(RequestIDConfig{Generator: UUIDv7}))
+	e.GET("/test", func(c *echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	ids := make([]string, 100)
+	for i := range ids {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+		ids[i] = rec.Header().Get(HeaderXRequestID)
+	}
+
+	id, err := uuid.Parse(ids[0])
+	if err != nil || id.Version() != 7 {
+		t.Fatalf("expected a UUIDv7, got %q (%v)", ids[0], err)
+	}
+	if !slices.IsSorted(ids) {
+		t.Fatalf("expected UUIDv7 request IDs to sort by creation time, got %v", ids)
+	}
+	if len(slices.Compact(slices.Clone(ids))) != len(ids) {
+		t.Fatal("expected unique request IDs")
+	}
+}
+
+func TestRequestIDWithConfig_PreservesValid(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestIDWithConfig(RequestIDConfig{Generator: func() string {
+		t.Fatal("expected no ID to be generated")
+		return ""
+	}}))
+	e.GET("/test", func(c *echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(HeaderXRequestID, "upstream-trace-42")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(HeaderXRequestID); got != "upstream-trace-42" {
+		t.Fatalf("expected inbound ID to be preserved, got %q", got)
+	}
+}
+
+func TestRequestIDWithConfig_HeaderFallbacks(t *testing.T) {
+	cfg := RequestIDConfig{Headers: []string{HeaderXRequestID, "x-correlation-id", "X-Trace-Id"}}
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"correlation ID only", map[string]string{"X-Correlation-ID": "gateway-7"}, "gateway-7"},
+		{"first listed wins", map[string]string{"X-Trace-Id": "trace-1", "X-Correlation-ID": "gateway-7"}, "gateway-7"},
+		{"invalid value skipped", map[string]string{HeaderXRequestID: "bad\nid", "X-Trace-Id": "trace-1"}, "trace-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(RequestIDWithConfig(cfg))
+			e.GET("/test", func(c *echo.Context) error {
+				return c.String(http.StatusOK, ctxkeys.RequestID(c.Request().Context()))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(HeaderXRequestID); got != tt.want {
+				t.Fatalf("expected X-Request-ID %q, got %q", tt.want, got)
+			}
+			if rec.Body.String() != tt.want {
+				t.Fatalf("expected context request ID %q, got %q", tt.want, rec.Body.String())
+			}
+			if rec.Header().Get("X-Correlation-ID") != "" {
+				t.Fatal("expected the source header not to be echoed by default")
+			}
+		})
+	}
+}
+
+func TestRequestIDWithConfig_EchoSource(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestIDWithConfig(RequestIDConfig{Headers: []string{"X-Correlation-ID"}, EchoSource: true}))
+	e.GET("/test", func(c *echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("X-Correlation-ID", "gateway-7")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Header().Get(HeaderXRequestID) != "gateway-7" || rec.Header().Get("X-Correlation-ID") != "gateway-7" {
+		t.Fatalf("expected the ID under both headers, got %v", rec.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(HeaderXRequestID, "ignored")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Header().Get(HeaderXRequestID) == "ignored" {
+		t.Fatal("expected X-Request-ID to be ignored when not listed")
+	}
+	if rec.Header().Get("X-Correlation-ID") != "" {
+		t.Fatal("expected no source header for a generated ID")
+	}
+}
+
+func TestParseRequestIDFormat(t *testing.T) {
+	for format, version := range map[string]uuid.Version{RequestIDFormatUUIDv4: 4, RequestIDFormatUUIDv7: 7} {
+		gen, err := ParseRequestIDFormat(format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if id := uuid.MustParse(gen()); id.Version() != version {
+			t.Fatalf("%s: expected version %d, got %d", format, version, id.Version())
+		}
+	}
+	if _, err := ParseRequestIDFormat("ulid"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestRequestID_SharedWithRequestLogger(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestID(), applog.RequestLogger())
+	var traceID *string
+	var stored any
+	e.GET("/test", func(c *echo.Context) error {
+		traceID = applog.TraceIDFromContext(c.Request().Context())
+		stored = c.Get("request_id")
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(HeaderXRequestID, "shared-id")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if traceID == nil || *traceID != "shared-id" {
+		t.Fatalf("expected the logger to see request ID shared-id, got %v", traceID)
+	}
+	if stored != nil {
+		t.Fatalf("expected no string-keyed request_id on the Echo context, got %v", stored)
+	}
+}
+
+func TestRequestID_StringKeyDoesNotOverride(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestID())
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			c.Set("request_id", "spoofed")
+			return next(c)
+		}
+	})
+	e.Use(applog.RequestLogger())
+	var traceID *string
+	e.GET("/test", func(c *echo.Context) error {
+		traceID = applog.TraceIDFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(HeaderXRequestID, "real-id")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if traceID == nil || *traceID != "real-id" {
+		t.Fatalf("expected request ID real-id, got %v", traceID)
+	}
+}
@@ -6,10 +6,26 @@ import (
 	"github.com/labstack/echo/v5"
 )
 
+// SecurityConfig configures the SecurityWithConfig middleware.
+type SecurityConfig struct {
+	// SkipPaths are path prefixes excluded from the security headers
+	// (e.g., "/v1/api-docs"). The Server header is still applied.
+	SkipPaths []string
+	// Server is sent as the Server header on every response, replacing any
+	// value set by handlers. Empty removes the header.
+	Server string
+}
+
 // Security returns Echo middleware that sets security headers on all responses.
 // Headers follow OWASP REST Security Cheat Sheet recommendations (2025).
 //
 // Paths in skipPaths are excluded from security headers (e.g., "/v1/api-docs").
+// It is SecurityWithConfig without a Server header.
+func Security(skipPaths ...string) echo.MiddlewareFunc {
+	return SecurityWithConfig(SecurityConfig{SkipPaths: skipPaths})
+}
+
+// SecurityWithConfig returns the Security middleware with the given config.
 //
 // Headers set:
 //   - Cache-Control: no-store
@@ -20,10 +36,29 @@ import (
 //   - Referrer-Policy: strict-origin-when-cross-origin
 //   - X-Content-Type-Options: nosniff
 //   - X-Frame-Options: DENY
-func Security(skipPaths ...string) echo.MiddlewareFunc {
+//
+// Server identity is never disclosed: the Server header is cfg.Server or
+// absent, and X-Powered-By is removed, on every response including skipped
+// paths. Both are applied just before the response is committed, so values
+// set by handlers are overridden.
+//
+// Strict-Transport-Security depends on the request scheme and is set by HSTS.
+func SecurityWithConfig(cfg SecurityConfig) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
-			for _, p := range skipPaths {
+			if resp, err := echo.UnwrapResponse(c.Response()); err == nil {
+				resp.Before(func() {
+					h := resp.Header()
+					h.Del("X-Powered-By")
+					if cfg.Server != "" {
+						h.Set("Server", cfg.Server)
+					} else {
+						h.Del("Server")
+					}
+				})
+			}
+
+			for _, p := range cfg.SkipPaths {
 				if strings.HasPrefix(c.Request().URL.Path, p) {
 					return next(c)
 				}
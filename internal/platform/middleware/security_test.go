@@ -79,3 +79,38 @@ func TestSecurity_NonSkipPath(t *testing.T) {
 		t.Fatalf("expected 'no-store' for non-skipped path, got %q", cc)
 	}
 }
+
+func TestSecurity_ServerIdentity(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		path   string
+	}{
+		{"configured", "api", "/test"},
+		{"configured on skipped path", "api", "/docs"},
+		{"removed", "", "/test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(SecurityWithConfig(SecurityConfig{SkipPaths: []string{"/docs"}, Server: tt.server}))
+			handler := func(c *echo.Context) error {
+				c.Response().Header().Set("Server", "Echo/5")
+				c.Response().Header().Set("X-Powered-By", "Go")
+				return c.NoContent(http.StatusNoContent)
+			}
+			e.GET("/test", handler)
+			e.GET("/docs", handler)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got := rec.Header().Get("Server"); got != tt.server {
+				t.Fatalf("expected Server %q, got %q", tt.server, got)
+			}
+			if _, ok := rec.Header()["X-Powered-By"]; ok {
+				t.Fatal("expected X-Powered-By to be absent")
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// HeaderRequestTimeout carries how many milliseconds the client is willing to
+// wait for a response.
+const HeaderRequestTimeout = "X-Request-Timeout"
+
+// DefaultRequestTimeout is the request deadline used when
+// RequestTimeoutConfig.Default is unset. It stays below the server's 10s
+// write timeout so the 503 still reaches the client.
+const DefaultRequestTimeout = 8 * time.Second
+
+// RequestTimeoutConfig configures the RequestTimeout middleware.
+type RequestTimeoutConfig struct {
+	// Default is the deadline for requests without a valid
+	// X-Request-Timeout header. Defaults to DefaultRequestTimeout.
+	Default time.Duration
+	// Max caps the deadline a client may ask for. Defaults to Default, so
+	// clients can only shorten it.
+	Max time.Duration
+	// Skipper bypasses the deadline, e.g. for long-lived streams.
+	Skipper func(c *echo.Context) bool
+}
+
+// RequestTimeout returns Echo middleware that applies a deadline to the
+// request context, so downstream calls such as Firestore abort once the
+// client would have given up. The deadline comes from the X-Request-Timeout
+// header in milliseconds, clamped to Max; absent, non-numeric, or
+// non-positive values use Default.
+//
+// Like Echo's ContextTimeout, which only supports a fixed timeout, handlers
+// that fail after the deadline has passed are answered with 503 Service
+// Unavailable instead of their own error.
+func RequestTimeout(cfg RequestTimeoutConfig) echo.MiddlewareFunc {
+	if cfg.Default <= 0 {
+		cfg.Default = DefaultRequestTimeout
+	}
+	if cfg.Max <= 0 {
+		cfg.Max = cfg.Default
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(req, cfg))
+			defer cancel()
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "request timed out").Wrap(err)
+			}
+			return err
+		}
+	}
+}
+
+func requestTimeout(r *http.Request, cfg RequestTimeoutConfig) time.Duration {
+	ms, err := strconv.ParseInt(r.Header.Get(HeaderRequestTimeout), 10, 64)
+	if err != nil || ms <= 0 {
+		return cfg.Default
+	}
+	if ms > cfg.Max.Milliseconds() {
+		return cfg.Max
+	}
+	return time.Duration(ms) * time.Millisecond
+}
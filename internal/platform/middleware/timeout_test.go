@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestRequestTimeout_ClientTimeoutExpires(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestTimeout(RequestTimeoutConfig{Default: time.Minute}))
+	e.GET("/slow", func(c *echo.Context) error {
+		ctx := c.Request().Context()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return c.NoContent(http.StatusNoContent)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	req.Header.Set(HeaderRequestTimeout, "20")
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the client deadline to end the request early, took %v", elapsed)
+	}
+}
+
+func TestRequestTimeout_Deadline(t *testing.T) {
+	const (
+		defTimeout = 2 * time.Second
+		maxTimeout = 5 * time.Second
+	)
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"absent", "", defTimeout},
+		{"within range", "1500", 1500 * time.Millisecond},
+		{"clamped", "600000", maxTimeout},
+		{"non-numeric", "soon", defTimeout},
+		{"negative", "-5", defTimeout},
+		{"zero", "0", defTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remaining time.Duration
+			e := echo.New()
+			e.Use(RequestTimeout(RequestTimeoutConfig{Default: defTimeout, Max: maxTimeout}))
+			e.GET("/", func(c *echo.Context) error {
+				deadline, ok := c.Request().Context().Deadline()
+				if !ok {
+					t.Fatal("expected a context deadline")
+				}
+				remaining = time.Until(deadline)
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderRequestTimeout, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("expected 204, got %d", rec.Code)
+			}
+			if remaining > tt.want || remaining < tt.want-time.Second {
+				t.Fatalf("expected deadline about %v away, got %v", tt.want, remaining)
+			}
+		})
+	}
+}
+
+func TestRequestTimeout_HandlerErrorBeforeDeadline(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestTimeout(RequestTimeoutConfig{}))
+	e.GET("/", func(*echo.Context) error {
+		return echo.NewHTTPError(http.StatusConflict, "conflict")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected handler error to pass through, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// TrailingSlashMode selects how TrailingSlash canonicalizes request paths.
+type TrailingSlashMode int
+
+const (
+	// TrailingSlashStrip removes trailing slashes ("/v1/items/" -> "/v1/items").
+	TrailingSlashStrip TrailingSlashMode = iota
+	// TrailingSlashAppend adds a trailing slash ("/v1/items" -> "/v1/items/").
+	TrailingSlashAppend
+)
+
+// TrailingSlash returns Echo middleware that normalizes trailing slashes in the
+// request path. GET and HEAD requests are answered with 308 Permanent Redirect
+// to the canonical path so clients and caches learn the correct URL. Other
+// methods are rewritten in place because redirecting them would require the
+// client to resend the body. The query string and percent-encoding such as
+// %2F are preserved, and the root path is never modified.
+//
+// Must be registered with Echo#Pre so the rewritten path is used for routing.
+func TrailingSlash(mode TrailingSlashMode) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			// Compare in escaped form so an encoded slash such as "/items%2F"
+			// is not mistaken for a trailing slash.
+			escaped := req.URL.EscapedPath()
+			target := canonicalPath(escaped, mode)
+			if target == escaped {
+				return next(c)
+			}
+			path, err := url.PathUnescape(target)
+			if err != nil {
+				return next(c)
+			}
+
+			uri := target
+			if req.URL.RawQuery != "" {
+				uri += "?" + req.URL.RawQuery
+			}
+
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				return c.Redirect(http.StatusPermanentRedirect, uri)
+			}
+
+			req.URL.Path = path
+			if req.URL.RawPath != "" {
+				req.URL.RawPath = target
+			}
+			req.RequestURI = uri
+			return next(c)
+		}
+	}
+}
+
+// canonicalPath returns path with trailing slashes stripped or appended.
+// Leading slashes are collapsed so the result cannot be interpreted as a
+// protocol-relative URL ("//evil.example") when used in a Location header.
+func canonicalPath(path string, mode TrailingSlashMode) string {
+	if path == "" || path == "/" {
+		return path
+	}
+
+	p := "/" + strings.TrimLeft(path, `/\`)
+	switch mode {
+	case TrailingSlashAppend:
+		if !strings.HasSuffix(p, "/") {
+			p += "/"
+		}
+	default:
+		p = strings.TrimRight(p, "/")
+		if p == "" {
+			p = "/"
+		}
+	}
+	return p
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newTrailingSlashEcho(mode TrailingSlashMode, paths ...string) *echo.Echo {
+	e := echo.New()
+	e.Pre(TrailingSlash(mode))
+	for _, p := range paths {
+		e.GET(p, func(c *echo.Context) error {
+			return c.String(http.StatusOK, c.Request().URL.Path)
+		})
+		e.POST(p, func(c *echo.Context) error {
+			return c.String(http.StatusCreated, c.Request().URL.Path)
+		})
+	}
+	return e
+}
+
+func TestTrailingSlash_RedirectsGET(t *testing.T) {
+	e := newTrailingSlashEcho(TrailingSlashStrip, "/v1/items")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected 308, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/v1/items" {
+		t.Fatalf("expected Location /v1/items, got %q", loc)
+	}
+}
+
+func TestTrailingSlash_RedirectPreservesQuery(t *testing.T) {
+	e := newTrailingSlashEcho(TrailingSlashStrip, "/v1/items")
+
+	req := httptest.NewRequest(http.MethodHead, "/v1/items/?limit=5&category=tools", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected 308, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/v1/items?limit=5&category=tools" {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+}
+
+func TestTrailingSlash_RewritesPOST(t *testing.T) {
+	e := newTrailingSlashEcho(TrailingSlashStrip, "/v1/hello")
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/hello/", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 without redirect, got %d", rec.Code)
+	}
+	if rec.Header().Get("Location") != "" {
+		t.Fatal("expected no Location header for rewritten request")
+	}
+	if rec.Body.String() != "/v1/hello" {
+		t.Fatalf("expected handler to see /v1/hello, got %q", rec.Body.String())
+	}
+}
+
+func TestTrailingSlash_PreservesEncodedSlash(t *testing.T) {
+	e := echo.New()
+	e.Pre(TrailingSlash(TrailingSlashStrip))
+	e.POST("/*", func(c *echo.Context) error {
+		return c.String(http.StatusCreated, c.Request().URL.EscapedPath())
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/items/a%2Fb/", nil))
+	if loc := rec.Header().Get("Location"); loc != "/v1/items/a%2Fb" {
+		t.Fatalf("expected Location /v1/items/a%%2Fb, got %q", loc)
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/items/a%2Fb/", nil))
+	if rec.Body.String() != "/v1/items/a%2Fb" {
+		t.Fatalf("expected handler to see /v1/items/a%%2Fb, got %q", rec.Body.String())
+	}
+}
+
+func TestTrailingSlash_EncodedTrailingSlashNotRedirected(t *testing.T) {
+	e := echo.New()
+	e.Pre(TrailingSlash(TrailingSlashStrip))
+	e.GET("/*", func(c *echo.Context) error {
+		return c.String(http.StatusOK, c.Request().URL.EscapedPath())
+	})
+
+	for _, path := range []string{"/items%2F", "/a/%2F"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != path {
+			t.Fatalf("%s: expected 200 with the path unchanged, got %d %q", path, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a/%2F/", nil))
+	if loc := rec.Header().Get("Location"); rec.Code != http.StatusPermanentRedirect || loc != "/a/%2F" {
+		t.Fatalf("expected 308 to /a/%%2F, got %d %q", rec.Code, loc)
+	}
+}
+
+func TestTrailingSlash_CanonicalPathPassesThrough(t *testing.T) {
+	e := newTrailingSlashEcho(TrailingSlashStrip, "/v1/items", "/")
+
+	for _, path := range []string{"/v1/items", "/"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestTrailingSlash_AppendMode(t *testing.T) {
+	e := newTrailingSlashEcho(TrailingSlashAppend, "/v1/items/")
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items?limit=5", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected 308, got %d", rec.Code)
+	}
+	loc := rec.Header().Get("Location")
+	if loc != "/v1/items/?limit=5" {
+		t.Fatalf("unexpected Location %q", loc)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, loc, nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected redirect target to be served without looping, got %d", rec.Code)
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		mode TrailingSlashMode
+		want string
+	}{
+		{"strip single", "/v1/items/", TrailingSlashStrip, "/v1/items"},
+		{"strip multiple", "/v1/items///", TrailingSlashStrip, "/v1/items"},
+		{"strip canonical", "/v1/items", TrailingSlashStrip, "/v1/items"},
+		{"strip root", "/", TrailingSlashStrip, "/"},
+		{"strip only slashes", "///", TrailingSlashStrip, "/"},
+		{"append", "/v1/items", TrailingSlashAppend, "/v1/items/"},
+		{"append canonical", "/v1/items/", TrailingSlashAppend, "/v1/items/"},
+		{"append root", "/", TrailingSlashAppend, "/"},
+		{"protocol-relative collapsed", "//evil.example/", TrailingSlashStrip, "/evil.example"},
+		{"backslash collapsed", `/\evil.example/`, TrailingSlashStrip, "/evil.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canonicalPath(tt.path, tt.mode); got != tt.want {
+				t.Fatalf("canonicalPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+)
+
+// DefaultUniqueHeaders are the request headers UniqueHeaders checks when
+// UniqueHeadersConfig.Headers is empty. Go's server already rejects
+// conflicting Content-Length and Host headers; the rest would otherwise be
+// resolved silently by taking the first value.
+var DefaultUniqueHeaders = []string{
+	echo.HeaderAuthorization,
+	echo.HeaderContentType,
+	echo.HeaderContentLength,
+	echo.HeaderContentEncoding,
+}
+
+// UniqueHeadersConfig configures the UniqueHeaders middleware.
+type UniqueHeadersConfig struct {
+	// Headers lists the headers that must appear at most once. Defaults to
+	// DefaultUniqueHeaders.
+	Headers []string
+	// Skipper bypasses the check.
+	Skipper func(c *echo.Context) bool
+}
+
+// UniqueHeaders returns Echo middleware that rejects requests repeating a
+// header that must appear at most once with 400 Bad Request. Components that
+// read the first value and proxies that read the last would disagree on, for
+// example, which credentials or body format a request carries, so such
+// requests are refused instead of guessed at. The response names the header
+// but never echoes its values.
+func UniqueHeaders(cfg UniqueHeadersConfig) echo.MiddlewareFunc {
+	headers := cfg.Headers
+	if len(headers) == 0 {
+		headers = DefaultUniqueHeaders
+	}
+	keys := make([]string, len(headers))
+	for i, h := range headers {
+		keys[i] = http.CanonicalHeaderKey(h)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			header := c.Request().Header
+			for _, key := range keys {
+				if len(header[key]) > 1 {
+					return echo.NewHTTPError(http.StatusBadRequest, "duplicate "+key+" header")
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestUniqueHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     UniqueHeadersConfig
+		headers [][2]string
+		want    int
+	}{
+		{"single headers", UniqueHeadersConfig{}, [][2]string{
+			{"Authorization", "Bearer a"},
+			{"Content-Type", "application/json"},
+		}, http.StatusOK},
+		{"duplicate Authorization", UniqueHeadersConfig{}, [][2]string{
+			{"Authorization", "Bearer a"},
+			{"Authorization", "Bearer b"},
+		}, http.StatusBadRequest},
+		{"identical duplicate Authorization", UniqueHeadersConfig{}, [][2]string{
+			{"Authorization", "Bearer a"},
+			{"authorization", "Bearer a"},
+		}, http.StatusBadRequest},
+		{"duplicate Content-Type", UniqueHeadersConfig{}, [][2]string{
+			{"Content-Type", "application/json"},
+			{"Content-Type", "application/x-www-form-urlencoded"},
+		}, http.StatusBadRequest},
+		{"repeated list header", UniqueHeadersConfig{}, [][2]string{
+			{"Accept", "application/json"},
+			{"Accept", "application/cbor"},
+		}, http.StatusOK},
+		{"custom headers", UniqueHeadersConfig{Headers: []string{"idempotency-key"}}, [][2]string{
+			{"Idempotency-Key", "a"},
+			{"Idempotency-Key", "b"},
+		}, http.StatusBadRequest},
+		{"custom headers replace defaults", UniqueHeadersConfig{Headers: []string{"Idempotency-Key"}}, [][2]string{
+			{"Authorization", "Bearer a"},
+			{"Authorization", "Bearer b"},
+		}, http.StatusOK},
+		{"skipped", UniqueHeadersConfig{Skipper: func(*echo.Context) bool { return true }}, [][2]string{
+			{"Authorization", "Bearer a"},
+			{"Authorization", "Bearer b"},
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(UniqueHeaders(tt.cfg))
+			e.POST("/", func(c *echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+			for _, h := range tt.headers {
+				req.Header.Add(h[0], h[1])
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+			if tt.want == http.StatusBadRequest && strings.Contains(rec.Body.String(), "Bearer") {
+				t.Fatalf("expected header values to stay out of the response, got %s", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v5"
+)
+
+// ValidUTF8 returns Echo middleware that rejects requests with invalid UTF-8
+// in the path, query string, or a textual body (JSON, form, or text) with 400
+// Bad Request, so malformed strings never reach logs or Firestore.
+//
+// The body is checked as raw bytes before binding because encoding/json
+// silently replaces invalid sequences with U+FFFD. Register it after
+// BodyLimit, since the body is read into memory.
+func ValidUTF8() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			if !utf8.ValidString(req.URL.Path) || !validQuery(req.URL.RawQuery) {
+				return echo.NewHTTPError(http.StatusBadRequest, "request URL contains invalid UTF-8")
+			}
+			if req.Body == nil || !textualBody(req.Header.Get(echo.HeaderContentType)) {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			if !utf8.Valid(body) {
+				return echo.NewHTTPError(http.StatusBadRequest, "request body contains invalid UTF-8")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			return next(c)
+		}
+	}
+}
+
+// validQuery reports whether every decoded key and value in rawQuery is
+// valid UTF-8. Pairs with malformed escapes are left to the binder.
+func validQuery(rawQuery string) bool {
+	values, _ := url.ParseQuery(rawQuery)
+	for key, vals := range values {
+		if !utf8.ValidString(key) {
+			return false
+		}
+		for _, v := range vals {
+			if !utf8.ValidString(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// textualBody reports whether contentType is decoded as text by the binder.
+func textualBody(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == echo.MIMEApplicationJSON ||
+		strings.HasSuffix(mediaType, "+json") ||
+		mediaType == echo.MIMEApplicationForm ||
+		strings.HasPrefix(mediaType, "text/")
+}
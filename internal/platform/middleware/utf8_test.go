@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func newValidUTF8Echo() *echo.Echo {
+	e := echo.New()
+	e.Use(ValidUTF8())
+	e.POST("/profile", func(c *echo.Context) error {
+		var input struct {
+			Firstname string `json:"firstname"`
+		}
+		if err := c.Bind(&input); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, input.Firstname)
+	})
+	e.GET("/items", func(c *echo.Context) error {
+		return c.String(http.StatusOK, c.QueryParam("category"))
+	})
+	return e
+}
+
+func TestValidUTF8_Body(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"invalid sequence in firstname", "application/json", "{\"firstname\":\"Jo\xffh\"}", http.StatusBadRequest},
+		{"truncated multibyte rune", "text/plain", "\xe6\x97", http.StatusBadRequest},
+		{"invalid form value", "application/x-www-form-urlencoded", "firstname=Jo\xffh", http.StatusBadRequest},
+		{"valid multibyte name", "application/json", `{"firstname":"Zoë 日本"}`, http.StatusOK},
+		{"binary body is left to the binder", "application/octet-stream", "\xff\xfe", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newValidUTF8Echo()
+			req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d; body: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidUTF8_BodyReachesHandler(t *testing.T) {
+	e := newValidUTF8Echo()
+	req := httptest.NewRequest(http.MethodPost, "/profile", strings.NewReader(`{"firstname":"Zoë"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Body.String() != "Zoë" {
+		t.Fatalf("expected the handler to bind the checked body, got %q", rec.Body.String())
+	}
+}
+
+func TestValidUTF8_Query(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   int
+	}{
+		{"valid query", "/items?category=t%C3%B6%C3%B6ls", http.StatusOK},
+		{"invalid value", "/items?category=%FF", http.StatusBadRequest},
+		{"invalid key", "/items?%FE=tools", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newValidUTF8Echo()
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package middleware
 
-import "github.com/labstack/echo/v5"
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
 
 // Vary returns Echo middleware that adds Accept to the Vary header on all responses.
 // Per RFC 9110 Section 12.5.5, the Vary header lists request headers
@@ -14,3 +19,25 @@ func Vary() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// addVary adds the tokens of values to the Vary header of h, skipping tokens
+// already listed. Tokens compare case-insensitively.
+func addVary(h http.Header, values []string) {
+	existing := make(map[string]struct{})
+	for _, v := range h.Values("Vary") {
+		for part := range strings.SplitSeq(v, ",") {
+			existing[strings.ToLower(strings.TrimSpace(part))] = struct{}{}
+		}
+	}
+	for _, v := range values {
+		for part := range strings.SplitSeq(v, ",") {
+			part = strings.TrimSpace(part)
+			key := strings.ToLower(part)
+			if _, ok := existing[key]; ok || part == "" {
+				continue
+			}
+			h.Add("Vary", part)
+			existing[key] = struct{}{}
+		}
+	}
+}
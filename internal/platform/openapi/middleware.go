@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+// Config configures the validation middleware.
+type Config struct {
+	// Skipper skips validation for matching requests. Defaults to skipping
+	// the documentation routes under /api-docs.
+	Skipper func(c *echo.Context) bool
+}
+
+// Middleware returns Echo middleware that validates requests against spec.
+func Middleware(spec *Spec) echo.MiddlewareFunc {
+	return MiddlewareWithConfig(spec, Config{})
+}
+
+// MiddlewareWithConfig returns validation middleware with the given config.
+// Requests the spec does not describe pass through so routing can answer
+// them; violations return 400 for malformed JSON and 422 otherwise.
+func MiddlewareWithConfig(spec *Spec, cfg Config) echo.MiddlewareFunc {
+	skipper := cfg.Skipper
+	if skipper == nil {
+		skipper = func(c *echo.Context) bool {
+			return strings.HasPrefix(c.Request().URL.Path, "/api-docs")
+		}
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if skipper(c) {
+				return next(c)
+			}
+			if err := spec.ValidateRequest(c.Request()); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// ValidateRequest checks the query, header, path parameters, and JSON body of
+// r against the matching operation. The body is restored for later binding.
+// Query parameters the operation does not declare are rejected.
+func (s *Spec) ValidateRequest(r *http.Request) error {
+	op, pathParams := s.find(r.Method, r.URL.Path)
+	if op == nil {
+		return nil
+	}
+
+	var fields []validate.FieldError
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var raw string
+		var present bool
+		switch p.In {
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		case "header":
+			raw = r.Header.Get(p.Name)
+			present = raw != ""
+		case "path":
+			raw, present = pathParams[p.Name]
+		default:
+			continue
+		}
+		loc := p.In + "." + p.Name
+		if !present {
+			if p.Required {
+				addError(&fields, loc, "is required", nil)
+			}
+			continue
+		}
+		s.validateValue(p.Schema, s.coerce(p.Schema, raw), loc, &fields)
+	}
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		if !slices.ContainsFunc(op.Parameters, func(p parameter) bool { return p.In == "query" && p.Name == name }) {
+			addError(&fields, "query."+name, "is not a documented parameter", query.Get(name))
+		}
+	}
+
+	if op.RequestBody != nil {
+		if err := s.validateBody(r, op.RequestBody, &fields); err != nil {
+			return err
+		}
+	}
+
+	if len(fields) > 0 {
+		return &validate.ValidationError{Message: "request does not match the API specification", Fields: fields}
+	}
+	return nil
+}
+
+func (s *Spec) validateBody(r *http.Request, rb *requestBody, fields *[]validate.FieldError) error {
+	media, ok := rb.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		if rb.Required {
+			addError(fields, "body", "is required", nil)
+		}
+		return nil
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mt, _, err := mime.ParseMediaType(ct); err != nil || mt != "application/json" {
+			return nil
+		}
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if len(bytes.TrimSpace(data)) == 0 {
+		if rb.Required {
+			addError(fields, "body", "is required", nil)
+		}
+		return nil
+	}
+
+	var body any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return respond.Error400("invalid JSON body")
+	}
+	s.validateValue(media.Schema, body, "body", fields)
+	return nil
+}
+
+// coerce converts a raw parameter string to the JSON type its schema
+// declares, leaving it as a string when it does not parse.
+func (s *Spec) coerce(schema *Schema, raw string) any {
+	schema = s.resolve(schema)
+	if schema == nil || len(schema.Type) == 0 {
+		return raw
+	}
+	switch schema.Type[0] {
+	case "integer", "number":
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
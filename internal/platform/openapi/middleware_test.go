@@ -0,0 +1,273 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+const testSpec = `{
+  "openapi": "3.1.0",
+  "servers": [{"url": "http://localhost:8080/v1"}],
+  "paths": {
+    "/hello": {
+      "post": {
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/hello.CreateInput"}}}
+        }
+      }
+    },
+    "/items": {
+      "get": {
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}},
+          {"name": "category", "in": "query", "schema": {"type": "string", "enum": ["tools", "power"]}}
+        ]
+      }
+    },
+    "/items/{id}": {
+      "get": {
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string", "minLength": 5}},
+          {"name": "If-None-Match", "in": "header", "required": true, "schema": {"type": "string"}}
+        ]
+      }
+    },
+    "/batch": {
+      "post": {
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Batch"}}}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "hello.CreateInput": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {"name": {"type": "string", "minLength": 1, "maxLength": 5}}
+      },
+      "Batch": {
+        "type": "object",
+        "properties": {
+          "items": {"type": "array", "maxItems": 2, "items": {"$ref": "#/components/schemas/hello.CreateInput"}},
+          "note": {"type": ["string", "null"]}
+        }
+      }
+    }
+  }
+}`
+
+func newTestEcho(t *testing.T) *echo.Echo {
+	t.Helper()
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.Use(Middleware(spec))
+	ok := func(c *echo.Context) error {
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.String(http.StatusOK, string(body))
+	}
+	e.POST("/v1/hello", ok)
+	e.GET("/v1/items", ok)
+	e.GET("/v1/items/:id", ok)
+	e.POST("/v1/batch", ok)
+	e.GET("/v1/undocumented", ok)
+	e.GET("/api-docs/openapi.json", ok)
+	return e
+}
+
+func serve(e *echo.Echo, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func problemLocations(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+	var problem respond.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal problem: %v", err)
+	}
+	locations := make([]string, len(problem.Errors))
+	for i, e := range problem.Errors {
+		locations[i] = e.Location
+	}
+	return locations
+}
+
+func TestMiddleware_MissingRequiredField(t *testing.T) {
+	e := newTestEcho(t)
+
+	rec := serve(e, http.MethodPost, "/v1/hello", `{"nickname":"x"}`, nil)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", ct)
+	}
+	if got := problemLocations(t, rec); len(got) != 1 || got[0] != "body.name" {
+		t.Fatalf("expected body.name error, got %v", got)
+	}
+}
+
+func TestMiddleware_ValidBodyReachesHandler(t *testing.T) {
+	e := newTestEcho(t)
+
+	rec := serve(e, http.MethodPost, "/v1/hello", `{"name":"Ann"}`, nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec.Body.String() != `{"name":"Ann"}` {
+		t.Fatalf("expected body restored for handler, got %q", rec.Body.String())
+	}
+}
+
+func TestMiddleware_BodyErrors(t *testing.T) {
+	e := newTestEcho(t)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		status int
+		want   []string
+	}{
+		{"malformed json", "/v1/hello", `{"name":`, http.StatusBadRequest, nil},
+		{"missing required body", "/v1/hello", "", http.StatusUnprocessableEntity, []string{"body"}},
+		{"wrong type", "/v1/hello", `{"name":42}`, http.StatusUnprocessableEntity, []string{"body.name"}},
+		{"too long", "/v1/hello", `{"name":"abcdef"}`, http.StatusUnprocessableEntity, []string{"body.name"}},
+		{
+			"nested array items", "/v1/batch", `{"items":[{"name":"ok"},{}],"note":null}`,
+			http.StatusUnprocessableEntity, []string{"body.items[1].name"},
+		},
+		{
+			"too many items", "/v1/batch", `{"items":[{"name":"a"},{"name":"b"},{"name":"c"}]}`,
+			http.StatusUnprocessableEntity, []string{"body.items"},
+		},
+		{"optional body omitted", "/v1/batch", "", http.StatusOK, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(e, http.MethodPost, tt.target, tt.body, nil)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.want != nil {
+				got := problemLocations(t, rec)
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware_Parameters(t *testing.T) {
+	e := newTestEcho(t)
+
+	tests := []struct {
+		name    string
+		target  string
+		headers map[string]string
+		status  int
+		want    []string
+	}{
+		{"valid query", "/v1/items?limit=10&category=tools", nil, http.StatusOK, nil},
+		{"out of range", "/v1/items?limit=500", nil, http.StatusUnprocessableEntity, []string{"query.limit"}},
+		{"not an integer", "/v1/items?limit=ten", nil, http.StatusUnprocessableEntity, []string{"query.limit"}},
+		{"bad enum", "/v1/items?category=garden", nil, http.StatusUnprocessableEntity, []string{"query.category"}},
+		{"undocumented", "/v1/items?debug=1", nil, http.StatusUnprocessableEntity, []string{"query.debug"}},
+		{"path and header", "/v1/items/item-001", map[string]string{"If-None-Match": `"v1"`}, http.StatusOK, nil},
+		{
+			"short path and missing header", "/v1/items/abc", nil,
+			http.StatusUnprocessableEntity, []string{"path.id", "header.If-None-Match"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(e, http.MethodGet, tt.target, "", tt.headers)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.want != nil {
+				got := problemLocations(t, rec)
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware_SkipsUndescribedAndDocs(t *testing.T) {
+	e := newTestEcho(t)
+
+	for _, target := range []string{"/v1/undocumented?anything=1", "/api-docs/openapi.json?x=1"} {
+		if rec := serve(e, http.MethodGet, target, "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestValidateRequest_ReturnsValidationError(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("failed to parse spec: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/v1/items?limit=0", nil)
+
+	var ve *validate.ValidationError
+	if err := spec.ValidateRequest(req); !errors.As(err, &ve) {
+		t.Fatalf("expected *validate.ValidationError, got %v", err)
+	}
+	if ve.Fields[0].Value != "0" || ve.Fields[0].Message != "query.limit must be at least 1" {
+		t.Fatalf("unexpected field error %+v", ve.Fields[0])
+	}
+}
+
+func TestLoad_GeneratedSpec(t *testing.T) {
+	spec, err := Load("../../../api-docs/swagger.json")
+	if err != nil {
+		t.Fatalf("failed to load generated spec: %v", err)
+	}
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.Use(Middleware(spec))
+	e.POST("/v1/hello", func(c *echo.Context) error { return c.NoContent(http.StatusCreated) })
+
+	rec := serve(e, http.MethodPost, "/v1/hello", `{}`, nil)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if got := problemLocations(t, rec); len(got) != 1 || got[0] != "body.name" {
+		t.Fatalf("expected body.name error, got %v", got)
+	}
+}
@@ -0,0 +1,182 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+// Schema is the subset of JSON Schema keywords used by the generated spec.
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       schemaTypes        `json:"type"`
+	Properties map[string]*Schema `json:"properties"`
+	Required   []string           `json:"required"`
+	Items      *Schema            `json:"items"`
+	Enum       []any              `json:"enum"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	MinItems   *int               `json:"minItems"`
+	MaxItems   *int               `json:"maxItems"`
+	AllOf      []*Schema          `json:"allOf"`
+	AnyOf      []*Schema          `json:"anyOf"`
+	OneOf      []*Schema          `json:"oneOf"`
+}
+
+// schemaTypes holds the "type" keyword, which OpenAPI 3.1 allows to be a
+// single type name or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// validateValue appends a field error to fields for every constraint of
+// schema that value violates. Values are decoded JSON: nil, bool, float64,
+// string, []any, or map[string]any.
+func (s *Spec) validateValue(schema *Schema, value any, loc string, fields *[]validate.FieldError) {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	for _, sub := range schema.AllOf {
+		s.validateValue(sub, value, loc, fields)
+	}
+	if len(schema.AnyOf) > 0 && !s.matchesAny(schema.AnyOf, value) {
+		addError(fields, loc, "does not match any allowed schema", value)
+		return
+	}
+	if len(schema.OneOf) > 0 && !s.matchesAny(schema.OneOf, value) {
+		addError(fields, loc, "does not match any allowed schema", value)
+		return
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(typ string) bool { return hasType(value, typ) }) {
+		addError(fields, loc, "must be of type "+joinTypes(schema.Type), value)
+		return
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return e == value }) {
+		addError(fields, loc, fmt.Sprintf("must be one of: %v", schema.Enum), value)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			addError(fields, loc, "must be at least "+formatNumber(*schema.Minimum), value)
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			addError(fields, loc, "must be at most "+formatNumber(*schema.Maximum), value)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && n < *schema.MinLength {
+			addError(fields, loc, "must be at least "+strconv.Itoa(*schema.MinLength)+" characters", value)
+		}
+		if schema.MaxLength != nil && n > *schema.MaxLength {
+			addError(fields, loc, "must be at most "+strconv.Itoa(*schema.MaxLength)+" characters", value)
+		}
+	case []any:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			addError(fields, loc, "must have at least "+strconv.Itoa(*schema.MinItems)+" items", nil)
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			addError(fields, loc, "must have at most "+strconv.Itoa(*schema.MaxItems)+" items", nil)
+		}
+		if schema.Items != nil {
+			for i, item := range v {
+				s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", loc, i), fields)
+			}
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				addError(fields, loc+"."+name, "is required", nil)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+			if pv, ok := v[name]; ok {
+				s.validateValue(schema.Properties[name], pv, loc+"."+name, fields)
+			}
+		}
+	}
+}
+
+func (s *Spec) matchesAny(schemas []*Schema, value any) bool {
+	for _, sub := range schemas {
+		var errs []validate.FieldError
+		s.validateValue(sub, value, "", &errs)
+		if len(errs) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasType(value any, typ string) bool {
+	switch typ {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	default:
+		return true
+	}
+}
+
+func joinTypes(types schemaTypes) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("%v", []string(types))
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func addError(fields *[]validate.FieldError, loc, message string, value any) {
+	fe := validate.FieldError{Field: loc, Message: loc + " " + message}
+	switch v := value.(type) {
+	case nil, map[string]any, []any:
+	case string:
+		fe.Value = v
+	default:
+		fe.Value = fmt.Sprint(v)
+	}
+	*fields = append(*fields, fe)
+}
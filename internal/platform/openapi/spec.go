@@ -0,0 +1,148 @@
+// Package openapi validates requests against the generated OpenAPI 3.1 spec.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Spec is the subset of an OpenAPI document needed to validate requests.
+type Spec struct {
+	basePath string
+	routes   []route
+	schemas  map[string]*Schema
+}
+
+type route struct {
+	method   string
+	segments []string
+	op       *operation
+}
+
+type document struct {
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type operation struct {
+	Parameters  []parameter  `json:"parameters"`
+	RequestBody *requestBody `json:"requestBody"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool `json:"required"`
+	Content  map[string]struct {
+		Schema *Schema `json:"schema"`
+	} `json:"content"`
+}
+
+// httpMethods are the path item keys that describe operations.
+var httpMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Load reads and parses the OpenAPI document at path.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses an OpenAPI 3.x JSON document. Paths are matched below the
+// path of the first server URL (for example /v1).
+func Parse(data []byte) (*Spec, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi spec: %w", err)
+	}
+
+	s := &Spec{schemas: doc.Components.Schemas}
+	if len(doc.Servers) > 0 {
+		u, err := url.Parse(doc.Servers[0].URL)
+		if err != nil {
+			return nil, fmt.Errorf("parse openapi server url: %w", err)
+		}
+		s.basePath = strings.TrimSuffix(u.Path, "/")
+	}
+
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			if !httpMethods[method] {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("parse openapi operation %s %s: %w", method, path, err)
+			}
+			s.routes = append(s.routes, route{
+				method:   strings.ToUpper(method),
+				segments: strings.Split(strings.Trim(path, "/"), "/"),
+				op:       &op,
+			})
+		}
+	}
+	return s, nil
+}
+
+// find returns the operation for method and request path, along with the
+// values of templated path segments, or nil when the spec does not describe
+// the request.
+func (s *Spec) find(method, path string) (*operation, map[string]string) {
+	rest, ok := strings.CutPrefix(path, s.basePath)
+	if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+		return nil, nil
+	}
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+
+	for _, r := range s.routes {
+		if r.method != method || len(r.segments) != len(segments) {
+			continue
+		}
+		params := map[string]string{}
+		matched := true
+		for i, seg := range r.segments {
+			if name, ok := strings.CutPrefix(seg, "{"); ok && strings.HasSuffix(name, "}") {
+				params[strings.TrimSuffix(name, "}")] = segments[i]
+				continue
+			}
+			if seg != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.op, params
+		}
+	}
+	return nil, nil
+}
+
+// resolve follows $ref pointers into components/schemas.
+func (s *Spec) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		name, ok := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		if !ok {
+			return nil
+		}
+		schema = s.schemas[name]
+	}
+	return schema
+}
@@ -1,25 +1,80 @@
 package pagination
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 // ErrInvalidCursor indicates the cursor could not be decoded.
 var ErrInvalidCursor = errors.New("invalid cursor format")
 
+// cursorMACSize is the truncated HMAC-SHA256 length appended to signed cursors.
+const cursorMACSize = 16
+
+var signingKey atomic.Pointer[[]byte]
+
+// SetSigningKey enables HMAC signing of encoded cursors so clients cannot
+// forge positions or reset their depth. Once set, DecodeCursor rejects
+// unsigned or altered cursors. An empty key disables signing.
+func SetSigningKey(key []byte) {
+	if len(key) == 0 {
+		signingKey.Store(nil)
+		return
+	}
+	k := append([]byte(nil), key...)
+	signingKey.Store(&k)
+}
+
+// Direction is the order in which a cursor walks the dataset.
+type Direction int
+
+const (
+	// Forward pages from the first item toward the last.
+	Forward Direction = iota
+	// Backward pages from the last item toward the first, e.g. for
+	// latest-first feeds over data sorted oldest first.
+	Backward
+)
+
 // Cursor represents a pagination position.
 type Cursor struct {
-	Type  string // resource type identifier
-	Value string // last seen value (ID, timestamp, etc.)
+	Type      string    // resource type identifier
+	Value     string    // last seen value (ID, timestamp, etc.)
+	Depth     int       // zero-based index of the page the cursor points to
+	Direction Direction // iteration order the cursor continues in
 }
 
-// Encode returns a URL-safe opaque Base64 representation.
+// ScopedType returns cursorType qualified by the active list filters, so a
+// cursor issued for one filtered view fails the handler's type check when
+// replayed with different filters. Without filters it returns cursorType.
+func ScopedType(cursorType string, filters url.Values) string {
+	if len(filters) == 0 {
+		return cursorType
+	}
+	return cursorType + "?" + filters.Encode()
+}
+
+// Encode returns a URL-safe opaque Base64 representation, followed by a
+// signature when a signing key is configured.
 func (c Cursor) Encode() string {
-	return base64.RawURLEncoding.EncodeToString(
-		[]byte(c.Type + ":" + c.Value),
-	)
+	prefix := c.Type
+	if c.Direction == Backward {
+		prefix = "-" + prefix
+	}
+	if c.Depth > 0 {
+		prefix += "@" + strconv.Itoa(c.Depth)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(prefix + ":" + c.Value))
+	if key := signingKey.Load(); key != nil {
+		encoded += "." + base64.RawURLEncoding.EncodeToString(cursorMAC(*key, encoded))
+	}
+	return encoded
 }
 
 // DecodeCursor parses a URL-safe Base64 cursor string.
@@ -27,13 +82,43 @@ func DecodeCursor(s string) (Cursor, error) {
 	if s == "" {
 		return Cursor{}, nil
 	}
+	if key := signingKey.Load(); key != nil {
+		payload, sig, ok := strings.Cut(s, ".")
+		if !ok {
+			return Cursor{}, ErrInvalidCursor
+		}
+		mac, err := base64.RawURLEncoding.DecodeString(sig)
+		if err != nil || !hmac.Equal(mac, cursorMAC(*key, payload)) {
+			return Cursor{}, ErrInvalidCursor
+		}
+		s = payload
+	}
 	b, err := base64.RawURLEncoding.DecodeString(s)
 	if err != nil {
 		return Cursor{}, ErrInvalidCursor
 	}
-	parts := strings.SplitN(string(b), ":", 2)
-	if len(parts) != 2 {
+	prefix, value, ok := strings.Cut(string(b), ":")
+	if !ok {
 		return Cursor{}, ErrInvalidCursor
 	}
-	return Cursor{Type: parts[0], Value: parts[1]}, nil
+	var c Cursor
+	if rest, ok := strings.CutPrefix(prefix, "-"); ok {
+		c.Direction = Backward
+		prefix = rest
+	}
+	c.Type, c.Value = prefix, value
+	if typ, depth, ok := strings.Cut(prefix, "@"); ok {
+		d, err := strconv.Atoi(depth)
+		if err != nil || d < 0 {
+			return Cursor{}, ErrInvalidCursor
+		}
+		c.Type, c.Depth = typ, d
+	}
+	return c, nil
+}
+
+func cursorMAC(key []byte, payload string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(payload))
+	return m.Sum(nil)[:cursorMACSize]
 }
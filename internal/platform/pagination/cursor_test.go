@@ -1,7 +1,9 @@
 package pagination
 
 import (
+	"encoding/base64"
 	"errors"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -182,3 +184,109 @@ func TestDecodeCursor_PaddingVariations(t *testing.T) {
 		})
 	}
 }
+
+func TestCursor_Depth_Roundtrip(t *testing.T) {
+	original := Cursor{Type: "item", Value: "a:b", Depth: 7}
+	decoded, err := DecodeCursor(original.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != original {
+		t.Fatalf("expected %+v, got %+v", original, decoded)
+	}
+}
+
+func TestDecodeCursor_InvalidDepth(t *testing.T) {
+	for _, raw := range []string{"item@x:1", "item@-1:1"} {
+		encoded := base64.RawURLEncoding.EncodeToString([]byte(raw))
+		if _, err := DecodeCursor(encoded); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("expected ErrInvalidCursor for %q, got %v", raw, err)
+		}
+	}
+}
+
+func TestCursor_Signed_Roundtrip(t *testing.T) {
+	SetSigningKey([]byte("test-key"))
+	t.Cleanup(func() { SetSigningKey(nil) })
+
+	original := Cursor{Type: "item", Value: "42", Depth: 3}
+	encoded := original.Encode()
+	if !strings.Contains(encoded, ".") {
+		t.Fatalf("expected signed cursor, got %q", encoded)
+	}
+	decoded, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != original {
+		t.Fatalf("expected %+v, got %+v", original, decoded)
+	}
+}
+
+func TestDecodeCursor_SignedRejectsTampering(t *testing.T) {
+	SetSigningKey([]byte("test-key"))
+	t.Cleanup(func() { SetSigningKey(nil) })
+
+	signed := Cursor{Type: "item", Value: "42", Depth: 3}.Encode()
+	_, sig, _ := strings.Cut(signed, ".")
+	forged := Cursor{Type: "item", Value: "42"}
+
+	SetSigningKey(nil)
+	unsigned := forged.Encode()
+	SetSigningKey([]byte("test-key"))
+
+	tests := map[string]string{
+		"unsigned":      unsigned,
+		"swapped body":  unsigned + "." + sig,
+		"bad signature": strings.TrimSuffix(signed, sig) + "AAAA",
+	}
+	for name, encoded := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeCursor(encoded); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCursor_Direction_Roundtrip(t *testing.T) {
+	for _, original := range []Cursor{
+		{Type: "item", Value: "item-030", Direction: Backward},
+		{Type: "item", Value: "", Direction: Backward},
+		{Type: "item?category=tools", Value: "a:b", Depth: 3, Direction: Backward},
+	} {
+		decoded, err := DecodeCursor(original.Encode())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if decoded != original {
+			t.Fatalf("expected %+v, got %+v", original, decoded)
+		}
+	}
+
+	forward := Cursor{Type: "item", Value: "x"}
+	if forward.Encode() == (Cursor{Type: "item", Value: "x", Direction: Backward}).Encode() {
+		t.Fatal("expected direction to change the encoded cursor")
+	}
+}
+
+func TestScopedType(t *testing.T) {
+	if got := ScopedType("item", nil); got != "item" {
+		t.Fatalf("expected unscoped type, got %q", got)
+	}
+
+	filters := url.Values{"category": {"tools"}, "q": {"a:b@c"}}
+	scoped := ScopedType("item", filters)
+	if scoped == ScopedType("item", url.Values{"category": {"power"}}) {
+		t.Fatal("expected different filters to produce different types")
+	}
+
+	original := Cursor{Type: scoped, Value: "item-001", Depth: 2}
+	decoded, err := DecodeCursor(original.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != original {
+		t.Fatalf("expected %+v, got %+v", original, decoded)
+	}
+}
@@ -6,6 +6,23 @@ import (
 	"strings"
 )
 
+// Link relations for links beyond next and prev.
+const (
+	RelSelf        = "self"
+	RelDescribedBy = "describedby"
+)
+
+// Link is an RFC 8288 link, such as the self or describedby relation.
+type Link struct {
+	Target string
+	Rel    string
+}
+
+// String formats l as a Link header value.
+func (l Link) String() string {
+	return fmt.Sprintf("<%s>; rel=\"%s\"", l.Target, l.Rel)
+}
+
 // BuildLinkHeader constructs RFC 8288 Link header, preserving existing query params.
 func BuildLinkHeader(baseURL string, query url.Values, nextCursor, prevCursor string) string {
 	var links []string
@@ -117,3 +117,19 @@ func TestBuildLinkHeader_RelativePath(t *testing.T) {
 		t.Errorf("should handle relative path, got %q", link)
 	}
 }
+
+func TestResult_AddLinks(t *testing.T) {
+	r := Result[string]{LinkHeader: `</items?cursor=abc>; rel="next"`}
+	r.AddLinks(Link{Target: "/items?limit=10", Rel: RelSelf}, Link{Target: "/api-docs/openapi.json", Rel: RelDescribedBy})
+
+	want := `</items?cursor=abc>; rel="next", </items?limit=10>; rel="self", </api-docs/openapi.json>; rel="describedby"`
+	if r.LinkHeader != want {
+		t.Fatalf("expected %q, got %q", want, r.LinkHeader)
+	}
+
+	var empty Result[string]
+	empty.AddLinks(Link{Target: "/items", Rel: RelSelf})
+	if empty.LinkHeader != `</items>; rel="self"` {
+		t.Fatalf("expected only the self link, got %q", empty.LinkHeader)
+	}
+}
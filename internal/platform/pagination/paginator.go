@@ -1,24 +1,66 @@
 package pagination
 
 import (
+	"cmp"
 	"net/url"
+	"slices"
 	"strconv"
 )
 
 // Result holds the outcome of a pagination operation.
+//
+// Total is the exact item count when known. Results from PaginateFetched leave
+// it zero and report HasMore instead.
 type Result[T any] struct {
 	Items      []T
 	Total      int
+	HasMore    bool
 	LinkHeader string
 	NextCursor string
 	PrevCursor string
 }
 
+// AddLinks appends links, such as self and describedby, to r.LinkHeader.
+func (r *Result[T]) AddLinks(links ...Link) {
+	for _, l := range links {
+		if r.LinkHeader != "" {
+			r.LinkHeader += ", "
+		}
+		r.LinkHeader += l.String()
+	}
+}
+
+// SortByKey sorts items in place by compare, breaking ties by ID so that every
+// item has a unique, stable position. A nil compare orders by ID alone.
+//
+// Pagination cursors record the last item's ID, so pages are only consistent
+// across requests when the dataset is sorted this way.
+func SortByKey[T any](items []T, compare func(a, b T) int, getID func(T) string) {
+	slices.SortStableFunc(items, func(a, b T) int {
+		if compare != nil {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(getID(a), getID(b))
+	})
+}
+
 // Paginate applies cursor-based pagination to a slice of items.
 //
+// Items must be in a deterministic total order with unique IDs, for example
+// sorted with SortByKey. The cursor value is the ID of the last item on the
+// previous page, so the next page starts immediately after that item even
+// when neighbouring items share the same sort key.
+//
+// A Backward cursor walks items from the end, so pages list items in reverse
+// order and the next link moves toward the first item. Start a backward walk
+// by passing Cursor{Direction: Backward}; every cursor issued from it keeps
+// that direction.
+//
 // Parameters:
-//   - items: The full slice of items to paginate
-//   - cursor: The decoded cursor from the request
+//   - items: The full slice of items to paginate, in a stable order
+//   - cursor: The decoded cursor from the request; its Depth is advanced in the next cursor
 //   - limit: Maximum items per page
 //   - cursorType: Type identifier for cursor validation (e.g., "item", "user")
 //   - getID: Function to extract the ID from an item
@@ -35,7 +77,10 @@ func Paginate[T any](
 	baseURL string,
 	query url.Values,
 ) Result[T] {
-	total := len(items)
+	if cursor.Direction == Backward {
+		items = slices.Clone(items)
+		slices.Reverse(items)
+	}
 
 	startIdx := 0
 	if cursor.Value != "" {
@@ -46,23 +91,84 @@ func Paginate[T any](
 			}
 		}
 	}
+	return paginate(items, startIdx, cursor, limit, cursorType, getID, baseURL, query)
+}
+
+// PaginateByID is Paginate for items sorted by ID alone, as SortByKey orders
+// them with a nil compare. The cursor is treated as a position in that order
+// rather than a reference to an item: when the cursor's item has been removed
+// since the cursor was issued, the page starts at the first item ordered after
+// it instead of restarting from the beginning.
+func PaginateByID[T any](
+	items []T,
+	cursor Cursor,
+	limit int,
+	cursorType string,
+	getID func(T) string,
+	baseURL string,
+	query url.Values,
+) Result[T] {
+	cmpID := func(item T, id string) int { return cmp.Compare(getID(item), id) }
+	if cursor.Direction == Backward {
+		items = slices.Clone(items)
+		slices.Reverse(items)
+		cmpID = func(item T, id string) int { return cmp.Compare(id, getID(item)) }
+	}
+
+	startIdx := 0
+	if cursor.Value != "" {
+		i, found := slices.BinarySearchFunc(items, cursor.Value, cmpID)
+		if found {
+			i++
+		}
+		startIdx = i
+	}
+	return paginate(items, startIdx, cursor, limit, cursorType, getID, baseURL, query)
+}
+
+// paginate builds the page of items starting at startIdx, in walk order.
+func paginate[T any](
+	items []T,
+	startIdx int,
+	cursor Cursor,
+	limit int,
+	cursorType string,
+	getID func(T) string,
+	baseURL string,
+	query url.Values,
+) Result[T] {
+	total := len(items)
 
 	endIdx := min(startIdx+limit, total)
 
 	pageItems := items[startIdx:endIdx]
+	if pageItems == nil {
+		// Empty pages encode as [] rather than null.
+		pageItems = []T{}
+	}
 
 	var nextCursor, prevCursor string
 
 	if endIdx < total && len(pageItems) > 0 {
-		nextCursor = Cursor{Type: cursorType, Value: getID(pageItems[len(pageItems)-1])}.Encode()
+		nextCursor = Cursor{
+			Type:      cursorType,
+			Value:     getID(pageItems[len(pageItems)-1]),
+			Depth:     cursor.Depth + 1,
+			Direction: cursor.Direction,
+		}.Encode()
 	}
 
 	if startIdx > 0 {
 		if startIdx <= limit {
-			prevCursor = Cursor{Type: cursorType, Value: ""}.Encode()
+			prevCursor = Cursor{Type: cursorType, Direction: cursor.Direction}.Encode()
 		} else {
 			prevLastIdx := startIdx - 1
-			prevCursor = Cursor{Type: cursorType, Value: getID(items[prevLastIdx-limit])}.Encode()
+			prevCursor = Cursor{
+				Type:      cursorType,
+				Value:     getID(items[prevLastIdx-limit]),
+				Depth:     max(cursor.Depth-1, 0),
+				Direction: cursor.Direction,
+			}.Encode()
 		}
 	}
 
@@ -75,8 +181,55 @@ func Paginate[T any](
 	return Result[T]{
 		Items:      pageItems,
 		Total:      total,
+		HasMore:    endIdx < total,
 		LinkHeader: linkHeader,
 		NextCursor: nextCursor,
 		PrevCursor: prevCursor,
 	}
 }
+
+// PaginateFetched builds a page from items fetched after the cursor with a
+// limit+1 query, avoiding an exact count of the full dataset. The extra item
+// only signals that another page exists and is trimmed from the result.
+//
+// cursor is the decoded request cursor, whose Depth is advanced in the next
+// cursor. Without a full view of the data no prev cursor is produced; clients
+// page backward by replaying earlier cursors. The caller fetches in the
+// cursor's Direction; the next cursor keeps it.
+func PaginateFetched[T any](
+	fetched []T,
+	cursor Cursor,
+	limit int,
+	cursorType string,
+	getID func(T) string,
+	baseURL string,
+	query url.Values,
+) Result[T] {
+	hasMore := len(fetched) > limit
+	pageItems := fetched
+	if hasMore {
+		pageItems = fetched[:limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(pageItems) > 0 {
+		nextCursor = Cursor{
+			Type:      cursorType,
+			Value:     getID(pageItems[len(pageItems)-1]),
+			Depth:     cursor.Depth + 1,
+			Direction: cursor.Direction,
+		}.Encode()
+	}
+
+	q := cloneValues(query)
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+
+	return Result[T]{
+		Items:      pageItems,
+		HasMore:    hasMore,
+		LinkHeader: BuildLinkHeader(baseURL, q, nextCursor, ""),
+		NextCursor: nextCursor,
+	}
+}
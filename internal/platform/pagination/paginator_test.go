@@ -2,6 +2,8 @@ package pagination
 
 import (
 	"net/url"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -155,4 +157,250 @@ func TestPaginate_PrevCursorThirdPage(t *testing.T) {
 	if prev.Value != "c" {
 		t.Fatalf("expected prev cursor to point to %q, got %q", "c", prev.Value)
 	}
+	if c2.Depth != 2 || prev.Depth != 1 {
+		t.Fatalf("expected depths 2 and 1, got %d and %d", c2.Depth, prev.Depth)
+	}
+}
+
+func TestPaginate_HasMore(t *testing.T) {
+	items := makeItems(5)
+	first := Paginate(items, Cursor{}, 3, "item", getTestID, "/items", nil)
+	if !first.HasMore {
+		t.Fatal("expected HasMore on first page")
+	}
+	cursor, err := DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	second := Paginate(items, cursor, 3, "item", getTestID, "/items", nil)
+	if second.HasMore {
+		t.Fatal("expected no HasMore on last page")
+	}
+}
+
+func pageIDs(items []testItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func TestPaginate_Backward(t *testing.T) {
+	items := makeItems(8)
+
+	first := Paginate(items, Cursor{Direction: Backward}, 3, "item", getTestID, "/items", nil)
+	if want := []string{"h", "g", "f"}; !slices.Equal(pageIDs(first.Items), want) {
+		t.Fatalf("expected first page %v, got %v", want, pageIDs(first.Items))
+	}
+	if first.PrevCursor != "" || !strings.Contains(first.LinkHeader, `rel="next"`) {
+		t.Fatalf("expected only a next link on the first page, got %q", first.LinkHeader)
+	}
+	if first.Total != 8 || !first.HasMore {
+		t.Fatalf("expected total 8 with more, got %d and %v", first.Total, first.HasMore)
+	}
+
+	next, err := DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	if next.Direction != Backward || next.Value != "f" || next.Depth != 1 {
+		t.Fatalf("expected backward cursor at f with depth 1, got %+v", next)
+	}
+
+	second := Paginate(items, next, 3, "item", getTestID, "/items", nil)
+	if want := []string{"e", "d", "c"}; !slices.Equal(pageIDs(second.Items), want) {
+		t.Fatalf("expected second page %v, got %v", want, pageIDs(second.Items))
+	}
+	if !strings.Contains(second.LinkHeader, `rel="next"`) || !strings.Contains(second.LinkHeader, `rel="prev"`) {
+		t.Fatalf("expected next and prev links, got %q", second.LinkHeader)
+	}
+	prev, err := DecodeCursor(second.PrevCursor)
+	if err != nil {
+		t.Fatalf("decode prev cursor: %v", err)
+	}
+	if prev.Direction != Backward || prev.Value != "" {
+		t.Fatalf("expected prev cursor to restart the backward walk, got %+v", prev)
+	}
+
+	next, err = DecodeCursor(second.NextCursor)
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	last := Paginate(items, next, 3, "item", getTestID, "/items", nil)
+	if want := []string{"b", "a"}; !slices.Equal(pageIDs(last.Items), want) {
+		t.Fatalf("expected last page %v, got %v", want, pageIDs(last.Items))
+	}
+	if last.NextCursor != "" || last.HasMore {
+		t.Fatal("expected no next cursor on the last backward page")
+	}
+	prev, err = DecodeCursor(last.PrevCursor)
+	if err != nil {
+		t.Fatalf("decode prev cursor: %v", err)
+	}
+	if prev.Direction != Backward || prev.Value != "f" {
+		t.Fatalf("expected prev cursor at f, got %+v", prev)
+	}
+	if items[0].ID != "a" {
+		t.Fatal("backward pagination must not reorder the caller's slice")
+	}
+}
+
+func TestPaginateByID_CursorItemRemoved(t *testing.T) {
+	items := makeItems(8)
+	first := PaginateByID(items, Cursor{}, 3, "item", getTestID, "/items", nil)
+	next, err := DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	if next.Value != "c" {
+		t.Fatalf("expected cursor at c, got %+v", next)
+	}
+
+	remaining := slices.DeleteFunc(slices.Clone(items), func(item testItem) bool { return item.ID == "c" })
+	second := PaginateByID(remaining, next, 3, "item", getTestID, "/items", nil)
+	if want := []string{"d", "e", "f"}; !slices.Equal(pageIDs(second.Items), want) {
+		t.Fatalf("expected second page %v, got %v", want, pageIDs(second.Items))
+	}
+	if second.Total != 7 || !second.HasMore {
+		t.Fatalf("expected total 7 with more, got %d and %v", second.Total, second.HasMore)
+	}
+
+	back := PaginateByID(remaining, Cursor{Value: "c", Direction: Backward}, 3, "item", getTestID, "/items", nil)
+	if want := []string{"b", "a"}; !slices.Equal(pageIDs(back.Items), want) {
+		t.Fatalf("expected backward page %v, got %v", want, pageIDs(back.Items))
+	}
+}
+
+func TestPaginateByID_MatchesPaginate(t *testing.T) {
+	items := makeItems(10)
+	for _, dir := range []Direction{Forward, Backward} {
+		for _, value := range []string{"", "a", "e", "j"} {
+			cursor := Cursor{Value: value, Direction: dir, Depth: 1}
+			want := Paginate(items, cursor, 3, "item", getTestID, "/items", nil)
+			got := PaginateByID(items, cursor, 3, "item", getTestID, "/items", nil)
+			if !slices.Equal(pageIDs(got.Items), pageIDs(want.Items)) || got.LinkHeader != want.LinkHeader {
+				t.Fatalf("cursor %+v: expected %v, got %v", cursor, pageIDs(want.Items), pageIDs(got.Items))
+			}
+		}
+	}
+}
+
+func TestSortByKey_TieBreaksByID(t *testing.T) {
+	items := []testItem{
+		{ID: "d", Name: "b"},
+		{ID: "c", Name: "a"},
+		{ID: "a", Name: "b"},
+		{ID: "b", Name: "a"},
+	}
+	SortByKey(items, func(a, b testItem) int { return strings.Compare(a.Name, b.Name) }, getTestID)
+
+	got := make([]string, len(items))
+	for i, item := range items {
+		got[i] = item.ID
+	}
+	if want := []string{"b", "c", "a", "d"}; !slices.Equal(got, want) {
+		t.Fatalf("expected order %v, got %v", want, got)
+	}
+}
+
+func TestSortByKey_NilCompareOrdersByID(t *testing.T) {
+	items := []testItem{{ID: "c"}, {ID: "a"}, {ID: "b"}}
+	SortByKey(items, nil, getTestID)
+
+	if items[0].ID != "a" || items[1].ID != "b" || items[2].ID != "c" {
+		t.Fatalf("expected ID order, got %v", items)
+	}
+}
+
+func TestPaginate_DuplicateSortKeys(t *testing.T) {
+	// Every item shares one of two sort keys; the ID tie-breaker must give
+	// each a unique position so walking the cursors visits all exactly once.
+	items := make([]testItem, 0, 9)
+	for _, id := range []string{"i", "c", "g", "a", "e", "h", "b", "f", "d"} {
+		name := "even"
+		if (id[0]-'a')%2 == 1 {
+			name = "odd"
+		}
+		items = append(items, testItem{ID: id, Name: name})
+	}
+	SortByKey(items, func(a, b testItem) int { return strings.Compare(a.Name, b.Name) }, getTestID)
+
+	var seen []string
+	cursor := Cursor{}
+	for range len(items) {
+		result := Paginate(items, cursor, 2, "item", getTestID, "/items", nil)
+		for _, item := range result.Items {
+			seen = append(seen, item.ID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		var err error
+		if cursor, err = DecodeCursor(result.NextCursor); err != nil {
+			t.Fatalf("failed to decode cursor: %v", err)
+		}
+	}
+
+	want := []string{"a", "c", "e", "g", "i", "b", "d", "f", "h"}
+	if !slices.Equal(seen, want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+}
+
+func TestPaginateFetched_FullPage(t *testing.T) {
+	// Four items fetched for a limit of three: one extra signals another page.
+	fetched := makeItems(4)
+	result := PaginateFetched(fetched, Cursor{}, 3, "item", getTestID, "/items", nil)
+	if !result.HasMore {
+		t.Fatal("expected HasMore on full page")
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(result.Items))
+	}
+	for _, item := range result.Items {
+		if item.ID == "d" {
+			t.Fatal("extra fetched item must not be returned")
+		}
+	}
+	if result.Total != 0 {
+		t.Fatalf("expected total to be left unset, got %d", result.Total)
+	}
+	next, err := DecodeCursor(result.NextCursor)
+	if err != nil {
+		t.Fatalf("decode cursor: %v", err)
+	}
+	if next.Value != "c" {
+		t.Fatalf("expected next cursor at %q, got %q", "c", next.Value)
+	}
+	if result.LinkHeader != `</items?cursor=`+result.NextCursor+`&limit=3>; rel="next"` {
+		t.Fatalf("unexpected link header %q", result.LinkHeader)
+	}
+}
+
+func TestPaginateFetched_LastPage(t *testing.T) {
+	fetched := makeItems(2)
+	result := PaginateFetched(fetched, Cursor{}, 3, "item", getTestID, "/items", url.Values{"category": {"tools"}})
+	if result.HasMore {
+		t.Fatal("expected no HasMore on last page")
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(result.Items))
+	}
+	if result.NextCursor != "" {
+		t.Fatalf("expected no next cursor, got %q", result.NextCursor)
+	}
+	if result.LinkHeader != "" {
+		t.Fatalf("expected no link header, got %q", result.LinkHeader)
+	}
+}
+
+func TestPaginateFetched_ExactlyLimit(t *testing.T) {
+	result := PaginateFetched(makeItems(3), Cursor{}, 3, "item", getTestID, "/items", nil)
+	if result.HasMore {
+		t.Fatal("expected no HasMore when fetch returned exactly limit items")
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(result.Items))
+	}
 }
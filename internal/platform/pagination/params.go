@@ -1,11 +1,30 @@
 package pagination
 
+import "errors"
+
 // DefaultLimit is the default number of items per page.
 const DefaultLimit = 20
 
 // MaxLimit is the maximum number of items per page.
 const MaxLimit = 100
 
+// DefaultMaxDepth is the default number of pages reachable by following
+// cursors from the first page.
+const DefaultMaxDepth = 20
+
+// ErrDepthExceeded indicates a cursor points beyond the allowed page depth.
+var ErrDepthExceeded = errors.New("pagination depth exceeded")
+
+// CheckDepth returns ErrDepthExceeded when the cursor points to a page at or
+// beyond maxDepth. A non-positive maxDepth disables the check. Depth is only
+// tamper-resistant when SetSigningKey has been called.
+func CheckDepth(c Cursor, maxDepth int) error {
+	if maxDepth > 0 && c.Depth >= maxDepth {
+		return ErrDepthExceeded
+	}
+	return nil
+}
+
 // Params provides a helper for pagination defaults.
 type Params struct {
 	Cursor string
@@ -1,6 +1,9 @@
 package pagination
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestParams_DefaultLimit(t *testing.T) {
 	p := Params{Limit: 0}
@@ -31,3 +34,15 @@ func TestConstants(t *testing.T) {
 		t.Fatalf("expected MaxLimit=100, got %d", MaxLimit)
 	}
 }
+
+func TestCheckDepth(t *testing.T) {
+	if err := CheckDepth(Cursor{Depth: 1}, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := CheckDepth(Cursor{Depth: 2}, 2); !errors.Is(err, ErrDepthExceeded) {
+		t.Fatalf("expected ErrDepthExceeded, got %v", err)
+	}
+	if err := CheckDepth(Cursor{Depth: 100}, 0); err != nil {
+		t.Fatalf("expected disabled check, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/pagination"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+// PageRequest is the parsed pagination input of a list request.
+type PageRequest struct {
+	// Cursor is the decoded cursor; the zero value means the first page.
+	Cursor pagination.Cursor
+	// Limit is the page size, defaulted and clamped to the configured bounds.
+	Limit int
+	// Offset is the number of items to skip; always zero unless
+	// PageConfig.Offset is set.
+	Offset int
+}
+
+// PageConfig configures the Pagination middleware.
+type PageConfig struct {
+	// CursorType rejects cursors issued for another resource type. Scoped
+	// types from pagination.ScopedType match on their base type; handlers
+	// compare the full scoped type themselves.
+	CursorType string
+	// DefaultLimit applies when limit is absent or zero. Defaults to
+	// pagination.DefaultLimit.
+	DefaultLimit int
+	// MaxLimit clamps larger limits. Defaults to pagination.MaxLimit.
+	MaxLimit int
+	// MaxDepth rejects cursors at or beyond this page depth. Defaults to
+	// pagination.DefaultMaxDepth; a negative value disables the check.
+	MaxDepth int
+	// Offset accepts the offset parameter for endpoints that page by offset.
+	// When false, a request carrying offset yields 400 instead of having it
+	// silently ignored.
+	Offset bool
+	// Skipper bypasses parsing, e.g. for streamed responses that ignore
+	// cursor and limit.
+	Skipper func(c *echo.Context) bool
+}
+
+type ctxPageKey struct{}
+
+// Pagination returns Echo middleware that parses the cursor, limit, and
+// offset query parameters into a PageRequest available via Page.
+//
+// A non-integer limit or offset yields 400 and a negative one 422, matching
+// Int. A limit of zero selects the default and one above MaxLimit is clamped.
+// Undecodable cursors, cursors of another type, and cursors beyond MaxDepth
+// yield 400.
+func Pagination(cfg PageConfig) echo.MiddlewareFunc {
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = pagination.DefaultLimit
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = pagination.MaxLimit
+	}
+	if cfg.MaxDepth == 0 {
+		cfg.MaxDepth = pagination.DefaultMaxDepth
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
+			page, err := parsePage(c, cfg)
+			if err != nil {
+				return err
+			}
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxPageKey{}, page)))
+			return next(c)
+		}
+	}
+}
+
+// Page returns the PageRequest parsed by the Pagination middleware and
+// whether the middleware ran for this request.
+func Page(c *echo.Context) (PageRequest, bool) {
+	page, ok := c.Request().Context().Value(ctxPageKey{}).(PageRequest)
+	return page, ok
+}
+
+func parsePage(c *echo.Context, cfg PageConfig) (PageRequest, error) {
+	limit, err := Int(c, "limit", Range(0, math.MaxInt))
+	if err != nil {
+		return PageRequest{}, err
+	}
+	if limit == 0 {
+		limit = cfg.DefaultLimit
+	}
+	limit = min(limit, cfg.MaxLimit)
+
+	var offset int
+	if cfg.Offset {
+		if offset, err = Int(c, "offset", Range(0, math.MaxInt)); err != nil {
+			return PageRequest{}, err
+		}
+	} else if c.Request().URL.Query().Has("offset") {
+		return PageRequest{}, respond.Error400("offset is not supported; page with cursor")
+	}
+
+	cursor, err := pagination.DecodeCursor(c.QueryParam("cursor"))
+	if err != nil {
+		return PageRequest{}, respond.Error400("invalid cursor format")
+	}
+	if base, _, _ := strings.Cut(cursor.Type, "?"); cfg.CursorType != "" && base != "" && base != cfg.CursorType {
+		return PageRequest{}, respond.Error400("cursor type mismatch")
+	}
+	if err := pagination.CheckDepth(cursor, cfg.MaxDepth); errors.Is(err, pagination.ErrDepthExceeded) {
+		return PageRequest{}, respond.Error400("pagination depth exceeded; narrow the filter or increase the limit")
+	}
+
+	return PageRequest{Cursor: cursor, Limit: limit, Offset: offset}, nil
+}
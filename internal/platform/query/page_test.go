@@ -0,0 +1,131 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/pagination"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+func newPageEcho(cfg PageConfig, got *PageRequest) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.GET("/items", func(c *echo.Context) error {
+		page, ok := Page(c)
+		if !ok {
+			return respond.Error500("no page request")
+		}
+		*got = page
+		return c.NoContent(http.StatusNoContent)
+	}, Pagination(cfg))
+	return e
+}
+
+func TestPagination(t *testing.T) {
+	cursor := pagination.Cursor{Type: "item", Value: "item-007", Depth: 2}
+
+	tests := []struct {
+		name   string
+		target string
+		want   PageRequest
+	}{
+		{"defaults", "/items", PageRequest{Limit: pagination.DefaultLimit}},
+		{"zero limit", "/items?limit=0", PageRequest{Limit: pagination.DefaultLimit}},
+		{"over max clamped", "/items?limit=500", PageRequest{Limit: pagination.MaxLimit}},
+		{
+			"typed request",
+			"/items?limit=5&offset=10&cursor=" + cursor.Encode(),
+			PageRequest{Cursor: cursor, Limit: 5, Offset: 10},
+		},
+		{
+			"scoped cursor type",
+			"/items?cursor=" + pagination.Cursor{Type: "item?category=tools", Value: "item-001"}.Encode(),
+			PageRequest{Cursor: pagination.Cursor{Type: "item?category=tools", Value: "item-001"}, Limit: 20},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PageRequest
+			e := newPageEcho(PageConfig{CursorType: "item", Offset: true}, &got)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != http.StatusNoContent {
+				t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body.String())
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPagination_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		offset bool
+		status int
+		detail string
+	}{
+		{"invalid cursor", "/items?cursor=!!!", false, http.StatusBadRequest, "invalid cursor format"},
+		{
+			"wrong cursor type",
+			"/items?cursor=" + pagination.Cursor{Type: "user", Value: "u1"}.Encode(),
+			false, http.StatusBadRequest, "cursor type mismatch",
+		},
+		{
+			"too deep",
+			"/items?cursor=" + pagination.Cursor{Type: "item", Value: "x", Depth: 3}.Encode(),
+			false, http.StatusBadRequest, "pagination depth exceeded; narrow the filter or increase the limit",
+		},
+		{"non-integer limit", "/items?limit=ten", false, http.StatusBadRequest, "invalid query parameter"},
+		{"negative limit", "/items?limit=-1", false, http.StatusUnprocessableEntity, "validation failed"},
+		{"negative offset", "/items?offset=-5", true, http.StatusUnprocessableEntity, "validation failed"},
+		{"unsupported offset", "/items?offset=5", false, http.StatusBadRequest, "offset is not supported; page with cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PageRequest
+			e := newPageEcho(PageConfig{CursorType: "item", MaxDepth: 3, Offset: tt.offset}, &got)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+			var problem respond.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if problem.Detail != tt.detail {
+				t.Fatalf("expected detail %q, got %q", tt.detail, problem.Detail)
+			}
+		})
+	}
+}
+
+func TestPagination_Skipper(t *testing.T) {
+	e := echo.New()
+	e.GET("/items", func(c *echo.Context) error {
+		_, ok := Page(c)
+		if ok {
+			return c.NoContent(http.StatusConflict)
+		}
+		return c.NoContent(http.StatusNoContent)
+	}, Pagination(PageConfig{Skipper: func(*echo.Context) bool { return true }}))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items?cursor=!!!", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected skipped request to reach handler without a page, got %d", rec.Code)
+	}
+}
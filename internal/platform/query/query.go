@@ -0,0 +1,94 @@
+// Package query provides typed query parameter parsing with Problem Details
+// errors that match the messages produced by the validate package.
+package query
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+// IntOption configures Int.
+type IntOption func(*intConfig)
+
+type intConfig struct {
+	required bool
+	def      int
+	bounded  bool
+	min, max int
+}
+
+// Required rejects requests where the parameter is absent or empty.
+func Required() IntOption {
+	return func(c *intConfig) { c.required = true }
+}
+
+// Default sets the value returned when the parameter is absent or empty.
+func Default(v int) IntOption {
+	return func(c *intConfig) { c.def = v }
+}
+
+// Range rejects values outside [lo, hi].
+func Range(lo, hi int) IntOption {
+	return func(c *intConfig) {
+		c.bounded = true
+		c.min, c.max = lo, hi
+	}
+}
+
+// Int parses the named query parameter as an integer.
+//
+// A value that is not an integer yields a 400 Problem Details error with a
+// field-level entry. A missing required value or one outside the configured
+// range yields a *validate.ValidationError, rendered as 422.
+func Int(c *echo.Context, name string, opts ...IntOption) (int, error) {
+	var cfg intConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	raw := c.QueryParam(name)
+	if raw == "" {
+		if cfg.required {
+			return 0, fieldError(name, name+" is required", raw)
+		}
+		return cfg.def, nil
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		p := respond.Error400("invalid query parameter")
+		p.Errors = []respond.ErrorDetail{{Message: name + " must be an integer", Location: name, Value: raw}}
+		return 0, p
+	}
+
+	if cfg.bounded {
+		if v < cfg.min {
+			return 0, fieldError(name, name+" must be at least "+strconv.Itoa(cfg.min), raw)
+		}
+		if v > cfg.max {
+			return 0, fieldError(name, name+" must be at most "+strconv.Itoa(cfg.max), raw)
+		}
+	}
+	return v, nil
+}
+
+// RequireString returns the named query parameter, or a
+// *validate.ValidationError when it is absent or empty.
+func RequireString(c *echo.Context, name string) (string, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return "", fieldError(name, name+" is required", v)
+	}
+	return v, nil
+}
+
+func fieldError(name, message, value string) error {
+	return &validate.ValidationError{
+		Message: "validation failed",
+		Fields:  []validate.FieldError{{Field: name, Message: message, Value: value}},
+	}
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+func newContext(target string) *echo.Context {
+	e := echo.New()
+	return e.NewContext(httptest.NewRequest(http.MethodGet, target, nil), httptest.NewRecorder())
+}
+
+func TestInt_Valid(t *testing.T) {
+	c := newContext("/items?limit=25")
+	v, err := Int(c, "limit", Range(1, 100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != 25 {
+		t.Fatalf("expected 25, got %d", v)
+	}
+}
+
+func TestInt_Default(t *testing.T) {
+	c := newContext("/items")
+	v, err := Int(c, "limit", Default(20), Range(1, 100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != 20 {
+		t.Fatalf("expected default 20, got %d", v)
+	}
+}
+
+func TestInt_NotInteger(t *testing.T) {
+	c := newContext("/items?limit=abc")
+	_, err := Int(c, "limit")
+
+	var pd *respond.ProblemDetails
+	if !errors.As(err, &pd) {
+		t.Fatalf("expected ProblemDetails, got %v", err)
+	}
+	if pd.Status != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", pd.Status)
+	}
+	if len(pd.Errors) != 1 || pd.Errors[0].Location != "limit" || pd.Errors[0].Message != "limit must be an integer" {
+		t.Fatalf("unexpected field errors: %+v", pd.Errors)
+	}
+}
+
+func TestInt_OutOfRange(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/items?limit=0", "limit must be at least 1"},
+		{"/items?limit=101", "limit must be at most 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			_, err := Int(newContext(tt.target), "limit", Range(1, 100))
+			var ve *validate.ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if ve.Fields[0].Message != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, ve.Fields[0].Message)
+			}
+		})
+	}
+}
+
+func TestInt_RequiredMissing(t *testing.T) {
+	_, err := Int(newContext("/items"), "limit", Required())
+	var ve *validate.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if ve.Fields[0].Message != "limit is required" {
+		t.Fatalf("unexpected message %q", ve.Fields[0].Message)
+	}
+}
+
+func TestRequireString(t *testing.T) {
+	v, err := RequireString(newContext("/items?category=tools"), "category")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v != "tools" {
+		t.Fatalf("expected tools, got %q", v)
+	}
+}
+
+func TestRequireString_Missing(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+	e.GET("/items", func(c *echo.Context) error {
+		if _, err := RequireString(c, "category"); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+}
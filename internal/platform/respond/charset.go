@@ -0,0 +1,41 @@
+package respond
+
+import (
+	"github.com/labstack/echo/v5"
+)
+
+// AcceptCharset returns Echo middleware that rejects requests whose
+// Accept-Charset header excludes UTF-8, the only charset the API emits, with
+// 406 Not Acceptable. UTF-8 is excluded when it is listed with q=0, or when
+// neither utf-8 nor * is listed with a positive q. Requests without the
+// header proceed.
+func AcceptCharset() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			if !acceptsUTF8(c.Request().Header.Get("Accept-Charset")) {
+				return Error406("only utf-8 responses are available")
+			}
+			return next(c)
+		}
+	}
+}
+
+// acceptsUTF8 reports whether an Accept-Charset header value allows UTF-8.
+// An explicit utf-8 entry takes precedence over the * wildcard.
+func acceptsUTF8(header string) bool {
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		return true
+	}
+
+	wildcardQ := -1.0
+	for _, r := range ranges {
+		switch r.typ {
+		case "utf-8":
+			return r.q > 0
+		case "*":
+			wildcardQ = max(wildcardQ, r.q)
+		}
+	}
+	return wildcardQ > 0
+}
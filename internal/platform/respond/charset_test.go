@@ -0,0 +1,59 @@
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestAcceptCharset(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"absent", "", http.StatusOK},
+		{"utf-8", "utf-8", http.StatusOK},
+		{"case insensitive", "UTF-8", http.StatusOK},
+		{"utf-8 among others", "iso-8859-1, utf-8;q=0.5", http.StatusOK},
+		{"wildcard", "iso-8859-1, *;q=0.1", http.StatusOK},
+		{"list without utf-8", "iso-8859-1, windows-1252;q=0.7", http.StatusNotAcceptable},
+		{"utf-8 refused", "utf-8;q=0", http.StatusNotAcceptable},
+		{"utf-8 refused despite wildcard", "*, utf-8;q=0", http.StatusNotAcceptable},
+		{"wildcard refused", "iso-8859-1, *;q=0", http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = NewHTTPErrorHandler()
+			e.Use(AcceptCharset())
+			e.GET("/", func(c *echo.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Charset", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+			if tt.want != http.StatusNotAcceptable {
+				return
+			}
+			var problem ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if problem.Status != http.StatusNotAcceptable || problem.Title != "Not Acceptable" {
+				t.Fatalf("unexpected problem: %+v", problem)
+			}
+		})
+	}
+}
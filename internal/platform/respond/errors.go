@@ -1,8 +1,12 @@
 package respond
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
 )
 
 // ProblemDetails represents an RFC 9457 Problem Details response.
@@ -13,6 +17,11 @@ type ProblemDetails struct {
 	Detail   string        `json:"detail,omitempty"   cbor:"detail,omitempty"   example:"resource not found"`
 	Instance string        `json:"instance,omitempty" cbor:"instance,omitempty" example:"/v1/items/42"`
 	Errors   []ErrorDetail `json:"errors,omitempty"   cbor:"errors,omitempty"`
+	// Failures lists the failed items of a batch operation; see MultiError.
+	Failures []ItemError `json:"failures,omitempty" cbor:"failures,omitempty"`
+	// Category lists the type code of a TypedError and its ancestors, most
+	// general first.
+	Category []string `json:"category,omitempty" cbor:"category,omitempty" example:"validation,validation/email"`
 }
 
 // ErrorDetail represents a single field-level error within a Problem Details response.
@@ -35,11 +44,30 @@ func (p *ProblemDetails) StatusCode() int {
 	return p.Status
 }
 
+// unknownStatusTitle is the title used for status codes without a standard
+// reason phrase, such as 499.
+const unknownStatusTitle = "Unknown Error"
+
+// StatusTitle returns the reason phrase for status, falling back to
+// "Unknown Error" for non-standard codes so Problem Details titles are never empty.
+func StatusTitle(status int) string {
+	if text := http.StatusText(status); text != "" {
+		return text
+	}
+	return unknownStatusTitle
+}
+
 // NewError creates a ProblemDetails error with the given status code and detail message.
 func NewError(status int, detail string) *ProblemDetails {
+	return NewErrorWithTitle(status, StatusTitle(status), detail)
+}
+
+// NewErrorWithTitle creates a ProblemDetails error with a custom title, for
+// non-standard status codes or a more specific summary than the reason phrase.
+func NewErrorWithTitle(status int, title, detail string) *ProblemDetails {
 	return &ProblemDetails{
 		Type:   "about:blank",
-		Title:  http.StatusText(status),
+		Title:  title,
 		Status: status,
 		Detail: detail,
 	}
@@ -65,14 +93,25 @@ func Error404(detail string) *ProblemDetails {
 	return NewError(http.StatusNotFound, detail)
 }
 
+// Error406 returns a 406 Not Acceptable ProblemDetails error.
+func Error406(detail string) *ProblemDetails {
+	return NewError(http.StatusNotAcceptable, detail)
+}
+
 // Error409 returns a 409 Conflict ProblemDetails error.
 func Error409(detail string) *ProblemDetails {
 	return NewError(http.StatusConflict, detail)
 }
 
-// Error422 returns a 422 Unprocessable Entity ProblemDetails error with field-level errors.
+// Error412 returns a 412 Precondition Failed ProblemDetails error.
+func Error412(detail string) *ProblemDetails {
+	return NewError(http.StatusPreconditionFailed, detail)
+}
+
+// Error422 returns a 422 ProblemTypeValidation ProblemDetails error with
+// field-level errors.
 func Error422(detail string, fields ...ErrorDetail) *ProblemDetails {
-	p := NewError(http.StatusUnprocessableEntity, detail)
+	p := TypedError(context.Background(), ProblemTypeValidation, detail)
 	p.Errors = fields
 	return p
 }
@@ -86,3 +125,39 @@ func Error500(detail string) *ProblemDetails {
 func Error503(detail string) *ProblemDetails {
 	return NewError(http.StatusServiceUnavailable, detail)
 }
+
+// ErrorMapping associates a sentinel error with the status and detail returned
+// when an error matches it via errors.Is. When Type names a registered problem
+// type code, the error is reported as TypedError and Status is ignored.
+type ErrorMapping struct {
+	Err    error
+	Status int
+	Detail string
+	Type   string
+}
+
+// FromError converts err into a ProblemDetails error using the first matching
+// mapping. Unmapped errors are logged and reported as a generic 500 so internal
+// details are never exposed to clients. Returns nil when err is nil, so the
+// result can be returned directly from a handler.
+func FromError(ctx context.Context, err error, mappings ...ErrorMapping) error {
+	if err == nil {
+		return nil
+	}
+	return problemFromError(ctx, err, mappings)
+}
+
+// problemFromError implements FromError for a non-nil err.
+func problemFromError(ctx context.Context, err error, mappings []ErrorMapping) *ProblemDetails {
+	for _, m := range mappings {
+		if !errors.Is(err, m.Err) {
+			continue
+		}
+		if m.Type != "" {
+			return TypedError(ctx, m.Type, m.Detail)
+		}
+		return NewError(m.Status, m.Detail)
+	}
+	applog.LogError(ctx, "unexpected service error", err)
+	return Error500("internal error")
+}
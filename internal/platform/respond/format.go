@@ -0,0 +1,219 @@
+package respond
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/metrics"
+)
+
+// Format is a response serialization negotiated from the Accept header.
+type Format int
+
+// Supported response formats.
+const (
+	FormatJSON Format = iota
+	FormatCBOR
+	// FormatNone means the Accept header excludes every supported format
+	// with q=0. Negotiate serves JSON regardless; NegotiateStrict answers 406.
+	FormatNone
+)
+
+// String returns the format's short name, suitable as a metrics label.
+func (f Format) String() string {
+	switch f {
+	case FormatCBOR:
+		return "cbor"
+	case FormatNone:
+		return "none"
+	default:
+		return "json"
+	}
+}
+
+// MediaType returns the media type the format is served as, or "" for
+// FormatNone.
+func (f Format) MediaType() string {
+	switch f {
+	case FormatCBOR:
+		return "application/cbor"
+	case FormatNone:
+		return ""
+	default:
+		return echo.MIMEApplicationJSON
+	}
+}
+
+// Formats lists the formats Negotiate can select, the default first.
+func Formats() []Format {
+	return []Format{FormatJSON, FormatCBOR}
+}
+
+type ctxFormatKey struct{}
+
+// negotiation is the format FormatNegotiation selected and why, cached on the
+// request context.
+type negotiation struct {
+	format Format
+	reason string
+}
+
+// PreferredFormat returns the format Negotiate uses for the request: CBOR
+// when the Accept header prefers it, JSON otherwise. The value cached by
+// FormatNegotiation is reused when present.
+func PreferredFormat(c *echo.Context) Format {
+	return requestFormat(c.Request())
+}
+
+// HeaderNegotiated reports the format Negotiate selected and why, such as
+// "cbor (explicit)", when FormatNegotiationConfig.ExposeDecision is set.
+const HeaderNegotiated = "X-Negotiated"
+
+// Negotiation reasons reported in the X-Negotiated header.
+const (
+	// reasonExplicit means the Accept header names the format.
+	reasonExplicit = "explicit"
+	// reasonDefault means the format was chosen without the Accept header
+	// naming it: the header is absent, only has wildcards, or accepts
+	// nothing supported.
+	reasonDefault = "default"
+	// reasonFallback means CBOR was selected but the body could only be
+	// encoded as JSON.
+	reasonFallback = "fallback"
+)
+
+// FormatNegotiationConfig configures the FormatNegotiation middleware.
+type FormatNegotiationConfig struct {
+	// ExposeDecision makes Negotiate report its choice in the X-Negotiated
+	// header, to help support diagnose clients receiving the wrong format.
+	ExposeDecision bool
+}
+
+type ctxExposeNegotiationKey struct{}
+
+// FormatNegotiation returns Echo middleware that parses the Accept header once
+// and caches the preferred format and the reason for it on the request context.
+func FormatNegotiation() echo.MiddlewareFunc {
+	return FormatNegotiationWithConfig(FormatNegotiationConfig{})
+}
+
+// FormatNegotiationWithConfig returns FormatNegotiation middleware with the
+// given config.
+func FormatNegotiationWithConfig(cfg FormatNegotiationConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			accept := req.Header.Get("Accept")
+			format := parseFormat(accept)
+			ctx := context.WithValue(req.Context(), ctxFormatKey{}, negotiation{
+				format: format,
+				reason: negotiationReason(accept, format),
+			})
+			if cfg.ExposeDecision {
+				ctx = context.WithValue(ctx, ctxExposeNegotiationKey{}, true)
+			}
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// recordNegotiation counts the format Negotiate selected and why in the
+// NegotiatedFormats metric, and sets the X-Negotiated header when enabled for
+// the request. An empty reason is taken from the value cached by
+// FormatNegotiation, or derived from the Accept header without it.
+func recordNegotiation(c *echo.Context, format Format, reason string) {
+	r := c.Request()
+	if reason == "" {
+		if n, ok := r.Context().Value(ctxFormatKey{}).(negotiation); ok && n.format == format {
+			reason = n.reason
+		} else {
+			reason = negotiationReason(r.Header.Get("Accept"), format)
+		}
+	}
+	metrics.IncCounter(metrics.NegotiatedFormats, metrics.Labels{"format": format.String(), "reason": reason})
+	if expose, _ := r.Context().Value(ctxExposeNegotiationKey{}).(bool); !expose {
+		return
+	}
+	c.Response().Header().Set(HeaderNegotiated, format.String()+" ("+reason+")")
+}
+
+// negotiationReason reports whether an acceptable range in header names
+// format specifically rather than through a wildcard.
+func negotiationReason(header string, format Format) string {
+	if header == "" {
+		return reasonDefault
+	}
+	if mr, ok := parseSingleAccept(header); ok {
+		return rangeReason(mr, format)
+	}
+	for _, mr := range parseAccept(header) {
+		if mr.q != 0 && rangeReason(mr, format) == reasonExplicit {
+			return reasonExplicit
+		}
+	}
+	return reasonDefault
+}
+
+// rangeReason reports whether a single media range names format specifically.
+func rangeReason(mr mediaRange, format Format) string {
+	matchesCBOR, matchesJSON, specificity := mr.formats()
+	matches := matchesJSON
+	if format == FormatCBOR {
+		matches = matchesCBOR
+	}
+	if matches && specificity >= 3 {
+		return reasonExplicit
+	}
+	return reasonDefault
+}
+
+func requestFormat(r *http.Request) Format {
+	if n, ok := r.Context().Value(ctxFormatKey{}).(negotiation); ok {
+		return n.format
+	}
+	return parseFormat(r.Header.Get("Accept"))
+}
+
+// AcceptParam returns the value of media type parameter name on the Accept
+// range that selected the negotiated format, such as "2" for version in
+// Accept: application/json;version=2. As in format selection, the most
+// specific range for the format wins, then the highest q. Parameter names are
+// case-insensitive. It reports false when that range lacks the parameter.
+func AcceptParam(r *http.Request, name string) (string, bool) {
+	cbor := requestFormat(r) == FormatCBOR
+	var (
+		best            *mediaRange
+		bestSpecificity int
+	)
+	ranges := parseAccept(r.Header.Get("Accept"))
+	for i, mr := range ranges {
+		if mr.q == 0 {
+			continue
+		}
+		matchesCBOR, matchesJSON, specificity := mr.formats()
+		if cbor && !matchesCBOR || !cbor && !matchesJSON {
+			continue
+		}
+		if best == nil || specificity > bestSpecificity || specificity == bestSpecificity && mr.q > best.q {
+			best, bestSpecificity = &ranges[i], specificity
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	v, ok := best.params[strings.ToLower(name)]
+	return v, ok
+}
+
+// parseFormat returns the format Negotiate serves for accept, defaulting to
+// JSON when the header excludes every supported format.
+func parseFormat(accept string) Format {
+	if selectFormat(accept) == FormatCBOR {
+		return FormatCBOR
+	}
+	return FormatJSON
+}
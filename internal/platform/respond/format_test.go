@@ -0,0 +1,266 @@
+package respond
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/metrics"
+)
+
+func TestPreferredFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+	}{
+		{"application/cbor", FormatCBOR},
+		{"application/cbor, application/json;q=0.5", FormatCBOR},
+		{"", FormatJSON},
+		{"application/json", FormatJSON},
+		{"*/*", FormatJSON},
+		{"text/html", FormatJSON},
+		{"application/json, application/cbor;q=0.5", FormatJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			if got := PreferredFormat(c); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormat_String(t *testing.T) {
+	if FormatJSON.String() != "json" || FormatCBOR.String() != "cbor" {
+		t.Fatalf("unexpected names %q and %q", FormatJSON, FormatCBOR)
+	}
+}
+
+func TestFormatNegotiation_CachedMatchesNegotiate(t *testing.T) {
+	for _, accept := range []string{"application/cbor", "application/json", ""} {
+		t.Run(accept, func(t *testing.T) {
+			e := echo.New()
+			e.Use(FormatNegotiation())
+
+			var cached Format
+			e.GET("/data", func(c *echo.Context) error {
+				cached = PreferredFormat(c)
+				return Negotiate(c, http.StatusOK, map[string]string{"ok": "yes"})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/data", nil)
+			req.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			gotCBOR := strings.HasPrefix(rec.Header().Get("Content-Type"), "application/cbor")
+			if gotCBOR != (cached == FormatCBOR) {
+				t.Fatalf("cached format %v does not match Content-Type %q", cached, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestFormatNegotiation_CachedValueUsed(t *testing.T) {
+	e := echo.New()
+	e.Use(FormatNegotiation())
+
+	var got Format
+	e.GET("/data", func(c *echo.Context) error {
+		c.Request().Header.Set("Accept", "application/json")
+		got = PreferredFormat(c)
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/cbor")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != FormatCBOR {
+		t.Fatalf("expected cached %v, got %v", FormatCBOR, got)
+	}
+}
+
+func TestFormatNegotiation_ExposeDecision(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"application/cbor", "cbor (explicit)"},
+		{"application/json", "json (explicit)"},
+		{"application/problem+json, application/cbor;q=0.5", "json (explicit)"},
+		{"", "json (default)"},
+		{"*/*", "json (default)"},
+		{"application/*, application/cbor;q=0", "json (default)"},
+		{"text/html", "json (default)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			e := echo.New()
+			e.Use(FormatNegotiationWithConfig(FormatNegotiationConfig{ExposeDecision: true}))
+			e.GET("/data", func(c *echo.Context) error {
+				return Negotiate(c, http.StatusOK, map[string]string{"ok": "yes"})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/data", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get(HeaderNegotiated); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatNegotiation_ExposeDecisionFallback(t *testing.T) {
+	e := echo.New()
+	e.Use(FormatNegotiationWithConfig(FormatNegotiationConfig{ExposeDecision: true}))
+	e.GET("/data", func(c *echo.Context) error {
+		return Negotiate(c, http.StatusOK, jsonOnly{})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/cbor")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(HeaderNegotiated); got != "json (fallback)" {
+		t.Fatalf("expected json (fallback), got %q", got)
+	}
+}
+
+func TestFormatNegotiation_ReasonCachedOnContext(t *testing.T) {
+	e := echo.New()
+	e.Use(FormatNegotiationWithConfig(FormatNegotiationConfig{ExposeDecision: true}))
+	e.GET("/data", func(c *echo.Context) error {
+		c.Request().Header.Set("Accept", "*/*")
+		return Negotiate(c, http.StatusOK, map[string]string{"ok": "yes"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/cbor")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(HeaderNegotiated); got != "cbor (explicit)" {
+		t.Fatalf("expected cbor (explicit), got %q", got)
+	}
+}
+
+func TestNegotiationReason_SingleRangeDoesNotAllocate(t *testing.T) {
+	if n := testing.AllocsPerRun(100, func() { negotiationReason("application/cbor", FormatCBOR) }); n != 0 {
+		t.Fatalf("expected no allocations, got %v", n)
+	}
+}
+
+func TestFormatNegotiation_DecisionHiddenByDefault(t *testing.T) {
+	e := echo.New()
+	e.Use(FormatNegotiation())
+	e.GET("/data", func(c *echo.Context) error {
+		return Negotiate(c, http.StatusOK, map[string]string{"ok": "yes"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/cbor")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(HeaderNegotiated); got != "" {
+		t.Fatalf("expected no X-Negotiated header, got %q", got)
+	}
+}
+
+func TestNegotiate_RecordsNegotiatedFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		format string
+		reason string
+	}{
+		{"application/cbor", "cbor", "explicit"},
+		{"", "json", "default"},
+		{"application/json", "json", "explicit"},
+		{"*/*", "json", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.reason, func(t *testing.T) {
+			mem := metrics.NewMemory()
+			metrics.SetRecorder(mem)
+			t.Cleanup(func() { metrics.SetRecorder(nil) })
+
+			e := echo.New()
+			e.Use(FormatNegotiation())
+			e.GET("/data", func(c *echo.Context) error {
+				return Negotiate(c, http.StatusOK, map[string]string{"ok": "yes"})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/data", nil)
+			req.Header.Set("Accept", tt.accept)
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			labels := metrics.Labels{"format": tt.format, "reason": tt.reason}
+			if got := mem.Count(metrics.NegotiatedFormats, labels); got != 1 {
+				t.Fatalf("expected %s/%s counted once, got %d", tt.format, tt.reason, got)
+			}
+		})
+	}
+}
+
+func TestNegotiate_RecordsFallback(t *testing.T) {
+	mem := metrics.NewMemory()
+	metrics.SetRecorder(mem)
+	t.Cleanup(func() { metrics.SetRecorder(nil) })
+
+	e := echo.New()
+	e.GET("/data", func(c *echo.Context) error {
+		return Negotiate(c, http.StatusOK, jsonOnly{})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/cbor")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := mem.Count(metrics.NegotiatedFormats, metrics.Labels{"format": "json", "reason": "fallback"}); got != 1 {
+		t.Fatalf("expected json/fallback counted once, got %d", got)
+	}
+	if got := mem.Count(metrics.NegotiatedFormats, metrics.Labels{"format": "cbor", "reason": "explicit"}); got != 0 {
+		t.Fatalf("expected no cbor/explicit count for a fallback, got %d", got)
+	}
+}
+
+func TestAcceptParam(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"application/json;version=2", "2", true},
+		{"application/cbor;version=3, application/json;version=2;q=0.5", "3", true},
+		{"application/cbor;version=3;q=0.5, application/json;version=2", "2", true},
+		{"application/json;version=1;q=0.5, application/vnd.api+json;version=2", "2", true},
+		{"application/json;version=2, */*;version=9", "2", true},
+		{"application/json;version=2;q=0, */*", "", false},
+		{"application/json", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+
+			got, ok := AcceptParam(req, "Version")
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
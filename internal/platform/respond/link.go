@@ -0,0 +1,23 @@
+package respond
+
+import (
+	"github.com/labstack/echo/v5"
+)
+
+// AddLink appends an RFC 8288 link to target with relation rel to the Link
+// response header, keeping the links already set in a single header value.
+func AddLink(c *echo.Context, target, rel string) {
+	h := c.Response().Header()
+	link := "<" + target + `>; rel="` + rel + `"`
+	if existing := h.Get("Link"); existing != "" {
+		link = existing + ", " + link
+	}
+	h.Set("Link", link)
+}
+
+// AddSelfLinks adds rel="self" for the request URI and rel="describedby" for
+// describedBy, the URL of the API description, to the Link response header.
+func AddSelfLinks(c *echo.Context, describedBy string) {
+	AddLink(c, c.Request().URL.RequestURI(), "self")
+	AddLink(c, describedBy, "describedby")
+}
@@ -0,0 +1,28 @@
+package respond
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestAddSelfLinks(t *testing.T) {
+	e := echo.New()
+	e.GET("/v1/items/:id", func(c *echo.Context) error {
+		AddLink(c, "/v1/items", "collection")
+		AddSelfLinks(c, "/api-docs/openapi.json")
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/items/item-001?fields=name", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	want := `</v1/items>; rel="collection", </v1/items/item-001?fields=name>; rel="self", ` +
+		`</api-docs/openapi.json>; rel="describedby"`
+	if got := rec.Header().Get("Link"); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,83 @@
+package respond
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// LocationConfig configures the Locations middleware.
+type LocationConfig struct {
+	// Absolute makes SetLocation emit fully-qualified URLs such as
+	// "https://api.example.com/v1/profile" instead of "/v1/profile".
+	Absolute bool
+}
+
+type ctxLocationKey struct{}
+
+// Locations returns Echo middleware that configures how SetLocation builds
+// Location headers for requests in its group. Without it, Location values
+// are relative.
+func Locations(cfg LocationConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxLocationKey{}, cfg)))
+			return next(c)
+		}
+	}
+}
+
+// SetLocation sets the Location header to path, an absolute path such as
+// "/v1/profile". When the Locations middleware enables absolute URLs, the
+// scheme and host the client used are prepended.
+func SetLocation(c *echo.Context, path string) {
+	req := c.Request()
+	cfg, _ := req.Context().Value(ctxLocationKey{}).(LocationConfig)
+	if cfg.Absolute {
+		path = AbsoluteURL(req, path)
+	}
+	c.Response().Header().Set("Location", path)
+}
+
+// AbsoluteURL returns path qualified with the scheme and host the client used
+// to reach the server. The first X-Forwarded-Proto and X-Forwarded-Host values
+// are honored when well-formed; otherwise the scheme comes from the connection
+// and the host from the Host header.
+func AbsoluteURL(r *http.Request, path string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	host := r.Host
+	fwdHost, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Host"), ",")
+	if fwdHost = strings.TrimSpace(fwdHost); validHost(fwdHost) {
+		host = fwdHost
+	}
+
+	return scheme + "://" + host + path
+}
+
+// validHost reports whether s looks like a host with an optional port, with
+// no characters that could change the meaning of a URL.
+func validHost(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == ':', r == '[', r == ']':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package respond
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func setupLocationEcho(mw ...echo.MiddlewareFunc) *echo.Echo {
+	e := echo.New()
+	e.Use(mw...)
+	e.POST("/v1/profile", func(c *echo.Context) error {
+		SetLocation(c, "/v1/profile")
+		return c.NoContent(http.StatusCreated)
+	})
+	return e
+}
+
+func TestSetLocation_RelativeByDefault(t *testing.T) {
+	e := setupLocationEcho()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/profile", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Location"); got != "/v1/profile" {
+		t.Fatalf("expected relative Location, got %q", got)
+	}
+}
+
+func TestSetLocation_Absolute(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"host header", nil, "http://example.com/v1/profile"},
+		{
+			"forwarded proto",
+			map[string]string{"X-Forwarded-Proto": "HTTPS"},
+			"https://example.com/v1/profile",
+		},
+		{
+			"forwarded proto and host",
+			map[string]string{"X-Forwarded-Proto": "https, http", "X-Forwarded-Host": "api.example.com:8443"},
+			"https://api.example.com:8443/v1/profile",
+		},
+		{
+			"invalid forwarded proto",
+			map[string]string{"X-Forwarded-Proto": "javascript"},
+			"http://example.com/v1/profile",
+		},
+		{
+			"invalid forwarded host",
+			map[string]string{"X-Forwarded-Host": "evil.example/path@x"},
+			"http://example.com/v1/profile",
+		},
+	}
+	e := setupLocationEcho(Locations(LocationConfig{Absolute: true}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/profile", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Location"); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+// ItemError reports the failure of one item of a batch operation within a
+// Problem Details response.
+type ItemError struct {
+	Index  int           `json:"index"            cbor:"index"            example:"2"`
+	Status int           `json:"status"           cbor:"status"           example:"409"`
+	Detail string        `json:"detail,omitempty" cbor:"detail,omitempty" example:"item already exists"`
+	Errors []ErrorDetail `json:"errors,omitempty" cbor:"errors,omitempty"`
+}
+
+// MultiError aggregates independent failures of batch items so a handler can
+// report them in one response. Returned from a handler, it is rendered as
+// Problem Details whose failures member lists each item with its own status.
+// The response status is the items' status when they all share one, 400 when
+// they are all client errors, and 500 otherwise.
+type MultiError struct {
+	Items []ItemError
+}
+
+// Add records the failure of the item at index with status and detail.
+func (m *MultiError) Add(index, status int, detail string) {
+	m.Items = append(m.Items, ItemError{Index: index, Status: status, Detail: detail})
+}
+
+// AddError records err as the failure of the item at index. Problem Details
+// and validation errors keep their status and field errors; other errors are
+// converted with FromError and mappings, so unmapped errors are logged and
+// reported as 500 without exposing their message. A nil err is ignored.
+func (m *MultiError) AddError(ctx context.Context, index int, err error, mappings ...ErrorMapping) {
+	if err == nil {
+		return
+	}
+	var ve *validate.ValidationError
+	if errors.As(err, &ve) {
+		item := ItemError{Index: index, Status: http.StatusUnprocessableEntity, Detail: ve.Message}
+		for _, f := range ve.Fields {
+			item.Errors = append(item.Errors, ErrorDetail{Message: f.Message, Location: f.Field, Value: f.Value})
+		}
+		m.Items = append(m.Items, item)
+		return
+	}
+
+	var pd *ProblemDetails
+	if !errors.As(err, &pd) {
+		pd = problemFromError(ctx, err, mappings)
+	}
+	m.Items = append(m.Items, ItemError{Index: index, Status: pd.Status, Detail: pd.Detail, Errors: pd.Errors})
+}
+
+// Err returns m when it holds failures and nil otherwise, so handlers can
+// return it unconditionally.
+func (m *MultiError) Err() error {
+	if len(m.Items) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error implements the error interface.
+func (m *MultiError) Error() string {
+	return fmt.Sprintf("%d items failed", len(m.Items))
+}
+
+// StatusCode implements echo.HTTPStatusCoder.
+func (m *MultiError) StatusCode() int {
+	if len(m.Items) == 0 {
+		return http.StatusInternalServerError
+	}
+	status := m.Items[0].Status
+	clientErrors := true
+	for _, item := range m.Items {
+		if item.Status != status {
+			status = 0
+		}
+		if item.Status < 400 || item.Status >= 500 {
+			clientErrors = false
+		}
+	}
+	switch {
+	case status != 0:
+		return status
+	case clientErrors:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// problem returns the Problem Details representation of m.
+func (m *MultiError) problem() ProblemDetails {
+	status := m.StatusCode()
+	return ProblemDetails{
+		Type:     "about:blank",
+		Title:    StatusTitle(status),
+		Status:   status,
+		Detail:   m.Error(),
+		Failures: m.Items,
+	}
+}
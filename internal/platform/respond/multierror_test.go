@@ -0,0 +1,109 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+var errDuplicate = errors.New("duplicate")
+
+func TestMultiError_Response(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.POST("/batch", func(c *echo.Context) error {
+		var m MultiError
+		m.AddError(c.Request().Context(), 0, nil)
+		m.AddError(c.Request().Context(), 1, errDuplicate,
+			ErrorMapping{Err: errDuplicate, Status: http.StatusConflict, Detail: "item already exists"})
+		m.AddError(c.Request().Context(), 3, &validate.ValidationError{
+			Message: "validation failed",
+			Fields:  []validate.FieldError{{Field: "name", Message: "name is required"}},
+		})
+		return m.Err()
+	})
+
+	decoders := map[string]func([]byte, any) error{
+		"application/json": json.Unmarshal,
+		"application/cbor": cbor.Unmarshal,
+	}
+	for accept, unmarshal := range decoders {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/batch", nil)
+			req.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400 for mixed client errors, got %d", rec.Code)
+			}
+			var problem ProblemDetails
+			if err := unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if len(problem.Failures) != 2 {
+				t.Fatalf("expected 2 failures, got %+v", problem.Failures)
+			}
+			conflict, invalid := problem.Failures[0], problem.Failures[1]
+			if conflict.Index != 1 || conflict.Status != http.StatusConflict || conflict.Detail != "item already exists" {
+				t.Fatalf("unexpected first failure %+v", conflict)
+			}
+			if invalid.Index != 3 || invalid.Status != http.StatusUnprocessableEntity {
+				t.Fatalf("unexpected second failure %+v", invalid)
+			}
+			if len(invalid.Errors) != 1 || invalid.Errors[0].Location != "name" {
+				t.Fatalf("expected field errors on the second failure, got %+v", invalid.Errors)
+			}
+		})
+	}
+}
+
+func TestMultiError_StatusCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		want     int
+	}{
+		{"shared status", []int{409, 409}, http.StatusConflict},
+		{"mixed client errors", []int{404, 422}, http.StatusBadRequest},
+		{"server error", []int{422, 503}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m MultiError
+			for i, status := range tt.statuses {
+				m.Add(i, status, "")
+			}
+			if got := m.StatusCode(); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMultiError_UnmappedErrorStaysInternal(t *testing.T) {
+	var m MultiError
+	m.AddError(context.Background(), 0, errors.New("connection reset by peer"))
+
+	if len(m.Items) != 1 || m.Items[0].Status != http.StatusInternalServerError {
+		t.Fatalf("expected a 500 failure, got %+v", m.Items)
+	}
+	if m.Items[0].Detail == "connection reset by peer" {
+		t.Fatal("expected the error message to stay internal")
+	}
+}
+
+func TestMultiError_ErrEmpty(t *testing.T) {
+	var m MultiError
+	if err := m.Err(); err != nil {
+		t.Fatalf("expected nil error without failures, got %v", err)
+	}
+}
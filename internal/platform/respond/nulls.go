@@ -0,0 +1,202 @@
+package respond
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// preferOptionalNull is the Prefer token asking for optional fields to be
+// sent as null instead of being omitted.
+const preferOptionalNull = "optional=null"
+
+// OptionalFielder is implemented by response types with optional fields that
+// are not tagged omitempty. OptionalFields lists their JSON names; in a full
+// representation those fields are sent as null when empty.
+type OptionalFielder interface {
+	OptionalFields() []string
+}
+
+type ctxFullRepresentationKey struct{}
+
+// FullRepresentation returns Echo middleware that makes Negotiate send every
+// optional field in JSON responses, using null for empty values, for legacy
+// clients that reject missing fields. Attach it to route groups serving such
+// clients; others can opt in per request with Prefer: optional=null.
+func FullRepresentation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxFullRepresentationKey{}, true)))
+			return next(c)
+		}
+	}
+}
+
+// wantsFullRepresentation reports whether the request asked for a full
+// representation with a Prefer header and whether FullRepresentation forces
+// one regardless.
+func wantsFullRepresentation(r *http.Request) (preferred, forced bool) {
+	v, ok := Preference(r, "optional")
+	preferred = ok && strings.EqualFold(v, "null")
+	forced, _ = r.Context().Value(ctxFullRepresentationKey{}).(bool)
+	return preferred, forced
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// marshalFull encodes v like json.Marshal, except that struct fields tagged
+// omitempty or omitzero, or listed by OptionalFielder, are written as null
+// when they would otherwise be omitted or empty. Types with their own JSON or
+// text marshaling and map values are encoded unchanged. Unlike json.Marshal,
+// fields promoted from unexported embedded structs are skipped.
+func marshalFull(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeFull(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeFull(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return encodePlain(buf, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeFull(buf, v.Elem())
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		if err := encodeFields(buf, v, &first); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	case reflect.Slice:
+		if v.IsNil() || t.Elem().Kind() == reflect.Uint8 {
+			return encodePlain(buf, v)
+		}
+		fallthrough
+	case reflect.Array:
+		buf.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeFull(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		return encodePlain(buf, v)
+	}
+}
+
+func encodeFields(buf *bytes.Buffer, v reflect.Value, first *bool) error {
+	t := v.Type()
+	var optional []string
+	if of, ok := v.Interface().(OptionalFielder); ok {
+		optional = of.OptionalFields()
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if f.Anonymous && name == "" && f.IsExported() {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := encodeFields(buf, fv, first); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if !*first {
+			buf.WriteByte(',')
+		}
+		*first = false
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		omitempty := strings.Contains(","+opts+",", ",omitempty,") || slices.Contains(optional, name)
+		omitzero := strings.Contains(","+opts+",", ",omitzero,")
+		if (omitempty && isEmptyValue(fv)) || (omitzero && fv.IsZero()) {
+			buf.WriteString("null")
+			continue
+		}
+		if err := encodeFull(buf, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodePlain(buf *bytes.Buffer, v reflect.Value) error {
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// isEmptyValue mirrors the omitempty rule of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+package respond
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/timeutil"
+)
+
+type legacyContact struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+func (legacyContact) OptionalFields() []string { return []string{"phone"} }
+
+type LegacyAudit struct {
+	UpdatedBy string `json:"updatedBy,omitempty"`
+}
+
+type legacyProfile struct {
+	LegacyAudit
+	ID        string          `json:"id"`
+	Nickname  string          `json:"nickname,omitempty"`
+	Avatar    *string         `json:"avatar,omitempty"`
+	Tags      []string        `json:"tags,omitempty"`
+	Deleted   timeutil.Time   `json:"deleted,omitzero"`
+	Contacts  []legacyContact `json:"contacts"`
+	Secret    string          `json:"-"`
+	CreatedAt timeutil.Time   `json:"createdAt"`
+	internal  string
+}
+
+func TestMarshalFull(t *testing.T) {
+	created := timeutil.NewTime(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	nick := "jd"
+
+	tests := []struct {
+		name string
+		data any
+		want string
+	}{
+		{
+			"empty optionals are null",
+			legacyProfile{
+				ID:        "u1",
+				Contacts:  []legacyContact{{Email: "a@example.com"}},
+				Secret:    "s",
+				CreatedAt: created,
+				internal:  "x",
+			},
+			`{"updatedBy":null,"id":"u1","nickname":null,"avatar":null,"tags":null,"deleted":null,` +
+				`"contacts":[{"email":"a@example.com","phone":null}],"createdAt":"2024-01-15T10:30:00.000Z"}`,
+		},
+		{
+			"set optionals are kept",
+			&legacyProfile{
+				LegacyAudit: LegacyAudit{UpdatedBy: "admin"},
+				ID:          "u2",
+				Nickname:    "n",
+				Avatar:      &nick,
+				Tags:        []string{"a"},
+				Contacts:    []legacyContact{{Email: "b@example.com", Phone: "+358401234567"}},
+				CreatedAt:   created,
+			},
+			`{"updatedBy":"admin","id":"u2","nickname":"n","avatar":"jd","tags":["a"],"deleted":null,` +
+				`"contacts":[{"email":"b@example.com","phone":"+358401234567"}],"createdAt":"2024-01-15T10:30:00.000Z"}`,
+		},
+		{"nil", nil, `null`},
+		{"map", map[string]int{"n": 1}, `{"n":1}`},
+		{"slice of structs", []legacyContact{{Email: "c"}}, `[{"email":"c","phone":null}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshalFull(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("expected\n%s\ngot\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNegotiate_FullRepresentation(t *testing.T) {
+	contact := legacyContact{Email: "a@example.com"}
+
+	tests := []struct {
+		name    string
+		group   bool
+		prefer  string
+		want    string
+		applied string
+	}{
+		{"default", false, "", `{"email":"a@example.com","phone":""}`, ""},
+		{"prefer header", false, "optional=null", `{"email":"a@example.com","phone":null}`, "optional=null"},
+		{"route group", true, "", `{"email":"a@example.com","phone":null}`, ""},
+		{"other preference", false, "return=representation", `{"email":"a@example.com","phone":""}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			g := e.Group("")
+			if tt.group {
+				g.Use(FullRepresentation())
+			}
+			g.GET("/contact", func(c *echo.Context) error {
+				return Negotiate(c, http.StatusOK, contact)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/contact", nil)
+			if tt.prefer != "" {
+				req.Header.Set("Prefer", tt.prefer)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", rec.Code)
+			}
+			if got := strings.TrimSuffix(rec.Body.String(), "\n"); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+			if got := rec.Header().Get("Preference-Applied"); got != tt.applied {
+				t.Fatalf("expected Preference-Applied %q, got %q", tt.applied, got)
+			}
+			if varies := rec.Header().Get("Vary") == "Prefer"; varies != !tt.group {
+				t.Fatalf("expected Vary: Prefer unless the route forces the full representation, got %q",
+					rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
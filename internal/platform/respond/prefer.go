@@ -0,0 +1,50 @@
+package respond
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// RFC 7240 header names.
+const (
+	HeaderPrefer            = "Prefer"
+	HeaderPreferenceApplied = "Preference-Applied"
+)
+
+// preferReturnMinimal is the Prefer token asking for a response without a body.
+const preferReturnMinimal = "return=minimal"
+
+// Preference returns the value of the named preference from the request's
+// Prefer headers and whether it was present. Names are case-insensitive and
+// the first occurrence wins, per RFC 7240. Preference parameters are ignored.
+func Preference(r *http.Request, name string) (string, bool) {
+	for _, v := range r.Header.Values(HeaderPrefer) {
+		for pref := range strings.SplitSeq(v, ",") {
+			pref, _, _ = strings.Cut(pref, ";")
+			key, value, _ := strings.Cut(pref, "=")
+			if strings.EqualFold(strings.TrimSpace(key), name) {
+				return strings.Trim(strings.TrimSpace(value), `"`), true
+			}
+		}
+	}
+	return "", false
+}
+
+// ReturnMinimal reports whether the client sent Prefer: return=minimal.
+func ReturnMinimal(r *http.Request) bool {
+	v, ok := Preference(r, "return")
+	return ok && strings.EqualFold(v, "minimal")
+}
+
+// NegotiateOrMinimal writes data like Negotiate unless the client prefers
+// return=minimal, in which case only the status and headers are sent and
+// Preference-Applied confirms the preference was honored.
+func NegotiateOrMinimal(c *echo.Context, status int, data any) error {
+	if ReturnMinimal(c.Request()) {
+		c.Response().Header().Add(HeaderPreferenceApplied, preferReturnMinimal)
+		return c.NoContent(status)
+	}
+	return Negotiate(c, status, data)
+}
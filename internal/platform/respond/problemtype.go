@@ -0,0 +1,95 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+)
+
+// ProblemTypeBase prefixes registered problem type codes to form the Type
+// URI. RFC 9457 resolves it against the request URI.
+const ProblemTypeBase = "/problems/"
+
+// ProblemTypeValidation is the problem type of requests that fail schema or
+// field validation, registered by this package. Business-rule violations
+// answered with 422 register their own types, so clients can tell the two
+// apart.
+const ProblemTypeValidation = "validation-error"
+
+// ErrInvalidTypeCode is returned by RegisterProblemType for codes that are
+// empty or contain empty segments.
+var ErrInvalidTypeCode = errors.New("invalid problem type code")
+
+// problemType is a registered problem type.
+type problemType struct {
+	status int
+	title  string
+}
+
+var (
+	problemTypesMu sync.RWMutex
+	problemTypes   = map[string]problemType{
+		ProblemTypeValidation: {status: http.StatusUnprocessableEntity, title: "Validation Error"},
+	}
+)
+
+// RegisterProblemType registers code as a problem type answered with status
+// and title. Codes are slash-separated paths from the most general category
+// to the most specific node, such as "validation/email/format"; ancestors need
+// not be registered themselves. Registering a code again replaces it.
+func RegisterProblemType(code string, status int, title string) error {
+	if code == "" || strings.Contains("/"+code+"/", "//") {
+		return ErrInvalidTypeCode
+	}
+	problemTypesMu.Lock()
+	defer problemTypesMu.Unlock()
+	problemTypes[code] = problemType{status: status, title: title}
+	return nil
+}
+
+// MustRegisterProblemType is like RegisterProblemType but panics when code is
+// invalid. It returns code so packages can register their types in a
+// package-level declaration, before any handler can reach TypedError.
+func MustRegisterProblemType(code string, status int, title string) string {
+	if err := RegisterProblemType(code, status, title); err != nil {
+		panic(fmt.Sprintf("respond: register problem type %q: %v", code, err))
+	}
+	return code
+}
+
+// TypedError creates a ProblemDetails error for the registered type code.
+// Type is the URI of the code itself, and the category extension member lists
+// the code and its ancestors from the top-level category down, so clients can
+// roll errors up at any level. Unregistered codes are logged and reported as
+// a generic 500.
+func TypedError(ctx context.Context, code, detail string) *ProblemDetails {
+	problemTypesMu.RLock()
+	pt, ok := problemTypes[code]
+	problemTypesMu.RUnlock()
+	if !ok {
+		applog.LogError(ctx, "unregistered problem type", ErrInvalidTypeCode, slog.String("code", code))
+		return Error500("internal error")
+	}
+
+	p := NewErrorWithTitle(pt.status, pt.title, detail)
+	p.Type = ProblemTypeBase + code
+	p.Category = categoryPath(code)
+	return p
+}
+
+// categoryPath returns code and its ancestors, most general first.
+func categoryPath(code string) []string {
+	var path []string
+	for i, r := range code {
+		if r == '/' {
+			path = append(path, code[:i])
+		}
+	}
+	return append(path, code)
+}
@@ -0,0 +1,108 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestTypedError_NestedCode(t *testing.T) {
+	if err := RegisterProblemType("validation/email/format", http.StatusUnprocessableEntity,
+		"Invalid Email Format"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.POST("/signup", func(c *echo.Context) error {
+		return TypedError(c.Request().Context(), "validation/email/format", "email must contain @")
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/signup", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	var problem ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if problem.Type != "/problems/validation/email/format" {
+		t.Fatalf("expected the most specific type URI, got %q", problem.Type)
+	}
+	if problem.Title != "Invalid Email Format" || problem.Detail != "email must contain @" {
+		t.Fatalf("unexpected title or detail: %+v", problem)
+	}
+	want := []string{"validation", "validation/email", "validation/email/format"}
+	if !slices.Equal(problem.Category, want) {
+		t.Fatalf("expected category %v, got %v", want, problem.Category)
+	}
+}
+
+func TestTypedError_TopLevelCode(t *testing.T) {
+	if err := RegisterProblemType("quota", http.StatusTooManyRequests, "Quota Exceeded"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	p := TypedError(context.Background(), "quota", "")
+	if p.Type != "/problems/quota" || !slices.Equal(p.Category, []string{"quota"}) {
+		t.Fatalf("unexpected problem %+v", p)
+	}
+}
+
+func TestTypedError_Unregistered(t *testing.T) {
+	p := TypedError(context.Background(), "validation/unknown", "detail")
+	if p.Status != http.StatusInternalServerError || p.Type != "about:blank" || p.Category != nil {
+		t.Fatalf("expected a generic 500, got %+v", p)
+	}
+}
+
+func TestRegisterProblemType_InvalidCode(t *testing.T) {
+	for _, code := range []string{"", "/validation", "validation/", "validation//email"} {
+		if err := RegisterProblemType(code, http.StatusBadRequest, "Bad"); !errors.Is(err, ErrInvalidTypeCode) {
+			t.Fatalf("code %q: expected ErrInvalidTypeCode, got %v", code, err)
+		}
+	}
+}
+
+func TestMustRegisterProblemType(t *testing.T) {
+	if code := MustRegisterProblemType("business/locked", http.StatusConflict, "Locked"); code != "business/locked" {
+		t.Fatalf("expected the code back, got %q", code)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for an invalid code")
+		}
+	}()
+	MustRegisterProblemType("business//locked", http.StatusConflict, "Locked")
+}
+
+func TestFromError_TypedMapping(t *testing.T) {
+	if err := RegisterProblemType("business/quota-exceeded", http.StatusUnprocessableEntity,
+		"Quota Exceeded"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	errQuota := errors.New("quota exceeded")
+	mappings := []ErrorMapping{
+		{Err: errQuota, Type: "business/quota-exceeded", Detail: "quota exceeded"},
+	}
+
+	var p *ProblemDetails
+	if !errors.As(FromError(context.Background(), fmt.Errorf("create: %w", errQuota), mappings...), &p) {
+		t.Fatal("expected ProblemDetails")
+	}
+	if p.Status != http.StatusUnprocessableEntity || p.Type != "/problems/business/quota-exceeded" {
+		t.Fatalf("expected the registered type, got %+v", p)
+	}
+	if p.Detail != "quota exceeded" {
+		t.Fatalf("expected the mapping detail, got %q", p.Detail)
+	}
+}
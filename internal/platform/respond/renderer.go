@@ -0,0 +1,91 @@
+package respond
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// ProblemRenderer renders Problem Details in an alternative error format,
+// such as a partner's vendor-specific media type.
+type ProblemRenderer interface {
+	// MediaType is the media type clients request via Accept, e.g.
+	// "application/vnd.acme.error+json".
+	MediaType() string
+	// Render returns the Content-Type and body for problem.
+	Render(problem ProblemDetails) (string, []byte, error)
+}
+
+// JSONProblemRenderer renders problems as JSON after remapping them to a
+// different schema with Map.
+type JSONProblemRenderer struct {
+	Type string
+	Map  func(ProblemDetails) any
+}
+
+// MediaType implements ProblemRenderer.
+func (r JSONProblemRenderer) MediaType() string {
+	return r.Type
+}
+
+// Render implements ProblemRenderer.
+func (r JSONProblemRenderer) Render(problem ProblemDetails) (string, []byte, error) {
+	b, err := json.Marshal(r.Map(problem))
+	if err != nil {
+		return "", nil, err
+	}
+	return r.Type, b, nil
+}
+
+// ProblemRendererConfig configures the ProblemRenderers middleware.
+type ProblemRendererConfig struct {
+	// Renderers are used when the Accept header ranks their media type above
+	// JSON and CBOR.
+	Renderers []ProblemRenderer
+	// Default, when set, renders every error in the group regardless of
+	// Accept.
+	Default ProblemRenderer
+}
+
+type problemRenderers struct {
+	cfg ProblemRendererConfig
+}
+
+type ctxRenderersKey struct{}
+
+// ProblemRenderers returns Echo middleware that makes the configured
+// renderers available to the error handler and Recoverer for requests in its
+// group. Errors use application/problem+json unless a renderer is selected.
+func ProblemRenderers(cfg ProblemRendererConfig) echo.MiddlewareFunc {
+	renderers := &problemRenderers{cfg: cfg}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxRenderersKey{}, renderers)))
+			return next(c)
+		}
+	}
+}
+
+// selectRenderer returns the renderer to use for the request, or nil for the
+// built-in formats.
+func selectRenderer(ctx context.Context, accept string) ProblemRenderer {
+	renderers, _ := ctx.Value(ctxRenderersKey{}).(*problemRenderers)
+	if renderers == nil {
+		return nil
+	}
+	if renderers.cfg.Default != nil {
+		return renderers.cfg.Default
+	}
+	for _, renderer := range renderers.cfg.Renderers {
+		typ, subtype, _ := strings.Cut(strings.ToLower(renderer.MediaType()), "/")
+		if prefersOverAPI(accept, func(mr mediaRange) bool {
+			return mr.typ == typ && mr.subtype == subtype
+		}) {
+			return renderer
+		}
+	}
+	return nil
+}
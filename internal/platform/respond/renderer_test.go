@@ -0,0 +1,142 @@
+package respond
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+const acmeErrorType = "application/vnd.acme.error+json"
+
+type acmeError struct {
+	Code    int    `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+var acmeRenderer = JSONProblemRenderer{
+	Type: acmeErrorType,
+	Map: func(p ProblemDetails) any {
+		return acmeError{Code: p.Status, Reason: p.Title, Message: p.Detail, Path: p.Instance}
+	},
+}
+
+func setupRendererEcho(cfg ProblemRendererConfig) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.Use(Recoverer())
+	g := e.Group("/partner", ProblemRenderers(cfg))
+	g.GET("/missing", func(c *echo.Context) error {
+		return Error404("widget not found")
+	})
+	g.GET("/panic", func(c *echo.Context) error {
+		panic("boom")
+	})
+	e.GET("/missing", func(c *echo.Context) error {
+		return Error404("widget not found")
+	})
+	return e
+}
+
+func TestProblemRenderers_NegotiatesVendorType(t *testing.T) {
+	e := setupRendererEcho(ProblemRendererConfig{Renderers: []ProblemRenderer{acmeRenderer}})
+
+	req := httptest.NewRequest(http.MethodGet, "/partner/missing", nil)
+	req.Header.Set("Accept", acmeErrorType)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != acmeErrorType {
+		t.Fatalf("expected %s, got %q", acmeErrorType, ct)
+	}
+	var body acmeError
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	want := acmeError{Code: 404, Reason: "Not Found", Message: "widget not found", Path: "/partner/missing"}
+	if body != want {
+		t.Fatalf("expected %+v, got %+v", want, body)
+	}
+}
+
+func TestProblemRenderers_DefaultStaysProblemJSON(t *testing.T) {
+	e := setupRendererEcho(ProblemRendererConfig{Renderers: []ProblemRenderer{acmeRenderer}})
+
+	for _, accept := range []string{"", "application/json", "application/json, " + acmeErrorType + ";q=0.5"} {
+		t.Run(accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/partner/missing", nil)
+			req.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Fatalf("expected application/problem+json, got %q", ct)
+			}
+			var problem ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if problem.Status != http.StatusNotFound || problem.Detail != "widget not found" {
+				t.Fatalf("expected RFC 9457 body, got %+v", problem)
+			}
+		})
+	}
+}
+
+func TestProblemRenderers_OutsideGroupIgnored(t *testing.T) {
+	e := setupRendererEcho(ProblemRendererConfig{Default: acmeRenderer})
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept", acmeErrorType)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", ct)
+	}
+}
+
+func TestProblemRenderers_DefaultRendererForGroup(t *testing.T) {
+	e := setupRendererEcho(ProblemRendererConfig{Default: acmeRenderer})
+
+	req := httptest.NewRequest(http.MethodGet, "/partner/panic", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != acmeErrorType {
+		t.Fatalf("expected %s, got %q", acmeErrorType, ct)
+	}
+	var body acmeError
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if body.Code != http.StatusInternalServerError || body.Message != "internal server error" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+}
+
+func TestProblemRenderers_RenderErrorFallsBack(t *testing.T) {
+	failing := JSONProblemRenderer{
+		Type: acmeErrorType,
+		Map:  func(ProblemDetails) any { return func() {} },
+	}
+	e := setupRendererEcho(ProblemRendererConfig{Default: failing})
+
+	req := httptest.NewRequest(http.MethodGet, "/partner/missing", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected fallback to application/problem+json, got %q", ct)
+	}
+}
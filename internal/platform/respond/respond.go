@@ -1,29 +1,43 @@
 package respond
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
+	"iter"
 	"log/slog"
 	"net/http"
-	"runtime/debug"
+	"runtime"
 	"strconv"
 	"strings"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/metrics"
 	"github.com/janisto/echo-playground/internal/platform/validate"
 )
 
 // mediaRange represents a parsed Accept header media range with quality value.
+// params holds the media type parameters preceding q, keyed by lowercase name,
+// such as version in application/json;version=2. It is nil when there are none.
 type mediaRange struct {
 	typ     string
 	subtype string
 	q       float64
+	params  map[string]string
 }
 
+// maxAcceptRanges bounds how many media ranges parseAccept reads, so an
+// oversized Accept header cannot make negotiation expensive.
+const maxAcceptRanges = 50
+
 // parseAccept parses an Accept header value into media ranges per RFC 9110.
+// Only the first maxAcceptRanges non-empty ranges are parsed; the rest are ignored.
 func parseAccept(header string) []mediaRange {
 	if header == "" {
 		return nil
@@ -31,6 +45,9 @@ func parseAccept(header string) []mediaRange {
 
 	var ranges []mediaRange
 	for part := range strings.SplitSeq(header, ",") {
+		if len(ranges) == maxAcceptRanges {
+			break
+		}
 		part = strings.TrimSpace(part)
 		if part == "" {
 			continue
@@ -40,13 +57,27 @@ func parseAccept(header string) []mediaRange {
 		mediaType := part
 		if before, after, ok := strings.Cut(part, ";"); ok {
 			mediaType = strings.TrimSpace(before)
+			weighted := false
 			for param := range strings.SplitSeq(after, ";") {
 				param = strings.TrimSpace(param)
 				if strings.HasPrefix(strings.ToLower(param), "q=") {
 					if qval, err := strconv.ParseFloat(param[2:], 64); err == nil && qval >= 0 && qval <= 1 {
 						mr.q = qval
 					}
+					// Parameters after q are accept extensions, not part of
+					// the media type.
+					weighted = true
+					continue
+				}
+				name, value, ok := strings.Cut(param, "=")
+				name = strings.ToLower(strings.TrimSpace(name))
+				if !ok || name == "" || weighted {
+					continue
+				}
+				if mr.params == nil {
+					mr.params = make(map[string]string)
 				}
+				mr.params[name] = unquoteParam(strings.TrimSpace(value))
 			}
 		}
 
@@ -62,55 +93,109 @@ func parseAccept(header string) []mediaRange {
 	return ranges
 }
 
+// parseSingleAccept parses an Accept header holding exactly one media range
+// without parameters, the common "application/json" case, without the
+// allocations of parseAccept. It reports false for any other header, which
+// parseAccept must handle.
+func parseSingleAccept(header string) (mediaRange, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || strings.ContainsAny(header, ",;") {
+		return mediaRange{}, false
+	}
+	mr := mediaRange{q: 1.0}
+	if before, after, ok := strings.Cut(header, "/"); ok {
+		mr.typ = strings.ToLower(strings.TrimSpace(before))
+		mr.subtype = strings.ToLower(strings.TrimSpace(after))
+	} else {
+		mr.typ = strings.ToLower(header)
+		mr.subtype = "*"
+	}
+	return mr, true
+}
+
+// unquoteParam returns a parameter value with RFC 9110 quoted-string quoting
+// removed.
+func unquoteParam(v string) string {
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return v
+	}
+	v = v[1 : len(v)-1]
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) {
+			i++
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
+
+// formats reports which of JSON and CBOR the range selects and how
+// specifically it names them.
+func (mr mediaRange) formats() (matchesCBOR, matchesJSON bool, specificity int) {
+	switch {
+	case mr.typ == "application" && mr.subtype == "problem+cbor":
+		return true, false, 4
+	case mr.typ == "application" && mr.subtype == "problem+json":
+		return false, true, 4
+	case mr.typ == "application" && mr.subtype == "cbor":
+		return true, false, 3
+	case mr.typ == "application" && mr.subtype == "json":
+		return false, true, 3
+	case mr.typ == "application" && strings.HasSuffix(mr.subtype, "+cbor"):
+		return true, false, 3
+	case mr.typ == "application" && strings.HasSuffix(mr.subtype, "+json"):
+		return false, true, 3
+	case mr.typ == "application" && mr.subtype == "*":
+		return true, true, 2
+	case mr.typ == "*" && mr.subtype == "*":
+		return true, true, 1
+	}
+	return false, false, 0
+}
+
 // selectFormat determines the preferred response format based on Accept header.
-// Returns true for CBOR, false for JSON (default).
+// Returns FormatJSON when the header expresses no preference between the
+// supported formats, and FormatNone when every supported format is explicitly
+// excluded with q=0.
 // Per RFC 9110: q-value is the primary ranking factor, specificity is tie-breaker.
-func selectFormat(header string) bool {
-	ranges := parseAccept(header)
+func selectFormat(header string) Format {
+	if header == "" {
+		return FormatJSON
+	}
+	if mr, ok := parseSingleAccept(header); ok {
+		// A lone range at q=1 selects CBOR only when it names CBOR alone;
+		// wildcards tie and fall back to JSON.
+		if matchesCBOR, matchesJSON, _ := mr.formats(); matchesCBOR && !matchesJSON {
+			return FormatCBOR
+		}
+		return FormatJSON
+	}
+	return selectFormatRanges(parseAccept(header))
+}
+
+// selectFormatRanges ranks parsed media ranges for selectFormat.
+func selectFormatRanges(ranges []mediaRange) Format {
 	if len(ranges) == 0 {
-		return false
+		return FormatJSON
 	}
 
 	var cborQ, jsonQ float64 = -1, -1
 	cborSpecificity, jsonSpecificity := 0, 0
+	cborExcluded, jsonExcluded := false, false
 
 	for _, mr := range ranges {
+		matchesCBOR, matchesJSON, specificity := mr.formats()
+
 		if mr.q == 0 {
+			cborExcluded = cborExcluded || matchesCBOR
+			jsonExcluded = jsonExcluded || matchesJSON
 			continue
 		}
 
-		specificity := 0
-		matchesCBOR, matchesJSON := false, false
-
-		switch {
-		case mr.typ == "application" && mr.subtype == "problem+cbor":
-			matchesCBOR = true
-			specificity = 4
-		case mr.typ == "application" && mr.subtype == "problem+json":
-			matchesJSON = true
-			specificity = 4
-		case mr.typ == "application" && mr.subtype == "cbor":
-			matchesCBOR = true
-			specificity = 3
-		case mr.typ == "application" && mr.subtype == "json":
-			matchesJSON = true
-			specificity = 3
-		case mr.typ == "application" && strings.HasSuffix(mr.subtype, "+cbor"):
-			matchesCBOR = true
-			specificity = 3
-		case mr.typ == "application" && strings.HasSuffix(mr.subtype, "+json"):
-			matchesJSON = true
-			specificity = 3
-		case mr.typ == "application" && mr.subtype == "*":
-			matchesCBOR = true
-			matchesJSON = true
-			specificity = 2
-		case mr.typ == "*" && mr.subtype == "*":
-			matchesCBOR = true
-			matchesJSON = true
-			specificity = 1
-		}
-
 		if matchesCBOR && (specificity > cborSpecificity || (specificity == cborSpecificity && mr.q > cborQ)) {
 			cborQ = mr.q
 			cborSpecificity = specificity
@@ -122,19 +207,111 @@ func selectFormat(header string) bool {
 	}
 
 	if cborQ <= 0 && jsonQ <= 0 {
-		return false
+		if cborExcluded && jsonExcluded {
+			return FormatNone
+		}
+		return FormatJSON
 	}
 
 	if cborQ > jsonQ {
-		return true
+		return FormatCBOR
 	}
 	if jsonQ > cborQ {
-		return false
+		return FormatJSON
 	}
 	if cborSpecificity > jsonSpecificity {
-		return true
+		return FormatCBOR
 	}
-	return false
+	return FormatJSON
+}
+
+// MIMEApplicationNDJSON is the media type for newline-delimited JSON streams.
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// ndjsonFlushInterval is the number of records written between flushes.
+const ndjsonFlushInterval = 100
+
+// PrefersNDJSON reports whether the Accept header ranks newline-delimited JSON
+// strictly above JSON and CBOR. NDJSON must be requested explicitly; wildcards
+// never select it.
+func PrefersNDJSON(header string) bool {
+	return prefersOverAPI(header, func(mr mediaRange) bool {
+		return mr.typ == "application" && (mr.subtype == "x-ndjson" || mr.subtype == "ndjson")
+	})
+}
+
+// MIMETextVCard is the media type of vCard contact data (RFC 6350).
+const MIMETextVCard = "text/vcard"
+
+// PrefersVCard reports whether the Accept header ranks vCard strictly above
+// JSON and CBOR. Like NDJSON, vCard must be requested explicitly.
+func PrefersVCard(header string) bool {
+	return prefersOverAPI(header, func(mr mediaRange) bool {
+		return mr.typ == "text" && mr.subtype == "vcard"
+	})
+}
+
+// prefersHTML reports whether the Accept header ranks text/html strictly above
+// JSON and CBOR, as browsers do for top-level navigation.
+func prefersHTML(header string) bool {
+	return prefersOverAPI(header, func(mr mediaRange) bool {
+		return mr.typ == "text" && mr.subtype == "html"
+	})
+}
+
+// prefersOverAPI reports whether the highest q-value among ranges accepted by
+// match is positive and strictly above every range that could select JSON or CBOR.
+func prefersOverAPI(header string, match func(mediaRange) bool) bool {
+	var matchQ, otherQ float64 = -1, -1
+	for _, mr := range parseAccept(header) {
+		switch {
+		case match(mr):
+			matchQ = max(matchQ, mr.q)
+		case mr.typ == "*" || mr.typ == "application" && (mr.subtype == "*" || mr.subtype == "json" ||
+			mr.subtype == "cbor" || strings.HasSuffix(mr.subtype, "+json") || strings.HasSuffix(mr.subtype, "+cbor")):
+			otherQ = max(otherQ, mr.q)
+		}
+	}
+	return matchQ > 0 && matchQ > otherQ
+}
+
+// StreamNDJSON writes each value from seq as one JSON object per line using
+// application/x-ndjson. The response is flushed periodically so large result
+// sets reach the client without being buffered in full. Streaming stops when
+// the client disconnects.
+func StreamNDJSON[T any](c *echo.Context, status int, seq iter.Seq[T]) error {
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	w.WriteHeader(status)
+
+	ctx := c.Request().Context()
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	n := 0
+	for v := range seq {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		n++
+		if n%ndjsonFlushInterval == 0 {
+			flush(w)
+		}
+	}
+	flush(w)
+	return nil
+}
+
+// flush sends buffered data to the client when the underlying writer supports
+// it. echo.Response.Flush panics on writers without http.Flusher, so the
+// unwrapped writer is flushed directly.
+func flush(w http.ResponseWriter) {
+	if r, err := echo.UnwrapResponse(w); err == nil {
+		w = r.ResponseWriter
+	}
+	_ = http.NewResponseController(w).Flush()
 }
 
 // ensureVary adds values to the Vary header without duplicating existing entries.
@@ -153,9 +330,22 @@ func ensureVary(h http.Header, values ...string) {
 	}
 }
 
+// problemPage renders Problem Details as a minimal HTML page for browsers.
+var problemPage = template.Must(template.New("problem").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>{{.Status}} {{.Title}}</title></head>
+<body>
+<h1>{{.Status}} {{.Title}}</h1>
+{{with .Detail}}<p>{{.}}</p>
+{{end}}</body>
+</html>
+`))
+
 // writeProblem writes a Problem Details response honoring content negotiation.
 // Uses application/problem+json (RFC 9457) by default.
 // Uses application/problem+cbor when CBOR is preferred via Accept header.
+// Uses an HTML page when text/html is explicitly preferred over JSON and CBOR.
+// A ProblemRenderer installed by ProblemRenderers takes precedence when selected.
 func writeProblem(w http.ResponseWriter, r *http.Request, problem ProblemDetails) {
 	if problem.Instance == "" {
 		problem.Instance = r.URL.Path
@@ -163,38 +353,201 @@ func writeProblem(w http.ResponseWriter, r *http.Request, problem ProblemDetails
 
 	ensureVary(w.Header(), "Origin", "Accept")
 
-	if selectFormat(r.Header.Get("Accept")) {
-		w.Header().Set("Content-Type", "application/problem+cbor")
-		w.WriteHeader(problem.Status)
-		if err := cbor.NewEncoder(w).Encode(problem); err != nil {
-			slog.ErrorContext(r.Context(), "failed to encode problem+cbor", slog.Any("error", err))
+	accept := r.Header.Get("Accept")
+	if renderer := selectRenderer(r.Context(), accept); renderer != nil {
+		contentType, body, err := renderer.Render(problem)
+		if err == nil {
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(problem.Status)
+			_, _ = w.Write(body)
+			return
 		}
-	} else {
-		w.Header().Set("Content-Type", "application/problem+json")
+		slog.ErrorContext(r.Context(), "failed to render problem", slog.Any("error", err))
+	}
+
+	if prefersHTML(accept) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		w.WriteHeader(problem.Status)
-		enc := json.NewEncoder(w)
-		enc.SetEscapeHTML(false)
-		if err := enc.Encode(problem); err != nil {
-			slog.ErrorContext(r.Context(), "failed to encode problem+json", slog.Any("error", err))
+		if err := problemPage.Execute(w, problem); err != nil {
+			slog.ErrorContext(r.Context(), "failed to render problem page", slog.Any("error", err))
 		}
+		return
+	}
+
+	if requestFormat(r) == FormatCBOR {
+		// Encoding before writing the status lets an encoding failure fall
+		// back to JSON and gives the response an exact Content-Length.
+		body, err := cborEncoder.Marshal(problem)
+		if err == nil {
+			w.Header().Set("Content-Type", "application/problem+cbor")
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(problem.Status)
+			if _, err = w.Write(body); err != nil {
+				slog.ErrorContext(r.Context(), "failed to write problem+cbor", slog.Any("error", err))
+			}
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to encode problem+cbor", slog.Any("error", err))
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(problem); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode problem+json", slog.Any("error", err))
 	}
 }
 
+// cborEncoder encodes CBOR responses. OmitEmptyGoValue applies omitempty with
+// encoding/json's rules, so a field is present in CBOR exactly when it is
+// present in JSON; the default mode also drops omitempty structs that encode
+// to an empty map, which JSON always keeps.
+var cborEncoder = func() cbor.EncMode {
+	em, err := cbor.EncOptions{OmitEmpty: cbor.OmitEmptyGoValue}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}()
+
+// cborFallbackWarning is sent when a CBOR response is served as JSON instead.
+const cborFallbackWarning = `299 - "response could not be encoded as CBOR; sent as JSON"`
+
 // Negotiate writes a response using content negotiation (JSON or CBOR).
+// When data cannot be encoded as CBOR but can be encoded as JSON, the JSON
+// form is sent with a Warning header; when neither works a 500 Problem
+// Details error is returned. JSON responses use the full representation, with
+// optional fields sent as null, under FullRepresentation or when the client
+// sends Prefer: optional=null; outside FullRepresentation they carry
+// Vary: Prefer.
 func Negotiate(c *echo.Context, status int, data any) error {
-	if selectFormat(c.Request().Header.Get("Accept")) {
-		b, err := cbor.Marshal(data)
+	if PreferredFormat(c) == FormatCBOR {
+		b, err := cborEncoder.Marshal(data)
+		if err == nil {
+			recordNegotiation(c, FormatCBOR, "")
+			return c.Blob(status, "application/cbor", b)
+		}
+
+		ctx := c.Request().Context()
+		jb, jsonErr := json.Marshal(data)
+		if jsonErr != nil {
+			slog.ErrorContext(ctx, "response serialization failed",
+				slog.String("type", fmt.Sprintf("%T", data)),
+				slog.Any("error", err),
+			)
+			return Error500("response serialization failed")
+		}
+		slog.WarnContext(ctx, "cbor serialization failed, falling back to json",
+			slog.String("type", fmt.Sprintf("%T", data)),
+			slog.Any("error", err),
+		)
+		c.Response().Header().Set("Warning", cborFallbackWarning)
+		recordNegotiation(c, FormatJSON, reasonFallback)
+		return c.JSONBlob(status, jb)
+	}
+	recordNegotiation(c, FormatJSON, "")
+	h := c.Response().Header()
+	preferred, forced := wantsFullRepresentation(c.Request())
+	if !forced {
+		// The JSON body depends on Prefer: optional=null, so shared caches
+		// must key on it even when it was not sent.
+		ensureVary(h, HeaderPrefer)
+	}
+	if preferred || forced {
+		b, err := marshalFull(data)
 		if err != nil {
-			return err
+			slog.ErrorContext(c.Request().Context(), "response serialization failed",
+				slog.String("type", fmt.Sprintf("%T", data)),
+				slog.Any("error", err),
+			)
+			return Error500("response serialization failed")
+		}
+		if preferred {
+			h.Add(HeaderPreferenceApplied, preferOptionalNull)
 		}
-		return c.Blob(status, "application/cbor", b)
+		return c.JSONBlob(status, b)
 	}
 	return c.JSON(status, data)
 }
 
+// NegotiateStrict writes a response like Negotiate, but returns a 406 Not
+// Acceptable error instead of defaulting to JSON when the Accept header
+// excludes every supported format with q=0. Headers that merely name no
+// supported format, such as text/html, still receive JSON.
+func NegotiateStrict(c *echo.Context, status int, data any) error {
+	if selectFormat(c.Request().Header.Get("Accept")) == FormatNone {
+		return Error406("neither application/json nor application/cbor is acceptable")
+	}
+	return Negotiate(c, status, data)
+}
+
+// RecovererConfig configures the Recoverer middleware.
+type RecovererConfig struct {
+	// StripHeaders lists response headers removed before the Problem Details
+	// response is written. Defaults to DefaultStripHeaders; an empty non-nil
+	// slice keeps all headers.
+	StripHeaders []string
+	// UserID returns the authenticated user's ID from the request context, or
+	// an empty string, to correlate panics with users. Optional.
+	UserID func(ctx context.Context) string
+	// StackDepth caps the stack frames logged with a recovered panic.
+	// Defaults to DefaultStackDepth.
+	StackDepth int
+}
+
+// DefaultStackDepth is the number of stack frames logged with a recovered
+// panic when RecovererConfig.StackDepth is unset.
+const DefaultStackDepth = 16
+
+// stackFrame is one frame of a recovered panic's stack in the log.
+type stackFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// panicFrames returns up to depth frames of the panicking goroutine's stack,
+// starting at the panic site. Frames of the runtime and of internal standard
+// library packages are dropped. It must be called from the deferred function
+// that recovered the panic.
+func panicFrames(depth int) []stackFrame {
+	// Skip runtime.Callers, panicFrames, and the deferred function.
+	pcs := make([]uintptr, depth+32)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+
+	frames := make([]stackFrame, 0, depth)
+	callers := runtime.CallersFrames(pcs)
+	for len(frames) < depth {
+		f, more := callers.Next()
+		if !trimmedFrame(f.Function) {
+			frames = append(frames, stackFrame{Func: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// trimmedFrame reports whether fn belongs to the runtime or an internal
+// standard library package.
+func trimmedFrame(fn string) bool {
+	return fn == "" || strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "runtime/") ||
+		strings.HasPrefix(fn, "internal/")
+}
+
 // Recoverer returns Echo middleware that recovers from panics with Problem Details.
 // Re-panics on http.ErrAbortHandler to preserve net/http abort semantics.
 func Recoverer() echo.MiddlewareFunc {
+	return RecovererWithConfig(RecovererConfig{})
+}
+
+// RecovererWithConfig returns Recoverer middleware with the given config.
+func RecovererWithConfig(cfg RecovererConfig) echo.MiddlewareFunc {
+	if cfg.StackDepth <= 0 {
+		cfg.StackDepth = DefaultStackDepth
+	}
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c *echo.Context) error {
 			defer func() {
@@ -203,11 +556,7 @@ func Recoverer() echo.MiddlewareFunc {
 						panic(rec)
 					}
 
-					stack := debug.Stack()
-					slog.ErrorContext(c.Request().Context(), "panic recovered",
-						slog.Any("error", rec),
-						slog.String("stack", string(stack)),
-					)
+					logPanic(c, cfg, rec, panicFrames(cfg.StackDepth))
 
 					resp, unwrapErr := echo.UnwrapResponse(c.Response())
 					if unwrapErr == nil && resp.Committed {
@@ -220,6 +569,7 @@ func Recoverer() echo.MiddlewareFunc {
 						Status: http.StatusInternalServerError,
 						Detail: "internal server error",
 					}
+					stripHeaders(c.Response().Header(), cfg.StripHeaders)
 					writeProblem(c.Response(), c.Request(), problem)
 				}
 			}()
@@ -228,8 +578,52 @@ func Recoverer() echo.MiddlewareFunc {
 	}
 }
 
+// logPanic records a recovered panic at critical severity, so on-call is
+// paged, together with a failed "panic" audit event.
+func logPanic(c *echo.Context, cfg RecovererConfig, rec any, frames []stackFrame) {
+	ctx := c.Request().Context()
+
+	var userID string
+	if cfg.UserID != nil {
+		userID = cfg.UserID(ctx)
+	}
+	requestID := ctxkeys.RequestID(ctx)
+
+	attrs := []slog.Attr{
+		slog.Any("error", rec),
+		slog.Any("frames", frames),
+	}
+	if userID != "" {
+		attrs = append(attrs, slog.String("userId", userID))
+	}
+	// The request logger already carries the request ID when it ran.
+	if requestID != "" && applog.TraceIDFromContext(ctx) == nil {
+		attrs = append(attrs, slog.String("requestId", requestID))
+	}
+	applog.LogCritical(ctx, "panic recovered", nil, attrs...)
+
+	applog.LogAuditEvent(ctx, "panic", userID, "request", c.Path(), "failure", map[string]any{
+		"method":    c.Request().Method,
+		"requestId": requestID,
+	})
+}
+
+// HTTPErrorHandlerConfig configures the handler returned by
+// NewHTTPErrorHandlerWithConfig.
+type HTTPErrorHandlerConfig struct {
+	// StripHeaders lists response headers removed before the Problem Details
+	// response is written. Defaults to DefaultStripHeaders; an empty non-nil
+	// slice keeps all headers.
+	StripHeaders []string
+}
+
 // NewHTTPErrorHandler returns an Echo HTTPErrorHandler that produces RFC 9457 Problem Details.
 func NewHTTPErrorHandler() echo.HTTPErrorHandler {
+	return NewHTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{})
+}
+
+// NewHTTPErrorHandlerWithConfig returns an HTTPErrorHandler with the given config.
+func NewHTTPErrorHandlerWithConfig(cfg HTTPErrorHandlerConfig) echo.HTTPErrorHandler {
 	return func(c *echo.Context, err error) {
 		resp, unwrapErr := echo.UnwrapResponse(c.Response())
 		if unwrapErr == nil && resp.Committed {
@@ -239,20 +633,19 @@ func NewHTTPErrorHandler() echo.HTTPErrorHandler {
 		var problem ProblemDetails
 
 		var pd *ProblemDetails
+		var me *MultiError
 		var he *echo.HTTPError
 		var ve *validate.ValidationError
 
 		switch {
+		case errors.As(err, &me):
+			problem = me.problem()
+
 		case errors.As(err, &pd):
 			problem = *pd
 
 		case errors.As(err, &ve):
-			problem = ProblemDetails{
-				Type:   "about:blank",
-				Title:  http.StatusText(http.StatusUnprocessableEntity),
-				Status: http.StatusUnprocessableEntity,
-				Detail: ve.Message,
-			}
+			problem = *TypedError(c.Request().Context(), ProblemTypeValidation, ve.Message)
 			if len(ve.Fields) > 0 {
 				problem.Errors = make([]ErrorDetail, len(ve.Fields))
 				for i, f := range ve.Fields {
@@ -280,10 +673,20 @@ func NewHTTPErrorHandler() echo.HTTPErrorHandler {
 				Detail: fmt.Sprintf("method %s not allowed", c.Request().Method),
 			}
 
+		case errors.Is(err, echo.ErrStatusRequestEntityTooLarge):
+			// Body limits surface from the body reader, so binders wrap them
+			// in a 400 that would otherwise hide the 413.
+			problem = ProblemDetails{
+				Type:   "about:blank",
+				Title:  http.StatusText(http.StatusRequestEntityTooLarge),
+				Status: http.StatusRequestEntityTooLarge,
+				Detail: "request body too large",
+			}
+
 		case errors.As(err, &he):
 			problem = ProblemDetails{
 				Type:   "about:blank",
-				Title:  http.StatusText(he.Code),
+				Title:  StatusTitle(he.Code),
 				Status: he.Code,
 				Detail: he.Message,
 			}
@@ -297,6 +700,15 @@ func NewHTTPErrorHandler() echo.HTTPErrorHandler {
 			}
 		}
 
+		if problem.Title == "" {
+			problem.Title = StatusTitle(problem.Status)
+		}
+
+		if problem.Status == http.StatusTooManyRequests {
+			metrics.IncCounter(metrics.RateLimitRejections, nil)
+		}
+
+		stripHeaders(c.Response().Header(), cfg.StripHeaders)
 		writeProblem(c.Response(), c.Request(), problem)
 	}
 }
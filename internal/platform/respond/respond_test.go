@@ -1,16 +1,28 @@
 package respond
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/labstack/echo/v5"
 
+	"github.com/janisto/echo-playground/internal/platform/ctxkeys"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/metrics"
 	"github.com/janisto/echo-playground/internal/platform/validate"
 )
 
@@ -32,6 +44,35 @@ func TestNewError(t *testing.T) {
 	}
 }
 
+func TestNewError_NonStandardStatus(t *testing.T) {
+	p := NewError(499, "client closed request")
+	if p.Title != "Unknown Error" {
+		t.Fatalf("expected fallback title, got %q", p.Title)
+	}
+	if p.Status != 499 {
+		t.Fatalf("expected 499, got %d", p.Status)
+	}
+}
+
+func TestNewErrorWithTitle(t *testing.T) {
+	p := NewErrorWithTitle(499, "Client Closed Request", "the client went away")
+	if p.Title != "Client Closed Request" {
+		t.Fatalf("expected custom title, got %q", p.Title)
+	}
+	if p.Status != 499 || p.Detail != "the client went away" || p.Type != "about:blank" {
+		t.Fatalf("unexpected problem %+v", p)
+	}
+}
+
+func TestStatusTitle(t *testing.T) {
+	if got := StatusTitle(http.StatusTooManyRequests); got != "Too Many Requests" {
+		t.Fatalf("expected standard reason phrase, got %q", got)
+	}
+	if got := StatusTitle(599); got != "Unknown Error" {
+		t.Fatalf("expected fallback, got %q", got)
+	}
+}
+
 func TestErrorConstructors(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -74,6 +115,9 @@ func TestError422WithFields(t *testing.T) {
 	if p.Status != http.StatusUnprocessableEntity {
 		t.Fatalf("expected 422, got %d", p.Status)
 	}
+	if p.Type != ProblemTypeBase+ProblemTypeValidation {
+		t.Fatalf("expected the validation problem type, got %q", p.Type)
+	}
 	if len(p.Errors) != 2 {
 		t.Fatalf("expected 2 errors, got %d", len(p.Errors))
 	}
@@ -119,6 +163,55 @@ func TestProblemDetailsImplementsError(t *testing.T) {
 	}
 }
 
+// --- FromError ---
+
+var (
+	errTestNotFound      = errors.New("not found")
+	errTestAlreadyExists = errors.New("already exists")
+)
+
+var testErrorMappings = []ErrorMapping{
+	{Err: errTestNotFound, Status: http.StatusNotFound, Detail: "resource not found"},
+	{Err: errTestAlreadyExists, Status: http.StatusConflict, Detail: "resource already exists"},
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+	}{
+		{"mapped not found", errTestNotFound, http.StatusNotFound, "resource not found"},
+		{"mapped wrapped conflict", fmt.Errorf("create: %w", errTestAlreadyExists), http.StatusConflict,
+			"resource already exists"},
+		{"unmapped", errors.New("database exploded"), http.StatusInternalServerError, "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p *ProblemDetails
+			if !errors.As(FromError(context.Background(), tt.err, testErrorMappings...), &p) {
+				t.Fatal("expected ProblemDetails")
+			}
+			if p.Status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, p.Status)
+			}
+			if p.Title != http.StatusText(tt.wantStatus) {
+				t.Fatalf("expected title %q, got %q", http.StatusText(tt.wantStatus), p.Title)
+			}
+			if p.Detail != tt.wantDetail {
+				t.Fatalf("expected detail %q, got %q", tt.wantDetail, p.Detail)
+			}
+		})
+	}
+}
+
+func TestFromError_Nil(t *testing.T) {
+	if err := FromError(context.Background(), nil, testErrorMappings...); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
 // --- parseAccept ---
 
 func TestParseAcceptEmpty(t *testing.T) {
@@ -183,77 +276,256 @@ func TestParseAcceptMultipleQParams(t *testing.T) {
 	}
 }
 
+func TestParseAcceptParams(t *testing.T) {
+	ranges := parseAccept(`application/cbor;q=0.5, Application/JSON; Version=2; profile="a \"b\"";q=0.9;ext=1`)
+	if len(ranges) != 2 {
+		t.Fatalf("expected 2 ranges, got %d", len(ranges))
+	}
+	if ranges[0].params != nil {
+		t.Fatalf("expected no params on the first range, got %v", ranges[0].params)
+	}
+	jr := ranges[1]
+	if jr.q != 0.9 {
+		t.Fatalf("expected q=0.9 alongside params, got %f", jr.q)
+	}
+	if got := jr.params["version"]; got != "2" {
+		t.Fatalf("expected version 2, got %q", got)
+	}
+	if got := jr.params["profile"]; got != `a "b"` {
+		t.Fatalf("expected unquoted profile, got %q", got)
+	}
+	if _, ok := jr.params["ext"]; ok {
+		t.Fatal("expected parameters after q to be ignored")
+	}
+	if selectFormat(`application/cbor;q=0.5, application/json;version=2;q=0.9`) != FormatJSON {
+		t.Fatal("expected version parameter not to affect q ranking")
+	}
+	if selectFormat(`application/cbor;version=1, application/json;version=2;q=0.9`) != FormatCBOR {
+		t.Fatal("expected CBOR at q=1 to win despite parameters")
+	}
+}
+
+func TestParseAcceptOversizedHeader(t *testing.T) {
+	header := "application/cbor" + strings.Repeat(", text/plain;q=0.1", 10000)
+
+	start := time.Now()
+	ranges := parseAccept(header)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("parsing took too long: %v", elapsed)
+	}
+	if len(ranges) != maxAcceptRanges {
+		t.Fatalf("expected %d ranges, got %d", maxAcceptRanges, len(ranges))
+	}
+	if selectFormat(header) != FormatCBOR {
+		t.Fatal("expected CBOR to be selected from the leading range")
+	}
+}
+
 // --- selectFormat ---
 
+// selectFormatCases are Accept headers and the format selectFormat picks for
+// them, shared by the fast and general path tests.
+var selectFormatCases = []struct {
+	name   string
+	accept string
+	want   Format
+}{
+	{"empty accept defaults to JSON", "", FormatJSON},
+	{"wildcard defaults to JSON", "*/*", FormatJSON},
+	{"application wildcard defaults to JSON", "application/*", FormatJSON},
+	{"explicit JSON", "application/json", FormatJSON},
+	{"explicit CBOR", "application/cbor", FormatCBOR},
+	{"CBOR with quality parameter", "application/cbor;q=1.0", FormatCBOR},
+	{"multiple types with equal q-values defaults to JSON", "application/json, application/cbor", FormatJSON},
+	{"CBOR preferred with quality", "application/json;q=0.9, application/cbor;q=1.0", FormatCBOR},
+	{"text/html defaults to JSON", "text/html", FormatJSON},
+	{"problem+cbor explicit", "application/problem+cbor", FormatCBOR},
+	{"problem+json explicit", "application/problem+json", FormatJSON},
+	{
+		"problem+cbor preferred over problem+json",
+		"application/problem+cbor;q=1.0, application/problem+json;q=0.5",
+		FormatCBOR,
+	},
+	{
+		"problem+json preferred over problem+cbor",
+		"application/problem+cbor;q=0.5, application/problem+json;q=1.0",
+		FormatJSON,
+	},
+	{"problem+cbor over base cbor same q", "application/cbor, application/problem+cbor", FormatCBOR},
+	{"CBOR excluded with q=0", "application/cbor;q=0, application/json", FormatJSON},
+	{"JSON preferred with higher quality", "application/cbor;q=0.5, application/json;q=0.9", FormatJSON},
+	{"CBOR only with low quality still accepted", "application/cbor;q=0.1", FormatCBOR},
+	{"wildcard with CBOR explicit prefers CBOR", "*/*;q=0.1, application/cbor;q=1.0", FormatCBOR},
+	{"wildcard with JSON explicit prefers JSON", "*/*;q=0.1, application/json;q=1.0", FormatJSON},
+	{
+		"q-value wins over specificity - JSON base over CBOR problem",
+		"application/problem+cbor;q=0.1, application/json;q=1.0",
+		FormatJSON,
+	},
+	{
+		"q-value wins over specificity - CBOR base over JSON problem",
+		"application/problem+json;q=0.1, application/cbor;q=1.0",
+		FormatCBOR,
+	},
+	{
+		"equal q-values use specificity as tie-breaker - CBOR wins",
+		"application/json;q=0.8, application/problem+cbor;q=0.8",
+		FormatCBOR,
+	},
+	{
+		"equal q-values use specificity as tie-breaker - JSON wins",
+		"application/cbor;q=0.8, application/problem+json;q=0.8",
+		FormatJSON,
+	},
+	{"malformed quality defaults to 1.0", "application/cbor;q=invalid", FormatCBOR},
+	{"whitespace handling", "  application/cbor  ;  q=1.0  ", FormatCBOR},
+	{"case insensitive type matching", "Application/CBOR", FormatCBOR},
+	{"both excluded with q=0", "application/json;q=0, application/cbor;q=0", FormatNone},
+	{"only wildcard with q=0", "*/*;q=0", FormatNone},
+	{"application wildcard with q=0", "application/*;q=0, text/html", FormatNone},
+	{"structured suffix wildcard +cbor", "application/*+cbor", FormatCBOR},
+	{"structured suffix wildcard +json", "application/*+json", FormatJSON},
+	{"no matching type", "image/png, text/plain", FormatJSON},
+	{"CBOR excluded JSON accepted", "application/cbor;q=0, application/json;q=1.0", FormatJSON},
+	{"JSON excluded CBOR accepted", "application/json;q=0, application/cbor;q=1.0", FormatCBOR},
+	{"single type without slash", "application", FormatJSON},
+	{"single wildcard type without slash", "*", FormatJSON},
+	{"single structured suffix +cbor", " application/vnd.example+cbor ", FormatCBOR},
+	{"whitespace only", "   ", FormatJSON},
+}
+
 func TestSelectFormatEdgeCases(t *testing.T) {
+	for _, tt := range selectFormatCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectFormat(tt.accept); got != tt.want {
+				t.Fatalf("selectFormat(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+			if got := selectFormatRanges(parseAccept(tt.accept)); got != tt.want {
+				t.Fatalf("general path for %q = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSingleAccept(t *testing.T) {
+	for _, tt := range selectFormatCases {
+		mr, ok := parseSingleAccept(tt.accept)
+		if !ok {
+			continue
+		}
+		ranges := parseAccept(tt.accept)
+		if len(ranges) != 1 || !reflect.DeepEqual(ranges[0], mr) {
+			t.Fatalf("parseSingleAccept(%q) = %+v, parseAccept gives %+v", tt.accept, mr, ranges)
+		}
+	}
+	for _, h := range []string{"", " ", "application/json;q=0.5", "application/json, application/cbor"} {
+		if _, ok := parseSingleAccept(h); ok {
+			t.Fatalf("expected parseSingleAccept(%q) to defer to parseAccept", h)
+		}
+	}
+}
+
+func TestSelectFormat_FastPathDoesNotAllocate(t *testing.T) {
+	for _, h := range []string{"", "application/json", "application/cbor", "*/*"} {
+		if n := testing.AllocsPerRun(100, func() { selectFormat(h) }); n != 0 {
+			t.Fatalf("selectFormat(%q) allocated %v times", h, n)
+		}
+	}
+}
+
+func BenchmarkSelectFormat(b *testing.B) {
+	headers := []string{"application/json", "application/cbor", "*/*"}
+	b.Run("fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, h := range headers {
+				_ = selectFormat(h)
+			}
+		}
+	})
+	b.Run("general", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, h := range headers {
+				_ = selectFormatRanges(parseAccept(h))
+			}
+		}
+	})
+	b.Run("complex", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_ = selectFormat("application/json;q=0.9, application/cbor;q=1.0, */*;q=0.1")
+		}
+	})
+}
+
+// --- PrefersNDJSON ---
+
+func TestPrefersNDJSON(t *testing.T) {
 	tests := []struct {
-		name       string
-		accept     string
-		expectCBOR bool
+		accept string
+		want   bool
 	}{
-		{"empty accept defaults to JSON", "", false},
-		{"wildcard defaults to JSON", "*/*", false},
-		{"application wildcard defaults to JSON", "application/*", false},
-		{"explicit JSON", "application/json", false},
-		{"explicit CBOR", "application/cbor", true},
-		{"CBOR with quality parameter", "application/cbor;q=1.0", true},
-		{"multiple types with equal q-values defaults to JSON", "application/json, application/cbor", false},
-		{"CBOR preferred with quality", "application/json;q=0.9, application/cbor;q=1.0", true},
-		{"text/html defaults to JSON", "text/html", false},
-		{"problem+cbor explicit", "application/problem+cbor", true},
-		{"problem+json explicit", "application/problem+json", false},
-		{
-			"problem+cbor preferred over problem+json",
-			"application/problem+cbor;q=1.0, application/problem+json;q=0.5",
-			true,
-		},
-		{
-			"problem+json preferred over problem+cbor",
-			"application/problem+cbor;q=0.5, application/problem+json;q=1.0",
-			false,
-		},
-		{"problem+cbor over base cbor same q", "application/cbor, application/problem+cbor", true},
-		{"CBOR excluded with q=0", "application/cbor;q=0, application/json", false},
-		{"JSON preferred with higher quality", "application/cbor;q=0.5, application/json;q=0.9", false},
-		{"CBOR only with low quality still accepted", "application/cbor;q=0.1", true},
-		{"wildcard with CBOR explicit prefers CBOR", "*/*;q=0.1, application/cbor;q=1.0", true},
-		{"wildcard with JSON explicit prefers JSON", "*/*;q=0.1, application/json;q=1.0", false},
-		{
-			"q-value wins over specificity - JSON base over CBOR problem",
-			"application/problem+cbor;q=0.1, application/json;q=1.0",
-			false,
-		},
-		{
-			"q-value wins over specificity - CBOR base over JSON problem",
-			"application/problem+json;q=0.1, application/cbor;q=1.0",
-			true,
-		},
-		{
-			"equal q-values use specificity as tie-breaker - CBOR wins",
-			"application/json;q=0.8, application/problem+cbor;q=0.8",
-			true,
-		},
-		{
-			"equal q-values use specificity as tie-breaker - JSON wins",
-			"application/cbor;q=0.8, application/problem+json;q=0.8",
-			false,
-		},
-		{"malformed quality defaults to 1.0", "application/cbor;q=invalid", true},
-		{"whitespace handling", "  application/cbor  ;  q=1.0  ", true},
-		{"case insensitive type matching", "Application/CBOR", true},
-		{"both excluded with q=0", "application/json;q=0, application/cbor;q=0", false},
-		{"only wildcard with q=0", "*/*;q=0", false},
-		{"structured suffix wildcard +cbor", "application/*+cbor", true},
-		{"structured suffix wildcard +json", "application/*+json", false},
-		{"no matching type", "image/png, text/plain", false},
-		{"CBOR excluded JSON accepted", "application/cbor;q=0, application/json;q=1.0", false},
-		{"JSON excluded CBOR accepted", "application/json;q=0, application/cbor;q=1.0", true},
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/x-ndjson", true},
+		{"application/ndjson", true},
+		{"application/x-ndjson, */*;q=0.1", true},
+		{"application/x-ndjson, application/json", false},
+		{"application/x-ndjson;q=0.5, application/cbor", false},
+		{"application/x-ndjson;q=0", false},
 	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := selectFormat(tt.accept)
-			if got != tt.expectCBOR {
-				t.Fatalf("selectFormat(%q) = %v, want %v", tt.accept, got, tt.expectCBOR)
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := PrefersNDJSON(tt.accept); got != tt.want {
+				t.Fatalf("PrefersNDJSON(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefersVCard(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"text/*", false},
+		{"text/vcard", true},
+		{"Text/VCard; charset=utf-8", true},
+		{"text/vcard, */*;q=0.1", true},
+		{"text/vcard, application/json", false},
+		{"text/vcard;q=0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := PrefersVCard(tt.accept); got != tt.want {
+				t.Fatalf("PrefersVCard(%q) = %v, want %v", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefersHTML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"text/html", true},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", true},
+		{"text/html, application/json", false},
+		{"text/html;q=0.5, application/problem+json", false},
+		{"text/html;q=0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			if got := prefersHTML(tt.accept); got != tt.want {
+				t.Fatalf("prefersHTML(%q) = %v, want %v", tt.accept, got, tt.want)
 			}
 		})
 	}
@@ -375,6 +647,28 @@ func TestWriteProblemCBOR(t *testing.T) {
 	}
 }
 
+func TestWriteProblemCBOR_ContentLength(t *testing.T) {
+	problem := ProblemDetails{
+		Type:   "about:blank",
+		Title:  "Conflict",
+		Status: http.StatusConflict,
+		Detail: "resource already exists",
+	}
+	req := httptest.NewRequest(http.MethodGet, "/conflict", nil)
+	req.Header.Set("Accept", "application/cbor")
+	rec := httptest.NewRecorder()
+
+	writeProblem(rec, req, problem)
+
+	got, err := strconv.Atoi(rec.Header().Get("Content-Length"))
+	if err != nil {
+		t.Fatalf("expected numeric Content-Length, got %q", rec.Header().Get("Content-Length"))
+	}
+	if got != rec.Body.Len() {
+		t.Fatalf("expected Content-Length %d to match body size %d", got, rec.Body.Len())
+	}
+}
+
 func TestWriteProblemVaryHeaders(t *testing.T) {
 	problem := ProblemDetails{Type: "about:blank", Title: "Not Found", Status: 404}
 	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
@@ -456,6 +750,52 @@ func TestWriteProblem_CBOREncodeError(t *testing.T) {
 	}
 }
 
+func TestWriteProblemHTML(t *testing.T) {
+	problem := ProblemDetails{
+		Type:   "about:blank",
+		Title:  "Not Found",
+		Status: http.StatusNotFound,
+		Detail: "resource <b>not</b> found",
+	}
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	rec := httptest.NewRecorder()
+
+	writeProblem(rec, req, problem)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Fatalf("expected text/html, got %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "<h1>404 Not Found</h1>") {
+		t.Fatalf("expected status heading, got %q", body)
+	}
+	if !strings.Contains(body, "resource &lt;b&gt;not&lt;/b&gt; found") {
+		t.Fatalf("expected escaped detail, got %q", body)
+	}
+}
+
+func TestWriteProblemJSONWhenJSONPreferred(t *testing.T) {
+	problem := ProblemDetails{
+		Type:   "about:blank",
+		Title:  "Internal Server Error",
+		Status: http.StatusInternalServerError,
+		Detail: "internal error",
+	}
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept", "application/json, text/html;q=0.9")
+	rec := httptest.NewRecorder()
+
+	writeProblem(rec, req, problem)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", ct)
+	}
+}
+
 // --- HTTPErrorHandler ---
 
 func TestHTTPErrorHandler_ProblemDetails(t *testing.T) {
@@ -515,6 +855,56 @@ func TestHTTPErrorHandler_EchoHTTPError(t *testing.T) {
 	}
 }
 
+func TestHTTPErrorHandler_WrappedBodyTooLarge(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.POST("/test", func(c *echo.Context) error {
+		return echo.ErrBadRequest.Wrap(echo.ErrStatusRequestEntityTooLarge)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for a body limit hit while binding, got %d", rec.Code)
+	}
+}
+
+func TestHTTPErrorHandler_NonStandardStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"echo HTTPError", echo.NewHTTPError(499, "client closed request")},
+		{"problem without title", &ProblemDetails{Type: "about:blank", Status: 499}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = NewHTTPErrorHandler()
+			e.GET("/test", func(c *echo.Context) error {
+				return tt.err
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != 499 {
+				t.Fatalf("expected 499, got %d", rec.Code)
+			}
+			var problem ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if problem.Title != "Unknown Error" {
+				t.Fatalf("expected fallback title, got %q", problem.Title)
+			}
+		})
+	}
+}
+
 func TestHTTPErrorHandler_ValidationError(t *testing.T) {
 	e := echo.New()
 	e.Validator = validate.New()
@@ -554,6 +944,9 @@ func TestHTTPErrorHandler_ValidationError(t *testing.T) {
 	if problem.Errors[0].Location != "name" {
 		t.Fatalf("expected location 'name', got %q", problem.Errors[0].Location)
 	}
+	if want := ProblemTypeBase + ProblemTypeValidation; problem.Type != want {
+		t.Fatalf("expected type %q, got %q", want, problem.Type)
+	}
 }
 
 func TestHTTPErrorHandler_BareError(t *testing.T) {
@@ -607,6 +1000,26 @@ func TestHTTPErrorHandler_NotFound(t *testing.T) {
 	}
 }
 
+func TestHTTPErrorHandler_NotFoundHTML(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/nonexistent", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected text/html, got %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "resource not found") {
+		t.Fatalf("expected detail in body, got %q", rec.Body.String())
+	}
+}
+
 func TestHTTPErrorHandler_MethodNotAllowed(t *testing.T) {
 	e := echo.New()
 	e.HTTPErrorHandler = NewHTTPErrorHandler()
@@ -852,6 +1265,273 @@ func TestRecovererRePanicsOnErrAbortHandler(t *testing.T) {
 
 // --- Negotiate ---
 
+func TestRecovererStripsSensitiveHeaders(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.Use(Recoverer())
+	e.GET("/panic", func(c *echo.Context) error {
+		c.Response().Header().Set("Set-Cookie", "session=secret")
+		c.Response().Header().Set("X-Debug", "trace")
+		c.Response().Header().Set("X-Request-ID", "req-1")
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if v := rec.Header().Get("Set-Cookie"); v != "" {
+		t.Fatalf("expected Set-Cookie stripped, got %q", v)
+	}
+	if v := rec.Header().Get("X-Debug"); v != "" {
+		t.Fatalf("expected X-Debug stripped, got %q", v)
+	}
+	if v := rec.Header().Get("X-Request-ID"); v != "req-1" {
+		t.Fatalf("expected X-Request-ID kept, got %q", v)
+	}
+}
+
+func TestRecovererWithConfigCustomStripHeaders(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.Use(RecovererWithConfig(RecovererConfig{StripHeaders: []string{"X-Internal"}}))
+	e.GET("/panic", func(c *echo.Context) error {
+		c.Response().Header().Set("X-Internal", "node-7")
+		c.Response().Header().Set("X-Debug", "trace")
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if v := rec.Header().Get("X-Internal"); v != "" {
+		t.Fatalf("expected X-Internal stripped, got %q", v)
+	}
+	if v := rec.Header().Get("X-Debug"); v != "trace" {
+		t.Fatalf("expected X-Debug kept with custom list, got %q", v)
+	}
+}
+
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r.Clone())
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func recordAttrs(r slog.Record) map[string]string {
+	attrs := make(map[string]string)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	return attrs
+}
+
+type ctxTestUserKey struct{}
+
+func TestRecovererCorrelatesPanic(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+	}{
+		{"authenticated", "user-123"},
+		{"anonymous", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &recordingHandler{}
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c *echo.Context) error {
+					ctx := ctxkeys.WithRequestID(c.Request().Context(), "req-1")
+					ctx = applog.ContextWithLogger(ctx, slog.New(h))
+					c.SetRequest(c.Request().WithContext(ctx))
+					return next(c)
+				}
+			})
+			e.Use(RecovererWithConfig(RecovererConfig{UserID: func(ctx context.Context) string {
+				id, _ := ctx.Value(ctxTestUserKey{}).(string)
+				return id
+			}}))
+			e.GET("/panic", func(c *echo.Context) error {
+				if tt.userID != "" {
+					ctx := context.WithValue(c.Request().Context(), ctxTestUserKey{}, tt.userID)
+					c.SetRequest(c.Request().WithContext(ctx))
+				}
+				panic("boom")
+			})
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("expected 500, got %d", rec.Code)
+			}
+			if len(h.records) != 2 {
+				t.Fatalf("expected panic and audit records, got %d", len(h.records))
+			}
+
+			panicRec := h.records[0]
+			if panicRec.Level != applog.LevelCritical {
+				t.Fatalf("expected critical severity, got %v", panicRec.Level)
+			}
+			attrs := recordAttrs(panicRec)
+			if attrs["requestId"] != "req-1" || attrs["error"] != "boom" {
+				t.Fatalf("expected request ID and error, got %v", attrs)
+			}
+			if uid, ok := attrs["userId"]; tt.userID == "" && ok || tt.userID != "" && uid != tt.userID {
+				t.Fatalf("expected userId %q, got %v", tt.userID, attrs)
+			}
+
+			audit := recordAttrs(h.records[1])
+			if audit["audit.action"] != "panic" || audit["audit.result"] != "failure" {
+				t.Fatalf("expected failed panic audit event, got %v", audit)
+			}
+			if audit["audit.user_id"] != tt.userID || audit["audit.resource_id"] != "/panic" {
+				t.Fatalf("unexpected audit attributes %v", audit)
+			}
+		})
+	}
+}
+
+func panicAt(depth int) {
+	if depth == 0 {
+		panic("deep boom")
+	}
+	panicAt(depth - 1)
+}
+
+func TestRecovererLogsBoundedFrames(t *testing.T) {
+	h := &recordingHandler{}
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			ctx := applog.ContextWithLogger(c.Request().Context(), slog.New(h))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.Use(RecovererWithConfig(RecovererConfig{StackDepth: 3}))
+	e.GET("/panic", func(*echo.Context) error {
+		panicAt(10)
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if len(h.records) == 0 {
+		t.Fatal("expected a panic record")
+	}
+	if recordValue(h.records[0], "stack") != nil {
+		t.Fatal("expected no unstructured stack attribute")
+	}
+	frames, _ := recordValue(h.records[0], "frames").([]stackFrame)
+	if len(frames) != 3 {
+		t.Fatalf("expected 3 frames, got %+v", frames)
+	}
+	for _, f := range frames {
+		if !strings.HasSuffix(f.Func, ".panicAt") || f.File == "" || f.Line == 0 {
+			t.Fatalf("expected panicAt frames without runtime frames, got %+v", frames)
+		}
+	}
+}
+
+func TestRecovererDefaultStackDepth(t *testing.T) {
+	h := &recordingHandler{}
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			ctx := applog.ContextWithLogger(c.Request().Context(), slog.New(h))
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	})
+	e.Use(Recoverer())
+	e.GET("/panic", func(*echo.Context) error {
+		panicAt(50)
+		return nil
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	frames, _ := recordValue(h.records[0], "frames").([]stackFrame)
+	if len(frames) != DefaultStackDepth {
+		t.Fatalf("expected %d frames, got %d", DefaultStackDepth, len(frames))
+	}
+}
+
+func recordValue(r slog.Record, key string) any {
+	var v any
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			v = a.Value.Any()
+			return false
+		}
+		return true
+	})
+	return v
+}
+
+func TestHTTPErrorHandlerStripsSensitiveHeaders(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.GET("/fail", func(c *echo.Context) error {
+		c.Response().Header().Set("Set-Cookie", "session=secret")
+		c.Response().Header().Set("Content-Disposition", "attachment")
+		return Error404("not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+	if v := rec.Header().Get("Set-Cookie"); v != "" {
+		t.Fatalf("expected Set-Cookie stripped, got %q", v)
+	}
+	if v := rec.Header().Get("Content-Disposition"); v != "" {
+		t.Fatalf("expected Content-Disposition stripped, got %q", v)
+	}
+}
+
+func TestHTTPErrorHandlerWithConfigKeepsHeaders(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandlerWithConfig(HTTPErrorHandlerConfig{StripHeaders: []string{}})
+	e.GET("/fail", func(c *echo.Context) error {
+		c.Response().Header().Set("Set-Cookie", "session=cleared")
+		return Error404("not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if v := rec.Header().Get("Set-Cookie"); v != "session=cleared" {
+		t.Fatalf("expected Set-Cookie kept, got %q", v)
+	}
+}
+
 func TestNegotiateJSON(t *testing.T) {
 	e := echo.New()
 	e.GET("/test", func(c *echo.Context) error {
@@ -878,6 +1558,57 @@ func TestNegotiateJSON(t *testing.T) {
 	}
 }
 
+func TestStreamNDJSON(t *testing.T) {
+	e := echo.New()
+	e.GET("/test", func(c *echo.Context) error {
+		return StreamNDJSON(c, http.StatusOK, slices.Values([]map[string]int{{"n": 1}, {"n": 2}, {"n": 3}}))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != MIMEApplicationNDJSON {
+		t.Fatalf("expected %s, got %q", MIMEApplicationNDJSON, ct)
+	}
+	if !rec.Flushed {
+		t.Fatal("expected response to be flushed")
+	}
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		var v map[string]int
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("line %d: failed to unmarshal %q: %v", i, line, err)
+		}
+		if v["n"] != i+1 {
+			t.Fatalf("line %d: expected n=%d, got %d", i, i+1, v["n"])
+		}
+	}
+}
+
+func TestStreamNDJSON_StopsOnCanceledContext(t *testing.T) {
+	e := echo.New()
+	e.GET("/test", func(c *echo.Context) error {
+		return StreamNDJSON(c, http.StatusOK, slices.Values([]int{1, 2}))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected no records after cancellation, got %q", rec.Body.String())
+	}
+}
+
 func TestNegotiateCBOR(t *testing.T) {
 	e := echo.New()
 	e.GET("/test", func(c *echo.Context) error {
@@ -905,6 +1636,46 @@ func TestNegotiateCBOR(t *testing.T) {
 	}
 }
 
+func TestNegotiateCBOR_OmitEmptyMatchesJSON(t *testing.T) {
+	type limits struct {
+		Max int `json:"max,omitempty"`
+	}
+	type body struct {
+		Limits  limits   `json:"limits,omitempty"`
+		Enabled bool     `json:"enabled,omitempty"`
+		Tags    []string `json:"tags,omitempty"`
+	}
+
+	e := echo.New()
+	e.GET("/test", func(c *echo.Context) error {
+		return Negotiate(c, http.StatusOK, body{Tags: []string{}})
+	})
+
+	decoded := make(map[string]map[string]any)
+	for _, accept := range []string{"application/json", "application/cbor"} {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var m map[string]any
+		unmarshal := json.Unmarshal
+		if accept == "application/cbor" {
+			unmarshal = cbor.Unmarshal
+		}
+		if err := unmarshal(rec.Body.Bytes(), &m); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", accept, err)
+		}
+		decoded[accept] = m
+	}
+
+	jsonKeys := slices.Sorted(maps.Keys(decoded["application/json"]))
+	cborKeys := slices.Sorted(maps.Keys(decoded["application/cbor"]))
+	if !slices.Equal(jsonKeys, []string{"limits"}) || !slices.Equal(cborKeys, jsonKeys) {
+		t.Fatalf("expected only limits in both formats, got JSON %v and CBOR %v", jsonKeys, cborKeys)
+	}
+}
+
 func TestWriteProblemPreservesInstance(t *testing.T) {
 	problem := ProblemDetails{
 		Type:     "about:blank",
@@ -942,6 +1713,55 @@ func TestNegotiateJSON_Status(t *testing.T) {
 	}
 }
 
+func TestNegotiateStrict(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   int
+	}{
+		{"both excluded with q=0", "application/json;q=0, application/cbor;q=0", http.StatusNotAcceptable},
+		{"wildcard excluded with q=0", "*/*;q=0", http.StatusNotAcceptable},
+		{"no preference", "", http.StatusOK},
+		{"no supported type named", "text/html", http.StatusOK},
+		{"CBOR excluded", "application/cbor;q=0, application/json", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = NewHTTPErrorHandler()
+			e.GET("/test", func(c *echo.Context) error {
+				return NegotiateStrict(c, http.StatusOK, map[string]string{"msg": "hello"})
+			})
+			e.GET("/lenient", func(c *echo.Context) error {
+				return Negotiate(c, http.StatusOK, map[string]string{"msg": "hello"})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+			if tt.want == http.StatusNotAcceptable {
+				if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+					t.Fatalf("expected Problem Details, got %q", ct)
+				}
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/lenient", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec = httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+				t.Fatalf("expected Negotiate to default to JSON, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
 func TestHTTPErrorHandler_EchoHTTPErrorNonStandard(t *testing.T) {
 	e := echo.New()
 	e.HTTPErrorHandler = NewHTTPErrorHandler()
@@ -966,6 +1786,24 @@ func TestHTTPErrorHandler_EchoHTTPErrorNonStandard(t *testing.T) {
 	}
 }
 
+func TestHTTPErrorHandler_RateLimitMetric(t *testing.T) {
+	mem := metrics.NewMemory()
+	metrics.SetRecorder(mem)
+	t.Cleanup(func() { metrics.SetRecorder(nil) })
+
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.GET("/test", func(c *echo.Context) error {
+		return echo.NewHTTPError(http.StatusTooManyRequests, "rate limited")
+	})
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if got := mem.Count(metrics.RateLimitRejections, nil); got != 1 {
+		t.Fatalf("expected rate limit counter 1, got %d", got)
+	}
+}
+
 func TestHTTPErrorHandler_ValidationErrorCBOR(t *testing.T) {
 	e := echo.New()
 	e.Validator = validate.New()
@@ -1045,6 +1883,54 @@ func TestNegotiateCBOR_MarshalError(t *testing.T) {
 	if rec.Code != http.StatusInternalServerError {
 		t.Fatalf("expected 500 for unmarshalable type, got %d", rec.Code)
 	}
+	if rec.Header().Get("Warning") != "" {
+		t.Fatalf("expected no Warning header, got %q", rec.Header().Get("Warning"))
+	}
+
+	var problem ProblemDetails
+	if err := cbor.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if problem.Detail != "response serialization failed" {
+		t.Fatalf("expected serialization detail, got %q", problem.Detail)
+	}
+}
+
+// jsonOnly encodes as JSON but fails to encode as CBOR.
+type jsonOnly struct{}
+
+func (jsonOnly) MarshalJSON() ([]byte, error) {
+	return []byte(`{"ok":true}`), nil
+}
+
+func (jsonOnly) MarshalCBOR() ([]byte, error) {
+	return nil, errors.New("cbor not supported")
+}
+
+func TestNegotiateCBOR_FallbackToJSON(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.GET("/test", func(c *echo.Context) error {
+		return Negotiate(c, http.StatusOK, jsonOnly{})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept", "application/cbor")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("expected JSON content type, got %q", ct)
+	}
+	if w := rec.Header().Get("Warning"); !strings.HasPrefix(w, "299 ") {
+		t.Fatalf("expected 299 Warning header, got %q", w)
+	}
+	if body := rec.Body.String(); body != `{"ok":true}` {
+		t.Fatalf("unexpected body %q", body)
+	}
 }
 
 func TestRecoverer_CommittedResponse(t *testing.T) {
@@ -1084,6 +1970,86 @@ func TestHTTPErrorHandler_CommittedResponse(t *testing.T) {
 	}
 }
 
+// --- Prefer ---
+
+func TestPreference(t *testing.T) {
+	tests := []struct {
+		name      string
+		headers   []string
+		pref      string
+		wantValue string
+		wantOK    bool
+	}{
+		{"absent", nil, "return", "", false},
+		{"token only", []string{"dry-run"}, "dry-run", "", true},
+		{"with value", []string{"return=minimal"}, "return", "minimal", true},
+		{"case-insensitive name", []string{"Return=minimal"}, "return", "minimal", true},
+		{"quoted value", []string{`return="minimal"`}, "return", "minimal", true},
+		{"with parameters", []string{"return=minimal; foo=bar"}, "return", "minimal", true},
+		{"list", []string{"respond-async, return=representation"}, "return", "representation", true},
+		{"first wins", []string{"return=minimal", "return=representation"}, "return", "minimal", true},
+		{"other preference", []string{"wait=10"}, "return", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			for _, h := range tt.headers {
+				req.Header.Add("Prefer", h)
+			}
+			value, ok := Preference(req, tt.pref)
+			if value != tt.wantValue || ok != tt.wantOK {
+				t.Fatalf("Preference(%q) = (%q, %v), want (%q, %v)", tt.pref, value, ok, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func newMinimalEcho() *echo.Echo {
+	e := echo.New()
+	e.POST("/test", func(c *echo.Context) error {
+		c.Response().Header().Set("Location", "/test/1")
+		return NegotiateOrMinimal(c, http.StatusCreated, map[string]string{"msg": "hello"})
+	})
+	return e
+}
+
+func TestNegotiateOrMinimal_Minimal(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("Prefer", "return=minimal")
+	rec := httptest.NewRecorder()
+	newMinimalEcho().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected empty body, got %q", rec.Body.String())
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "return=minimal" {
+		t.Fatalf("expected Preference-Applied return=minimal, got %q", got)
+	}
+	if got := rec.Header().Get("Location"); got != "/test/1" {
+		t.Fatalf("expected Location header, got %q", got)
+	}
+}
+
+func TestNegotiateOrMinimal_Representation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("Prefer", "return=representation")
+	rec := httptest.NewRecorder()
+	newMinimalEcho().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if rec.Body.Len() == 0 {
+		t.Fatal("expected response body")
+	}
+	if got := rec.Header().Get("Preference-Applied"); got != "" {
+		t.Fatalf("expected no Preference-Applied, got %q", got)
+	}
+}
+
 // --- helpers ---
 
 func headerSet(values []string) map[string]struct{} {
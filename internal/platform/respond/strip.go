@@ -0,0 +1,23 @@
+package respond
+
+import "net/http"
+
+// DefaultStripHeaders lists response headers removed before an error response
+// is written, so state set by a failed handler does not leak into the
+// Problem Details response.
+var DefaultStripHeaders = []string{
+	"Content-Disposition",
+	"Set-Cookie",
+	"X-Debug",
+	"X-Powered-By",
+}
+
+// stripHeaders deletes names from h. A nil names slice uses DefaultStripHeaders.
+func stripHeaders(h http.Header, names []string) {
+	if names == nil {
+		names = DefaultStripHeaders
+	}
+	for _, name := range names {
+		h.Del(name)
+	}
+}
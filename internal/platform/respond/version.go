@@ -0,0 +1,90 @@
+package respond
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+)
+
+// versionParam is the Accept media type parameter selecting a response version.
+const versionParam = "version"
+
+type ctxVersionKey struct{}
+
+// VersionNegotiation returns Echo middleware that reads the version parameter
+// of the Accept range selecting the response format, as in
+// Accept: application/json;version=2, and caches it on the request context
+// for Versioned handlers. Versions that are not positive integers are rejected
+// with 406 Not Acceptable.
+func VersionNegotiation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			req := c.Request()
+			version, ok := parseVersion(req)
+			if !ok {
+				return Error406("version must be a positive integer")
+			}
+			c.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxVersionKey{}, version)))
+			return next(c)
+		}
+	}
+}
+
+// RequestedVersion returns the version the client asked for in Accept. It
+// reports false when no valid version was requested. The value cached by
+// VersionNegotiation is reused when present.
+func RequestedVersion(r *http.Request) (int, bool) {
+	version, ok := r.Context().Value(ctxVersionKey{}).(int)
+	if !ok {
+		version, _ = parseVersion(r)
+	}
+	return version, version > 0
+}
+
+// parseVersion returns the requested version, 0 when none was requested, and
+// false when the version parameter is malformed.
+func parseVersion(r *http.Request) (int, bool) {
+	v, ok := AcceptParam(r, versionParam)
+	if !ok {
+		return 0, true
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// Versioned returns a handler that dispatches to the handler registered for
+// the requested version, letting a route change its response shape without a
+// new URL path. Requests without a version get the latest one. Requesting a
+// version that is not registered, or one that is malformed, yields 406 Not
+// Acceptable.
+func Versioned(versions map[int]echo.HandlerFunc) echo.HandlerFunc {
+	latest := 0
+	for v := range versions {
+		latest = max(latest, v)
+	}
+
+	return func(c *echo.Context) error {
+		r := c.Request()
+		version, cached := r.Context().Value(ctxVersionKey{}).(int)
+		if !cached {
+			var ok bool
+			if version, ok = parseVersion(r); !ok {
+				return Error406("version must be a positive integer")
+			}
+		}
+		if version == 0 {
+			version = latest
+		}
+		h, ok := versions[version]
+		if !ok {
+			return Error406(fmt.Sprintf("version %d is not available", version))
+		}
+		return h(c)
+	}
+}
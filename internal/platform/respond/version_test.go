@@ -0,0 +1,80 @@
+package respond
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestVersioned(t *testing.T) {
+	for _, withMiddleware := range []bool{true, false} {
+		e := echo.New()
+		e.HTTPErrorHandler = NewHTTPErrorHandler()
+		if withMiddleware {
+			e.Use(FormatNegotiation(), VersionNegotiation())
+		}
+		e.GET("/profile", Versioned(map[int]echo.HandlerFunc{
+			1: func(c *echo.Context) error { return c.String(http.StatusOK, "v1") },
+			2: func(c *echo.Context) error { return c.String(http.StatusOK, "v2") },
+		}))
+
+		tests := []struct {
+			accept string
+			status int
+			body   string
+		}{
+			{"application/json;version=1", http.StatusOK, "v1"},
+			{"application/json;version=2", http.StatusOK, "v2"},
+			{"application/json", http.StatusOK, "v2"},
+			{"", http.StatusOK, "v2"},
+			{"application/cbor;version=1, application/json;version=2;q=0.5", http.StatusOK, "v1"},
+			{"application/json;version=3", http.StatusNotAcceptable, ""},
+			{"application/json;version=latest", http.StatusNotAcceptable, ""},
+			{"application/json;version=0", http.StatusNotAcceptable, ""},
+		}
+		for _, tt := range tests {
+			name := tt.accept
+			if withMiddleware {
+				name += " cached"
+			}
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+				req.Header.Set("Accept", tt.accept)
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+
+				if rec.Code != tt.status {
+					t.Fatalf("expected %d, got %d; body: %s", tt.status, rec.Code, rec.Body.String())
+				}
+				if tt.body != "" && rec.Body.String() != tt.body {
+					t.Fatalf("expected %q, got %q", tt.body, rec.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestRequestedVersion(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   int
+		ok     bool
+	}{
+		{"application/json;version=4", 4, true},
+		{"application/json", 0, false},
+		{"application/json;version=x", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.accept)
+
+			got, ok := RequestedVersion(req)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("expected (%d, %v), got (%d, %v)", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
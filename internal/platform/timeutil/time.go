@@ -2,33 +2,88 @@ package timeutil
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// RFC3339Seconds is RFC 3339 UTC with whole-second precision.
+const RFC3339Seconds = "2006-01-02T15:04:05Z"
+
 // RFC3339Millis is RFC 3339 UTC with fixed millisecond precision.
 const RFC3339Millis = "2006-01-02T15:04:05.000Z"
 
 // RFC3339Micros is RFC 3339 UTC with fixed microsecond precision.
 const RFC3339Micros = "2006-01-02T15:04:05.000000Z"
 
-// Time wraps time.Time to ensure consistent RFC 3339 millisecond precision
-// in JSON and CBOR marshaling. Output format is always "2024-01-15T10:30:00.000Z".
+// RFC3339Nanos is RFC 3339 UTC with fixed nanosecond precision.
+const RFC3339Nanos = "2006-01-02T15:04:05.000000000Z"
+
+// Precision selects the fractional-second digits used when marshaling a Time.
+// The zero value is PrecisionMillis.
+type Precision int
+
+const (
+	PrecisionMillis Precision = iota
+	PrecisionSeconds
+	PrecisionMicros
+	PrecisionNanos
+)
+
+// Layout returns the fixed-width RFC 3339 layout for the precision.
+// Unknown values fall back to RFC3339Millis.
+func (p Precision) Layout() string {
+	switch p {
+	case PrecisionSeconds:
+		return RFC3339Seconds
+	case PrecisionMicros:
+		return RFC3339Micros
+	case PrecisionNanos:
+		return RFC3339Nanos
+	default:
+		return RFC3339Millis
+	}
+}
+
+// precision holds the Precision every Time marshals with.
+var precision atomic.Int32
+
+// SetPrecision selects the precision every Time marshals with. It is meant to
+// be called once at startup; the default is PrecisionMillis.
+func SetPrecision(p Precision) {
+	precision.Store(int32(p))
+}
+
+// Time wraps time.Time to ensure consistent RFC 3339 precision in JSON and
+// CBOR marshaling. Output uses millisecond precision
+// ("2024-01-15T10:30:00.000Z") unless SetPrecision selects another.
 type Time struct {
 	time.Time
 }
 
-// MarshalJSON implements json.Marshaler with fixed millisecond precision.
+// format renders t in UTC using the package precision.
+func (t Time) format() string {
+	return t.UTC().Format(Precision(precision.Load()).Layout())
+}
+
+// MarshalJSON implements json.Marshaler with fixed precision (milliseconds by default).
 func (t Time) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + t.UTC().Format(RFC3339Millis) + `"`), nil
+	return []byte(`"` + t.format() + `"`), nil
 }
 
-// UnmarshalJSON implements json.Unmarshaler, accepting RFC 3339 variants.
+// UnmarshalJSON implements json.Unmarshaler, accepting RFC 3339 variants and
+// numeric Unix epoch seconds (integer or decimal).
 // JSON null preserves the existing value, matching time.Time stdlib behavior.
 func (t *Time) UnmarshalJSON(data []byte) error {
 	if string(data) == "null" {
 		return nil
 	}
 	s := string(data)
+	if len(s) > 0 && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) {
+		return t.unmarshalJSONEpoch(s)
+	}
 	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
 		s = s[1 : len(s)-1]
 	}
@@ -43,10 +98,65 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// MarshalCBOR implements cbor.Marshaler with fixed millisecond precision.
+// unmarshalJSONEpoch parses a JSON number as Unix epoch seconds. Plain integer
+// and decimal forms are parsed exactly so large values and sub-second digits do
+// not lose precision through float64; exponent forms fall back to float64.
+func (t *Time) unmarshalJSONEpoch(s string) error {
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+	if sec, err := strconv.ParseInt(intPart, 10, 64); err == nil {
+		if !hasFrac {
+			t.Time = time.Unix(sec, 0).UTC()
+			return nil
+		}
+		if nsec, ok := parseFractionNanos(fracPart); ok {
+			if strings.HasPrefix(intPart, "-") {
+				nsec = -nsec
+			}
+			t.Time = time.Unix(sec, nsec).UTC()
+			return nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return errors.New("timeutil: invalid JSON epoch number")
+	}
+	parsed, err := epochFloat(f)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// parseFractionNanos converts the digits after a decimal point to nanoseconds,
+// truncating beyond nanosecond precision.
+func parseFractionNanos(frac string) (int64, bool) {
+	if frac == "" {
+		return 0, false
+	}
+	var nsec int64
+	for i := range 9 {
+		nsec *= 10
+		if i < len(frac) {
+			c := frac[i]
+			if c < '0' || c > '9' {
+				return 0, false
+			}
+			nsec += int64(c - '0')
+		}
+	}
+	for i := 9; i < len(frac); i++ {
+		if frac[i] < '0' || frac[i] > '9' {
+			return 0, false
+		}
+	}
+	return nsec, true
+}
+
+// MarshalCBOR implements cbor.Marshaler with fixed precision (milliseconds by default).
 // Encodes as CBOR tag 0 (standard date/time string per RFC 8949 section 3.4.1).
 func (t Time) MarshalCBOR() ([]byte, error) {
-	s := t.UTC().Format(RFC3339Millis)
+	s := t.format()
 	data := make([]byte, 0, 2+len(s))
 	data = append(data, 0xc0) // tag 0
 	data = appendCBORTextString(data, s)
@@ -54,15 +164,24 @@ func (t Time) MarshalCBOR() ([]byte, error) {
 }
 
 // UnmarshalCBOR implements cbor.Unmarshaler, accepting CBOR tag 0 date/time
-// strings and bare text strings.
+// strings, tag 1 epoch-based date/times (integer or floating-point seconds
+// per RFC 8949 section 3.4.2), and bare text strings or numbers.
 func (t *Time) UnmarshalCBOR(data []byte) error {
 	if len(data) == 0 {
 		return errors.New("timeutil: empty CBOR data")
 	}
-	// Strip optional tag 0 (0xc0).
-	if data[0] == 0xc0 {
+	// Strip optional tag 0 (0xc0) or tag 1 (0xc1).
+	if data[0] == 0xc0 || data[0] == 0xc1 {
 		data = data[1:]
 	}
+	if len(data) > 0 && data[0]&0xe0 != 0x60 {
+		parsed, err := decodeCBOREpoch(data)
+		if err != nil {
+			return err
+		}
+		t.Time = parsed
+		return nil
+	}
 	s, err := decodeCBORTextString(data)
 	if err != nil {
 		return err
@@ -129,6 +248,118 @@ func decodeCBORTextString(data []byte) (string, error) {
 	return string(data[offset : offset+length]), nil //nolint:gosec // G602 false positive: bounds checked above
 }
 
+// decodeCBOREpoch decodes a CBOR unsigned integer (major type 0), negative
+// integer (major type 1), or floating-point value (major type 7) as Unix epoch
+// seconds.
+func decodeCBOREpoch(data []byte) (time.Time, error) {
+	major := data[0] & 0xe0
+	info := data[0] & 0x1f
+	switch major {
+	case 0x00, 0x20:
+		n, err := decodeCBORArgument(data)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if n > math.MaxInt64 {
+			return time.Time{}, errors.New("timeutil: CBOR epoch out of range")
+		}
+		sec := int64(n)
+		if major == 0x20 {
+			sec = -1 - sec
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	case 0xe0:
+		var f float64
+		switch info {
+		case 25:
+			if len(data) < 3 {
+				return time.Time{}, errors.New("timeutil: truncated CBOR float")
+			}
+			f = float16ToFloat64(uint16(data[1])<<8 | uint16(data[2]))
+		case 26:
+			if len(data) < 5 {
+				return time.Time{}, errors.New("timeutil: truncated CBOR float")
+			}
+			bits := uint32(data[1])<<24 | uint32(data[2])<<16 | uint32(data[3])<<8 | uint32(data[4])
+			f = float64(math.Float32frombits(bits))
+		case 27:
+			if len(data) < 9 {
+				return time.Time{}, errors.New("timeutil: truncated CBOR float")
+			}
+			var bits uint64
+			for _, b := range data[1:9] {
+				bits = bits<<8 | uint64(b)
+			}
+			f = math.Float64frombits(bits)
+		default:
+			return time.Time{}, errors.New("timeutil: expected CBOR number")
+		}
+		return epochFloat(f)
+	default:
+		return time.Time{}, errors.New("timeutil: expected CBOR text string or number")
+	}
+}
+
+// decodeCBORArgument decodes the unsigned argument of a CBOR integer head.
+func decodeCBORArgument(data []byte) (uint64, error) {
+	info := data[0] & 0x1f
+	var size int
+	switch {
+	case info <= 23:
+		return uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, errors.New("timeutil: unsupported CBOR integer encoding")
+	}
+	if len(data) < 1+size {
+		return 0, errors.New("timeutil: truncated CBOR integer")
+	}
+	var n uint64
+	for _, b := range data[1 : 1+size] {
+		n = n<<8 | uint64(b)
+	}
+	return n, nil
+}
+
+// float16ToFloat64 converts an IEEE 754 half-precision value to float64.
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(mant+1024, exp-25)
+	}
+}
+
+// epochFloat converts floating-point Unix epoch seconds to a UTC time,
+// rejecting NaN, infinities, and values outside the int64 seconds range.
+func epochFloat(f float64) (time.Time, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return time.Time{}, errors.New("timeutil: epoch out of range")
+	}
+	sec, frac := math.Modf(f)
+	nsec := int64(math.Round(frac * 1e9))
+	return time.Unix(int64(sec), nsec).UTC(), nil
+}
+
 // NewTime creates a Time from a standard time.Time.
 func NewTime(t time.Time) Time {
 	return Time{Time: t}
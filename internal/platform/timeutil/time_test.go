@@ -162,9 +162,100 @@ func TestUnmarshalCBOR_InvalidTimeString(t *testing.T) {
 
 func TestUnmarshalCBOR_InvalidMajorType(t *testing.T) {
 	var ts Time
-	err := ts.UnmarshalCBOR([]byte{0x01})
+	err := ts.UnmarshalCBOR([]byte{0x41, 0x01})
 	if err == nil {
-		t.Fatal("expected error for non-text-string CBOR")
+		t.Fatal("expected error for byte string CBOR")
+	}
+}
+
+func TestUnmarshalCBOR_Tag1Integer(t *testing.T) {
+	// tag 1, uint32 1705314600 (2024-01-15T10:30:00Z)
+	data := []byte{0xc1, 0x1a, 0x65, 0xa5, 0x09, 0x28}
+	var ts Time
+	if err := ts.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	if !ts.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, ts.Time)
+	}
+}
+
+func TestUnmarshalCBOR_Tag1Float(t *testing.T) {
+	// tag 1, float64 1705314600.5
+	data := []byte{0xc1, 0xfb, 0x41, 0xd9, 0x69, 0x42, 0x4a, 0x20, 0x00, 0x00}
+	var ts Time
+	if err := ts.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2024, 1, 15, 10, 30, 0, 500000000, time.UTC)
+	if !ts.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, ts.Time)
+	}
+}
+
+func TestUnmarshalCBOR_BareInteger(t *testing.T) {
+	var ts Time
+	if err := ts.UnmarshalCBOR([]byte{0x18, 0x3c}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ts.Equal(time.Unix(60, 0)) {
+		t.Fatalf("expected epoch+60s, got %v", ts.Time)
+	}
+}
+
+func TestUnmarshalCBOR_NegativeInteger(t *testing.T) {
+	var ts Time
+	if err := ts.UnmarshalCBOR([]byte{0xc1, 0x20}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ts.Equal(time.Unix(-1, 0)) {
+		t.Fatalf("expected epoch-1s, got %v", ts.Time)
+	}
+}
+
+func TestUnmarshalCBOR_HalfAndSingleFloat(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want time.Time
+	}{
+		{"half 1.5", []byte{0xc1, 0xf9, 0x3e, 0x00}, time.Unix(1, 500000000)},
+		{"single 100.25", []byte{0xc1, 0xfa, 0x42, 0xc8, 0x80, 0x00}, time.Unix(100, 250000000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ts Time
+			if err := ts.UnmarshalCBOR(tt.data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ts.Equal(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, ts.Time)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCBOR_EpochErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated uint32", []byte{0xc1, 0x1a, 0x65}},
+		{"uint64 overflow", []byte{0xc1, 0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"truncated float64", []byte{0xc1, 0xfb, 0x41}},
+		{"float NaN", []byte{0xc1, 0xf9, 0x7e, 0x00}},
+		{"float infinity", []byte{0xc1, 0xf9, 0x7c, 0x00}},
+		{"simple value", []byte{0xc1, 0xf5}},
+		{"tag without content", []byte{0xc1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ts Time
+			if err := ts.UnmarshalCBOR(tt.data); err == nil {
+				t.Fatalf("expected error, got %v", ts.Time)
+			}
+		})
 	}
 }
 
@@ -342,3 +433,140 @@ func TestDecodeCBORTextString_TwoByteLengthTruncatedPayload(t *testing.T) {
 		t.Fatal("expected error for truncated 2-byte length payload")
 	}
 }
+
+func TestUnmarshalJSON_EpochInteger(t *testing.T) {
+	var ts Time
+	if err := ts.UnmarshalJSON([]byte(`1705314600`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	if !ts.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, ts.Time)
+	}
+}
+
+func TestUnmarshalJSON_EpochDecimal(t *testing.T) {
+	var ts Time
+	if err := ts.UnmarshalJSON([]byte(`1705314600.123`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ts.UTC().Format(RFC3339Millis); got != "2024-01-15T10:30:00.123Z" {
+		t.Fatalf("unexpected time: %s", got)
+	}
+}
+
+func TestUnmarshalJSON_EpochInvalid(t *testing.T) {
+	for _, in := range []string{`1e400`, `12abc`, `-`} {
+		var ts Time
+		if err := ts.UnmarshalJSON([]byte(in)); err == nil {
+			t.Fatalf("expected error for %s", in)
+		}
+	}
+}
+
+func TestPrecision_Marshal(t *testing.T) {
+	base := time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		name      string
+		precision Precision
+		want      string
+	}{
+		{"default millis", PrecisionMillis, "2024-06-01T12:00:00.123Z"},
+		{"seconds", PrecisionSeconds, "2024-06-01T12:00:00Z"},
+		{"micros", PrecisionMicros, "2024-06-01T12:00:00.123456Z"},
+		{"nanos", PrecisionNanos, "2024-06-01T12:00:00.123456789Z"},
+		{"unknown falls back to millis", Precision(99), "2024-06-01T12:00:00.123Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPrecision(tt.precision)
+			t.Cleanup(func() { SetPrecision(PrecisionMillis) })
+			ts := NewTime(base)
+
+			b, err := json.Marshal(ts)
+			if err != nil {
+				t.Fatalf("marshal error: %v", err)
+			}
+			if string(b) != `"`+tt.want+`"` {
+				t.Fatalf("expected %q, got %s", tt.want, b)
+			}
+
+			cb, err := ts.MarshalCBOR()
+			if err != nil {
+				t.Fatalf("cbor marshal error: %v", err)
+			}
+			s, err := decodeCBORTextString(cb[1:])
+			if err != nil {
+				t.Fatalf("cbor decode error: %v", err)
+			}
+			if s != tt.want {
+				t.Fatalf("expected CBOR %q, got %q", tt.want, s)
+			}
+		})
+	}
+}
+
+func TestPrecision_NotPartOfValue(t *testing.T) {
+	SetPrecision(PrecisionNanos)
+	t.Cleanup(func() { SetPrecision(PrecisionMillis) })
+
+	base := NewTime(time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC))
+	cb, err := base.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("cbor marshal error: %v", err)
+	}
+	var fromJSON, fromCBOR Time
+	if err := json.Unmarshal([]byte(`1717243200.123456789`), &fromJSON); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if err := fromCBOR.UnmarshalCBOR(cb); err != nil {
+		t.Fatalf("cbor unmarshal error: %v", err)
+	}
+	if fromJSON != base || fromCBOR != base {
+		t.Fatalf("expected values equal to %v, got %v and %v", base, fromJSON, fromCBOR)
+	}
+}
+
+func TestPrecision_Roundtrip(t *testing.T) {
+	base := time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		precision Precision
+		want      time.Time
+	}{
+		{PrecisionSeconds, base.Truncate(time.Second)},
+		{PrecisionMillis, base.Truncate(time.Millisecond)},
+		{PrecisionMicros, base.Truncate(time.Microsecond)},
+		{PrecisionNanos, base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.precision.Layout(), func(t *testing.T) {
+			SetPrecision(tt.precision)
+			t.Cleanup(func() { SetPrecision(PrecisionMillis) })
+			original := NewTime(base)
+
+			b, err := json.Marshal(original)
+			if err != nil {
+				t.Fatalf("marshal error: %v", err)
+			}
+			var fromJSON Time
+			if err := json.Unmarshal(b, &fromJSON); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
+			}
+			if !fromJSON.Equal(tt.want) {
+				t.Fatalf("JSON roundtrip: want %v, got %v", tt.want, fromJSON.Time)
+			}
+
+			cb, err := original.MarshalCBOR()
+			if err != nil {
+				t.Fatalf("cbor marshal error: %v", err)
+			}
+			var fromCBOR Time
+			if err := fromCBOR.UnmarshalCBOR(cb); err != nil {
+				t.Fatalf("cbor unmarshal error: %v", err)
+			}
+			if !fromCBOR.Equal(tt.want) {
+				t.Fatalf("CBOR roundtrip: want %v, got %v", tt.want, fromCBOR.Time)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package timing
+
+import (
+	"github.com/labstack/echo/v5"
+)
+
+// ServerTiming returns Echo middleware that attaches a Recorder to the request
+// context and writes its segments to the Server-Timing header just before the
+// response is committed. Segments still running at that point are omitted, and
+// nothing is emitted when the response was already committed upstream.
+func ServerTiming() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c *echo.Context) error {
+			resp, err := echo.UnwrapResponse(c.Response())
+			if err != nil || resp.Committed {
+				return next(c)
+			}
+
+			rec := NewRecorder()
+			c.SetRequest(c.Request().WithContext(NewContext(c.Request().Context(), rec)))
+
+			resp.Before(func() {
+				if v := rec.Header(); v != "" {
+					resp.Header().Set(HeaderServerTiming, v)
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
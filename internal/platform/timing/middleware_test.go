@@ -0,0 +1,99 @@
+package timing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestServerTiming_EmitsRecordedSegment(t *testing.T) {
+	e := echo.New()
+	e.Use(ServerTiming())
+	e.GET("/", func(c *echo.Context) error {
+		rec := FromContext(c.Request().Context())
+		rec.Start("db")
+		time.Sleep(time.Millisecond)
+		rec.Stop("db")
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	header := rec.Header().Get(HeaderServerTiming)
+	name, dur, ok := strings.Cut(header, ";dur=")
+	if !ok || name != "db" {
+		t.Fatalf("expected db segment, got %q", header)
+	}
+	ms, err := strconv.ParseFloat(dur, 64)
+	if err != nil {
+		t.Fatalf("expected numeric duration, got %q", dur)
+	}
+	if ms <= 0 {
+		t.Fatalf("expected positive duration, got %v", ms)
+	}
+}
+
+func TestServerTiming_NoSegments(t *testing.T) {
+	e := echo.New()
+	e.Use(ServerTiming())
+	e.GET("/", func(c *echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Get(HeaderServerTiming); got != "" {
+		t.Fatalf("expected no Server-Timing header, got %q", got)
+	}
+}
+
+func TestServerTiming_SkipsCommittedResponse(t *testing.T) {
+	e := echo.New()
+	e.Use(
+		func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c *echo.Context) error {
+				c.Response().WriteHeader(http.StatusAccepted)
+				return next(c)
+			}
+		},
+		ServerTiming(),
+	)
+	e.GET("/", func(c *echo.Context) error {
+		if FromContext(c.Request().Context()) != nil {
+			t.Error("expected no recorder for committed response")
+		}
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Result().Header.Get(HeaderServerTiming); got != "" {
+		t.Fatalf("expected no Server-Timing header, got %q", got)
+	}
+}
+
+func TestServerTiming_ErrorResponse(t *testing.T) {
+	e := echo.New()
+	e.Use(ServerTiming())
+	e.GET("/", func(c *echo.Context) error {
+		rec := FromContext(c.Request().Context())
+		rec.Start("auth")
+		rec.Stop("auth")
+		return echo.ErrUnauthorized
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !strings.HasPrefix(rec.Header().Get(HeaderServerTiming), "auth;dur=") {
+		t.Fatalf("expected auth segment on error response, got %q", rec.Header().Get(HeaderServerTiming))
+	}
+}
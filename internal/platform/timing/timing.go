@@ -0,0 +1,119 @@
+// Package timing records named phase durations for a request and exposes
+// them to clients through the Server-Timing response header.
+package timing
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderServerTiming is the Server-Timing response header name.
+const HeaderServerTiming = "Server-Timing"
+
+type ctxRecorderKey struct{}
+
+// Recorder accumulates durations of named segments. Repeated Start/Stop
+// pairs for the same name add to its total. A nil Recorder is a no-op, so
+// callers can use FromContext without checking for middleware presence.
+type Recorder struct {
+	mu       sync.Mutex
+	segments []*segment
+	now      func() time.Time
+}
+
+type segment struct {
+	name    string
+	total   time.Duration
+	started time.Time
+	running bool
+	stopped bool
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{now: time.Now}
+}
+
+// Start begins timing the named segment. Starting a running segment is a no-op.
+func (r *Recorder) Start(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.lookup(name)
+	if s == nil {
+		s = &segment{name: name}
+		r.segments = append(r.segments, s)
+	}
+	if !s.running {
+		s.started = r.now()
+		s.running = true
+	}
+}
+
+// Stop ends timing the named segment. Stopping a segment that is not
+// running is a no-op.
+func (r *Recorder) Stop(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s := r.lookup(name); s != nil && s.running {
+		s.total += r.now().Sub(s.started)
+		s.running = false
+		s.stopped = true
+	}
+}
+
+// Header formats completed segments as a Server-Timing header value with
+// durations in milliseconds. It returns an empty string when nothing was recorded.
+func (r *Recorder) Header() string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parts := make([]string, 0, len(r.segments))
+	for _, s := range r.segments {
+		if !s.stopped {
+			continue
+		}
+		ms := float64(s.total) / float64(time.Millisecond)
+		parts = append(parts, s.name+";dur="+strconv.FormatFloat(ms, 'f', 3, 64))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *Recorder) lookup(name string) *segment {
+	for _, s := range r.segments {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// NewContext returns a copy of ctx carrying r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctxRecorderKey{}, r)
+}
+
+// FromContext returns the Recorder carried by ctx, or nil if none is present.
+func FromContext(ctx context.Context) *Recorder {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(ctxRecorderKey{}).(*Recorder)
+	return r
+}
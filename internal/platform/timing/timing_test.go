@@ -0,0 +1,75 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func fakeClock(step time.Duration) func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestRecorder_Header(t *testing.T) {
+	r := NewRecorder()
+	r.now = fakeClock(2 * time.Millisecond)
+
+	r.Start("auth")
+	r.Stop("auth")
+	r.Start("db")
+	r.Stop("db")
+
+	got := r.Header()
+	want := "auth;dur=2.000, db;dur=2.000"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRecorder_Accumulates(t *testing.T) {
+	r := NewRecorder()
+	r.now = fakeClock(time.Millisecond)
+
+	r.Start("db")
+	r.Stop("db")
+	r.Start("db")
+	r.Stop("db")
+
+	if got := r.Header(); got != "db;dur=2.000" {
+		t.Fatalf("expected accumulated duration, got %q", got)
+	}
+}
+
+func TestRecorder_OmitsRunningSegments(t *testing.T) {
+	r := NewRecorder()
+	r.Start("db")
+	r.Stop("serialize")
+
+	if got := r.Header(); got != "" {
+		t.Fatalf("expected empty header, got %q", got)
+	}
+}
+
+func TestRecorder_NilIsNoop(t *testing.T) {
+	var r *Recorder
+	r.Start("db")
+	r.Stop("db")
+	if got := r.Header(); got != "" {
+		t.Fatalf("expected empty header, got %q", got)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != nil {
+		t.Fatal("expected nil recorder for bare context")
+	}
+
+	r := NewRecorder()
+	if got := FromContext(NewContext(context.Background(), r)); got != r {
+		t.Fatalf("expected stored recorder, got %v", got)
+	}
+}
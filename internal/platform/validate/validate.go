@@ -28,11 +28,23 @@ func (e *ValidationError) Error() string {
 
 // AppValidator wraps go-playground/validator for Echo's Validator interface.
 type AppValidator struct {
-	v *validator.Validate
+	v            *validator.Validate
+	valueContext bool
+}
+
+// Option configures an AppValidator.
+type Option func(*AppValidator)
+
+// WithValueContext appends the rejected value to field messages, e.g.
+// `category must be one of: tools, power (got "toys")`. The value is always
+// available in FieldError.Value; enable this only for clients that show the
+// message alone, since values can contain personal data.
+func WithValueContext() Option {
+	return func(av *AppValidator) { av.valueContext = true }
 }
 
 // New creates a new AppValidator.
-func New() *AppValidator {
+func New(opts ...Option) *AppValidator {
 	v := validator.New()
 
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -48,7 +60,15 @@ func New() *AppValidator {
 		return fld.Name
 	})
 
-	return &AppValidator{v: v}
+	if err := v.RegisterValidation("implies", implies); err != nil {
+		panic(err)
+	}
+
+	av := &AppValidator{v: v}
+	for _, opt := range opts {
+		opt(av)
+	}
+	return av
 }
 
 // Validate validates the given struct and returns a *ValidationError on failure.
@@ -64,7 +84,7 @@ func (av *AppValidator) Validate(i any) error {
 		for idx, fe := range ve {
 			fields[idx] = FieldError{
 				Field:   fe.Field(),
-				Message: buildMessage(fe),
+				Message: av.message(fe),
 				Value:   fmt.Sprintf("%v", fe.Value()),
 			}
 		}
@@ -85,22 +105,115 @@ func tagName(fld reflect.StructField, tag string) string {
 	return name
 }
 
+// implies is a cross-field rule: when the bool field is true, the sibling
+// named by the parameter (its JSON name or Go name) must also be true, e.g.
+// `validate:"implies=terms"` on a marketing consent flag.
+func implies(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.Bool || !fl.Field().Bool() {
+		return true
+	}
+	other, ok := siblingField(fl.Parent(), fl.Param())
+	for ok && other.Kind() == reflect.Pointer {
+		if other.IsNil() {
+			return false
+		}
+		other = other.Elem()
+	}
+	return ok && other.Kind() == reflect.Bool && other.Bool()
+}
+
+func siblingField(parent reflect.Value, name string) (reflect.Value, bool) {
+	for parent.Kind() == reflect.Pointer {
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	t := parent.Type()
+	for i := range t.NumField() {
+		if fld := t.Field(i); tagName(fld, "json") == name || fld.Name == name {
+			return parent.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func (av *AppValidator) message(fe validator.FieldError) string {
+	msg := buildMessage(fe)
+	if !av.valueContext || fe.Tag() == "required" {
+		return msg
+	}
+	if v, ok := fe.Value().(string); ok {
+		return fmt.Sprintf("%s (got %q)", msg, v)
+	}
+	return fmt.Sprintf("%s (got %v)", msg, fe.Value())
+}
+
 func buildMessage(fe validator.FieldError) string {
 	field := fe.Field()
 	switch fe.Tag() {
 	case "required":
 		return field + " is required"
-	case "min":
+	case "min", "gte":
+		if unit := sizeUnit(fe.Kind()); unit != "" {
+			return field + " must " + unit + " at least " + fe.Param() + " " + itemNoun(fe)
+		}
+		if fe.Tag() == "gte" {
+			return field + " must be greater than or equal to " + fe.Param()
+		}
 		return field + " must be at least " + fe.Param()
-	case "max":
+	case "max", "lte":
+		if unit := sizeUnit(fe.Kind()); unit != "" {
+			return field + " must " + unit + " at most " + fe.Param() + " " + itemNoun(fe)
+		}
+		if fe.Tag() == "lte" {
+			return field + " must be less than or equal to " + fe.Param()
+		}
 		return field + " must be at most " + fe.Param()
+	case "len":
+		if unit := sizeUnit(fe.Kind()); unit != "" {
+			return field + " must " + unit + " exactly " + fe.Param() + " " + itemNoun(fe)
+		}
+		return field + " must equal " + fe.Param()
 	case "email":
 		return field + " must be a valid email address"
 	case "e164":
 		return field + " must be a valid E.164 phone number"
+	case "url":
+		return field + " must be a valid URL"
+	case "uuid":
+		return field + " must be a valid UUID"
+	case "alphanum":
+		return field + " must contain only letters and digits"
 	case "oneof":
-		return field + " must be one of: " + fe.Param()
+		return field + " must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "implies":
+		return field + " requires " + fe.Param() + " to be true"
 	default:
 		return field + " failed on " + fe.Tag() + " validation"
 	}
 }
+
+// sizeUnit returns the verb for size constraints on strings and collections,
+// whose min, max, and len rules compare lengths, or "" for other kinds.
+func sizeUnit(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "be"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "contain"
+	default:
+		return ""
+	}
+}
+
+func itemNoun(fe validator.FieldError) string {
+	noun := "item"
+	if fe.Kind() == reflect.String {
+		noun = "character"
+	}
+	if fe.Param() != "1" {
+		noun += "s"
+	}
+	return noun
+}
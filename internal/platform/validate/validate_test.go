@@ -186,7 +186,7 @@ func TestValidate_Oneof(t *testing.T) {
 	if ve.Fields[0].Field != "category" {
 		t.Fatalf("expected field 'category', got %q", ve.Fields[0].Field)
 	}
-	if ve.Fields[0].Message != "category must be one of: electronics tools accessories" {
+	if ve.Fields[0].Message != "category must be one of: electronics, tools, accessories" {
 		t.Fatalf("unexpected message: %s", ve.Fields[0].Message)
 	}
 }
@@ -299,6 +299,57 @@ func TestValidate_DefaultBuildMessage(t *testing.T) {
 	}
 }
 
+type consentInput struct {
+	Marketing bool  `json:"marketing" validate:"implies=terms"`
+	Terms     *bool `json:"terms"`
+	Analytics bool  `json:"analytics" validate:"implies=Tracking"`
+	Tracking  bool  `json:"tracking"`
+}
+
+func TestValidate_Implies(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		input  consentInput
+		failed []string
+	}{
+		{"both true", consentInput{Marketing: true, Terms: &yes}, nil},
+		{"antecedent false", consentInput{Marketing: false, Terms: &yes}, nil},
+		{"both false", consentInput{Terms: &no}, nil},
+		{"consequent false", consentInput{Marketing: true, Terms: &no}, []string{"marketing"}},
+		{"consequent nil", consentInput{Marketing: true}, []string{"marketing"}},
+		{"go name", consentInput{Analytics: true, Tracking: true}, nil},
+		{"go name false", consentInput{Analytics: true}, []string{"analytics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.input)
+			if tt.failed == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected *ValidationError, got %T", err)
+			}
+			if len(ve.Fields) != 1 || ve.Fields[0].Field != tt.failed[0] {
+				t.Fatalf("expected error on %v, got %+v", tt.failed, ve.Fields)
+			}
+		})
+	}
+
+	err := New().Validate(consentInput{Marketing: true, Terms: &no})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %T", err)
+	}
+	if ve.Fields[0].Message != "marketing requires terms to be true" {
+		t.Fatalf("unexpected message %q", ve.Fields[0].Message)
+	}
+}
+
 type noTagInput struct {
 	Name string `validate:"required"`
 }
@@ -353,3 +404,77 @@ func TestValidate_NonStructInput(t *testing.T) {
 		t.Fatal("expected non-empty message")
 	}
 }
+
+type constraintInput struct {
+	Code     string   `json:"code"     validate:"omitempty,len=6"`
+	Tags     []string `json:"tags"     validate:"omitempty,min=1,max=2"`
+	Pair     []int    `json:"pair"     validate:"omitempty,len=2"`
+	Nickname *string  `json:"nickname" validate:"omitempty,min=3,max=20"`
+	Price    float64  `json:"price"    validate:"gte=0,lte=1000"`
+	Quantity int      `json:"quantity" validate:"omitempty,len=1"`
+	Bio      string   `json:"bio"      validate:"omitempty,lte=10"`
+	Website  string   `json:"website"  validate:"omitempty,url"`
+	ID       string   `json:"id"       validate:"omitempty,uuid"`
+	Handle   string   `json:"handle"   validate:"omitempty,alphanum"`
+}
+
+func TestValidate_ConstraintMessages(t *testing.T) {
+	short := "jd"
+	tests := []struct {
+		name  string
+		input constraintInput
+		field string
+		want  string
+	}{
+		{"len string", constraintInput{Code: "abc"}, "code", "code must be exactly 6 characters"},
+		{"len slice", constraintInput{Pair: []int{1}}, "pair", "pair must contain exactly 2 items"},
+		{"len number", constraintInput{Quantity: 3}, "quantity", "quantity must equal 1"},
+		{"max slice", constraintInput{Tags: []string{"a", "b", "c"}}, "tags", "tags must contain at most 2 items"},
+		{"min pointer string", constraintInput{Nickname: &short}, "nickname", "nickname must be at least 3 characters"},
+		{"gte number", constraintInput{Price: -1}, "price", "price must be greater than or equal to 0"},
+		{"lte number", constraintInput{Price: 1001}, "price", "price must be less than or equal to 1000"},
+		{"lte string", constraintInput{Bio: "far too long"}, "bio", "bio must be at most 10 characters"},
+		{"url", constraintInput{Website: "not a url"}, "website", "website must be a valid URL"},
+		{"uuid", constraintInput{ID: "1234"}, "id", "id must be a valid UUID"},
+		{"alphanum", constraintInput{Handle: "j_doe"}, "handle", "handle must contain only letters and digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate(tt.input)
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			if len(ve.Fields) != 1 {
+				t.Fatalf("expected 1 field error, got %+v", ve.Fields)
+			}
+			if ve.Fields[0].Field != tt.field || ve.Fields[0].Message != tt.want {
+				t.Fatalf("expected %s: %q, got %s: %q", tt.field, tt.want, ve.Fields[0].Field, ve.Fields[0].Message)
+			}
+		})
+	}
+}
+
+func TestValidate_ValueContext(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		want  string
+	}{
+		{"string", listInput{Category: "toys"}, `category must be one of: electronics, tools, accessories (got "toys")`},
+		{"number", listInput{Limit: 101}, "limit must be at most 100 (got 101)"},
+		{"required omits value", pathInput{}, "id is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(WithValueContext()).Validate(tt.input)
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			if ve.Fields[0].Message != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, ve.Fields[0].Message)
+			}
+		})
+	}
+}
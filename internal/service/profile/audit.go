@@ -0,0 +1,29 @@
+package profile
+
+// redacted replaces personal data in audit diffs.
+const redacted = "[REDACTED]"
+
+// auditChanges returns the fields that differ between before and after, keyed
+// by their JSON name, for inclusion in audit event details. Personal data is
+// redacted so the log records that a field changed without its values.
+func auditChanges(before, after *Profile) map[string]any {
+	changes := make(map[string]any)
+	recordChange(changes, "firstname", before.Firstname, after.Firstname, true)
+	recordChange(changes, "lastname", before.Lastname, after.Lastname, true)
+	recordChange(changes, "email", before.Email, after.Email, true)
+	recordChange(changes, "phoneNumber", before.PhoneNumber, after.PhoneNumber, true)
+	recordChange(changes, "marketing", before.Marketing, after.Marketing, false)
+	recordChange(changes, "terms", before.Terms, after.Terms, false)
+	return changes
+}
+
+func recordChange[T comparable](changes map[string]any, field string, old, new T, personal bool) {
+	if old == new {
+		return
+	}
+	if personal {
+		changes[field] = map[string]any{"old": redacted, "new": redacted}
+		return
+	}
+	changes[field] = map[string]any{"old": old, "new": new}
+}
@@ -0,0 +1,111 @@
+package profile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+)
+
+func TestAuditChanges(t *testing.T) {
+	now := time.Now()
+	before := NewProfile("user-1", testCreateParams(), now)
+	after := *before
+
+	firstname := "Jane"
+	email := "jane@example.com"
+	marketing := true
+	ApplyUpdate(&after, UpdateParams{Firstname: &firstname, Email: &email, Marketing: &marketing}, now.Add(time.Second))
+
+	changes := auditChanges(before, &after)
+
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changed fields, got %v", changes)
+	}
+	for _, field := range []string{"lastname", "phoneNumber", "terms"} {
+		if _, ok := changes[field]; ok {
+			t.Fatalf("expected unchanged %s to be absent, got %v", field, changes[field])
+		}
+	}
+
+	fn, ok := changes["firstname"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected firstname diff entry, got %v", changes["firstname"])
+	}
+	if fn["old"] != redacted || fn["new"] != redacted {
+		t.Fatalf("expected firstname values redacted, got %v", fn)
+	}
+
+	em, ok := changes["email"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected email diff entry, got %v", changes["email"])
+	}
+	if em["old"] != redacted || em["new"] != redacted {
+		t.Fatalf("expected email values redacted, got %v", em)
+	}
+
+	mk, ok := changes["marketing"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected marketing diff entry, got %v", changes["marketing"])
+	}
+	if mk["old"] != false || mk["new"] != true {
+		t.Fatalf("expected marketing false -> true, got %v", mk)
+	}
+}
+
+func TestAuditChanges_NoChanges(t *testing.T) {
+	p := NewProfile("user-1", testCreateParams(), time.Now())
+	if changes := auditChanges(p, p); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+}
+
+func TestFirestoreStore_UpdateAuditsChanges(t *testing.T) {
+	store, _ := newFakeStore()
+	var buf bytes.Buffer
+	ctx := applog.ContextWithLogger(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	if _, err := store.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	buf.Reset()
+
+	email := "new@example.com"
+	marketing := true
+	if _, err := store.Update(ctx, "user-1", UpdateParams{Email: &email, Marketing: &marketing}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	var details map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to unmarshal log entry: %v", err)
+		}
+		if entry["msg"] == "Audit event" && entry["audit.action"] == "update" {
+			details, _ = entry["audit.details"].(map[string]any)
+		}
+	}
+	changes, ok := details["changes"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected audit changes, got %v", details)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changed fields, got %v", changes)
+	}
+	if em, _ := changes["email"].(map[string]any); em["old"] != redacted || em["new"] != redacted {
+		t.Fatalf("expected email values redacted, got %v", changes["email"])
+	}
+	if mk, _ := changes["marketing"].(map[string]any); mk["old"] != false || mk["new"] != true {
+		t.Fatalf("expected marketing false -> true, got %v", changes["marketing"])
+	}
+	if bytes.Contains(buf.Bytes(), []byte(email)) {
+		t.Fatal("expected the new email to be absent from the log")
+	}
+}
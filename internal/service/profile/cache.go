@@ -0,0 +1,167 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Cache defaults applied when CacheConfig fields are zero.
+const (
+	DefaultCacheMaxStale   = 10 * time.Minute
+	DefaultCacheMaxEntries = 10000
+)
+
+// CacheConfig configures CachedService.
+type CacheConfig struct {
+	// MaxStale is how old a cached profile may be and still be served by
+	// Stale.
+	MaxStale time.Duration
+	// MaxEntries bounds the number of cached profiles.
+	MaxEntries int
+}
+
+type cacheEntry struct {
+	profile  *Profile
+	cachedAt time.Time
+}
+
+// CachedService decorates a Service with a read-through cache of the
+// profiles it has returned. Every read still goes to the wrapped service, and
+// writes update or evict the entry, so the cache never answers Get itself; it
+// only backs Stale, which handlers consult when Get fails with a transient
+// error.
+type CachedService struct {
+	next Service
+	cfg  CacheConfig
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCachedService wraps next with a profile cache.
+func NewCachedService(next Service, cfg CacheConfig) *CachedService {
+	if cfg.MaxStale <= 0 {
+		cfg.MaxStale = DefaultCacheMaxStale
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultCacheMaxEntries
+	}
+	return &CachedService{next: next, cfg: cfg, now: time.Now, entries: map[string]cacheEntry{}}
+}
+
+// Stale returns the cached profile of userID and its age when it is younger
+// than MaxStale.
+func (s *CachedService) Stale(userID string) (*Profile, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[userID]
+	if !ok {
+		return nil, 0, false
+	}
+	age := s.now().Sub(e.cachedAt)
+	if age > s.cfg.MaxStale {
+		delete(s.entries, userID)
+		return nil, 0, false
+	}
+	p := *e.profile
+	return &p, age, true
+}
+
+func (s *CachedService) Create(ctx context.Context, userID string, params CreateParams) (*Profile, error) {
+	p, err := s.next.Create(ctx, userID, params)
+	s.record(userID, p, err)
+	return p, err
+}
+
+func (s *CachedService) Get(ctx context.Context, userID string) (*Profile, error) {
+	p, err := s.next.Get(ctx, userID)
+	s.record(userID, p, err)
+	return p, err
+}
+
+func (s *CachedService) Exists(ctx context.Context, userID string) (bool, error) {
+	return s.next.Exists(ctx, userID)
+}
+
+func (s *CachedService) Update(ctx context.Context, userID string, params UpdateParams) (*Profile, error) {
+	p, err := s.next.Update(ctx, userID, params)
+	s.record(userID, p, err)
+	return p, err
+}
+
+func (s *CachedService) Upsert(ctx context.Context, userID string, params CreateParams) (*Profile, bool, error) {
+	p, created, err := s.next.Upsert(ctx, userID, params)
+	s.record(userID, p, err)
+	return p, created, err
+}
+
+func (s *CachedService) Delete(ctx context.Context, userID string, params DeleteParams) error {
+	err := s.next.Delete(ctx, userID, params)
+	// A failed delete may still have committed, so the entry is evicted
+	// regardless of the outcome to keep Stale from resurrecting it.
+	s.evict(userID)
+	return err
+}
+
+func (s *CachedService) Merge(ctx context.Context, sourceUID, targetUID string) (*Profile, error) {
+	p, err := s.next.Merge(ctx, sourceUID, targetUID)
+	// A failed merge may still have committed, so neither entry is trusted
+	// for Stale afterwards.
+	s.evict(sourceUID)
+	if err != nil {
+		s.evict(targetUID)
+		return nil, err
+	}
+	s.store(targetUID, p)
+	return p, nil
+}
+
+// record caches p after a successful call and evicts userID when the
+// profile is gone. Other failures leave the entry for Stale.
+func (s *CachedService) record(userID string, p *Profile, err error) {
+	switch {
+	case err == nil && p != nil:
+		s.store(userID, p)
+	case errors.Is(err, ErrNotFound):
+		s.evict(userID)
+	}
+}
+
+func (s *CachedService) store(userID string, p *Profile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if _, ok := s.entries[userID]; !ok && len(s.entries) >= s.cfg.MaxEntries {
+		s.evictOne(now)
+	}
+	cp := *p
+	s.entries[userID] = cacheEntry{profile: &cp, cachedAt: now}
+}
+
+// evictOne removes expired entries, or an arbitrary one when none expired.
+// Callers must hold s.mu.
+func (s *CachedService) evictOne(now time.Time) {
+	for id, e := range s.entries {
+		if now.Sub(e.cachedAt) > s.cfg.MaxStale {
+			delete(s.entries, id)
+		}
+	}
+	if len(s.entries) < s.cfg.MaxEntries {
+		return
+	}
+	for id := range s.entries {
+		delete(s.entries, id)
+		return
+	}
+}
+
+func (s *CachedService) evict(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, userID)
+}
+
+var _ Service = (*CachedService)(nil)
@@ -0,0 +1,114 @@
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestCache(next Service, cfg CacheConfig, now *time.Time) *CachedService {
+	s := NewCachedService(next, cfg)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestCachedService_StaleAfterOutage(t *testing.T) {
+	now := time.Now()
+	flaky := &flakyService{Service: NewMockStore(), err: status.Error(codes.Unavailable, "unavailable")}
+	svc := newTestCache(flaky, CacheConfig{MaxStale: time.Minute}, &now)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	flaky.failures = 1
+	if _, err := svc.Get(ctx, "user-1"); err == nil {
+		t.Fatal("expected the injected outage")
+	}
+
+	now = now.Add(30 * time.Second)
+	p, age, ok := svc.Stale("user-1")
+	if !ok || p.Firstname != "John" || age != 30*time.Second {
+		t.Fatalf("expected a 30s old cached profile, got %v %v %v", p, age, ok)
+	}
+	p.Firstname = "Mutated"
+	if cached, _, _ := svc.Stale("user-1"); cached.Firstname != "John" {
+		t.Fatal("expected Stale to return a copy")
+	}
+
+	now = now.Add(time.Minute)
+	if _, _, ok = svc.Stale("user-1"); ok {
+		t.Fatal("expected entries older than MaxStale to expire")
+	}
+}
+
+func TestCachedService_DeleteEvicts(t *testing.T) {
+	now := time.Now()
+	svc := newTestCache(NewMockStore(), CacheConfig{}, &now)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := svc.Delete(ctx, "user-1", DeleteParams{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, ok := svc.Stale("user-1"); ok {
+		t.Fatal("expected a deleted profile to leave the cache")
+	}
+}
+
+func TestCachedService_DeleteEvictsOnTransientError(t *testing.T) {
+	now := time.Now()
+	flaky := &flakyService{Service: NewMockStore(), err: status.Error(codes.Unavailable, "unavailable")}
+	svc := newTestCache(flaky, CacheConfig{}, &now)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	flaky.failures = 1
+	if err := svc.Delete(ctx, "user-1", DeleteParams{}); err == nil {
+		t.Fatal("expected the injected outage")
+	}
+	if _, _, ok := svc.Stale("user-1"); ok {
+		t.Fatal("expected a possibly committed delete to leave the cache")
+	}
+}
+
+func TestCachedService_MergeEvictsOnError(t *testing.T) {
+	now := time.Now()
+	svc := newTestCache(NewMockStore(), CacheConfig{}, &now)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Merge(ctx, "user-1", "missing"); err == nil {
+		t.Fatal("expected merge into a missing profile to fail")
+	}
+	if _, _, ok := svc.Stale("user-1"); ok {
+		t.Fatal("expected a failed merge to evict the source")
+	}
+}
+
+func TestCachedService_MaxEntries(t *testing.T) {
+	now := time.Now()
+	svc := newTestCache(NewMockStore(), CacheConfig{MaxEntries: 2}, &now)
+	ctx := context.Background()
+
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		if _, err := svc.Create(ctx, id, testCreateParams()); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	if n := len(svc.entries); n != 2 {
+		t.Fatalf("expected 2 cached profiles, got %d", n)
+	}
+	if _, _, ok := svc.Stale("user-3"); !ok {
+		t.Fatal("expected the newest profile to be cached")
+	}
+}
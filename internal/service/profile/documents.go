@@ -0,0 +1,182 @@
+package profile
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/janisto/echo-playground/internal/platform/budget"
+)
+
+// Documents is the subset of Firestore operations FirestoreStore performs.
+// Paths are slash-separated document paths such as "profiles/user-123". Like
+// Firestore, implementations report a missing document with a gRPC NotFound
+// status and decode documents into dst with Firestore struct tags.
+type Documents interface {
+	Get(ctx context.Context, path string, dst any) error
+	// Exists reports whether the document exists without reading its fields.
+	Exists(ctx context.Context, path string) (bool, error)
+	// Probe queries collection ordered by orderBy, descending, with limit 1,
+	// so missing indexes and connectivity problems surface as errors.
+	Probe(ctx context.Context, collection, orderBy string) error
+	// RunTransaction runs fn in a transaction, retrying it on contention.
+	// Writes take effect only when fn returns nil.
+	RunTransaction(ctx context.Context, fn func(ctx context.Context, tx Transaction) error) error
+}
+
+// Transaction is the subset of Firestore transaction operations
+// FirestoreStore performs. Every Get must precede the first write.
+type Transaction interface {
+	Get(path string, dst any) error
+	Set(path string, data any) error
+	Delete(path string) error
+}
+
+// clientDocuments implements Documents with a Firestore client.
+type clientDocuments struct {
+	client *firestore.Client
+}
+
+// FirestoreDocuments returns the Documents backed by client.
+func FirestoreDocuments(client *firestore.Client) Documents {
+	return clientDocuments{client: client}
+}
+
+func (d clientDocuments) Get(ctx context.Context, path string, dst any) error {
+	ref, err := docRef(d.client, path)
+	if err != nil {
+		return err
+	}
+	doc, err := ref.Get(ctx)
+	if err != nil {
+		return err
+	}
+	return doc.DataTo(dst)
+}
+
+// Exists selects no fields, so only the document name is read.
+func (d clientDocuments) Exists(ctx context.Context, path string) (bool, error) {
+	ref, err := docRef(d.client, path)
+	if err != nil {
+		return false, err
+	}
+	docs, err := ref.Parent.Where(firestore.DocumentID, "==", ref).
+		Select().
+		Limit(1).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return false, err
+	}
+	return len(docs) > 0, nil
+}
+
+func (d clientDocuments) Probe(ctx context.Context, collection, orderBy string) error {
+	_, err := d.client.Collection(collection).
+		OrderBy(orderBy, firestore.Desc).
+		Limit(1).
+		Documents(ctx).
+		GetAll()
+	return err
+}
+
+func (d clientDocuments) RunTransaction(
+	ctx context.Context,
+	fn func(ctx context.Context, tx Transaction) error,
+) error {
+	return d.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		return fn(ctx, clientTransaction{client: d.client, tx: tx})
+	})
+}
+
+// clientTransaction implements Transaction with a Firestore transaction.
+type clientTransaction struct {
+	client *firestore.Client
+	tx     *firestore.Transaction
+}
+
+func (t clientTransaction) Get(path string, dst any) error {
+	ref, err := docRef(t.client, path)
+	if err != nil {
+		return err
+	}
+	doc, err := t.tx.Get(ref)
+	if err != nil {
+		return err
+	}
+	return doc.DataTo(dst)
+}
+
+func (t clientTransaction) Set(path string, data any) error {
+	ref, err := docRef(t.client, path)
+	if err != nil {
+		return err
+	}
+	return t.tx.Set(ref, data)
+}
+
+func (t clientTransaction) Delete(path string) error {
+	ref, err := docRef(t.client, path)
+	if err != nil {
+		return err
+	}
+	return t.tx.Delete(ref)
+}
+
+// budgetedDocuments runs every operation under the request's budget.Budget,
+// so the Firestore calls one request makes share a single time allowance.
+type budgetedDocuments struct {
+	next Documents
+}
+
+func (d budgetedDocuments) Get(ctx context.Context, path string, dst any) error {
+	return budget.Run(ctx, func(ctx context.Context) error {
+		return d.next.Get(ctx, path, dst)
+	})
+}
+
+func (d budgetedDocuments) Exists(ctx context.Context, path string) (bool, error) {
+	var exists bool
+	err := budget.Run(ctx, func(ctx context.Context) error {
+		var err error
+		exists, err = d.next.Exists(ctx, path)
+		return err
+	})
+	return exists, err
+}
+
+func (d budgetedDocuments) Probe(ctx context.Context, collection, orderBy string) error {
+	return budget.Run(ctx, func(ctx context.Context) error {
+		return d.next.Probe(ctx, collection, orderBy)
+	})
+}
+
+// RunTransaction spends the budget on the whole transaction, including
+// retries on contention.
+func (d budgetedDocuments) RunTransaction(
+	ctx context.Context,
+	fn func(ctx context.Context, tx Transaction) error,
+) error {
+	return budget.Run(ctx, func(ctx context.Context) error {
+		return d.next.RunTransaction(ctx, fn)
+	})
+}
+
+// docRef resolves a "collection/id" path built by docPath. IDs come from
+// callers such as Firebase UIDs and may contain "/", which would address a
+// different document or none at all, so such paths are rejected.
+func docRef(client *firestore.Client, path string) (*firestore.DocumentRef, error) {
+	collection, id, ok := strings.Cut(path, "/")
+	if !ok || collection == "" || id == "" || strings.Contains(id, "/") {
+		return nil, status.Error(codes.InvalidArgument, "invalid document path")
+	}
+	return client.Collection(collection).Doc(id), nil
+}
+
+// docPath returns the path of document id in collection.
+func docPath(collection, id string) string {
+	return collection + "/" + id
+}
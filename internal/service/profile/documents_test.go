@@ -0,0 +1,169 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDocuments implements Documents in memory, so FirestoreStore logic can
+// be tested without the emulator. Transactions are serialized and their writes
+// applied only when the transaction function succeeds.
+type fakeDocuments struct {
+	mu   sync.Mutex
+	docs map[string]any
+	// err, when set, fails every operation.
+	err error
+	// delay, when set, makes every operation wait that long or until its
+	// context is done.
+	delay time.Duration
+	// calls counts the operations started.
+	calls int
+}
+
+func newFakeDocuments() *fakeDocuments {
+	return &fakeDocuments{docs: make(map[string]any)}
+}
+
+// newFakeStore returns a FirestoreStore backed by a fakeDocuments.
+func newFakeStore(opts ...FirestoreOption) (*FirestoreStore, *fakeDocuments) {
+	docs := newFakeDocuments()
+	return NewDocumentStore(docs, opts...), docs
+}
+
+// wait records an operation and applies delay. Callers must hold f.mu.
+func (f *fakeDocuments) wait(ctx context.Context) error {
+	f.calls++
+	if f.delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(f.delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (f *fakeDocuments) Get(ctx context.Context, path string, dst any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	return f.get(path, dst)
+}
+
+// get copies the document at path into dst. Callers must hold f.mu.
+func (f *fakeDocuments) get(path string, dst any) error {
+	if f.err != nil {
+		return f.err
+	}
+	v, ok := f.docs[path]
+	if !ok {
+		return status.Errorf(codes.NotFound, "document %s not found", path)
+	}
+	out := reflect.ValueOf(dst)
+	if out.Kind() != reflect.Pointer || !reflect.TypeOf(v).AssignableTo(out.Elem().Type()) {
+		return fmt.Errorf("cannot decode %T into %T", v, dst)
+	}
+	out.Elem().Set(reflect.ValueOf(v))
+	return nil
+}
+
+func (f *fakeDocuments) Exists(ctx context.Context, path string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.wait(ctx); err != nil {
+		return false, err
+	}
+	if f.err != nil {
+		return false, f.err
+	}
+	_, ok := f.docs[path]
+	return ok, nil
+}
+
+func (f *fakeDocuments) Probe(ctx context.Context, _, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+	return f.err
+}
+
+func (f *fakeDocuments) RunTransaction(ctx context.Context, fn func(ctx context.Context, tx Transaction) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.wait(ctx); err != nil {
+		return err
+	}
+
+	tx := &fakeTransaction{docs: f, writes: make(map[string]any)}
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	for path, v := range tx.writes {
+		if v == nil {
+			delete(f.docs, path)
+			continue
+		}
+		f.docs[path] = v
+	}
+	return nil
+}
+
+// fakeTransaction stages writes until its transaction commits. A nil write
+// deletes the document.
+type fakeTransaction struct {
+	docs   *fakeDocuments
+	writes map[string]any
+}
+
+func (t *fakeTransaction) Get(path string, dst any) error {
+	if len(t.writes) > 0 {
+		return errors.New("firestore: read after write in transaction")
+	}
+	return t.docs.get(path, dst)
+}
+
+func (t *fakeTransaction) Set(path string, data any) error {
+	t.writes[path] = data
+	return nil
+}
+
+func (t *fakeTransaction) Delete(path string) error {
+	t.writes[path] = nil
+	return nil
+}
+
+var _ Documents = (*fakeDocuments)(nil)
+
+func TestDocRef(t *testing.T) {
+	client := &firestore.Client{}
+
+	ref, err := docRef(client, docPath(profilesCollection, "user-1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.ID != "user-1" || ref.Parent.ID != profilesCollection {
+		t.Fatalf("expected profiles/user-1, got %s/%s", ref.Parent.ID, ref.ID)
+	}
+
+	for _, id := range []string{"a/b", "a/b/c", ""} {
+		if _, err := docRef(client, docPath(profilesCollection, id)); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("id %q: expected InvalidArgument, got %v", id, err)
+		}
+	}
+}
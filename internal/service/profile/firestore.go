@@ -3,17 +3,21 @@ package profile
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/janisto/echo-playground/internal/platform/fieldcrypt"
 	applog "github.com/janisto/echo-playground/internal/platform/logging"
 )
 
-const profilesCollection = "profiles"
+const (
+	profilesCollection = "profiles"
+	// mergedCollection archives profiles merged into another profile.
+	mergedCollection = "merged_profiles"
+)
 
 func categorizeError(err error) string {
 	switch {
@@ -21,6 +25,12 @@ func categorizeError(err error) string {
 		return "already_exists"
 	case errors.Is(err, ErrNotFound):
 		return "not_found"
+	case errors.Is(err, ErrVersionMismatch):
+		return "version_mismatch"
+	case errors.Is(err, ErrSameProfile):
+		return "same_profile"
+	case IsTransient(err):
+		return "transient"
 	default:
 		return "internal_error"
 	}
@@ -38,58 +48,145 @@ type firestoreProfile struct {
 	UpdatedAt   time.Time `firestore:"updated_at"`
 }
 
+// firestoreMergedProfile is the archived form of a merged source profile. The
+// profile keeps its stored encoding, so encrypted fields stay bound to the
+// source document ID.
+type firestoreMergedProfile struct {
+	Profile    firestoreProfile `firestore:"profile"`
+	MergedInto string           `firestore:"merged_into"`
+	MergedAt   time.Time        `firestore:"merged_at"`
+}
+
+func toFirestoreProfile(p *Profile) firestoreProfile {
+	return firestoreProfile{
+		Firstname:   p.Firstname,
+		Lastname:    p.Lastname,
+		Email:       p.Email,
+		PhoneNumber: p.PhoneNumber,
+		Marketing:   p.Marketing,
+		Terms:       p.Terms,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func (fp firestoreProfile) toProfile(id string) *Profile {
+	return &Profile{
+		ID:          id,
+		Firstname:   fp.Firstname,
+		Lastname:    fp.Lastname,
+		Email:       fp.Email,
+		PhoneNumber: fp.PhoneNumber,
+		Marketing:   fp.Marketing,
+		Terms:       fp.Terms,
+		CreatedAt:   fp.CreatedAt,
+		UpdatedAt:   fp.UpdatedAt,
+	}
+}
+
 // FirestoreStore implements Service using Firestore with transactions.
 type FirestoreStore struct {
-	client *firestore.Client
+	docs    Documents
+	keyring *fieldcrypt.Keyring
+}
+
+// FirestoreOption configures a FirestoreStore.
+type FirestoreOption func(*FirestoreStore)
+
+// WithFieldEncryption encrypts Email and PhoneNumber with keyring before they
+// are written and decrypts them on read.
+func WithFieldEncryption(keyring *fieldcrypt.Keyring) FirestoreOption {
+	return func(s *FirestoreStore) {
+		s.keyring = keyring
+	}
 }
 
 // NewFirestoreStore creates a new Firestore-backed store.
-func NewFirestoreStore(client *firestore.Client) *FirestoreStore {
-	return &FirestoreStore{client: client}
+func NewFirestoreStore(client *firestore.Client, opts ...FirestoreOption) *FirestoreStore {
+	return NewDocumentStore(FirestoreDocuments(client), opts...)
+}
+
+// NewDocumentStore creates a FirestoreStore that performs its Firestore
+// operations through docs, such as a fake in tests. Each operation runs under
+// the budget.Budget on its context, if any, and fails with budget.ErrExhausted
+// once the request has spent it.
+func NewDocumentStore(docs Documents, opts ...FirestoreOption) *FirestoreStore {
+	s := &FirestoreStore{docs: budgetedDocuments{next: docs}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// encode converts p to its stored form, encrypting PII fields when a keyring
+// is configured.
+func (s *FirestoreStore) encode(p *Profile) (firestoreProfile, error) {
+	fp := toFirestoreProfile(p)
+	if s.keyring == nil {
+		return fp, nil
+	}
+	var err error
+	if fp.Email, err = s.keyring.Encrypt(fp.Email, piiAAD(p.ID, "email")); err != nil {
+		return firestoreProfile{}, err
+	}
+	if fp.PhoneNumber, err = s.keyring.Encrypt(fp.PhoneNumber, piiAAD(p.ID, "phone_number")); err != nil {
+		return firestoreProfile{}, err
+	}
+	return fp, nil
+}
+
+// decode converts a stored document to a Profile, decrypting PII fields when
+// a keyring is configured.
+func (s *FirestoreStore) decode(id string, fp firestoreProfile) (*Profile, error) {
+	if s.keyring != nil {
+		var err error
+		if fp.Email, err = s.keyring.Decrypt(fp.Email, piiAAD(id, "email")); err != nil {
+			return nil, err
+		}
+		if fp.PhoneNumber, err = s.keyring.Decrypt(fp.PhoneNumber, piiAAD(id, "phone_number")); err != nil {
+			return nil, err
+		}
+	}
+	return fp.toProfile(id), nil
+}
+
+// piiAAD binds an encrypted value to its document and field.
+func piiAAD(id, field string) string {
+	return docPath(profilesCollection, id) + "#" + field
 }
 
 // Create creates a new profile using a transaction to prevent duplicates.
 func (s *FirestoreStore) Create(ctx context.Context, userID string, params CreateParams) (*Profile, error) {
-	docRef := s.client.Collection(profilesCollection).Doc(userID)
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	path := docPath(profilesCollection, userID)
 	now := time.Now().UTC()
 
 	var result *Profile
 
-	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		doc, err := tx.Get(docRef)
-		if err == nil && doc.Exists() {
+	err = s.docs.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+		var existing firestoreProfile
+		err := tx.Get(path, &existing)
+		if err == nil {
 			return ErrAlreadyExists
 		}
-		if err != nil && status.Code(err) != codes.NotFound {
+		if status.Code(err) != codes.NotFound {
 			return err
 		}
 
-		fp := firestoreProfile{
-			Firstname:   params.Firstname,
-			Lastname:    params.Lastname,
-			Email:       strings.ToLower(strings.TrimSpace(params.Email)),
-			PhoneNumber: strings.TrimSpace(params.PhoneNumber),
-			Marketing:   params.Marketing,
-			Terms:       params.Terms,
-			CreatedAt:   now,
-			UpdatedAt:   now,
+		p := NewProfile(userID, params, now)
+		fp, err := s.encode(p)
+		if err != nil {
+			return err
 		}
-
-		if err := tx.Set(docRef, fp); err != nil {
+		if err := tx.Set(path, fp); err != nil {
 			return err
 		}
 
-		result = &Profile{
-			ID:          userID,
-			Firstname:   fp.Firstname,
-			Lastname:    fp.Lastname,
-			Email:       fp.Email,
-			PhoneNumber: fp.PhoneNumber,
-			Marketing:   fp.Marketing,
-			Terms:       fp.Terms,
-			CreatedAt:   fp.CreatedAt,
-			UpdatedAt:   fp.UpdatedAt,
-		}
+		result = p
 		return nil
 	})
 	if err != nil {
@@ -105,85 +202,61 @@ func (s *FirestoreStore) Create(ctx context.Context, userID string, params Creat
 
 // Get retrieves a profile by user ID.
 func (s *FirestoreStore) Get(ctx context.Context, userID string) (*Profile, error) {
-	docRef := s.client.Collection(profilesCollection).Doc(userID)
-	doc, err := docRef.Get(ctx)
-	if err != nil {
+	var fp firestoreProfile
+	if err := s.docs.Get(ctx, docPath(profilesCollection, userID), &fp); err != nil {
 		if status.Code(err) == codes.NotFound {
 			return nil, ErrNotFound
 		}
 		return nil, err
 	}
 
-	var fp firestoreProfile
-	if err := doc.DataTo(&fp); err != nil {
-		return nil, err
-	}
+	return s.decode(userID, fp)
+}
 
-	return &Profile{
-		ID:          userID,
-		Firstname:   fp.Firstname,
-		Lastname:    fp.Lastname,
-		Email:       fp.Email,
-		PhoneNumber: fp.PhoneNumber,
-		Marketing:   fp.Marketing,
-		Terms:       fp.Terms,
-		CreatedAt:   fp.CreatedAt,
-		UpdatedAt:   fp.UpdatedAt,
-	}, nil
+// Exists reports whether a profile document exists. Only the document name is
+// read, so nothing is decrypted.
+func (s *FirestoreStore) Exists(ctx context.Context, userID string) (bool, error) {
+	return s.docs.Exists(ctx, docPath(profilesCollection, userID))
 }
 
 // Update updates a profile using a transaction for atomicity.
 func (s *FirestoreStore) Update(ctx context.Context, userID string, params UpdateParams) (*Profile, error) {
-	docRef := s.client.Collection(profilesCollection).Doc(userID)
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	path := docPath(profilesCollection, userID)
 
 	var result *Profile
+	var changes map[string]any
 
-	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		doc, err := tx.Get(docRef)
-		if err != nil {
+	err = s.docs.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+		var fp firestoreProfile
+		if err := tx.Get(path, &fp); err != nil {
 			if status.Code(err) == codes.NotFound {
 				return ErrNotFound
 			}
 			return err
 		}
 
-		var fp firestoreProfile
-		if err := doc.DataTo(&fp); err != nil {
+		before, err := s.decode(userID, fp)
+		if err != nil {
 			return err
 		}
+		p := *before
+		ApplyUpdate(&p, params, time.Now().UTC())
 
-		if params.Firstname != nil {
-			fp.Firstname = *params.Firstname
-		}
-		if params.Lastname != nil {
-			fp.Lastname = *params.Lastname
-		}
-		if params.Email != nil {
-			fp.Email = strings.ToLower(strings.TrimSpace(*params.Email))
-		}
-		if params.PhoneNumber != nil {
-			fp.PhoneNumber = strings.TrimSpace(*params.PhoneNumber)
-		}
-		if params.Marketing != nil {
-			fp.Marketing = *params.Marketing
+		stored, err := s.encode(&p)
+		if err != nil {
+			return err
 		}
-		fp.UpdatedAt = time.Now().UTC()
-
-		if err := tx.Set(docRef, fp); err != nil {
+		if err := tx.Set(path, stored); err != nil {
 			return err
 		}
 
-		result = &Profile{
-			ID:          userID,
-			Firstname:   fp.Firstname,
-			Lastname:    fp.Lastname,
-			Email:       fp.Email,
-			PhoneNumber: fp.PhoneNumber,
-			Marketing:   fp.Marketing,
-			Terms:       fp.Terms,
-			CreatedAt:   fp.CreatedAt,
-			UpdatedAt:   fp.UpdatedAt,
-		}
+		result = &p
+		changes = auditChanges(before, &p)
 		return nil
 	})
 	if err != nil {
@@ -192,25 +265,91 @@ func (s *FirestoreStore) Update(ctx context.Context, userID string, params Updat
 		return nil, err
 	}
 
-	applog.LogAuditEvent(ctx, "update", userID, "profile", userID, "success", nil)
+	applog.LogAuditEvent(ctx, "update", userID, "profile", userID, "success",
+		map[string]any{"changes": changes})
 
 	return result, nil
 }
 
-// Delete removes a profile using a transaction to ensure it exists.
-func (s *FirestoreStore) Delete(ctx context.Context, userID string) error {
-	docRef := s.client.Collection(profilesCollection).Doc(userID)
+// Upsert creates or replaces a profile in a single transaction, so concurrent
+// calls never create duplicates or lose CreatedAt.
+func (s *FirestoreStore) Upsert(ctx context.Context, userID string, params CreateParams) (*Profile, bool, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, false, err
+	}
+
+	path := docPath(profilesCollection, userID)
+
+	var result *Profile
+	var created bool
+	var changes map[string]any
+
+	err = s.docs.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+		now := time.Now().UTC()
+		p := NewProfile(userID, params, now)
+		created, changes = true, nil
+
+		var fp firestoreProfile
+		err := tx.Get(path, &fp)
+		switch {
+		case err == nil:
+			var before *Profile
+			if before, err = s.decode(userID, fp); err != nil {
+				return err
+			}
+			p = ReplaceProfile(before, params, now)
+			created = false
+			changes = auditChanges(before, p)
+		case status.Code(err) != codes.NotFound:
+			return err
+		}
 
-	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		_, err := tx.Get(docRef)
+		stored, err := s.encode(p)
 		if err != nil {
+			return err
+		}
+		if err := tx.Set(path, stored); err != nil {
+			return err
+		}
+
+		result = p
+		return nil
+	})
+	if err != nil {
+		applog.LogAuditEvent(ctx, "upsert", userID, "profile", userID, "failure",
+			map[string]any{"error": categorizeError(err)})
+		return nil, false, err
+	}
+
+	if created {
+		applog.LogAuditEvent(ctx, "create", userID, "profile", userID, "success", nil)
+	} else {
+		applog.LogAuditEvent(ctx, "update", userID, "profile", userID, "success",
+			map[string]any{"changes": changes})
+	}
+
+	return result, created, nil
+}
+
+// Delete removes a profile using a transaction to ensure it exists and, for
+// conditional deletes, that its version has not changed.
+func (s *FirestoreStore) Delete(ctx context.Context, userID string, params DeleteParams) error {
+	path := docPath(profilesCollection, userID)
+
+	err := s.docs.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+		var fp firestoreProfile
+		if err := tx.Get(path, &fp); err != nil {
 			if status.Code(err) == codes.NotFound {
 				return ErrNotFound
 			}
 			return err
 		}
+		if !params.matches(fp.toProfile(userID)) {
+			return ErrVersionMismatch
+		}
 
-		return tx.Delete(docRef)
+		return tx.Delete(path)
 	})
 	if err != nil {
 		applog.LogAuditEvent(ctx, "delete", userID, "profile", userID, "failure",
@@ -223,4 +362,66 @@ func (s *FirestoreStore) Delete(ctx context.Context, userID string) error {
 	return nil
 }
 
+// Merge folds the source profile into the target in one transaction, so the
+// target is updated, the source archived in merged_profiles, and the source
+// document deleted together or not at all.
+func (s *FirestoreStore) Merge(ctx context.Context, sourceUID, targetUID string) (*Profile, error) {
+	if sourceUID == targetUID {
+		return nil, ErrSameProfile
+	}
+
+	ids := []string{sourceUID, targetUID}
+
+	var result *Profile
+	var changes map[string]any
+
+	err := s.docs.RunTransaction(ctx, func(ctx context.Context, tx Transaction) error {
+		stored := make([]firestoreProfile, len(ids))
+		decoded := make([]*Profile, len(ids))
+		for i, id := range ids {
+			err := tx.Get(docPath(profilesCollection, id), &stored[i])
+			if status.Code(err) == codes.NotFound {
+				return ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if decoded[i], err = s.decode(id, stored[i]); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		p := MergeProfiles(decoded[0], decoded[1], now)
+		fp, err := s.encode(p)
+		if err != nil {
+			return err
+		}
+		if err = tx.Set(docPath(profilesCollection, targetUID), fp); err != nil {
+			return err
+		}
+		archived := firestoreMergedProfile{Profile: stored[0], MergedInto: targetUID, MergedAt: now}
+		if err = tx.Set(docPath(mergedCollection, sourceUID), archived); err != nil {
+			return err
+		}
+		if err = tx.Delete(docPath(profilesCollection, sourceUID)); err != nil {
+			return err
+		}
+
+		result = p
+		changes = auditChanges(decoded[1], p)
+		return nil
+	})
+	if err != nil {
+		applog.LogAuditEvent(ctx, "merge", targetUID, "profile", sourceUID, "failure",
+			map[string]any{"error": categorizeError(err), "target": targetUID})
+		return nil, err
+	}
+
+	applog.LogAuditEvent(ctx, "merge", targetUID, "profile", sourceUID, "success",
+		map[string]any{"changes": changes, "target": targetUID})
+
+	return result, nil
+}
+
 var _ Service = (*FirestoreStore)(nil)
@@ -4,14 +4,20 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/janisto/echo-playground/internal/platform/budget"
+	"github.com/janisto/echo-playground/internal/platform/fieldcrypt"
 	"github.com/janisto/echo-playground/internal/testutil"
 )
 
-func newTestStore(t *testing.T) (*FirestoreStore, func()) {
+func newTestStore(t *testing.T, opts ...FirestoreOption) (*FirestoreStore, func()) {
 	t.Helper()
 	testutil.RequireEmulator(t)
 
@@ -21,11 +27,13 @@ func newTestStore(t *testing.T) (*FirestoreStore, func()) {
 		t.Fatalf("failed to create firestore client: %v", err)
 	}
 
-	store := NewFirestoreStore(client)
+	store := NewFirestoreStore(client, opts...)
 	cleanup := func() {
-		docs, _ := client.Collection(profilesCollection).Documents(ctx).GetAll()
-		for _, doc := range docs {
-			_, _ = doc.Ref.Delete(ctx)
+		for _, collection := range []string{profilesCollection, mergedCollection} {
+			docs, _ := client.Collection(collection).Documents(ctx).GetAll()
+			for _, doc := range docs {
+				_, _ = doc.Ref.Delete(ctx)
+			}
 		}
 		_ = client.Close()
 	}
@@ -80,6 +88,82 @@ func TestFirestoreStore_CreateAndGet(t *testing.T) {
 	}
 }
 
+func newTestKeyring(t *testing.T) *fieldcrypt.Keyring {
+	t.Helper()
+	keyring, err := fieldcrypt.NewKeyring(fieldcrypt.Key{ID: "k1", Secret: make([]byte, fieldcrypt.KeySize)})
+	if err != nil {
+		t.Fatalf("failed to create keyring: %v", err)
+	}
+	return keyring
+}
+
+func TestFirestoreStore_EncodeDecode_Encrypted(t *testing.T) {
+	store := NewFirestoreStore(nil, WithFieldEncryption(newTestKeyring(t)))
+	p := &Profile{ID: "user-001", Firstname: "John", Email: "john@example.com", PhoneNumber: "+1234567890"}
+
+	fp, err := store.encode(p)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(fp.Email) || !fieldcrypt.IsEncrypted(fp.PhoneNumber) {
+		t.Fatalf("expected encrypted PII, got %q and %q", fp.Email, fp.PhoneNumber)
+	}
+	if fp.Firstname != "John" {
+		t.Fatalf("expected firstname stored as plaintext, got %q", fp.Firstname)
+	}
+
+	got, err := store.decode("user-001", fp)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if got.Email != p.Email || got.PhoneNumber != p.PhoneNumber {
+		t.Fatalf("expected decrypted PII, got %q and %q", got.Email, got.PhoneNumber)
+	}
+
+	if _, err := store.decode("user-002", fp); err == nil {
+		t.Fatal("expected ciphertext bound to another document to fail")
+	}
+}
+
+func TestFirestoreStore_EncryptedAtRest(t *testing.T) {
+	store, cleanup := newTestStore(t, WithFieldEncryption(newTestKeyring(t)))
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := store.Create(ctx, "user-enc", CreateParams{
+		Firstname:   "Jane",
+		Lastname:    "Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+1234567890",
+		Terms:       true,
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	var raw firestoreProfile
+	if err = store.docs.Get(ctx, docPath(profilesCollection, "user-enc"), &raw); err != nil {
+		t.Fatalf("failed to read raw document: %v", err)
+	}
+	email := raw.Email
+	if !fieldcrypt.IsEncrypted(email) || strings.Contains(email, "jane@example.com") {
+		t.Fatalf("expected email ciphertext at rest, got %q", email)
+	}
+
+	newEmail := "jane.doe@example.com"
+	if _, err := store.Update(ctx, "user-enc", UpdateParams{Email: &newEmail}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	got, err := store.Get(ctx, "user-enc")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Email != newEmail || got.PhoneNumber != "+1234567890" {
+		t.Fatalf("expected plaintext from Get, got %q and %q", got.Email, got.PhoneNumber)
+	}
+}
+
 func TestFirestoreStore_CreateDuplicate(t *testing.T) {
 	store, cleanup := newTestStore(t)
 	defer cleanup()
@@ -113,6 +197,29 @@ func TestFirestoreStore_GetNotFound(t *testing.T) {
 	}
 }
 
+func TestFirestoreStore_Exists(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	exists, err := store.Exists(ctx, "user-exists")
+	if err != nil || exists {
+		t.Fatalf("expected false before create, got %v, %v", exists, err)
+	}
+
+	_, err = store.Create(ctx, "user-exists", CreateParams{
+		Firstname: "A", Lastname: "B", Email: "a@b.com", PhoneNumber: "+1", Terms: true,
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	exists, err = store.Exists(ctx, "user-exists")
+	if err != nil || !exists {
+		t.Fatalf("expected true after create, got %v, %v", exists, err)
+	}
+}
+
 func TestFirestoreStore_Update(t *testing.T) {
 	store, cleanup := newTestStore(t)
 	defer cleanup()
@@ -164,6 +271,46 @@ func TestFirestoreStore_Update(t *testing.T) {
 	}
 }
 
+func TestFirestoreStore_Upsert(t *testing.T) {
+	store, cleanup := newTestStore(t, WithFieldEncryption(newTestKeyring(t)))
+	defer cleanup()
+	ctx := context.Background()
+
+	first, created, err := store.Upsert(ctx, "user-upsert", testCreateParams())
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if !created {
+		t.Fatal("expected first upsert to create")
+	}
+	createdAt := first.CreatedAt.Truncate(time.Microsecond)
+
+	params := testCreateParams()
+	params.Lastname = "Smith"
+	params.Marketing = true
+	replaced, created, err := store.Upsert(ctx, "user-upsert", params)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if created {
+		t.Fatal("expected second upsert to replace")
+	}
+	if !replaced.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected CreatedAt %v to be kept, got %v", createdAt, replaced.CreatedAt)
+	}
+
+	got, err := store.Get(ctx, "user-upsert")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Lastname != "Smith" || !got.Marketing || got.Email != "john@example.com" {
+		t.Fatalf("expected replaced profile, got %+v", got)
+	}
+	if !got.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected stored CreatedAt %v, got %v", createdAt, got.CreatedAt)
+	}
+}
+
 func TestFirestoreStore_UpdateNotFound(t *testing.T) {
 	store, cleanup := newTestStore(t)
 	defer cleanup()
@@ -220,7 +367,7 @@ func TestFirestoreStore_Delete(t *testing.T) {
 		t.Fatalf("Create failed: %v", err)
 	}
 
-	if err := store.Delete(ctx, "user-del"); err != nil {
+	if err := store.Delete(ctx, "user-del", DeleteParams{}); err != nil {
 		t.Fatalf("Delete failed: %v", err)
 	}
 
@@ -235,12 +382,77 @@ func TestFirestoreStore_DeleteNotFound(t *testing.T) {
 	defer cleanup()
 	ctx := context.Background()
 
-	err := store.Delete(ctx, "nonexistent")
+	err := store.Delete(ctx, "nonexistent", DeleteParams{})
 	if !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
 }
 
+func TestFirestoreStore_Merge(t *testing.T) {
+	store, cleanup := newTestStore(t, WithFieldEncryption(newTestKeyring(t)))
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "user-src", CreateParams{
+		Firstname:   "Jane",
+		Lastname:    "Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+358401234567",
+		Terms:       true,
+	}); err != nil {
+		t.Fatalf("Create source failed: %v", err)
+	}
+	if _, err := store.Create(ctx, "user-dst", CreateParams{
+		Firstname: "Janet",
+		Lastname:  "Doe",
+		Email:     "janet@example.com",
+		Terms:     true,
+	}); err != nil {
+		t.Fatalf("Create target failed: %v", err)
+	}
+
+	merged, err := store.Merge(ctx, "user-src", "user-dst")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if merged.Email != "janet@example.com" || merged.PhoneNumber != "+358401234567" {
+		t.Fatalf("expected target email and source phone number, got %+v", merged)
+	}
+
+	got, err := store.Get(ctx, "user-dst")
+	if err != nil {
+		t.Fatalf("Get target failed: %v", err)
+	}
+	if got.PhoneNumber != "+358401234567" {
+		t.Fatalf("expected merged phone number to be stored, got %q", got.PhoneNumber)
+	}
+	if _, err = store.Get(ctx, "user-src"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected source to be gone, got %v", err)
+	}
+
+	var archived firestoreMergedProfile
+	if err = store.docs.Get(ctx, docPath(mergedCollection, "user-src"), &archived); err != nil {
+		t.Fatalf("expected archived source, got %v", err)
+	}
+	if archived.MergedInto != "user-dst" || archived.Profile.Email == "jane@example.com" {
+		t.Fatalf("expected encrypted archive merged into user-dst, got %+v", archived)
+	}
+}
+
+func TestFirestoreStore_MergeNotFound(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	params := CreateParams{Firstname: "Alice", Lastname: "Smith", Email: "alice@example.com"}
+	if _, err := store.Create(ctx, "user-dst", params); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := store.Merge(ctx, "missing", "user-dst"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestCategorizeError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -249,6 +461,8 @@ func TestCategorizeError(t *testing.T) {
 	}{
 		{"already exists", ErrAlreadyExists, "already_exists"},
 		{"not found", ErrNotFound, "not_found"},
+		{"same profile", ErrSameProfile, "same_profile"},
+		{"transient", status.Error(codes.Unavailable, "unavailable"), "transient"},
 		{"generic error", context.Canceled, "internal_error"},
 	}
 	for _, tt := range tests {
@@ -260,3 +474,156 @@ func TestCategorizeError(t *testing.T) {
 		})
 	}
 }
+
+func TestFirestoreStore_DeleteVersion(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "user-ver", testCreateParams()); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	// Read back so the version reflects Firestore's stored timestamp precision.
+	p, err := store.Get(ctx, "user-ver")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	err = store.Delete(ctx, "user-ver", DeleteParams{Versions: []string{"stale"}})
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch, got %v", err)
+	}
+
+	if err := store.Delete(ctx, "user-ver", DeleteParams{Versions: []string{p.Version()}}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+}
+
+func TestFirestoreStore_Fake_ErrorMapping(t *testing.T) {
+	ctx := context.Background()
+	name := "Renamed"
+	tests := []struct {
+		name string
+		op   func(*FirestoreStore) error
+		want error
+	}{
+		{"create duplicate", func(s *FirestoreStore) error {
+			_, err := s.Create(ctx, "user-1", testCreateParams())
+			return err
+		}, ErrAlreadyExists},
+		{"get missing", func(s *FirestoreStore) error {
+			_, err := s.Get(ctx, "missing")
+			return err
+		}, ErrNotFound},
+		{"update missing", func(s *FirestoreStore) error {
+			_, err := s.Update(ctx, "missing", UpdateParams{Firstname: &name})
+			return err
+		}, ErrNotFound},
+		{"delete missing", func(s *FirestoreStore) error {
+			return s.Delete(ctx, "missing", DeleteParams{})
+		}, ErrNotFound},
+		{"delete stale version", func(s *FirestoreStore) error {
+			return s.Delete(ctx, "user-1", DeleteParams{Versions: []string{"stale"}})
+		}, ErrVersionMismatch},
+		{"merge missing source", func(s *FirestoreStore) error {
+			_, err := s.Merge(ctx, "missing", "user-1")
+			return err
+		}, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := newFakeStore()
+			if _, err := store.Create(ctx, "user-1", testCreateParams()); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			if err := tt.op(store); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if _, err := store.Get(ctx, "user-1"); err != nil {
+				t.Fatalf("expected user-1 to be unchanged, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFirestoreStore_Fake_Lifecycle(t *testing.T) {
+	store, docs := newFakeStore(WithFieldEncryption(newTestKeyring(t)))
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	var raw firestoreProfile
+	if err := docs.Get(ctx, docPath(profilesCollection, "user-1"), &raw); err != nil {
+		t.Fatalf("failed to read raw document: %v", err)
+	}
+	if !fieldcrypt.IsEncrypted(raw.Email) {
+		t.Fatalf("expected email ciphertext at rest, got %q", raw.Email)
+	}
+
+	name := "Renamed"
+	updated, err := store.Update(ctx, "user-1", UpdateParams{Firstname: &name})
+	if err != nil || updated.Firstname != name {
+		t.Fatalf("expected updated firstname, got %+v, %v", updated, err)
+	}
+	if exists, err := store.Exists(ctx, "user-1"); err != nil || !exists {
+		t.Fatalf("expected user-1 to exist, got %v, %v", exists, err)
+	}
+
+	if err = store.Delete(ctx, "user-1", DeleteParams{Versions: []string{updated.Version()}}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err = store.Get(ctx, "user-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestFirestoreStore_Fake_TransientErrorsPassThrough(t *testing.T) {
+	store, docs := newFakeStore()
+	docs.err = status.Error(codes.Unavailable, "unavailable")
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "user-1"); !IsTransient(err) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected the transient Get error unchanged, got %v", err)
+	}
+	if _, err := store.Create(ctx, "user-1", testCreateParams()); !IsTransient(err) {
+		t.Fatalf("expected the transient Create error unchanged, got %v", err)
+	}
+	if err := store.Ready(ctx); !IsTransient(err) {
+		t.Fatalf("expected the transient Ready error unchanged, got %v", err)
+	}
+}
+
+func TestFirestoreStore_Fake_BudgetExhausted(t *testing.T) {
+	store, docs := newFakeStore()
+	if _, err := store.Create(context.Background(), "user-1", testCreateParams()); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	docs.delay = 40 * time.Millisecond
+	ctx := budget.NewContext(context.Background(), budget.New(60*time.Millisecond))
+
+	if _, err := store.Get(ctx, "user-1"); err != nil {
+		t.Fatalf("expected the first call to fit the budget, got %v", err)
+	}
+
+	name := "Renamed"
+	_, err := store.Update(ctx, "user-1", UpdateParams{Firstname: &name})
+	if !errors.Is(err, budget.ErrExhausted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second call to exhaust the budget, got %v", err)
+	}
+
+	calls := docs.calls
+	if _, err = store.Get(ctx, "user-1"); !errors.Is(err, budget.ErrExhausted) {
+		t.Fatalf("expected ErrExhausted once the budget is spent, got %v", err)
+	}
+	if docs.calls != calls {
+		t.Fatal("expected no Firestore call after the budget is spent")
+	}
+
+	docs.delay = 0
+	p, err := store.Get(context.Background(), "user-1")
+	if err != nil || p.Firstname == name {
+		t.Fatalf("expected the timed-out update not to be written, got %+v, %v", p, err)
+	}
+}
@@ -2,7 +2,6 @@ package profile
 
 import (
 	"context"
-	"strings"
 	"sync"
 	"time"
 )
@@ -11,14 +10,27 @@ import (
 type MockStore struct {
 	mu       sync.RWMutex
 	profiles map[string]*Profile
+	merged   map[string]MergedProfile
+}
+
+// MergedProfile is the archived copy of a profile merged into another.
+type MergedProfile struct {
+	Profile    *Profile
+	MergedInto string
+	MergedAt   time.Time
 }
 
 // NewMockStore creates a new in-memory profile store.
 func NewMockStore() *MockStore {
-	return &MockStore{profiles: make(map[string]*Profile)}
+	return &MockStore{profiles: make(map[string]*Profile), merged: make(map[string]MergedProfile)}
 }
 
 func (m *MockStore) Create(_ context.Context, userID string, params CreateParams) (*Profile, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -26,18 +38,7 @@ func (m *MockStore) Create(_ context.Context, userID string, params CreateParams
 		return nil, ErrAlreadyExists
 	}
 
-	now := time.Now().UTC()
-	p := &Profile{
-		ID:          userID,
-		Firstname:   params.Firstname,
-		Lastname:    params.Lastname,
-		Email:       strings.ToLower(strings.TrimSpace(params.Email)),
-		PhoneNumber: strings.TrimSpace(params.PhoneNumber),
-		Marketing:   params.Marketing,
-		Terms:       params.Terms,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
+	p := NewProfile(userID, params, time.Now().UTC())
 	m.profiles[userID] = p
 
 	return p, nil
@@ -55,7 +56,20 @@ func (m *MockStore) Get(_ context.Context, userID string) (*Profile, error) {
 	return p, nil
 }
 
+func (m *MockStore) Exists(_ context.Context, userID string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.profiles[userID]
+	return ok, nil
+}
+
 func (m *MockStore) Update(_ context.Context, userID string, params UpdateParams) (*Profile, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -64,37 +78,83 @@ func (m *MockStore) Update(_ context.Context, userID string, params UpdateParams
 		return nil, ErrNotFound
 	}
 
-	if params.Firstname != nil {
-		p.Firstname = *params.Firstname
-	}
-	if params.Lastname != nil {
-		p.Lastname = *params.Lastname
-	}
-	if params.Email != nil {
-		p.Email = strings.ToLower(strings.TrimSpace(*params.Email))
-	}
-	if params.PhoneNumber != nil {
-		p.PhoneNumber = strings.TrimSpace(*params.PhoneNumber)
+	ApplyUpdate(p, params, time.Now().UTC())
+
+	return p, nil
+}
+
+func (m *MockStore) Upsert(_ context.Context, userID string, params CreateParams) (*Profile, bool, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, false, err
 	}
-	if params.Marketing != nil {
-		p.Marketing = *params.Marketing
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	if existing, ok := m.profiles[userID]; ok {
+		p := ReplaceProfile(existing, params, now)
+		m.profiles[userID] = p
+		return p, false, nil
 	}
-	p.UpdatedAt = time.Now().UTC()
 
-	return p, nil
+	p := NewProfile(userID, params, now)
+	m.profiles[userID] = p
+
+	return p, true, nil
 }
 
-func (m *MockStore) Delete(_ context.Context, userID string) error {
+func (m *MockStore) Delete(_ context.Context, userID string, params DeleteParams) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, ok := m.profiles[userID]; !ok {
+	p, ok := m.profiles[userID]
+	if !ok {
 		return ErrNotFound
 	}
+	if !params.matches(p) {
+		return ErrVersionMismatch
+	}
 
 	delete(m.profiles, userID)
 
 	return nil
 }
 
+func (m *MockStore) Merge(_ context.Context, sourceUID, targetUID string) (*Profile, error) {
+	if sourceUID == targetUID {
+		return nil, ErrSameProfile
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source, ok := m.profiles[sourceUID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	target, ok := m.profiles[targetUID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	p := MergeProfiles(source, target, now)
+	m.profiles[targetUID] = p
+	m.merged[sourceUID] = MergedProfile{Profile: source, MergedInto: targetUID, MergedAt: now}
+	delete(m.profiles, sourceUID)
+
+	return p, nil
+}
+
+// Merged returns the archived copy of sourceUID after a Merge.
+func (m *MockStore) Merged(sourceUID string) (MergedProfile, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mp, ok := m.merged[sourceUID]
+	return mp, ok
+}
+
 var _ Service = (*MockStore)(nil)
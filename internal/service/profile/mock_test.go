@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestMockStore_UpdateAllFields(t *testing.T) {
@@ -124,7 +125,7 @@ func TestMockStore_DeleteNotFound(t *testing.T) {
 	store := NewMockStore()
 	ctx := context.Background()
 
-	err := store.Delete(ctx, "nonexistent")
+	err := store.Delete(ctx, "nonexistent", DeleteParams{})
 	if !errors.Is(err, ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -140,6 +141,28 @@ func TestMockStore_GetNotFound(t *testing.T) {
 	}
 }
 
+func TestMockStore_Exists(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	exists, err := store.Exists(ctx, "user-exists")
+	if err != nil || exists {
+		t.Fatalf("expected false before create, got %v, %v", exists, err)
+	}
+
+	_, err = store.Create(ctx, "user-exists", CreateParams{
+		Firstname: "A", Lastname: "B", Email: "a@b.com", PhoneNumber: "+1", Terms: true,
+	})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	exists, err = store.Exists(ctx, "user-exists")
+	if err != nil || !exists {
+		t.Fatalf("expected true after create, got %v, %v", exists, err)
+	}
+}
+
 func TestMockStore_DuplicateCreate(t *testing.T) {
 	store := NewMockStore()
 	ctx := context.Background()
@@ -157,3 +180,115 @@ func TestMockStore_DuplicateCreate(t *testing.T) {
 		t.Fatalf("expected ErrAlreadyExists, got %v", err)
 	}
 }
+
+func TestMockStore_DeleteVersion(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	p, err := store.Create(ctx, "user-1", testCreateParams())
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	err = store.Delete(ctx, "user-1", DeleteParams{Versions: []string{"stale"}})
+	if !errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected ErrVersionMismatch, got %v", err)
+	}
+	if _, err := store.Get(ctx, "user-1"); err != nil {
+		t.Fatalf("expected profile to remain, got %v", err)
+	}
+
+	if err := store.Delete(ctx, "user-1", DeleteParams{Versions: []string{p.Version()}}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+}
+
+func TestMockStore_Upsert(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	first, created, err := store.Upsert(ctx, "user-1", testCreateParams())
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if !created {
+		t.Fatal("expected first upsert to create")
+	}
+
+	params := testCreateParams()
+	params.Firstname = "Jane"
+	params.Email = "  Jane@Example.com "
+	replaced, created, err := store.Upsert(ctx, "user-1", params)
+	if err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if created {
+		t.Fatal("expected second upsert to replace")
+	}
+	if replaced.Firstname != "Jane" || replaced.Email != "jane@example.com" {
+		t.Fatalf("expected replaced and normalized fields, got %+v", replaced)
+	}
+	if !replaced.CreatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("expected CreatedAt %v to be kept, got %v", first.CreatedAt, replaced.CreatedAt)
+	}
+	if replaced.UpdatedAt.Before(first.UpdatedAt) {
+		t.Fatalf("expected UpdatedAt to advance, got %v", replaced.UpdatedAt)
+	}
+}
+
+func TestMockStore_Merge(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	source, err := store.Create(ctx, "source", CreateParams{
+		Firstname:   "Jane",
+		Lastname:    "Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+358401234567",
+		Marketing:   true,
+		Terms:       true,
+	})
+	if err != nil {
+		t.Fatalf("Create source failed: %v", err)
+	}
+	source.CreatedAt = source.CreatedAt.Add(-time.Hour)
+	if _, err = store.Create(ctx, "target", CreateParams{
+		Firstname: "Janet",
+		Lastname:  "Doe",
+		Email:     "janet@example.com",
+		Terms:     true,
+	}); err != nil {
+		t.Fatalf("Create target failed: %v", err)
+	}
+
+	merged, err := store.Merge(ctx, "source", "target")
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if merged.ID != "target" || merged.Firstname != "Janet" || merged.Email != "janet@example.com" {
+		t.Fatalf("expected target values to win, got %+v", merged)
+	}
+	if merged.PhoneNumber != "+358401234567" {
+		t.Fatalf("expected phone number from source, got %q", merged.PhoneNumber)
+	}
+	if merged.Marketing {
+		t.Fatal("expected marketing consent to stay with the target")
+	}
+	if !merged.CreatedAt.Equal(source.CreatedAt) {
+		t.Fatalf("expected the earlier CreatedAt %v, got %v", source.CreatedAt, merged.CreatedAt)
+	}
+
+	if _, err = store.Get(ctx, "source"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected source to be gone, got %v", err)
+	}
+	if archived, ok := store.Merged("source"); !ok || archived.MergedInto != "target" {
+		t.Fatalf("expected source archived as merged into target, got %+v", archived)
+	}
+
+	if _, err = store.Merge(ctx, "source", "target"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound merging a merged source, got %v", err)
+	}
+	if _, err = store.Merge(ctx, "target", "target"); !errors.Is(err, ErrSameProfile) {
+		t.Fatalf("expected ErrSameProfile, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package profile
+
+import (
+	"cmp"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+// NormalizeText converts s to Unicode NFC, strips control and invisible
+// formatting characters (zero-width spaces, byte order marks, bidi controls),
+// and trims surrounding whitespace. Zero-width joiners are kept because some
+// scripts require them.
+func NormalizeText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\u200c' || r == '\u200d':
+			return r
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			return -1
+		default:
+			return r
+		}
+	}, norm.NFC.String(s))
+	return strings.TrimSpace(s)
+}
+
+// NormalizeEmail normalizes an email address and lowercases it.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(NormalizeText(email))
+}
+
+// NormalizePhoneNumber trims whitespace from a phone number.
+func NormalizePhoneNumber(phone string) string {
+	return strings.TrimSpace(phone)
+}
+
+// Normalize returns params with all fields normalized. It returns a
+// *validate.ValidationError when a required field is empty afterwards.
+func (p CreateParams) Normalize() (CreateParams, error) {
+	var fields []validate.FieldError
+	p.Firstname = normalizeField(p.Firstname, "firstname", NormalizeText, &fields)
+	p.Lastname = normalizeField(p.Lastname, "lastname", NormalizeText, &fields)
+	p.Email = normalizeField(p.Email, "email", NormalizeEmail, &fields)
+	p.PhoneNumber = NormalizePhoneNumber(p.PhoneNumber)
+	return p, validationError(fields)
+}
+
+// Normalize returns params with all provided fields normalized. It returns a
+// *validate.ValidationError when a provided field is empty afterwards.
+func (p UpdateParams) Normalize() (UpdateParams, error) {
+	var fields []validate.FieldError
+	p.Firstname = normalizeOptional(p.Firstname, "firstname", NormalizeText, &fields)
+	p.Lastname = normalizeOptional(p.Lastname, "lastname", NormalizeText, &fields)
+	p.Email = normalizeOptional(p.Email, "email", NormalizeEmail, &fields)
+	if p.PhoneNumber != nil {
+		phone := NormalizePhoneNumber(*p.PhoneNumber)
+		p.PhoneNumber = &phone
+	}
+	return p, validationError(fields)
+}
+
+func normalizeField(v, name string, fn func(string) string, fields *[]validate.FieldError) string {
+	out := fn(v)
+	if out == "" {
+		*fields = append(*fields, validate.FieldError{
+			Field:   name,
+			Message: name + " must contain visible characters",
+			Value:   v,
+		})
+	}
+	return out
+}
+
+func normalizeOptional(v *string, name string, fn func(string) string, fields *[]validate.FieldError) *string {
+	if v == nil {
+		return nil
+	}
+	out := normalizeField(*v, name, fn, fields)
+	return &out
+}
+
+func validationError(fields []validate.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return &validate.ValidationError{Message: "validation failed", Fields: fields}
+}
+
+// NewProfile builds a profile from create params normalized with
+// CreateParams.Normalize.
+func NewProfile(userID string, params CreateParams, now time.Time) *Profile {
+	return &Profile{
+		ID:          userID,
+		Firstname:   params.Firstname,
+		Lastname:    params.Lastname,
+		Email:       params.Email,
+		PhoneNumber: params.PhoneNumber,
+		Marketing:   params.Marketing,
+		Terms:       params.Terms,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// ReplaceProfile builds the profile that replaces existing with params,
+// normalized with CreateParams.Normalize, keeping its ID and CreatedAt.
+func ReplaceProfile(existing *Profile, params CreateParams, now time.Time) *Profile {
+	p := NewProfile(existing.ID, params, now)
+	p.CreatedAt = existing.CreatedAt
+	return p
+}
+
+// MergeProfiles builds the profile that results from merging source into
+// target. Target values win, except that empty names, email, and phone number
+// are filled from source. Terms stay accepted if either profile accepted them,
+// while Marketing keeps the target's consent. CreatedAt is the earlier of the
+// two.
+func MergeProfiles(source, target *Profile, now time.Time) *Profile {
+	p := *target
+	p.Firstname = cmp.Or(target.Firstname, source.Firstname)
+	p.Lastname = cmp.Or(target.Lastname, source.Lastname)
+	p.Email = cmp.Or(target.Email, source.Email)
+	p.PhoneNumber = cmp.Or(target.PhoneNumber, source.PhoneNumber)
+	p.Terms = target.Terms || source.Terms
+	if source.CreatedAt.Before(target.CreatedAt) {
+		p.CreatedAt = source.CreatedAt
+	}
+	p.UpdatedAt = now
+	return &p
+}
+
+// ApplyUpdate applies the non-nil fields of params, normalized with
+// UpdateParams.Normalize, to p and sets UpdatedAt to now.
+func ApplyUpdate(p *Profile, params UpdateParams, now time.Time) {
+	if params.Firstname != nil {
+		p.Firstname = *params.Firstname
+	}
+	if params.Lastname != nil {
+		p.Lastname = *params.Lastname
+	}
+	if params.Email != nil {
+		p.Email = *params.Email
+	}
+	if params.PhoneNumber != nil {
+		p.PhoneNumber = *params.PhoneNumber
+	}
+	if params.Marketing != nil {
+		p.Marketing = *params.Marketing
+	}
+	p.UpdatedAt = now
+}
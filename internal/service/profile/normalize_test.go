@@ -0,0 +1,110 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+func testCreateParams() CreateParams {
+	return CreateParams{
+		Firstname:   "John",
+		Lastname:    "Doe",
+		Email:       "john@example.com",
+		PhoneNumber: "+358401234567",
+		Terms:       true,
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"NFD to NFC", "Jose\u0301", "Jos\u00e9"},
+		{"already NFC", "Jos\u00e9", "Jos\u00e9"},
+		{"zero-width space", "Jo\u200bhn", "John"},
+		{"byte order mark", "\ufeffJohn", "John"},
+		{"bidi override", "\u202eJohn", "John"},
+		{"control characters", "Jo\x00hn\t", "John"},
+		{"zero-width joiner kept", "a\u200db", "a\u200db"},
+		{"surrounding whitespace", "  John  ", "John"},
+		{"only invisible", "\u200b\u200b", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.in); got != tt.want {
+				t.Fatalf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	if got := NormalizeEmail(" John\u200b@Example.COM "); got != "john@example.com" {
+		t.Fatalf("expected john@example.com, got %q", got)
+	}
+}
+
+func TestMockStore_CreateStoresNFC(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	params := testCreateParams()
+	params.Firstname = "Jose\u0301"
+	if _, err := store.Create(ctx, "user-1", params); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	p, err := store.Get(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if p.Firstname != "Jos\u00e9" {
+		t.Fatalf("expected NFC firstname, got %q", p.Firstname)
+	}
+}
+
+func TestMockStore_CreateRejectsInvisibleName(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	params := testCreateParams()
+	params.Lastname = "\u200b"
+	_, err := store.Create(ctx, "user-1", params)
+
+	var ve *validate.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if len(ve.Fields) != 1 || ve.Fields[0].Field != "lastname" {
+		t.Fatalf("expected lastname field error, got %+v", ve.Fields)
+	}
+	if _, err := store.Get(ctx, "user-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected nothing stored, got %v", err)
+	}
+}
+
+func TestMockStore_UpdateRejectsInvisibleName(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	name := "\u200b\u200b"
+	_, err := store.Update(ctx, "user-1", UpdateParams{Firstname: &name})
+	var ve *validate.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	p, _ := store.Get(ctx, "user-1")
+	if p.Firstname != "John" {
+		t.Fatalf("expected firstname unchanged, got %q", p.Firstname)
+	}
+}
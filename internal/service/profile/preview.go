@@ -0,0 +1,49 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PreviewNew returns the profile that Create would store for a user without a
+// profile. Unlike PreviewCreate it never reads the store.
+func PreviewNew(userID string, params CreateParams) (*Profile, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	return NewProfile(userID, params, time.Now().UTC()), nil
+}
+
+// PreviewCreate returns the profile that Create would store without writing it.
+// It returns ErrAlreadyExists when the user already has a profile.
+func PreviewCreate(ctx context.Context, svc Service, userID string, params CreateParams) (*Profile, error) {
+	p, err := PreviewNew(userID, params)
+	if err != nil {
+		return nil, err
+	}
+	_, err = svc.Get(ctx, userID)
+	if err == nil {
+		return nil, ErrAlreadyExists
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PreviewUpdate returns the profile that Update would store without writing it.
+func PreviewUpdate(ctx context.Context, svc Service, userID string, params UpdateParams) (*Profile, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	current, err := svc.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	p := *current
+	ApplyUpdate(&p, params, time.Now().UTC())
+	return &p, nil
+}
@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPreviewCreate(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	p, err := PreviewCreate(ctx, store, "user-1", CreateParams{
+		Firstname:   "John",
+		Lastname:    "Doe",
+		Email:       "  John@Example.com ",
+		PhoneNumber: " +358401234567 ",
+		Terms:       true,
+	})
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if p.Email != "john@example.com" {
+		t.Fatalf("expected normalized email, got %q", p.Email)
+	}
+	if p.PhoneNumber != "+358401234567" {
+		t.Fatalf("expected trimmed phone, got %q", p.PhoneNumber)
+	}
+	if _, err := store.Get(ctx, "user-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected nothing persisted, got %v", err)
+	}
+}
+
+func TestPreviewNew(t *testing.T) {
+	p, err := PreviewNew("user-1", CreateParams{
+		Firstname:   " John ",
+		Lastname:    "Doe",
+		Email:       "John@Example.com",
+		PhoneNumber: "+358401234567",
+		Terms:       true,
+	})
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if p.ID != "user-1" || p.Email != "john@example.com" || p.Firstname != "John" {
+		t.Fatalf("expected a normalized profile for user-1, got %+v", p)
+	}
+}
+
+func TestPreviewCreate_AlreadyExists(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if _, err := PreviewCreate(ctx, store, "user-1", testCreateParams()); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+}
+
+func TestPreviewUpdate(t *testing.T) {
+	store := NewMockStore()
+	ctx := context.Background()
+
+	if _, err := store.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	email := " Jane@Example.com"
+	p, err := PreviewUpdate(ctx, store, "user-1", UpdateParams{Email: &email})
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if p.Email != "jane@example.com" {
+		t.Fatalf("expected normalized email, got %q", p.Email)
+	}
+	if p.Firstname != "John" {
+		t.Fatalf("expected unchanged firstname, got %q", p.Firstname)
+	}
+
+	stored, err := store.Get(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if stored.Email != "john@example.com" {
+		t.Fatalf("expected stored email unchanged, got %q", stored.Email)
+	}
+}
+
+func TestPreviewUpdate_NotFound(t *testing.T) {
+	_, err := PreviewUpdate(context.Background(), NewMockStore(), "missing", UpdateParams{})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
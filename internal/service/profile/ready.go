@@ -0,0 +1,9 @@
+package profile
+
+import "context"
+
+// Ready runs the profile list query with limit 1 so that an unreachable
+// database fails readiness instead of live requests.
+func (s *FirestoreStore) Ready(ctx context.Context) error {
+	return s.docs.Probe(ctx, profilesCollection, "updated_at")
+}
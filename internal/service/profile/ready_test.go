@@ -0,0 +1,15 @@
+package profile
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFirestoreStore_Ready(t *testing.T) {
+	store, cleanup := newTestStore(t)
+	defer cleanup()
+
+	if err := store.Ready(context.Background()); err != nil {
+		t.Fatalf("expected ready, got %v", err)
+	}
+}
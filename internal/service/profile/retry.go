@@ -0,0 +1,177 @@
+package profile
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	"github.com/janisto/echo-playground/internal/platform/timing"
+)
+
+// Retry defaults applied when RetryConfig fields are zero.
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 50 * time.Millisecond
+	DefaultRetryMaxDelay  = time.Second
+)
+
+// RetryConfig configures RetryService backoff.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts including the first.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles per attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between attempts.
+	MaxDelay time.Duration
+}
+
+// RetryService decorates a Service, retrying operations that fail with
+// transient errors using exponential backoff with jitter. Domain errors,
+// context cancellation, and other failures are returned immediately, and no
+// retry is attempted when the backoff would exceed the context deadline.
+//
+// Create and Merge are not idempotent: an attempt that commits but reports a
+// transient error would be answered by ErrAlreadyExists or ErrNotFound on
+// retry, so both are attempted exactly once. Aborted is left to the
+// transaction runner, which already retries contention.
+type RetryService struct {
+	next Service
+	cfg  RetryConfig
+}
+
+// NewRetryService wraps next with retry behavior.
+func NewRetryService(next Service, cfg RetryConfig) *RetryService {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultRetryAttempts
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultRetryBaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultRetryMaxDelay
+	}
+	return &RetryService{next: next, cfg: cfg}
+}
+
+func (s *RetryService) Create(ctx context.Context, userID string, params CreateParams) (*Profile, error) {
+	return withRetry(ctx, s.once(), "create", func() (*Profile, error) {
+		return s.next.Create(ctx, userID, params)
+	})
+}
+
+func (s *RetryService) Get(ctx context.Context, userID string) (*Profile, error) {
+	return withRetry(ctx, s.cfg, "get", func() (*Profile, error) {
+		return s.next.Get(ctx, userID)
+	})
+}
+
+func (s *RetryService) Exists(ctx context.Context, userID string) (bool, error) {
+	return withRetry(ctx, s.cfg, "exists", func() (bool, error) {
+		return s.next.Exists(ctx, userID)
+	})
+}
+
+func (s *RetryService) Update(ctx context.Context, userID string, params UpdateParams) (*Profile, error) {
+	return withRetry(ctx, s.cfg, "update", func() (*Profile, error) {
+		return s.next.Update(ctx, userID, params)
+	})
+}
+
+func (s *RetryService) Upsert(ctx context.Context, userID string, params CreateParams) (*Profile, bool, error) {
+	type result struct {
+		profile *Profile
+		created bool
+	}
+	r, err := withRetry(ctx, s.cfg, "upsert", func() (result, error) {
+		p, created, err := s.next.Upsert(ctx, userID, params)
+		return result{p, created}, err
+	})
+	return r.profile, r.created, err
+}
+
+func (s *RetryService) Delete(ctx context.Context, userID string, params DeleteParams) error {
+	_, err := withRetry(ctx, s.cfg, "delete", func() (struct{}, error) {
+		return struct{}{}, s.next.Delete(ctx, userID, params)
+	})
+	return err
+}
+
+func (s *RetryService) Merge(ctx context.Context, sourceUID, targetUID string) (*Profile, error) {
+	return withRetry(ctx, s.once(), "merge", func() (*Profile, error) {
+		return s.next.Merge(ctx, sourceUID, targetUID)
+	})
+}
+
+// once returns a config that records timing without retrying.
+func (s *RetryService) once() RetryConfig {
+	cfg := s.cfg
+	cfg.MaxAttempts = 1
+	return cfg
+}
+
+// IsTransient reports whether err is a gRPC error that may succeed on retry.
+func IsTransient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether withRetry should try again after err. Aborted is
+// excluded because RunTransaction has already retried it.
+func retryable(err error) bool {
+	return IsTransient(err) && status.Code(err) != codes.Aborted
+}
+
+func withRetry[T any](ctx context.Context, cfg RetryConfig, op string, fn func() (T, error)) (T, error) {
+	rec := timing.FromContext(ctx)
+	rec.Start("db")
+	defer rec.Stop("db")
+
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || !retryable(err) || attempt >= cfg.MaxAttempts || ctx.Err() != nil {
+			return result, err
+		}
+
+		delay := backoff(cfg, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return result, err
+		}
+
+		applog.LogWarn(ctx, "retrying transient service error",
+			slog.String("operation", op),
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the retry following attempt, using
+// exponential growth capped at MaxDelay with equal jitter.
+func backoff(cfg RetryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay
+	for i := 1; i < attempt && delay < cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, cfg.MaxDelay)
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+var _ Service = (*RetryService)(nil)
@@ -0,0 +1,279 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/janisto/echo-playground/internal/platform/timing"
+)
+
+// flakyService fails each operation with err until failures are exhausted,
+// then delegates to the embedded store.
+type flakyService struct {
+	Service
+	err      error
+	failures int
+	calls    int
+}
+
+func (s *flakyService) fail() error {
+	s.calls++
+	if s.failures > 0 {
+		s.failures--
+		return s.err
+	}
+	return nil
+}
+
+func (s *flakyService) Create(ctx context.Context, userID string, params CreateParams) (*Profile, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return s.Service.Create(ctx, userID, params)
+}
+
+func (s *flakyService) Get(ctx context.Context, userID string) (*Profile, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return s.Service.Get(ctx, userID)
+}
+
+func (s *flakyService) Upsert(ctx context.Context, userID string, params CreateParams) (*Profile, bool, error) {
+	if err := s.fail(); err != nil {
+		return nil, false, err
+	}
+	return s.Service.Upsert(ctx, userID, params)
+}
+
+func (s *flakyService) Merge(ctx context.Context, sourceUID, targetUID string) (*Profile, error) {
+	if err := s.fail(); err != nil {
+		return nil, err
+	}
+	return s.Service.Merge(ctx, sourceUID, targetUID)
+}
+
+func (s *flakyService) Delete(ctx context.Context, userID string, params DeleteParams) error {
+	if err := s.fail(); err != nil {
+		return err
+	}
+	return s.Service.Delete(ctx, userID, params)
+}
+
+func testRetryConfig() RetryConfig {
+	return RetryConfig{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+}
+
+func TestRetryService_SucceedsAfterTransientFailures(t *testing.T) {
+	flaky := &flakyService{
+		Service: NewMockStore(),
+		err:     status.Error(codes.Unavailable, "unavailable"),
+	}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	if _, err := svc.Create(context.Background(), "user-1", testCreateParams()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	flaky.calls = 0
+	flaky.failures = 2
+
+	p, err := svc.Get(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if p.Firstname != "John" {
+		t.Fatalf("expected firstname John, got %q", p.Firstname)
+	}
+	if flaky.calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_Upsert(t *testing.T) {
+	flaky := &flakyService{
+		Service:  NewMockStore(),
+		err:      status.Error(codes.ResourceExhausted, "exhausted"),
+		failures: 1,
+	}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	p, created, err := svc.Upsert(context.Background(), "user-1", testCreateParams())
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if !created || p.Firstname != "John" {
+		t.Fatalf("expected created profile, got created=%v %+v", created, p)
+	}
+	if flaky.calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_RecordsDBTiming(t *testing.T) {
+	rec := timing.NewRecorder()
+	ctx := timing.NewContext(context.Background(), rec)
+	svc := NewRetryService(NewMockStore(), testRetryConfig())
+
+	if _, err := svc.Create(ctx, "user-1", testCreateParams()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(rec.Header(), "db;dur=") {
+		t.Fatalf("expected db segment, got %q", rec.Header())
+	}
+}
+
+func TestRetryService_GivesUpAfterMaxAttempts(t *testing.T) {
+	transient := status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	flaky := &flakyService{Service: NewMockStore(), err: transient, failures: 10}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	_, err := svc.Get(context.Background(), "user-1")
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expected last transient error, got %v", err)
+	}
+	if flaky.calls != 4 {
+		t.Fatalf("expected 4 calls, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_DoesNotRetryNonTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"not found", ErrNotFound},
+		{"already exists", ErrAlreadyExists},
+		{"canceled", context.Canceled},
+		{"permission denied", status.Error(codes.PermissionDenied, "denied")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyService{Service: NewMockStore(), err: tt.err, failures: 1}
+			svc := NewRetryService(flaky, testRetryConfig())
+
+			err := svc.Delete(context.Background(), "user-1", DeleteParams{})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if flaky.calls != 1 {
+				t.Fatalf("expected 1 call, got %d", flaky.calls)
+			}
+		})
+	}
+}
+
+func TestRetryService_DoesNotRetryCreate(t *testing.T) {
+	flaky := &flakyService{
+		Service:  NewMockStore(),
+		err:      status.Error(codes.Unavailable, "unavailable"),
+		failures: 1,
+	}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	if _, err := svc.Create(context.Background(), "user-1", testCreateParams()); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+	if flaky.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_DoesNotRetryMerge(t *testing.T) {
+	flaky := &flakyService{
+		Service:  NewMockStore(),
+		err:      status.Error(codes.Unavailable, "unavailable"),
+		failures: 1,
+	}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	if _, err := svc.Merge(context.Background(), "user-1", "user-2"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+	if flaky.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_LeavesAbortedToTransactionRunner(t *testing.T) {
+	flaky := &flakyService{
+		Service:  NewMockStore(),
+		err:      status.Error(codes.Aborted, "aborted"),
+		failures: 1,
+	}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	if _, err := svc.Get(context.Background(), "user-1"); status.Code(err) != codes.Aborted {
+		t.Fatalf("expected aborted error, got %v", err)
+	}
+	if flaky.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_StopsWhenContextCanceled(t *testing.T) {
+	flaky := &flakyService{
+		Service:  NewMockStore(),
+		err:      status.Error(codes.Unavailable, "unavailable"),
+		failures: 10,
+	}
+	svc := NewRetryService(flaky, testRetryConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := svc.Get(ctx, "user-1"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+	if flaky.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", flaky.calls)
+	}
+}
+
+func TestRetryService_RespectsDeadline(t *testing.T) {
+	flaky := &flakyService{
+		Service:  NewMockStore(),
+		err:      status.Error(codes.Unavailable, "unavailable"),
+		failures: 10,
+	}
+	svc := NewRetryService(flaky, RetryConfig{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := svc.Get(ctx, "user-1"); err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected no wait beyond deadline, took %v", elapsed)
+	}
+	if flaky.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", flaky.calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{10, time.Second},
+		{100, time.Second},
+	}
+	for _, tt := range tests {
+		d := backoff(cfg, tt.attempt)
+		if d < tt.max/2 || d > tt.max {
+			t.Fatalf("attempt %d: expected delay in [%v, %v], got %v", tt.attempt, tt.max/2, tt.max, d)
+		}
+	}
+}
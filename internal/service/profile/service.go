@@ -3,13 +3,17 @@ package profile
 import (
 	"context"
 	"errors"
+	"slices"
+	"strconv"
 	"time"
 )
 
 // Service errors.
 var (
-	ErrNotFound      = errors.New("profile not found")
-	ErrAlreadyExists = errors.New("profile already exists")
+	ErrNotFound        = errors.New("profile not found")
+	ErrAlreadyExists   = errors.New("profile already exists")
+	ErrVersionMismatch = errors.New("profile version mismatch")
+	ErrSameProfile     = errors.New("cannot merge a profile into itself")
 )
 
 // Profile represents stored profile data.
@@ -25,6 +29,12 @@ type Profile struct {
 	UpdatedAt   time.Time
 }
 
+// Version returns an opaque identifier that changes whenever the profile is
+// written. It uses microsecond precision to match Firestore timestamps.
+func (p *Profile) Version() string {
+	return strconv.FormatInt(p.UpdatedAt.UnixMicro(), 36)
+}
+
 // CreateParams for creating a profile.
 type CreateParams struct {
 	Firstname   string
@@ -44,14 +54,41 @@ type UpdateParams struct {
 	Marketing   *bool
 }
 
+// DeleteParams for deleting a profile.
+type DeleteParams struct {
+	// Versions, when non-empty, makes the delete conditional: it fails with
+	// ErrVersionMismatch unless the stored profile's Version is listed.
+	Versions []string
+}
+
+// matches reports whether p satisfies the delete precondition.
+func (d DeleteParams) matches(p *Profile) bool {
+	return len(d.Versions) == 0 || slices.Contains(d.Versions, p.Version())
+}
+
 // Service defines profile operations.
 //
-// Implementations must normalize input data:
-//   - Email: lowercase and trim whitespace
+// Implementations must normalize params with CreateParams.Normalize and
+// UpdateParams.Normalize before storing and return the resulting
+// *validate.ValidationError when a field is empty after normalization:
+//   - Firstname, Lastname: NFC, strip control and formatting characters, trim
+//   - Email: as above, then lowercase
 //   - PhoneNumber: trim whitespace
 type Service interface {
 	Create(ctx context.Context, userID string, params CreateParams) (*Profile, error)
 	Get(ctx context.Context, userID string) (*Profile, error)
+	// Exists reports whether userID has a profile without loading it.
+	Exists(ctx context.Context, userID string) (bool, error)
 	Update(ctx context.Context, userID string, params UpdateParams) (*Profile, error)
-	Delete(ctx context.Context, userID string) error
+	// Upsert creates the profile or atomically replaces every field of an
+	// existing one, keeping its CreatedAt. created reports whether the
+	// profile did not exist before.
+	Upsert(ctx context.Context, userID string, params CreateParams) (p *Profile, created bool, err error)
+	Delete(ctx context.Context, userID string, params DeleteParams) error
+	// Merge atomically folds the profile of sourceUID into the profile of
+	// targetUID with MergeProfiles, archives the source as merged into the
+	// target, and removes it from the live profiles. It returns the merged
+	// target, ErrNotFound when either profile is missing, and ErrSameProfile
+	// when both IDs are equal.
+	Merge(ctx context.Context, sourceUID, targetUID string) (*Profile, error)
 }
@@ -5,6 +5,8 @@ import (
 	"net"
 	"os"
 	"testing"
+
+	"cloud.google.com/go/firestore"
 )
 
 // RequireEmulator skips the test if the Firebase Emulator is not running.
@@ -28,3 +30,18 @@ func RequireEmulator(t *testing.T) {
 
 // EmulatorProjectID returns the project ID used for emulator tests.
 const EmulatorProjectID = "demo-test-project"
+
+// NewFirestoreClient returns a Firestore client connected to the emulator,
+// skipping the test when it is unavailable. The client is closed when the
+// test finishes.
+func NewFirestoreClient(t *testing.T) *firestore.Client {
+	t.Helper()
+	RequireEmulator(t)
+
+	client, err := firestore.NewClient(context.Background(), EmulatorProjectID)
+	if err != nil {
+		t.Fatalf("failed to create firestore client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
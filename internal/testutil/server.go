@@ -0,0 +1,90 @@
+package testutil
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+
+	"github.com/janisto/echo-playground/internal/http/health"
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	applog "github.com/janisto/echo-playground/internal/platform/logging"
+	appmiddleware "github.com/janisto/echo-playground/internal/platform/middleware"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+	"github.com/janisto/echo-playground/internal/platform/timing"
+	"github.com/janisto/echo-playground/internal/platform/validate"
+)
+
+// errAuthDisabled is returned by the verifier of servers built with
+// ServerOptions.Unauthenticated.
+var errAuthDisabled = errors.New("authentication disabled for test server")
+
+// ServerOptions configures NewTestServer.
+type ServerOptions struct {
+	// Middleware replaces DefaultMiddleware when non-nil. Pass an empty slice
+	// to run handlers without global middleware.
+	Middleware []echo.MiddlewareFunc
+	// Verifier authenticates protected routes. Defaults to a MockVerifier
+	// returning auth.TestUser.
+	Verifier auth.Verifier
+	// Unauthenticated makes the default verifier reject every token so
+	// protected routes respond with 401.
+	Unauthenticated bool
+	// Register mounts routes on the /v1 group using the resolved verifier.
+	// Services such as a profile mock or emulator-backed store are captured
+	// by the closure.
+	Register func(v1 *echo.Group, verifier auth.Verifier)
+}
+
+// DefaultMiddleware returns the production middleware stack without access
+// logging, which would only add noise to test output.
+func DefaultMiddleware() []echo.MiddlewareFunc {
+	return []echo.MiddlewareFunc{
+		appmiddleware.Security("/api-docs"),
+		appmiddleware.Vary(),
+		appmiddleware.CORS(),
+		appmiddleware.RequestID(),
+		appmiddleware.UniqueHeaders(appmiddleware.UniqueHeadersConfig{}),
+		middleware.BodyLimit(appmiddleware.MaxBodyBytes),
+		appmiddleware.DecompressBody(appmiddleware.MaxBodyBytes),
+		applog.RequestLogger(),
+		appmiddleware.ValidUTF8(),
+		timing.ServerTiming(),
+		respond.FormatNegotiation(),
+		respond.AcceptCharset(),
+		respond.VersionNegotiation(),
+		respond.Recoverer(),
+	}
+}
+
+// NewTestServer builds an Echo instance wired like cmd/server: validator,
+// Problem Details error handler, trailing slash stripping, the middleware
+// stack, the health endpoint, and the routes added by opts.Register.
+func NewTestServer(opts ServerOptions) *echo.Echo {
+	e := echo.New()
+	e.Validator = validate.New()
+	e.HTTPErrorHandler = respond.NewHTTPErrorHandler()
+
+	e.Pre(appmiddleware.TrailingSlash(appmiddleware.TrailingSlashStrip))
+
+	mw := opts.Middleware
+	if mw == nil {
+		mw = DefaultMiddleware()
+	}
+	e.Use(mw...)
+
+	e.GET("/health", health.Handler)
+
+	verifier := opts.Verifier
+	if verifier == nil {
+		verifier = &auth.MockVerifier{User: auth.TestUser()}
+		if opts.Unauthenticated {
+			verifier = &auth.MockVerifier{Error: errAuthDisabled}
+		}
+	}
+
+	if opts.Register != nil {
+		opts.Register(e.Group("/v1", appmiddleware.NoBody(appmiddleware.NoBodyConfig{})), verifier)
+	}
+	return e
+}
@@ -0,0 +1,103 @@
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+
+	"github.com/janisto/echo-playground/internal/platform/auth"
+	"github.com/janisto/echo-playground/internal/platform/respond"
+)
+
+func registerSampleRoutes(v1 *echo.Group, verifier auth.Verifier) {
+	v1.GET("/ping", func(c *echo.Context) error {
+		return respond.Negotiate(c, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	v1.GET("/panic", func(*echo.Context) error {
+		panic("boom")
+	})
+	protected := v1.Group("", auth.Middleware(verifier))
+	protected.GET("/me", func(c *echo.Context) error {
+		user, err := auth.UserFromEchoContext(c)
+		if err != nil {
+			return respond.Error401("unauthorized")
+		}
+		return respond.Negotiate(c, http.StatusOK, map[string]string{"uid": user.UID})
+	})
+}
+
+func serve(e *echo.Echo, path string, authenticated bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authenticated {
+		req.Header.Set("Authorization", "Bearer test-token")
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNewTestServer_MiddlewareChain(t *testing.T) {
+	e := NewTestServer(ServerOptions{Register: registerSampleRoutes})
+
+	rec := serve(e, "/v1/ping", false)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Fatal("expected X-Request-ID from RequestID middleware")
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatal("expected security headers")
+	}
+}
+
+func TestNewTestServer_Recoverer(t *testing.T) {
+	e := NewTestServer(ServerOptions{Register: registerSampleRoutes})
+
+	rec := serve(e, "/v1/panic", false)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected application/problem+json, got %q", ct)
+	}
+}
+
+func TestNewTestServer_Auth(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ServerOptions
+		want int
+	}{
+		{"authenticated", ServerOptions{Register: registerSampleRoutes}, http.StatusOK},
+		{"unauthenticated", ServerOptions{Register: registerSampleRoutes, Unauthenticated: true}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(NewTestServer(tt.opts), "/v1/me", true)
+			if rec.Code != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestNewTestServer_CustomMiddleware(t *testing.T) {
+	e := NewTestServer(ServerOptions{
+		Middleware: []echo.MiddlewareFunc{},
+		Register:   registerSampleRoutes,
+	})
+
+	rec := serve(e, "/health", false)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("X-Request-ID") != "" {
+		t.Fatal("expected no X-Request-ID without default middleware")
+	}
+}